@@ -6,8 +6,12 @@ import (
 	pb "gateway/proto/taskpb"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type TaskController struct {
@@ -38,10 +42,10 @@ func (tc *TaskController) TasksCreate(c *gin.Context) {
 		return
 	}
 
-	resp, err := tc.GRPCClient.CreateTask(context.Background(), &req)
+	resp, err := tc.GRPCClient.CreateTask(userContext(c), &req)
 	if err != nil {
 		logger.Log(logger.LevelError, "Failed to create task", gin.H{"error": err.Error()})
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": err.Error()})
 		return
 	}
 
@@ -57,6 +61,9 @@ func (tc *TaskController) TasksCreate(c *gin.Context) {
 // @Param        title        query     string  false  "Filter by title"
 // @Param        creator_id   query     uint64  false  "Filter by creator ID"
 // @Param        performer_id query     uint64  false  "Filter by performer ID"
+// @Param        project_id   query     uint64  false  "Filter by project ID"
+// @Param        tenant_id    query     uint64  false  "Filter by tenant ID"
+// @Param        view         query     string  false  "Run a saved view; other filters override it"
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]string
 // @Failure      500  {object}  map[string]string
@@ -66,6 +73,9 @@ func (tc *TaskController) TasksIndex(c *gin.Context) {
 	title := c.Query("title")
 	creatorID := c.Query("creator_id")
 	performerID := c.Query("performer_id")
+	projectID := c.Query("project_id")
+	tenantID := c.Query("tenant_id")
+	viewID := c.Query("view")
 
 	req := &pb.GetTasksRequest{}
 
@@ -73,6 +83,11 @@ func (tc *TaskController) TasksIndex(c *gin.Context) {
 		req.Title = title
 	}
 
+	if viewID != "" {
+		req.ViewId = viewID
+		req.RequesterId = requesterID(c)
+	}
+
 	if creatorID != "" {
 		creatorID, err := strconv.ParseUint(creatorID, 10, 64)
 		if err != nil {
@@ -93,10 +108,30 @@ func (tc *TaskController) TasksIndex(c *gin.Context) {
 		req.PerformerId = performerID
 	}
 
-	resp, err := tc.GRPCClient.GetTasks(context.Background(), req)
+	if projectID != "" {
+		projectID, err := strconv.ParseUint(projectID, 10, 64)
+		if err != nil {
+			logger.Log(logger.LevelError, "Invalid project_id format", gin.H{"project_id": projectID})
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid project_id"})
+			return
+		}
+		req.ProjectId = projectID
+	}
+
+	if tenantID != "" {
+		tenantID, err := strconv.ParseUint(tenantID, 10, 64)
+		if err != nil {
+			logger.Log(logger.LevelError, "Invalid tenant_id format", gin.H{"tenant_id": tenantID})
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid tenant_id"})
+			return
+		}
+		req.TenantId = tenantID
+	}
+
+	resp, err := tc.GRPCClient.GetTasks(userContext(c), req)
 	if err != nil {
 		logger.Log(logger.LevelError, "Failed to retrieve task list", gin.H{"error": err.Error()})
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": err.Error()})
 		return
 	}
 
@@ -104,6 +139,96 @@ func (tc *TaskController) TasksIndex(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": resp.Tasks})
 }
 
+// Task Stats
+// @Summary      Count tasks by group
+// @Description  Counts tasks matching optional filters, grouped by status, performer, creator, label, project, tenant or created_week
+// @Tags         tasks
+// @Produce      json
+// @Param        group_by     query     string  false  "Comma-separated dimensions, e.g. status,performer"
+// @Param        title        query     string  false  "Filter by title"
+// @Param        status       query     string  false  "Filter by status"
+// @Param        creator_id   query     uint64  false  "Filter by creator ID"
+// @Param        performer_id query     uint64  false  "Filter by performer ID"
+// @Param        project_id   query     uint64  false  "Filter by project ID"
+// @Param        tenant_id    query     uint64  false  "Filter by tenant ID"
+// @Param        limit        query     int     false  "Keep only the largest groups"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /tasks/stats [get]
+func (tc *TaskController) TasksStats(c *gin.Context) {
+	req := &pb.GetTaskStatsRequest{
+		Title:  c.Query("title"),
+		Status: c.Query("status"),
+	}
+	for _, value := range c.QueryArray("group_by") {
+		for _, dim := range strings.Split(value, ",") {
+			if dim = strings.TrimSpace(dim); dim != "" {
+				req.GroupBy = append(req.GroupBy, dim)
+			}
+		}
+	}
+
+	ids := map[string]*uint64{
+		"creator_id":   &req.CreatorId,
+		"performer_id": &req.PerformerId,
+		"project_id":   &req.ProjectId,
+		"tenant_id":    &req.TenantId,
+	}
+	for name, dst := range ids {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			logger.Log(logger.LevelError, "Invalid "+name+" format", gin.H{name: value})
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid " + name})
+			return
+		}
+		*dst = id
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || limit < 0 {
+			logger.Log(logger.LevelError, "Invalid limit format", gin.H{"limit": value})
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid limit"})
+			return
+		}
+		req.Limit = int32(limit)
+	}
+
+	resp, err := tc.GRPCClient.GetTaskStats(userContext(c), req)
+	if err != nil {
+		logger.Log(logger.LevelError, "Failed to retrieve task stats", gin.H{"error": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": err.Error()})
+		return
+	}
+
+	// each group as an object keyed by dimension: {"status": "done", "count": 3}
+	groups := make([]gin.H, 0, len(resp.Groups))
+	for _, g := range resp.Groups {
+		group := gin.H{"count": g.Count}
+		for i, dim := range resp.GroupBy {
+			if i < len(g.Keys) {
+				group[dim] = g.Keys[i]
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	logger.Log(logger.LevelInfo, "Task stats retrieved successfully", gin.H{"group_by": resp.GroupBy, "groups": len(groups)})
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"group_by":          resp.GroupBy,
+		"groups":            groups,
+		"total":             resp.Total,
+		"failed_shards":     resp.FailedShards,
+		"rebalance_pending": resp.RebalancePending,
+	}})
+}
+
 // Get Task by ID
 // @Summary      Get task by ID
 // @Description  Retrieves a single task by its ID
@@ -124,10 +249,15 @@ func (tc *TaskController) TasksShow(c *gin.Context) {
 		return
 	}
 
-	resp, err := tc.GRPCClient.GetTask(context.Background(), &pb.GetTaskRequest{Id: id})
+	resp, err := tc.GRPCClient.GetTask(userContext(c), &pb.GetTaskRequest{Id: id})
 	if err != nil {
+		code := http.StatusNotFound
+		if status.Code(err) == codes.Unavailable {
+			// the task's shard is down
+			code = http.StatusServiceUnavailable
+		}
 		logger.Log(logger.LevelError, "Task not found", gin.H{"error": err.Error()})
-		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		c.JSON(code, gin.H{"message": err.Error()})
 		return
 	}
 
@@ -156,10 +286,10 @@ func (tc *TaskController) TasksUpdate(c *gin.Context) {
 		return
 	}
 
-	resp, err := tc.GRPCClient.UpdateTask(context.Background(), &req)
+	resp, err := tc.GRPCClient.UpdateTask(userContext(c), &req)
 	if err != nil {
 		logger.Log(logger.LevelError, "Failed to update task", gin.H{"error": err.Error()})
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": err.Error()})
 		return
 	}
 
@@ -187,13 +317,23 @@ func (tc *TaskController) TasksDelete(c *gin.Context) {
 		return
 	}
 
-	_, err = tc.GRPCClient.DeleteTask(context.Background(), &pb.DeleteTaskRequest{Id: id})
+	_, err = tc.GRPCClient.DeleteTask(userContext(c), &pb.DeleteTaskRequest{Id: id})
 	if err != nil {
 		logger.Log(logger.LevelError, "Failed to delete task", gin.H{"error": err.Error()})
-		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": err.Error()})
 		return
 	}
 
 	logger.Log(logger.LevelInfo, "Task deleted successfully", gin.H{"task_id": id})
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// userContext passes the authenticated user to the tasks service, which routes the
+// user's reads to shard primaries for a short while after they wrote.
+func userContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if id := requesterID(c); id != 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-user-id", strconv.FormatUint(id, 10))
+	}
+	return ctx
+}
@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"context"
+	"gateway/logger"
+	pb "gateway/proto/taskpb"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ViewController struct {
+	GRPCClient pb.TaskServiceClient
+}
+
+func NewViewController(grpcClient pb.TaskServiceClient) *ViewController {
+	return &ViewController{GRPCClient: grpcClient}
+}
+
+// Create View
+// @Summary      Create a saved view
+// @Description  Saves a task filter, sort and column set under a name. The view is owned by the caller.
+// @Tags         views
+// @Accept       json
+// @Produce      json
+// @Param        view  body      pb.CreateViewRequest  true  "View input"
+// @Success      201   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /views [post]
+func (vc *ViewController) ViewsCreate(c *gin.Context) {
+	var req pb.CreateViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log(logger.LevelError, "Failed to bind JSON", gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	req.OwnerId = requesterID(c)
+
+	resp, err := vc.GRPCClient.CreateView(context.Background(), &req)
+	if err != nil {
+		logger.Log(logger.LevelError, "Failed to create view", gin.H{"error": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": status.Convert(err).Message()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": resp.View})
+}
+
+// List Views
+// @Summary      List saved views
+// @Description  Returns the caller's views plus views shared with the given project
+// @Tags         views
+// @Produce      json
+// @Param        project_id  query     uint64  false  "Include views shared with this project"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /views [get]
+func (vc *ViewController) ViewsIndex(c *gin.Context) {
+	req := &pb.ListViewsRequest{RequesterId: requesterID(c)}
+
+	if projectID := c.Query("project_id"); projectID != "" {
+		id, err := strconv.ParseUint(projectID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid project_id"})
+			return
+		}
+		req.ProjectId = id
+	}
+
+	resp, err := vc.GRPCClient.ListViews(context.Background(), req)
+	if err != nil {
+		logger.Log(logger.LevelError, "Failed to list views", gin.H{"error": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": status.Convert(err).Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp.Views})
+}
+
+// Get View by ID
+// @Summary      Get saved view by ID
+// @Tags         views
+// @Produce      json
+// @Param        id   path      string  true  "View ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /views/{id} [get]
+func (vc *ViewController) ViewsShow(c *gin.Context) {
+	resp, err := vc.GRPCClient.GetView(context.Background(), &pb.GetViewRequest{
+		Id:          c.Param("id"),
+		RequesterId: requesterID(c),
+	})
+	if err != nil {
+		logger.Log(logger.LevelError, "Failed to get view", gin.H{"error": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": status.Convert(err).Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp.View})
+}
+
+// Update View
+// @Summary      Update a saved view
+// @Description  Replaces the view definition; only the owner may update it
+// @Tags         views
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                 true  "View ID"
+// @Param        view  body      pb.UpdateViewRequest   true  "Updated view input"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /views/{id} [put]
+func (vc *ViewController) ViewsUpdate(c *gin.Context) {
+	var req pb.UpdateViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log(logger.LevelError, "Failed to bind JSON", gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	req.Id = c.Param("id")
+	req.RequesterId = requesterID(c)
+
+	resp, err := vc.GRPCClient.UpdateView(context.Background(), &req)
+	if err != nil {
+		logger.Log(logger.LevelError, "Failed to update view", gin.H{"error": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": status.Convert(err).Message()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": resp.View})
+}
+
+// Delete View
+// @Summary      Delete a saved view
+// @Description  Deletes a view; only the owner may delete it
+// @Tags         views
+// @Produce      json
+// @Param        id   path      string  true  "View ID"
+// @Success      204  "No Content"
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Security     BearerAuth
+// @Router       /views/{id} [delete]
+func (vc *ViewController) ViewsDelete(c *gin.Context) {
+	_, err := vc.GRPCClient.DeleteView(context.Background(), &pb.DeleteViewRequest{
+		Id:          c.Param("id"),
+		RequesterId: requesterID(c),
+	})
+	if err != nil {
+		logger.Log(logger.LevelError, "Failed to delete view", gin.H{"error": err.Error()})
+		c.JSON(httpStatusFromGRPC(err), gin.H{"message": status.Convert(err).Message()})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// requesterID returns the authenticated user set by middleware.RequireAuth.
+func requesterID(c *gin.Context) uint64 {
+	return uint64(c.GetInt("user_id"))
+}
+
+func httpStatusFromGRPC(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Aborted:
+		return http.StatusConflict
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -76,10 +76,22 @@ func main() {
 	{
 		tasksGroup.POST("", taskController.TasksCreate)
 		tasksGroup.GET("", taskController.TasksIndex)
+		tasksGroup.GET("/stats", taskController.TasksStats)
 		tasksGroup.GET("/:id", taskController.TasksShow)
 		tasksGroup.PUT("/:id", taskController.TasksUpdate)
 		tasksGroup.DELETE("/:id", taskController.TasksDelete)
 	}
+
+	viewController := controllers.NewViewController(grpcClient)
+
+	viewsGroup := r.Group("/views", middleware.RequireAuth)
+	{
+		viewsGroup.POST("", viewController.ViewsCreate)
+		viewsGroup.GET("", viewController.ViewsIndex)
+		viewsGroup.GET("/:id", viewController.ViewsShow)
+		viewsGroup.PUT("/:id", viewController.ViewsUpdate)
+		viewsGroup.DELETE("/:id", viewController.ViewsDelete)
+	}
 	r.GET("/tasks/notifications", consumers.HandleWebSocketConnection)
 
 	authUrl := os.Getenv("AUTH_SERVICE_URL")
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
-// - protoc-gen-go-grpc v1.3.0
-// - protoc             v4.24.4
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
 // source: task.proto
 
 package taskpb
@@ -15,26 +15,44 @@ import (
 
 // This is a compile-time assertion to ensure that this generated file
 // is compatible with the grpc package it is being compiled against.
-// Requires gRPC-Go v1.32.0 or later.
-const _ = grpc.SupportPackageIsVersion7
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TaskService_CreateTask_FullMethodName = "/task.tasks/CreateTask"
-	TaskService_GetTask_FullMethodName    = "/task.tasks/GetTask"
-	TaskService_GetTasks_FullMethodName   = "/task.tasks/GetTasks"
-	TaskService_UpdateTask_FullMethodName = "/task.tasks/UpdateTask"
-	TaskService_DeleteTask_FullMethodName = "/task.tasks/DeleteTask"
+	TaskService_CreateTask_FullMethodName   = "/task.TaskService/CreateTask"
+	TaskService_GetTask_FullMethodName      = "/task.TaskService/GetTask"
+	TaskService_GetTasks_FullMethodName     = "/task.TaskService/GetTasks"
+	TaskService_GetTaskStats_FullMethodName = "/task.TaskService/GetTaskStats"
+	TaskService_UpdateTask_FullMethodName   = "/task.TaskService/UpdateTask"
+	TaskService_DeleteTask_FullMethodName   = "/task.TaskService/DeleteTask"
+	TaskService_ImportTasks_FullMethodName  = "/task.TaskService/ImportTasks"
+	TaskService_ExportTasks_FullMethodName  = "/task.TaskService/ExportTasks"
+	TaskService_WatchTasks_FullMethodName   = "/task.TaskService/WatchTasks"
+	TaskService_CreateView_FullMethodName   = "/task.TaskService/CreateView"
+	TaskService_GetView_FullMethodName      = "/task.TaskService/GetView"
+	TaskService_ListViews_FullMethodName    = "/task.TaskService/ListViews"
+	TaskService_UpdateView_FullMethodName   = "/task.TaskService/UpdateView"
+	TaskService_DeleteView_FullMethodName   = "/task.TaskService/DeleteView"
 )
 
-// TaskServiceClient is the client API for tasks service.
+// TaskServiceClient is the client API for TaskService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type TaskServiceClient interface {
 	CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error)
 	GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error)
 	GetTasks(ctx context.Context, in *GetTasksRequest, opts ...grpc.CallOption) (*GetTasksResponse, error)
+	GetTaskStats(ctx context.Context, in *GetTaskStatsRequest, opts ...grpc.CallOption) (*GetTaskStatsResponse, error)
 	UpdateTask(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error)
 	DeleteTask(ctx context.Context, in *DeleteTaskRequest, opts ...grpc.CallOption) (*DeleteTaskResponse, error)
+	ImportTasks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportTasksRequest, ImportTasksResponse], error)
+	ExportTasks(ctx context.Context, in *ExportTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Task], error)
+	WatchTasks(ctx context.Context, in *WatchTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskChange], error)
+	CreateView(ctx context.Context, in *CreateViewRequest, opts ...grpc.CallOption) (*ViewResponse, error)
+	GetView(ctx context.Context, in *GetViewRequest, opts ...grpc.CallOption) (*ViewResponse, error)
+	ListViews(ctx context.Context, in *ListViewsRequest, opts ...grpc.CallOption) (*ListViewsResponse, error)
+	UpdateView(ctx context.Context, in *UpdateViewRequest, opts ...grpc.CallOption) (*ViewResponse, error)
+	DeleteView(ctx context.Context, in *DeleteViewRequest, opts ...grpc.CallOption) (*DeleteViewResponse, error)
 }
 
 type taskServiceClient struct {
@@ -46,8 +64,9 @@ func NewTaskServiceClient(cc grpc.ClientConnInterface) TaskServiceClient {
 }
 
 func (c *taskServiceClient) CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TaskResponse)
-	err := c.cc.Invoke(ctx, TaskService_CreateTask_FullMethodName, in, out, opts...)
+	err := c.cc.Invoke(ctx, TaskService_CreateTask_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +74,9 @@ func (c *taskServiceClient) CreateTask(ctx context.Context, in *CreateTaskReques
 }
 
 func (c *taskServiceClient) GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TaskResponse)
-	err := c.cc.Invoke(ctx, TaskService_GetTask_FullMethodName, in, out, opts...)
+	err := c.cc.Invoke(ctx, TaskService_GetTask_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -64,8 +84,19 @@ func (c *taskServiceClient) GetTask(ctx context.Context, in *GetTaskRequest, opt
 }
 
 func (c *taskServiceClient) GetTasks(ctx context.Context, in *GetTasksRequest, opts ...grpc.CallOption) (*GetTasksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetTasksResponse)
-	err := c.cc.Invoke(ctx, TaskService_GetTasks_FullMethodName, in, out, opts...)
+	err := c.cc.Invoke(ctx, TaskService_GetTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) GetTaskStats(ctx context.Context, in *GetTaskStatsRequest, opts ...grpc.CallOption) (*GetTaskStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTaskStatsResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetTaskStats_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -73,8 +104,9 @@ func (c *taskServiceClient) GetTasks(ctx context.Context, in *GetTasksRequest, o
 }
 
 func (c *taskServiceClient) UpdateTask(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*TaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TaskResponse)
-	err := c.cc.Invoke(ctx, TaskService_UpdateTask_FullMethodName, in, out, opts...)
+	err := c.cc.Invoke(ctx, TaskService_UpdateTask_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -82,29 +114,143 @@ func (c *taskServiceClient) UpdateTask(ctx context.Context, in *UpdateTaskReques
 }
 
 func (c *taskServiceClient) DeleteTask(ctx context.Context, in *DeleteTaskRequest, opts ...grpc.CallOption) (*DeleteTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeleteTaskResponse)
-	err := c.cc.Invoke(ctx, TaskService_DeleteTask_FullMethodName, in, out, opts...)
+	err := c.cc.Invoke(ctx, TaskService_DeleteTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ImportTasks(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportTasksRequest, ImportTasksResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskService_ServiceDesc.Streams[0], TaskService_ImportTasks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportTasksRequest, ImportTasksResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskService_ImportTasksClient = grpc.ClientStreamingClient[ImportTasksRequest, ImportTasksResponse]
+
+func (c *taskServiceClient) ExportTasks(ctx context.Context, in *ExportTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Task], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskService_ServiceDesc.Streams[1], TaskService_ExportTasks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportTasksRequest, Task]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskService_ExportTasksClient = grpc.ServerStreamingClient[Task]
+
+func (c *taskServiceClient) WatchTasks(ctx context.Context, in *WatchTasksRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TaskService_ServiceDesc.Streams[2], TaskService_WatchTasks_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTasksRequest, TaskChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskService_WatchTasksClient = grpc.ServerStreamingClient[TaskChange]
+
+func (c *taskServiceClient) CreateView(ctx context.Context, in *CreateViewRequest, opts ...grpc.CallOption) (*ViewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ViewResponse)
+	err := c.cc.Invoke(ctx, TaskService_CreateView_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) GetView(ctx context.Context, in *GetViewRequest, opts ...grpc.CallOption) (*ViewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ViewResponse)
+	err := c.cc.Invoke(ctx, TaskService_GetView_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) ListViews(ctx context.Context, in *ListViewsRequest, opts ...grpc.CallOption) (*ListViewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListViewsResponse)
+	err := c.cc.Invoke(ctx, TaskService_ListViews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *taskServiceClient) UpdateView(ctx context.Context, in *UpdateViewRequest, opts ...grpc.CallOption) (*ViewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ViewResponse)
+	err := c.cc.Invoke(ctx, TaskService_UpdateView_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// TaskServiceServer is the server API for tasks service.
+func (c *taskServiceClient) DeleteView(ctx context.Context, in *DeleteViewRequest, opts ...grpc.CallOption) (*DeleteViewResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteViewResponse)
+	err := c.cc.Invoke(ctx, TaskService_DeleteView_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TaskServiceServer is the server API for TaskService service.
 // All implementations must embed UnimplementedTaskServiceServer
-// for forward compatibility
+// for forward compatibility.
 type TaskServiceServer interface {
 	CreateTask(context.Context, *CreateTaskRequest) (*TaskResponse, error)
 	GetTask(context.Context, *GetTaskRequest) (*TaskResponse, error)
 	GetTasks(context.Context, *GetTasksRequest) (*GetTasksResponse, error)
+	GetTaskStats(context.Context, *GetTaskStatsRequest) (*GetTaskStatsResponse, error)
 	UpdateTask(context.Context, *UpdateTaskRequest) (*TaskResponse, error)
 	DeleteTask(context.Context, *DeleteTaskRequest) (*DeleteTaskResponse, error)
+	ImportTasks(grpc.ClientStreamingServer[ImportTasksRequest, ImportTasksResponse]) error
+	ExportTasks(*ExportTasksRequest, grpc.ServerStreamingServer[Task]) error
+	WatchTasks(*WatchTasksRequest, grpc.ServerStreamingServer[TaskChange]) error
+	CreateView(context.Context, *CreateViewRequest) (*ViewResponse, error)
+	GetView(context.Context, *GetViewRequest) (*ViewResponse, error)
+	ListViews(context.Context, *ListViewsRequest) (*ListViewsResponse, error)
+	UpdateView(context.Context, *UpdateViewRequest) (*ViewResponse, error)
+	DeleteView(context.Context, *DeleteViewRequest) (*DeleteViewResponse, error)
 	mustEmbedUnimplementedTaskServiceServer()
 }
 
-// UnimplementedTaskServiceServer must be embedded to have forward compatible implementations.
-type UnimplementedTaskServiceServer struct {
-}
+// UnimplementedTaskServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTaskServiceServer struct{}
 
 func (UnimplementedTaskServiceServer) CreateTask(context.Context, *CreateTaskRequest) (*TaskResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateTask not implemented")
@@ -115,13 +261,41 @@ func (UnimplementedTaskServiceServer) GetTask(context.Context, *GetTaskRequest)
 func (UnimplementedTaskServiceServer) GetTasks(context.Context, *GetTasksRequest) (*GetTasksResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTasks not implemented")
 }
+func (UnimplementedTaskServiceServer) GetTaskStats(context.Context, *GetTaskStatsRequest) (*GetTaskStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTaskStats not implemented")
+}
 func (UnimplementedTaskServiceServer) UpdateTask(context.Context, *UpdateTaskRequest) (*TaskResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateTask not implemented")
 }
 func (UnimplementedTaskServiceServer) DeleteTask(context.Context, *DeleteTaskRequest) (*DeleteTaskResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteTask not implemented")
 }
+func (UnimplementedTaskServiceServer) ImportTasks(grpc.ClientStreamingServer[ImportTasksRequest, ImportTasksResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportTasks not implemented")
+}
+func (UnimplementedTaskServiceServer) ExportTasks(*ExportTasksRequest, grpc.ServerStreamingServer[Task]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportTasks not implemented")
+}
+func (UnimplementedTaskServiceServer) WatchTasks(*WatchTasksRequest, grpc.ServerStreamingServer[TaskChange]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTasks not implemented")
+}
+func (UnimplementedTaskServiceServer) CreateView(context.Context, *CreateViewRequest) (*ViewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateView not implemented")
+}
+func (UnimplementedTaskServiceServer) GetView(context.Context, *GetViewRequest) (*ViewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetView not implemented")
+}
+func (UnimplementedTaskServiceServer) ListViews(context.Context, *ListViewsRequest) (*ListViewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListViews not implemented")
+}
+func (UnimplementedTaskServiceServer) UpdateView(context.Context, *UpdateViewRequest) (*ViewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateView not implemented")
+}
+func (UnimplementedTaskServiceServer) DeleteView(context.Context, *DeleteViewRequest) (*DeleteViewResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteView not implemented")
+}
 func (UnimplementedTaskServiceServer) mustEmbedUnimplementedTaskServiceServer() {}
+func (UnimplementedTaskServiceServer) testEmbeddedByValue()                     {}
 
 // UnsafeTaskServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to TaskServiceServer will
@@ -131,6 +305,13 @@ type UnsafeTaskServiceServer interface {
 }
 
 func RegisterTaskServiceServer(s grpc.ServiceRegistrar, srv TaskServiceServer) {
+	// If the following call pancis, it indicates UnimplementedTaskServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
 	s.RegisterService(&TaskService_ServiceDesc, srv)
 }
 
@@ -188,6 +369,24 @@ func _TaskService_GetTasks_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TaskService_GetTaskStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetTaskStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetTaskStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetTaskStats(ctx, req.(*GetTaskStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TaskService_UpdateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(UpdateTaskRequest)
 	if err := dec(in); err != nil {
@@ -224,11 +423,130 @@ func _TaskService_DeleteTask_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
-// TaskService_ServiceDesc is the grpc.ServiceDesc for tasks service.
+func _TaskService_ImportTasks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TaskServiceServer).ImportTasks(&grpc.GenericServerStream[ImportTasksRequest, ImportTasksResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskService_ImportTasksServer = grpc.ClientStreamingServer[ImportTasksRequest, ImportTasksResponse]
+
+func _TaskService_ExportTasks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportTasksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TaskServiceServer).ExportTasks(m, &grpc.GenericServerStream[ExportTasksRequest, Task]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskService_ExportTasksServer = grpc.ServerStreamingServer[Task]
+
+func _TaskService_WatchTasks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTasksRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TaskServiceServer).WatchTasks(m, &grpc.GenericServerStream[WatchTasksRequest, TaskChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TaskService_WatchTasksServer = grpc.ServerStreamingServer[TaskChange]
+
+func _TaskService_CreateView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).CreateView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_CreateView_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).CreateView(ctx, req.(*CreateViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_GetView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).GetView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_GetView_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).GetView(ctx, req.(*GetViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_ListViews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListViewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).ListViews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_ListViews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).ListViews(ctx, req.(*ListViewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_UpdateView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).UpdateView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_UpdateView_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).UpdateView(ctx, req.(*UpdateViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TaskService_DeleteView_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteViewRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TaskServiceServer).DeleteView(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TaskService_DeleteView_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TaskServiceServer).DeleteView(ctx, req.(*DeleteViewRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TaskService_ServiceDesc is the grpc.ServiceDesc for TaskService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var TaskService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "task.tasks",
+	ServiceName: "task.TaskService",
 	HandlerType: (*TaskServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
@@ -243,6 +561,10 @@ var TaskService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTasks",
 			Handler:    _TaskService_GetTasks_Handler,
 		},
+		{
+			MethodName: "GetTaskStats",
+			Handler:    _TaskService_GetTaskStats_Handler,
+		},
 		{
 			MethodName: "UpdateTask",
 			Handler:    _TaskService_UpdateTask_Handler,
@@ -251,6 +573,676 @@ var TaskService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteTask",
 			Handler:    _TaskService_DeleteTask_Handler,
 		},
+		{
+			MethodName: "CreateView",
+			Handler:    _TaskService_CreateView_Handler,
+		},
+		{
+			MethodName: "GetView",
+			Handler:    _TaskService_GetView_Handler,
+		},
+		{
+			MethodName: "ListViews",
+			Handler:    _TaskService_ListViews_Handler,
+		},
+		{
+			MethodName: "UpdateView",
+			Handler:    _TaskService_UpdateView_Handler,
+		},
+		{
+			MethodName: "DeleteView",
+			Handler:    _TaskService_DeleteView_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportTasks",
+			Handler:       _TaskService_ImportTasks_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportTasks",
+			Handler:       _TaskService_ExportTasks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchTasks",
+			Handler:       _TaskService_WatchTasks_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "task.proto",
+}
+
+const (
+	ShardAdmin_AddShard_FullMethodName              = "/task.ShardAdmin/AddShard"
+	ShardAdmin_GetRebalanceProgress_FullMethodName  = "/task.ShardAdmin/GetRebalanceProgress"
+	ShardAdmin_DrainShard_FullMethodName            = "/task.ShardAdmin/DrainShard"
+	ShardAdmin_ListShards_FullMethodName            = "/task.ShardAdmin/ListShards"
+	ShardAdmin_VerifyShards_FullMethodName          = "/task.ShardAdmin/VerifyShards"
+	ShardAdmin_RebuildDirectory_FullMethodName      = "/task.ShardAdmin/RebuildDirectory"
+	ShardAdmin_SetShardWeight_FullMethodName        = "/task.ShardAdmin/SetShardWeight"
+	ShardAdmin_SimulateShardWeights_FullMethodName  = "/task.ShardAdmin/SimulateShardWeights"
+	ShardAdmin_ListHotPerformers_FullMethodName     = "/task.ShardAdmin/ListHotPerformers"
+	ShardAdmin_SetPerformerPlacement_FullMethodName = "/task.ShardAdmin/SetPerformerPlacement"
+	ShardAdmin_GetShardStatus_FullMethodName        = "/task.ShardAdmin/GetShardStatus"
+	ShardAdmin_SetShardKey_FullMethodName           = "/task.ShardAdmin/SetShardKey"
+	ShardAdmin_SetTenantPlacement_FullMethodName    = "/task.ShardAdmin/SetTenantPlacement"
+	ShardAdmin_ExplainPlacement_FullMethodName      = "/task.ShardAdmin/ExplainPlacement"
+	ShardAdmin_DumpRing_FullMethodName              = "/task.ShardAdmin/DumpRing"
+)
+
+// ShardAdminClient is the client API for ShardAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ShardAdminClient interface {
+	AddShard(ctx context.Context, in *AddShardRequest, opts ...grpc.CallOption) (*AddShardResponse, error)
+	GetRebalanceProgress(ctx context.Context, in *GetRebalanceProgressRequest, opts ...grpc.CallOption) (*RebalanceProgress, error)
+	DrainShard(ctx context.Context, in *DrainShardRequest, opts ...grpc.CallOption) (*DrainShardResponse, error)
+	ListShards(ctx context.Context, in *ListShardsRequest, opts ...grpc.CallOption) (*ListShardsResponse, error)
+	VerifyShards(ctx context.Context, in *VerifyShardsRequest, opts ...grpc.CallOption) (*VerifyShardsResponse, error)
+	RebuildDirectory(ctx context.Context, in *RebuildDirectoryRequest, opts ...grpc.CallOption) (*RebuildDirectoryResponse, error)
+	SetShardWeight(ctx context.Context, in *SetShardWeightRequest, opts ...grpc.CallOption) (*SetShardWeightResponse, error)
+	SimulateShardWeights(ctx context.Context, in *SimulateShardWeightsRequest, opts ...grpc.CallOption) (*SimulateShardWeightsResponse, error)
+	ListHotPerformers(ctx context.Context, in *ListHotPerformersRequest, opts ...grpc.CallOption) (*ListHotPerformersResponse, error)
+	SetPerformerPlacement(ctx context.Context, in *SetPerformerPlacementRequest, opts ...grpc.CallOption) (*SetPerformerPlacementResponse, error)
+	GetShardStatus(ctx context.Context, in *GetShardStatusRequest, opts ...grpc.CallOption) (*GetShardStatusResponse, error)
+	SetShardKey(ctx context.Context, in *SetShardKeyRequest, opts ...grpc.CallOption) (*SetShardKeyResponse, error)
+	SetTenantPlacement(ctx context.Context, in *SetTenantPlacementRequest, opts ...grpc.CallOption) (*SetTenantPlacementResponse, error)
+	ExplainPlacement(ctx context.Context, in *ExplainPlacementRequest, opts ...grpc.CallOption) (*ExplainPlacementResponse, error)
+	DumpRing(ctx context.Context, in *DumpRingRequest, opts ...grpc.CallOption) (*DumpRingResponse, error)
+}
+
+type shardAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewShardAdminClient(cc grpc.ClientConnInterface) ShardAdminClient {
+	return &shardAdminClient{cc}
+}
+
+func (c *shardAdminClient) AddShard(ctx context.Context, in *AddShardRequest, opts ...grpc.CallOption) (*AddShardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddShardResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_AddShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) GetRebalanceProgress(ctx context.Context, in *GetRebalanceProgressRequest, opts ...grpc.CallOption) (*RebalanceProgress, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RebalanceProgress)
+	err := c.cc.Invoke(ctx, ShardAdmin_GetRebalanceProgress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) DrainShard(ctx context.Context, in *DrainShardRequest, opts ...grpc.CallOption) (*DrainShardResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DrainShardResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_DrainShard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) ListShards(ctx context.Context, in *ListShardsRequest, opts ...grpc.CallOption) (*ListShardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListShardsResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_ListShards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) VerifyShards(ctx context.Context, in *VerifyShardsRequest, opts ...grpc.CallOption) (*VerifyShardsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyShardsResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_VerifyShards_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) RebuildDirectory(ctx context.Context, in *RebuildDirectoryRequest, opts ...grpc.CallOption) (*RebuildDirectoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RebuildDirectoryResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_RebuildDirectory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) SetShardWeight(ctx context.Context, in *SetShardWeightRequest, opts ...grpc.CallOption) (*SetShardWeightResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetShardWeightResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_SetShardWeight_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) SimulateShardWeights(ctx context.Context, in *SimulateShardWeightsRequest, opts ...grpc.CallOption) (*SimulateShardWeightsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SimulateShardWeightsResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_SimulateShardWeights_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) ListHotPerformers(ctx context.Context, in *ListHotPerformersRequest, opts ...grpc.CallOption) (*ListHotPerformersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListHotPerformersResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_ListHotPerformers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) SetPerformerPlacement(ctx context.Context, in *SetPerformerPlacementRequest, opts ...grpc.CallOption) (*SetPerformerPlacementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetPerformerPlacementResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_SetPerformerPlacement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) GetShardStatus(ctx context.Context, in *GetShardStatusRequest, opts ...grpc.CallOption) (*GetShardStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetShardStatusResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_GetShardStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) SetShardKey(ctx context.Context, in *SetShardKeyRequest, opts ...grpc.CallOption) (*SetShardKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetShardKeyResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_SetShardKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) SetTenantPlacement(ctx context.Context, in *SetTenantPlacementRequest, opts ...grpc.CallOption) (*SetTenantPlacementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetTenantPlacementResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_SetTenantPlacement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) ExplainPlacement(ctx context.Context, in *ExplainPlacementRequest, opts ...grpc.CallOption) (*ExplainPlacementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainPlacementResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_ExplainPlacement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shardAdminClient) DumpRing(ctx context.Context, in *DumpRingRequest, opts ...grpc.CallOption) (*DumpRingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DumpRingResponse)
+	err := c.cc.Invoke(ctx, ShardAdmin_DumpRing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ShardAdminServer is the server API for ShardAdmin service.
+// All implementations must embed UnimplementedShardAdminServer
+// for forward compatibility.
+type ShardAdminServer interface {
+	AddShard(context.Context, *AddShardRequest) (*AddShardResponse, error)
+	GetRebalanceProgress(context.Context, *GetRebalanceProgressRequest) (*RebalanceProgress, error)
+	DrainShard(context.Context, *DrainShardRequest) (*DrainShardResponse, error)
+	ListShards(context.Context, *ListShardsRequest) (*ListShardsResponse, error)
+	VerifyShards(context.Context, *VerifyShardsRequest) (*VerifyShardsResponse, error)
+	RebuildDirectory(context.Context, *RebuildDirectoryRequest) (*RebuildDirectoryResponse, error)
+	SetShardWeight(context.Context, *SetShardWeightRequest) (*SetShardWeightResponse, error)
+	SimulateShardWeights(context.Context, *SimulateShardWeightsRequest) (*SimulateShardWeightsResponse, error)
+	ListHotPerformers(context.Context, *ListHotPerformersRequest) (*ListHotPerformersResponse, error)
+	SetPerformerPlacement(context.Context, *SetPerformerPlacementRequest) (*SetPerformerPlacementResponse, error)
+	GetShardStatus(context.Context, *GetShardStatusRequest) (*GetShardStatusResponse, error)
+	SetShardKey(context.Context, *SetShardKeyRequest) (*SetShardKeyResponse, error)
+	SetTenantPlacement(context.Context, *SetTenantPlacementRequest) (*SetTenantPlacementResponse, error)
+	ExplainPlacement(context.Context, *ExplainPlacementRequest) (*ExplainPlacementResponse, error)
+	DumpRing(context.Context, *DumpRingRequest) (*DumpRingResponse, error)
+	mustEmbedUnimplementedShardAdminServer()
+}
+
+// UnimplementedShardAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedShardAdminServer struct{}
+
+func (UnimplementedShardAdminServer) AddShard(context.Context, *AddShardRequest) (*AddShardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddShard not implemented")
+}
+func (UnimplementedShardAdminServer) GetRebalanceProgress(context.Context, *GetRebalanceProgressRequest) (*RebalanceProgress, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRebalanceProgress not implemented")
+}
+func (UnimplementedShardAdminServer) DrainShard(context.Context, *DrainShardRequest) (*DrainShardResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrainShard not implemented")
+}
+func (UnimplementedShardAdminServer) ListShards(context.Context, *ListShardsRequest) (*ListShardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShards not implemented")
+}
+func (UnimplementedShardAdminServer) VerifyShards(context.Context, *VerifyShardsRequest) (*VerifyShardsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyShards not implemented")
+}
+func (UnimplementedShardAdminServer) RebuildDirectory(context.Context, *RebuildDirectoryRequest) (*RebuildDirectoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RebuildDirectory not implemented")
+}
+func (UnimplementedShardAdminServer) SetShardWeight(context.Context, *SetShardWeightRequest) (*SetShardWeightResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetShardWeight not implemented")
+}
+func (UnimplementedShardAdminServer) SimulateShardWeights(context.Context, *SimulateShardWeightsRequest) (*SimulateShardWeightsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SimulateShardWeights not implemented")
+}
+func (UnimplementedShardAdminServer) ListHotPerformers(context.Context, *ListHotPerformersRequest) (*ListHotPerformersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListHotPerformers not implemented")
+}
+func (UnimplementedShardAdminServer) SetPerformerPlacement(context.Context, *SetPerformerPlacementRequest) (*SetPerformerPlacementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPerformerPlacement not implemented")
+}
+func (UnimplementedShardAdminServer) GetShardStatus(context.Context, *GetShardStatusRequest) (*GetShardStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetShardStatus not implemented")
+}
+func (UnimplementedShardAdminServer) SetShardKey(context.Context, *SetShardKeyRequest) (*SetShardKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetShardKey not implemented")
+}
+func (UnimplementedShardAdminServer) SetTenantPlacement(context.Context, *SetTenantPlacementRequest) (*SetTenantPlacementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTenantPlacement not implemented")
+}
+func (UnimplementedShardAdminServer) ExplainPlacement(context.Context, *ExplainPlacementRequest) (*ExplainPlacementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainPlacement not implemented")
+}
+func (UnimplementedShardAdminServer) DumpRing(context.Context, *DumpRingRequest) (*DumpRingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DumpRing not implemented")
+}
+func (UnimplementedShardAdminServer) mustEmbedUnimplementedShardAdminServer() {}
+func (UnimplementedShardAdminServer) testEmbeddedByValue()                    {}
+
+// UnsafeShardAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ShardAdminServer will
+// result in compilation errors.
+type UnsafeShardAdminServer interface {
+	mustEmbedUnimplementedShardAdminServer()
+}
+
+func RegisterShardAdminServer(s grpc.ServiceRegistrar, srv ShardAdminServer) {
+	// If the following call pancis, it indicates UnimplementedShardAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ShardAdmin_ServiceDesc, srv)
+}
+
+func _ShardAdmin_AddShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).AddShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_AddShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).AddShard(ctx, req.(*AddShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_GetRebalanceProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRebalanceProgressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).GetRebalanceProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_GetRebalanceProgress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).GetRebalanceProgress(ctx, req.(*GetRebalanceProgressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_DrainShard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainShardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).DrainShard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_DrainShard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).DrainShard(ctx, req.(*DrainShardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_ListShards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).ListShards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_ListShards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).ListShards(ctx, req.(*ListShardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_VerifyShards_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyShardsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).VerifyShards(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_VerifyShards_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).VerifyShards(ctx, req.(*VerifyShardsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_RebuildDirectory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebuildDirectoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).RebuildDirectory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_RebuildDirectory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).RebuildDirectory(ctx, req.(*RebuildDirectoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_SetShardWeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetShardWeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).SetShardWeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_SetShardWeight_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).SetShardWeight(ctx, req.(*SetShardWeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_SimulateShardWeights_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SimulateShardWeightsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).SimulateShardWeights(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_SimulateShardWeights_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).SimulateShardWeights(ctx, req.(*SimulateShardWeightsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_ListHotPerformers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHotPerformersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).ListHotPerformers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_ListHotPerformers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).ListHotPerformers(ctx, req.(*ListHotPerformersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_SetPerformerPlacement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPerformerPlacementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).SetPerformerPlacement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_SetPerformerPlacement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).SetPerformerPlacement(ctx, req.(*SetPerformerPlacementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_GetShardStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetShardStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).GetShardStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_GetShardStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).GetShardStatus(ctx, req.(*GetShardStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_SetShardKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetShardKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).SetShardKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_SetShardKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).SetShardKey(ctx, req.(*SetShardKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_SetTenantPlacement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTenantPlacementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).SetTenantPlacement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_SetTenantPlacement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).SetTenantPlacement(ctx, req.(*SetTenantPlacementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_ExplainPlacement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainPlacementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).ExplainPlacement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_ExplainPlacement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).ExplainPlacement(ctx, req.(*ExplainPlacementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShardAdmin_DumpRing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpRingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShardAdminServer).DumpRing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShardAdmin_DumpRing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShardAdminServer).DumpRing(ctx, req.(*DumpRingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ShardAdmin_ServiceDesc is the grpc.ServiceDesc for ShardAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ShardAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "task.ShardAdmin",
+	HandlerType: (*ShardAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddShard",
+			Handler:    _ShardAdmin_AddShard_Handler,
+		},
+		{
+			MethodName: "GetRebalanceProgress",
+			Handler:    _ShardAdmin_GetRebalanceProgress_Handler,
+		},
+		{
+			MethodName: "DrainShard",
+			Handler:    _ShardAdmin_DrainShard_Handler,
+		},
+		{
+			MethodName: "ListShards",
+			Handler:    _ShardAdmin_ListShards_Handler,
+		},
+		{
+			MethodName: "VerifyShards",
+			Handler:    _ShardAdmin_VerifyShards_Handler,
+		},
+		{
+			MethodName: "RebuildDirectory",
+			Handler:    _ShardAdmin_RebuildDirectory_Handler,
+		},
+		{
+			MethodName: "SetShardWeight",
+			Handler:    _ShardAdmin_SetShardWeight_Handler,
+		},
+		{
+			MethodName: "SimulateShardWeights",
+			Handler:    _ShardAdmin_SimulateShardWeights_Handler,
+		},
+		{
+			MethodName: "ListHotPerformers",
+			Handler:    _ShardAdmin_ListHotPerformers_Handler,
+		},
+		{
+			MethodName: "SetPerformerPlacement",
+			Handler:    _ShardAdmin_SetPerformerPlacement_Handler,
+		},
+		{
+			MethodName: "GetShardStatus",
+			Handler:    _ShardAdmin_GetShardStatus_Handler,
+		},
+		{
+			MethodName: "SetShardKey",
+			Handler:    _ShardAdmin_SetShardKey_Handler,
+		},
+		{
+			MethodName: "SetTenantPlacement",
+			Handler:    _ShardAdmin_SetTenantPlacement_Handler,
+		},
+		{
+			MethodName: "ExplainPlacement",
+			Handler:    _ShardAdmin_ExplainPlacement_Handler,
+		},
+		{
+			MethodName: "DumpRing",
+			Handler:    _ShardAdmin_DumpRing_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "task.proto",
@@ -8,13 +8,20 @@ type TaskEvent struct {
 	PerformerID  int    `json:"performer_id"`
 	CreatorID    int    `json:"creator_id"`
 	ObserversIDs []int  `json:"observers_ids"`
-	Status       string `json:"status"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
+	// MentionedUserID is set only for UserMentioned events.
+	MentionedUserID int    `json:"mentioned_user_id,omitempty"`
+	Status          string `json:"status"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
 }
 
 const (
 	EventTaskCreated = "TaskCreated"
 	EventTaskUpdated = "TaskUpdated"
 	EventTaskDeleted = "TaskDeleted"
+	// EventUserMentioned targets only the mentioned user, who need not be an observer.
+	EventUserMentioned = "UserMentioned"
+	// EventTaskDueSoon and EventTaskOverdue are deadline reminders from the tasks scheduler.
+	EventTaskDueSoon = "TaskDueSoon"
+	EventTaskOverdue = "TaskOverdue"
 )
@@ -15,7 +15,8 @@ func NewProcessEvent(sendNotification *SendNotification) *ProcessEvent {
 
 func (uc *ProcessEvent) Execute(ctx context.Context, event domain.TaskEvent) error {
 	switch event.Event {
-	case domain.EventTaskCreated, domain.EventTaskUpdated, domain.EventTaskDeleted:
+	case domain.EventTaskCreated, domain.EventTaskUpdated, domain.EventTaskDeleted, domain.EventUserMentioned,
+		domain.EventTaskDueSoon, domain.EventTaskOverdue:
 		return uc.sendNotification.Execute(ctx, event)
 	default:
 		return domain.ErrUnknownEventType
@@ -29,17 +29,7 @@ func NewSendNotification(
 }
 
 func (uc *SendNotification) Execute(ctx context.Context, event domain.TaskEvent) error {
-	recipients := make(map[int]struct{})
-	for _, id := range event.ObserversIDs {
-		recipients[id] = struct{}{}
-	}
-	recipients[event.PerformerID] = struct{}{}
-	recipients[event.CreatorID] = struct{}{}
-
-	userIDs := make([]int, 0, len(recipients))
-	for id := range recipients {
-		userIDs = append(userIDs, id)
-	}
+	userIDs := recipientIDs(event)
 
 	if len(userIDs) == 0 {
 		return nil
@@ -82,3 +72,27 @@ func (uc *SendNotification) Execute(ctx context.Context, event domain.TaskEvent)
 
 	return nil
 }
+
+// recipientIDs returns who should hear about event: the mentioned user for mentions,
+// otherwise the task's performer, creator and observers.
+func recipientIDs(event domain.TaskEvent) []int {
+	if event.Event == domain.EventUserMentioned {
+		if event.MentionedUserID == 0 {
+			return nil
+		}
+		return []int{event.MentionedUserID}
+	}
+
+	recipients := make(map[int]struct{})
+	for _, id := range event.ObserversIDs {
+		recipients[id] = struct{}{}
+	}
+	recipients[event.PerformerID] = struct{}{}
+	recipients[event.CreatorID] = struct{}{}
+
+	userIDs := make([]int, 0, len(recipients))
+	for id := range recipients {
+		userIDs = append(userIDs, id)
+	}
+	return userIDs
+}
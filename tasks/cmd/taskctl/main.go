@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"tasks/internal/commands"
+)
+
+type command struct {
+	run   func(ctx context.Context, args []string) error
+	usage string
+}
+
+var registry = map[string]command{
+	"import": {commands.ImportTasks, "import tasks from csv, jsonl, jira or trello files"},
+	"export": {commands.ExportTasks, "export tasks to csv or jsonl"},
+
+	"add-shard":         {commands.AddShard, "add a shard at runtime and start rebalancing"},
+	"rebalance-status":  {commands.RebalanceStatus, "show progress of the latest rebalance"},
+	"drain-shard":       {commands.DrainShard, "take a shard off the ring, migrate its data and remove it"},
+	"shards":            {commands.ListShards, "list shards with their ids and states"},
+	"shard-verify":      {commands.ShardVerify, "check shards and task->shard mappings for drift; -repair fixes it"},
+	"rebuild-directory": {commands.RebuildDirectory, "repopulate the task->shard directory and its Redis cache from the shards"},
+	"shard-weight":      {commands.SetShardWeight, "change a shard's share of the ring and start rebalancing"},
+	"ring-sim":          {commands.RingSim, "preview key distribution and performer moves for proposed shard weights"},
+	"hot-performers":    {commands.HotPerformers, "list performers with a large share of a shard's tasks or requests"},
+	"place-performer":   {commands.PlacePerformer, "pin a performer to a shard or split it over several"},
+	"shard-key":         {commands.SetShardKey, "place tasks on the ring by performer, tenant or project and start rebalancing"},
+	"place-tenant":      {commands.PlaceTenant, "dedicate shards to a tenant"},
+	"migrate":           {commands.Migrate, "show, apply or revert shard schema migrations and run backfills"},
+	"shard-status":      {commands.ShardStatus, "show shard circuit breakers, pings, connection pools and replica lag"},
+	"explain":           {commands.ExplainPlacement, "show where a task or performer should live and where the task is"},
+	"dump-ring":         {commands.DumpRing, "show each shard's vnodes and share of the ring"},
+	"backup":            {commands.Backup, "write every shard's tasks, the directory and the id counter to an archive"},
+	"restore":           {commands.Restore, "load a backup archive, placing tasks on the current shards"},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := registry[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "taskctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: taskctl <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", name, registry[name].usage)
+	}
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"tasks/internal/infrastructure/directory"
+)
+
+// Backup implements `taskctl backup -file tasks.tar.gz`. Like migrate it connects to the
+// shard databases directly (DB_SHARD_URLS plus the shared topology in REDIS_URL, and
+// DIRECTORY_DB_URL for the directory).
+func Backup(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	file := fs.String("file", "", "archive to write (.tar.gz)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	sm, closeShards, err := openShards(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer closeShards()
+	directory.InitFromEnv()
+
+	tmp := *file + ".partial"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	m, err := sm.Backup(ctx, out)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, *file); err != nil {
+		return err
+	}
+
+	fmt.Printf("backup of %s, schema version %d, shard key %s\n", m.CreatedAt.Format("2006-01-02 15:04:05 MST"), m.SchemaVersion, m.ShardKey)
+	fmt.Printf("%5s  %-20s %8s %8s\n", "index", "id", "tasks", "views")
+	for _, s := range m.Shards {
+		fmt.Printf("%5d  %-20s %8d %8d\n", s.Index, s.ID, s.Tasks, s.Views)
+	}
+	fmt.Printf("max task id %d, id counter %d, %d directory entries\n", m.MaxTaskID, m.IDCounter, m.DirectoryEntries)
+	if m.SkippedMoves > 0 {
+		fmt.Printf("%d tasks were being moved; only the copy the move keeps was written\n", m.SkippedMoves)
+	}
+	fmt.Printf("written to %s\n", *file)
+	return nil
+}
+
+// Restore implements `taskctl restore -file tasks.tar.gz [-force]`. The archive may come
+// from a store with another shard count: tasks are placed under the current topology.
+func Restore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	file := fs.String("file", "", "archive written by `taskctl backup`")
+	force := fs.Bool("force", false, "restore into shards that already hold tasks or views, replacing those with the same id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+	in, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	sm, closeShards, err := openShards(ctx, true)
+	if err != nil {
+		return err
+	}
+	defer closeShards()
+	directory.InitFromEnv()
+
+	report, err := sm.Restore(ctx, in, *force)
+	if err != nil {
+		return err
+	}
+
+	m := report.Manifest
+	fmt.Printf("restored a backup of %s taken on %d shards\n", m.CreatedAt.Format("2006-01-02 15:04:05 MST"), len(m.Shards))
+	infos := sm.Shards()
+	indexes := make([]int, 0, len(report.Tasks))
+	for idx := range report.Tasks {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	fmt.Printf("%5s  %-20s %8s\n", "index", "id", "tasks")
+	for _, idx := range indexes {
+		fmt.Printf("%5d  %-20s %8d\n", idx, infos[idx].ID, report.Tasks[idx])
+	}
+	if key := sm.ShardKey(); m.ShardKey != "" && m.ShardKey != string(key) {
+		fmt.Printf("note: the backup was taken with shard key %s, tasks were placed by %s\n", m.ShardKey, key)
+	}
+	fmt.Printf("%d saved views restored onto their owners' shards\n", report.Views)
+	if report.Duplicates > 0 {
+		fmt.Printf("%d tasks were in the backup more than once; the newest copy of each was kept\n", report.Duplicates)
+	}
+	if report.StaleEntries > 0 {
+		fmt.Printf("%d directory entries of the backup had no task in it (tasks created or deleted while the backup ran)\n", report.StaleEntries)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const defaultTasksAddr = "localhost:50051"
+
+// tasksAddr returns the tasks gRPC address from TASKS_GRPC_ADDR or the local default.
+func tasksAddr() string {
+	if addr := os.Getenv("TASKS_GRPC_ADDR"); addr != "" {
+		return addr
+	}
+	return defaultTasksAddr
+}
+
+func dialTasks(addr string) (*grpc.ClientConn, taskpb.TaskServiceClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, taskpb.NewTaskServiceClient(conn), nil
+}
+
+func dialAdmin(addr string) (*grpc.ClientConn, taskpb.ShardAdminClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, taskpb.NewShardAdminClient(conn), nil
+}
+
+// withAdminToken attaches ADMIN_TOKEN (or the given token) as bearer metadata.
+func withAdminToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"tasks/internal/infrastructure/taskfile"
+	"tasks/proto/taskpb"
+)
+
+// ExportTasks implements `taskctl export`: it streams ExportTasks into a CSV or JSONL file.
+func ExportTasks(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	file := fs.String("file", "", "output file (default stdout)")
+	format := fs.String("format", taskfile.FormatCSV, "csv or jsonl")
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	performerID := fs.Uint64("performer-id", 0, "only export tasks of this performer")
+	creatorID := fs.Uint64("creator-id", 0, "only export tasks of this creator")
+	tenantID := fs.Uint64("tenant-id", 0, "only export tasks of this tenant")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	out := io.Writer(os.Stdout)
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	writer, err := taskfile.NewWriter(*format, out)
+	if err != nil {
+		return err
+	}
+
+	conn, client, err := dialTasks(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := client.ExportTasks(ctx, &taskpb.ExportTasksRequest{
+		PerformerId: *performerID,
+		CreatorId:   *creatorID,
+		TenantId:    *tenantID,
+	})
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for {
+		task, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rec := taskfile.Record{
+			ExternalID:  strconv.FormatUint(task.Id, 10),
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      task.Status,
+			PerformerID: task.PerformerId,
+			CreatorID:   task.CreatorId,
+			ProjectID:   task.ProjectId,
+			ObserverIDs: task.ObserverIds,
+		}
+		if task.DueAt != nil {
+			due := task.DueAt.AsTime()
+			rec.DueAt = &due
+		}
+		if err := writer.Write(rec); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d tasks\n", count)
+	return nil
+}
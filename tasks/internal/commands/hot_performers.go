@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"tasks/proto/taskpb"
+)
+
+// HotPerformers implements `taskctl hot-performers`: it lists performers holding a large
+// share of one shard's tasks or requests.
+func HotPerformers(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("hot-performers", flag.ContinueOnError)
+	taskShare := fs.Float64("task-share", 0, "minimum share of a shard's tasks (default 0.1)")
+	minTasks := fs.Int64("min-tasks", 0, "minimum number of tasks (default 1000)")
+	requestShare := fs.Float64("request-share", 0, "minimum share of a shard's requests (default 0.1)")
+	minRequests := fs.Int64("min-requests", 0, "minimum requests in the last minute (default 600)")
+	limit := fs.Int("limit", 0, "maximum number of performers (default 20)")
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	res, err := client.ListHotPerformers(withAdminToken(ctx, *token), &taskpb.ListHotPerformersRequest{
+		TaskShare:    *taskShare,
+		MinTasks:     *minTasks,
+		RequestShare: *requestShare,
+		MinRequests:  *minRequests,
+		Limit:        int32(*limit),
+	})
+	if err != nil {
+		return err
+	}
+	if len(res.Performers) == 0 {
+		fmt.Println("no hot performers")
+		return nil
+	}
+	fmt.Printf("%-12s %-20s %10s %7s %10s %7s  %s\n", "performer", "shard", "tasks", "share", "req/min", "share", "placement")
+	for _, p := range res.Performers {
+		fmt.Printf("%-12d %-20s %10d %6.1f%% %10d %6.1f%%  %s\n",
+			p.PerformerId, p.ShardId, p.Tasks, 100*p.TaskShare, p.Requests, 100*p.RequestShare, placement(p.Override))
+	}
+	return nil
+}
+
+// PlacePerformer implements `taskctl place-performer`: it pins a performer to one shard,
+// splits it over several, or with -clear puts it back on the ring.
+func PlacePerformer(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("place-performer", flag.ContinueOnError)
+	performer := fs.Uint64("performer", 0, "performer id")
+	shards := fs.String("shards", "", "comma-separated shard ids; one pins, several split")
+	splitBy := fs.String("by", "project", "secondary key for splitting: project or creator")
+	unpin := fs.Bool("clear", false, "remove the override")
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	wait := fs.Bool("wait", false, "wait until rebalancing finishes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *performer == 0 {
+		return errors.New("-performer is required")
+	}
+	var ids []string
+	for _, id := range strings.Split(*shards, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 && !*unpin {
+		return errors.New("-shards or -clear is required")
+	}
+	if *unpin {
+		ids = nil
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx = withAdminToken(ctx, *token)
+
+	res, err := client.SetPerformerPlacement(ctx, &taskpb.SetPerformerPlacementRequest{
+		PerformerId: *performer,
+		ShardIds:    ids,
+		SplitBy:     *splitBy,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("performer %d: %s (topology version %d)\n", *performer,
+		placement(&taskpb.PerformerOverride{ShardIds: ids, SplitBy: *splitBy}), res.TopologyVersion)
+	if !res.RebalanceStarted {
+		fmt.Println("a rebalance was already running; run it again once it finishes to move the tasks")
+	}
+	if !*wait {
+		return nil
+	}
+	return waitForRebalance(ctx, client)
+}
+
+func placement(o *taskpb.PerformerOverride) string {
+	switch {
+	case o == nil || len(o.ShardIds) == 0:
+		return "ring"
+	case len(o.ShardIds) == 1:
+		return "pinned to " + o.ShardIds[0]
+	}
+	return fmt.Sprintf("split over %s by %s", strings.Join(o.ShardIds, ","), o.SplitBy)
+}
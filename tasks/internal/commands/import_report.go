@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// ImportReport is persisted after every batch so an interrupted import can be resumed:
+// rows that already have a task ID are skipped on the next run.
+type ImportReport struct {
+	Source    string               `json:"source"`
+	Format    string               `json:"format"`
+	DryRun    bool                 `json:"dry_run"`
+	UpdatedAt time.Time            `json:"updated_at"`
+	Total     int                  `json:"total"`
+	Created   int                  `json:"created"`
+	Failed    int                  `json:"failed"`
+	Rows      map[string]ReportRow `json:"rows"`
+}
+
+type ReportRow struct {
+	TaskID uint64 `json:"task_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// loadImportReport reads the report at path; a missing file yields an empty report.
+func loadImportReport(path string) (*ImportReport, error) {
+	report := &ImportReport{Rows: make(map[string]ReportRow)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, err
+	}
+	if report.Rows == nil {
+		report.Rows = make(map[string]ReportRow)
+	}
+	return report, nil
+}
+
+// imported reports whether the row was created by a previous run.
+func (r *ImportReport) imported(externalID string) bool {
+	return r.Rows[externalID].TaskID != 0
+}
+
+func (r *ImportReport) record(externalID string, taskID uint64, errMsg string) {
+	r.Rows[externalID] = ReportRow{TaskID: taskID, Error: errMsg}
+}
+
+// save recomputes the counters and writes the report atomically via a temp file.
+func (r *ImportReport) save(path string) error {
+	r.Total, r.Created, r.Failed = len(r.Rows), 0, 0
+	for _, row := range r.Rows {
+		if row.TaskID != 0 {
+			r.Created++
+		}
+		if row.Error != "" {
+			r.Failed++
+		}
+	}
+	r.UpdatedAt = time.Now().UTC()
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"tasks/internal/infrastructure/auth"
+	"tasks/internal/infrastructure/taskfile"
+	"tasks/internal/ports"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ImportTasks implements `taskctl import`: it reads a CSV/JSONL/Jira/Trello file, resolves
+// user emails through the auth service and streams the rows to ImportTasks in batches.
+func ImportTasks(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "file to import")
+	format := fs.String("format", taskfile.FormatCSV, "csv, jsonl, jira or trello")
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	dryRun := fs.Bool("dry-run", false, "validate rows without creating tasks")
+	reportPath := fs.String("report", "", "import report path (default <file>.report.json)")
+	batchSize := fs.Int("batch", 100, "rows per ImportTasks stream")
+	authURL := fs.String("auth-url", os.Getenv("AUTH_SERVICE_URL"), "auth service URL for email lookup")
+	authToken := fs.String("auth-token", os.Getenv("AUTH_SERVICE_TOKEN"), "auth service token")
+	userMapPath := fs.String("user-map", "", "CSV of handle,email pairs for Jira/Trello users")
+	defaultCreator := fs.Uint64("default-creator", 0, "creator ID for rows without a creator")
+	tenantID := fs.Uint64("tenant-id", 0, "tenant of every imported task")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("import: -file is required")
+	}
+	if *batchSize <= 0 {
+		*batchSize = 100
+	}
+	if *reportPath == "" {
+		*reportPath = *file + ".report.json"
+	}
+
+	users := taskfile.UserMap{}
+	if *userMapPath != "" {
+		f, err := os.Open(*userMapPath)
+		if err != nil {
+			return err
+		}
+		users, err = taskfile.LoadUserMap(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("user map: %w", err)
+		}
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	reader, err := taskfile.NewReader(*format, in, users)
+	if err != nil {
+		return err
+	}
+
+	report, err := loadImportReport(*reportPath)
+	if err != nil {
+		return fmt.Errorf("load report: %w", err)
+	}
+	report.Source, report.Format, report.DryRun = *file, *format, *dryRun
+
+	conn, client, err := dialTasks(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	imp := &importer{
+		client:         client,
+		report:         report,
+		reportPath:     *reportPath,
+		dryRun:         *dryRun,
+		defaultCreator: *defaultCreator,
+		tenantID:       *tenantID,
+	}
+	if *authURL != "" {
+		imp.users = auth.NewUserDirectory(*authURL, *authToken)
+	}
+
+	skipped := 0
+	batch := make([]*taskpb.ImportTasksRequest, 0, *batchSize)
+	for {
+		rec, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *taskfile.RowError
+		if errors.As(err, &rowErr) {
+			if !report.imported(rowErr.ExternalID) {
+				report.record(rowErr.ExternalID, 0, rowErr.Err.Error())
+			}
+			continue
+		}
+		if err != nil {
+			_ = report.save(*reportPath)
+			return err
+		}
+		if report.imported(rec.ExternalID) {
+			skipped++
+			continue
+		}
+
+		req, err := imp.toRequest(ctx, rec)
+		if err != nil {
+			report.record(rec.ExternalID, 0, err.Error())
+			continue
+		}
+		batch = append(batch, req)
+		if len(batch) == *batchSize {
+			if err := imp.send(ctx, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := imp.send(ctx, batch); err != nil {
+		return err
+	}
+	if err := report.save(*reportPath); err != nil {
+		return err
+	}
+
+	fmt.Printf("import %s: %d rows, %d created, %d failed, %d skipped from previous run (dry-run=%t)\n",
+		*file, report.Total, report.Created, report.Failed, skipped, *dryRun)
+	fmt.Printf("report written to %s\n", *reportPath)
+	return nil
+}
+
+type importer struct {
+	client         taskpb.TaskServiceClient
+	users          ports.UserDirectory
+	report         *ImportReport
+	reportPath     string
+	dryRun         bool
+	defaultCreator uint64
+	tenantID       uint64
+}
+
+// toRequest resolves emails to user IDs; explicit IDs in the file take precedence.
+func (imp *importer) toRequest(ctx context.Context, rec taskfile.Record) (*taskpb.ImportTasksRequest, error) {
+	performerID, err := imp.resolve(ctx, rec.PerformerID, rec.PerformerEmail)
+	if err != nil {
+		return nil, fmt.Errorf("performer: %w", err)
+	}
+	creatorID, err := imp.resolve(ctx, rec.CreatorID, rec.CreatorEmail)
+	if err != nil {
+		return nil, fmt.Errorf("creator: %w", err)
+	}
+	if creatorID == 0 {
+		creatorID = imp.defaultCreator
+	}
+
+	observerIDs := append([]uint64(nil), rec.ObserverIDs...)
+	for _, email := range rec.ObserverEmails {
+		id, err := imp.resolve(ctx, 0, email)
+		if err != nil {
+			return nil, fmt.Errorf("observer: %w", err)
+		}
+		observerIDs = append(observerIDs, id)
+	}
+
+	task := &taskpb.CreateTaskRequest{
+		Title:       rec.Title,
+		Description: rec.Description,
+		Status:      rec.Status,
+		PerformerId: performerID,
+		CreatorId:   creatorID,
+		ProjectId:   rec.ProjectID,
+		TenantId:    imp.tenantID,
+		ObserverIds: observerIDs,
+	}
+	if rec.DueAt != nil {
+		task.DueAt = timestamppb.New(*rec.DueAt)
+	}
+
+	return &taskpb.ImportTasksRequest{
+		ExternalId: rec.ExternalID,
+		DryRun:     imp.dryRun,
+		Task:       task,
+	}, nil
+}
+
+func (imp *importer) resolve(ctx context.Context, id uint64, email string) (uint64, error) {
+	if id != 0 || email == "" {
+		return id, nil
+	}
+	if imp.users == nil {
+		return 0, fmt.Errorf("cannot resolve %s: no -auth-url configured", email)
+	}
+	user, err := imp.users.FindByEmail(ctx, email)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(user.ID), nil
+}
+
+// send streams one batch and persists the report, so a crash loses at most one batch of progress.
+func (imp *importer) send(ctx context.Context, batch []*taskpb.ImportTasksRequest) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	stream, err := imp.client.ImportTasks(ctx)
+	if err != nil {
+		return err
+	}
+	for _, req := range batch {
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	for _, r := range resp.Results {
+		imp.report.record(r.ExternalId, r.TaskId, r.Error)
+	}
+	return imp.report.save(imp.reportPath)
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/schema"
+)
+
+// Migrate implements `taskctl migrate status|up|down|backfill`. It connects to the shard
+// databases directly rather than through the service, which refuses to start while the
+// shards are on different schema versions. The shards are DB_SHARD_URLS plus, when
+// REDIS_URL is set, the shards added at runtime.
+func Migrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: taskctl migrate status|up|down|backfill [-to N]")
+	}
+	action := args[0]
+	fs := flag.NewFlagSet("migrate "+action, flag.ContinueOnError)
+	to := fs.Int("to", -1, "target version (up: default the latest; down: default one below the current)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	sm, closeShards, err := openShards(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer closeShards()
+
+	switch action {
+	case "status":
+	case "up":
+		err = sm.MigrateUp(ctx, max(*to, 0))
+	case "down":
+		target := *to
+		if target < 0 {
+			statuses, err := sm.SchemaStatus(ctx)
+			if err != nil {
+				return err
+			}
+			for _, st := range statuses {
+				target = max(target, st.Version-1)
+			}
+		}
+		err = sm.MigrateDown(ctx, max(target, 0))
+	case "backfill":
+		err = sm.RunBackfills(ctx)
+	default:
+		return fmt.Errorf("unknown action %q; want status, up, down or backfill", action)
+	}
+	if err != nil {
+		return err
+	}
+	return printSchemaStatus(ctx, sm)
+}
+
+// openShards connects to the shard databases. When Redis is configured, or required, it
+// also loads the shared topology and keeps Redis open until the returned func is called.
+func openShards(ctx context.Context, needRedis bool) (*shard.ShardManager, func(), error) {
+	shard.InitShardManager()
+	if !cache.Configured() {
+		if needRedis {
+			return nil, nil, errors.New("REDIS_URL (or REDIS_SENTINEL_ADDRS) is not set")
+		}
+		return shard.ShardMgr, func() {}, nil
+	}
+	cache.InitRedisFromEnv()
+	if err := shard.ShardMgr.SyncTopology(ctx); err != nil {
+		cache.CloseRedis()
+		return nil, nil, fmt.Errorf("load shard topology: %w", err)
+	}
+	return shard.ShardMgr, func() { cache.CloseRedis() }, nil
+}
+
+// printSchemaStatus prints every shard's version and returns an error if they differ.
+func printSchemaStatus(ctx context.Context, sm *shard.ShardManager) error {
+	statuses, err := sm.SchemaStatus(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("latest version: %d\n", schema.Default.Latest())
+	fmt.Printf("%5s  %-20s %7s  %-12s %s\n", "index", "id", "version", "pending", "backfilling")
+	for _, st := range statuses {
+		fmt.Printf("%5d  %-20s %7d  %-12s %s\n", st.Shard, st.ShardID, st.Version, versionList(st.Pending), versionList(st.Backfilling))
+	}
+	return sm.CheckSchema(ctx)
+}
+
+func versionList(versions []int) string {
+	if len(versions) == 0 {
+		return "-"
+	}
+	s := make([]string, len(versions))
+	for i, v := range versions {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, ",")
+}
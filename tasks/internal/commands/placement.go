@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"tasks/proto/taskpb"
+)
+
+// ExplainPlacement implements `taskctl explain -task N` or `-performer N`: where a task
+// should live under the current placement, and where its rows and directory entry are.
+// It fails when a task is found off its expected shard or the directory disagrees.
+func ExplainPlacement(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	taskID := fs.Uint64("task", 0, "task id")
+	performerID := fs.Uint64("performer", 0, "performer id, explained as for a new task of the performer")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *taskID == 0 && *performerID == 0 {
+		return errors.New("-task or -performer is required")
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	res, err := client.ExplainPlacement(withAdminToken(ctx, *token), &taskpb.ExplainPlacementRequest{TaskId: *taskID, PerformerId: *performerID})
+	if err != nil {
+		return err
+	}
+
+	if res.TaskId != 0 {
+		fmt.Printf("task %d\n", res.TaskId)
+		if !res.TaskFound {
+			fmt.Println("  not found on any shard")
+		}
+	}
+	if res.TaskId == 0 || res.TaskFound {
+		fmt.Printf("  key:       performer %d, project %d, creator %d, tenant %d\n", res.PerformerId, res.ProjectId, res.CreatorId, res.TenantId)
+		fmt.Printf("  shard key: %s\n", res.ShardKey)
+		if res.Vnode != "" {
+			fmt.Printf("  ring:      key %q hash %d -> vnode %s (hash %d) on shard %d (%s)\n", res.RingKey, res.Hash, res.Vnode, res.VnodeHash, res.RingShardIndex, res.RingShardId)
+		}
+		if res.ExpectedShardIndex >= 0 {
+			fmt.Printf("  placement: shard %d (%s) by %s\n", res.ExpectedShardIndex, res.ExpectedShardId, res.Rule)
+		} else {
+			fmt.Println("  placement: any ring shard (no key, round-robin)")
+		}
+	}
+	switch {
+	case res.DirectoryError != "":
+		fmt.Printf("  directory: lookup failed: %s\n", res.DirectoryError)
+	case res.TaskId == 0:
+	case res.DirectoryShardIndex >= 0:
+		fmt.Printf("  directory: shard %d\n", res.DirectoryShardIndex)
+	default:
+		fmt.Println("  directory: no entry")
+	}
+	for _, c := range res.Copies {
+		state := "live"
+		if c.Deleted {
+			state = "soft-deleted"
+		}
+		fmt.Printf("  row:       shard %d (%s), performer %d, tenant %d, %s, updated %s\n",
+			c.ShardIndex, c.ShardId, c.PerformerId, c.TenantId, state, c.UpdatedAt.AsTime().Format("2006-01-02 15:04:05"))
+	}
+	if res.RebalancePending {
+		fmt.Println("a rebalance is pending; rows off the expected shard may be moves in flight")
+	}
+
+	var problems []string
+	if len(res.Copies) > 1 {
+		problems = append(problems, fmt.Sprintf("the task is on %d shards", len(res.Copies)))
+	}
+	for _, c := range res.Copies {
+		if res.ExpectedShardIndex >= 0 && c.ShardIndex != res.ExpectedShardIndex {
+			problems = append(problems, fmt.Sprintf("row on shard %d, placement says %d", c.ShardIndex, res.ExpectedShardIndex))
+		}
+		if res.DirectoryShardIndex >= 0 && c.ShardIndex != res.DirectoryShardIndex {
+			problems = append(problems, fmt.Sprintf("row on shard %d, directory says %d", c.ShardIndex, res.DirectoryShardIndex))
+		}
+	}
+	if res.TaskId != 0 && res.TaskFound && res.DirectoryShardIndex < 0 && res.DirectoryError == "" {
+		problems = append(problems, "no directory entry")
+	}
+	if len(problems) > 0 && !res.RebalancePending {
+		for _, p := range problems {
+			fmt.Println("problem:", p)
+		}
+		return fmt.Errorf("%d problems found; `taskctl shard-verify -repair` fixes them", len(problems))
+	}
+	return nil
+}
+
+// DumpRing implements `taskctl dump-ring [-vnodes]`: each shard's vnodes and share of the
+// hash space, and with -vnodes every vnode in ring order.
+func DumpRing(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("dump-ring", flag.ContinueOnError)
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	vnodes := fs.Bool("vnodes", false, "also list every vnode")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	res, err := client.DumpRing(withAdminToken(ctx, *token), &taskpb.DumpRingRequest{IncludeVnodes: *vnodes})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("topology version %d, shard key %s, %d vnodes per unit of weight\n", res.TopologyVersion, res.ShardKey, res.VnodesPerShard)
+	fmt.Printf("%5s  %-20s %-9s %6s %7s %8s  %s\n", "index", "id", "state", "weight", "vnodes", "share", "note")
+	for _, s := range res.Shards {
+		note := ""
+		switch {
+		case s.DedicatedTenantId != 0:
+			note = fmt.Sprintf("dedicated to tenant %d", s.DedicatedTenantId)
+		case s.Vnodes == 0:
+			note = "off the ring"
+		}
+		fmt.Printf("%5d  %-20s %-9s %6g %7d %7.2f%%  %s\n", s.Index, s.Id, s.State, s.Weight, s.Vnodes, 100*s.Share, note)
+	}
+	if *vnodes {
+		fmt.Printf("\n%10s  %5s  %s\n", "hash", "shard", "vnode")
+		for _, v := range res.Vnodes {
+			fmt.Printf("%10d  %5d  %s\n", v.Hash, v.ShardIndex, v.Key)
+		}
+	}
+	return nil
+}
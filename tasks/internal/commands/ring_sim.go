@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"tasks/proto/taskpb"
+)
+
+// RingSim implements `taskctl ring-sim`: it shows how keys and performers would be spread
+// over the shards with the proposed weights, and how many performers would move.
+func RingSim(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("ring-sim", flag.ContinueOnError)
+	weights := make(map[string]float64)
+	fs.Func("weight", "proposed weight as id=weight; repeatable", func(v string) error {
+		id, w, ok := strings.Cut(v, "=")
+		if !ok || id == "" {
+			return errors.New("want id=weight")
+		}
+		f, err := strconv.ParseFloat(w, 64)
+		if err != nil {
+			return err
+		}
+		weights[id] = f
+		return nil
+	})
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(weights) == 0 {
+		return errors.New("at least one -weight is required")
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	res, err := client.SimulateShardWeights(withAdminToken(ctx, *token), &taskpb.SimulateShardWeightsRequest{Weights: weights})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%5s  %-20s %13s %15s %21s %21s\n", "index", "id", "weight", "key share", "performers", "tasks")
+	for _, d := range res.Shards {
+		fmt.Printf("%5d  %-20s %5g -> %-5g %6.2f%% -> %5.2f%% %9d -> %-9d %9d -> %-9d\n",
+			d.Index, d.Id, d.CurrentWeight, d.ProposedWeight, 100*d.CurrentShare, 100*d.ProposedShare,
+			d.CurrentPerformers, d.ProposedPerformers, d.CurrentTasks, d.ProposedTasks)
+	}
+	fmt.Printf("performers moving: %d of %d (%.2f%%), tasks moving: %d of %d\n",
+		res.PerformersMoved, res.PerformersTotal, percent(res.PerformersMoved, res.PerformersTotal),
+		res.TasksMoved, res.TasksTotal)
+	return nil
+}
+
+func percent(n, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
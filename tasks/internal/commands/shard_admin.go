@@ -0,0 +1,224 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"tasks/proto/taskpb"
+	"time"
+)
+
+// AddShard implements `taskctl add-shard`: it registers a new shard DSN with the running
+// service and optionally waits for the rebalance it triggers.
+func AddShard(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("add-shard", flag.ContinueOnError)
+	dsn := fs.String("dsn", "", "postgres DSN of the new shard")
+	id := fs.String("id", "", "stable shard id (default: the new slot index)")
+	weight := fs.Float64("weight", 0, "share of the ring relative to other shards (default 1)")
+	var replicas []string
+	fs.Func("replica", "postgres DSN of a read replica of the new shard (repeatable)", func(v string) error {
+		replicas = append(replicas, v)
+		return nil
+	})
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	wait := fs.Bool("wait", false, "wait until rebalancing finishes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dsn == "" {
+		return errors.New("-dsn is required")
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx = withAdminToken(ctx, *token)
+
+	res, err := client.AddShard(ctx, &taskpb.AddShardRequest{Dsn: *dsn, Id: *id, Weight: *weight, Replicas: replicas})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("shard %d (%s) added (topology version %d, %d slots)\n", res.ShardIndex, res.ShardId, res.TopologyVersion, res.ShardCount)
+	if !res.RebalanceStarted {
+		fmt.Println("a rebalance was already running; run it again once it finishes to cover the new shard")
+	}
+	if !*wait {
+		return nil
+	}
+	return waitForRebalance(ctx, client)
+}
+
+// DrainShard implements `taskctl drain-shard`: it takes a shard off the ring and lets
+// the rebalance move its data away and remove it.
+func DrainShard(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("drain-shard", flag.ContinueOnError)
+	id := fs.String("id", "", "id of the shard to drain")
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	wait := fs.Bool("wait", false, "wait until rebalancing finishes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return errors.New("-id is required")
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx = withAdminToken(ctx, *token)
+
+	res, err := client.DrainShard(ctx, &taskpb.DrainShardRequest{ShardId: *id})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("shard %d (%s) is %s (topology version %d)\n", res.Shard.Index, res.Shard.Id, res.Shard.State, res.TopologyVersion)
+	if !res.RebalanceStarted {
+		fmt.Println("a rebalance was already running; the shard is removed after a pass that starts after the drain")
+	}
+	if !*wait {
+		return nil
+	}
+	return waitForRebalance(ctx, client)
+}
+
+// SetShardWeight implements `taskctl shard-weight`: it changes a shard's share of the
+// ring and lets the rebalance move the affected performers. Run `taskctl ring-sim` first
+// to see how many would move.
+func SetShardWeight(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("shard-weight", flag.ContinueOnError)
+	id := fs.String("id", "", "id of the shard")
+	weight := fs.Float64("weight", 0, "new weight")
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	wait := fs.Bool("wait", false, "wait until rebalancing finishes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *id == "" || *weight <= 0 {
+		return errors.New("-id and a positive -weight are required")
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx = withAdminToken(ctx, *token)
+
+	res, err := client.SetShardWeight(ctx, &taskpb.SetShardWeightRequest{ShardId: *id, Weight: *weight})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("shard %d (%s) has weight %g (topology version %d)\n", res.Shard.Index, res.Shard.Id, res.Shard.Weight, res.TopologyVersion)
+	if !res.RebalanceStarted {
+		fmt.Println("a rebalance was already running; run it again once it finishes to apply the new weight")
+	}
+	if !*wait {
+		return nil
+	}
+	return waitForRebalance(ctx, client)
+}
+
+// ListShards implements `taskctl shards`.
+func ListShards(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("shards", flag.ContinueOnError)
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	res, err := client.ListShards(withAdminToken(ctx, *token), &taskpb.ListShardsRequest{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("topology version %d, shard key %s\n", res.TopologyVersion, res.ShardKey)
+	for _, s := range res.Shards {
+		fmt.Printf("%5d  %-20s %-9s weight %g\n", s.Index, s.Id, s.State, s.Weight)
+	}
+	for _, o := range res.Overrides {
+		fmt.Printf("performer %d: %s\n", o.PerformerId, placement(o))
+	}
+	for _, p := range res.Tenants {
+		fmt.Printf("tenant %d: %s\n", p.TenantId, tenantPlacement(p))
+	}
+	printReplicas(res.Replicas)
+	return nil
+}
+
+func waitForRebalance(ctx context.Context, client taskpb.ShardAdminClient) error {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		p, err := client.GetRebalanceProgress(ctx, &taskpb.GetRebalanceProgressRequest{})
+		if err != nil {
+			return err
+		}
+		printProgress(p)
+		if !p.Running {
+			return nil
+		}
+	}
+}
+
+// RebalanceStatus implements `taskctl rebalance-status`.
+func RebalanceStatus(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rebalance-status", flag.ContinueOnError)
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	p, err := client.GetRebalanceProgress(withAdminToken(ctx, *token), &taskpb.GetRebalanceProgressRequest{})
+	if err != nil {
+		return err
+	}
+	if p.StartedAt == nil {
+		fmt.Println("no rebalance has run yet")
+		return nil
+	}
+	printProgress(p)
+	return nil
+}
+
+func printProgress(p *taskpb.RebalanceProgress) {
+	state := "finished"
+	if p.Running {
+		state = "running"
+	}
+	fmt.Printf("%s: shards %d/%d, performers %d/%d, tasks moved %d, failed %d, remaining %d of %d (topology version %d)\n",
+		state, p.ShardsScanned, p.ShardsTotal, p.PerformersDone, p.PerformersTotal,
+		p.TasksMoved, p.TasksFailed, p.TasksRemaining, p.TasksTotal, p.TopologyVersion)
+	if p.ResumedMoves > 0 {
+		fmt.Printf("resumed %d interrupted moves\n", p.ResumedMoves)
+	}
+	if p.LastError != "" {
+		fmt.Printf("last error: %s\n", p.LastError)
+	}
+}
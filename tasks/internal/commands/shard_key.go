@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"tasks/proto/taskpb"
+)
+
+// SetShardKey implements `taskctl shard-key`: it switches the task field placing tasks
+// on the ring and starts the rebalance that moves them.
+func SetShardKey(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("shard-key", flag.ContinueOnError)
+	key := fs.String("key", "", "performer, tenant or project")
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	wait := fs.Bool("wait", false, "wait until rebalancing finishes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *key == "" {
+		return errors.New("-key is required")
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx = withAdminToken(ctx, *token)
+
+	res, err := client.SetShardKey(ctx, &taskpb.SetShardKeyRequest{ShardKey: *key})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("shard key %s (topology version %d)\n", *key, res.TopologyVersion)
+	if !res.RebalanceStarted {
+		fmt.Println("a rebalance was already running; run it again once it finishes to move the tasks")
+	}
+	if !*wait {
+		return nil
+	}
+	return waitForRebalance(ctx, client)
+}
+
+// PlaceTenant implements `taskctl place-tenant`: it dedicates shards to a tenant, or
+// with -clear puts the tenant back on the ring.
+func PlaceTenant(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("place-tenant", flag.ContinueOnError)
+	tenant := fs.Uint64("tenant", 0, "tenant id")
+	shards := fs.String("shards", "", "comma-separated ids of the shards to dedicate")
+	unpin := fs.Bool("clear", false, "remove the placement")
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	wait := fs.Bool("wait", false, "wait until rebalancing finishes")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *tenant == 0 {
+		return errors.New("-tenant is required")
+	}
+	var ids []string
+	for _, id := range strings.Split(*shards, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 && !*unpin {
+		return errors.New("-shards or -clear is required")
+	}
+	if *unpin {
+		ids = nil
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx = withAdminToken(ctx, *token)
+
+	res, err := client.SetTenantPlacement(ctx, &taskpb.SetTenantPlacementRequest{TenantId: *tenant, ShardIds: ids})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("tenant %d: %s (topology version %d)\n", *tenant,
+		tenantPlacement(&taskpb.TenantPlacement{ShardIds: ids}), res.TopologyVersion)
+	if !res.RebalanceStarted {
+		fmt.Println("a rebalance was already running; run it again once it finishes to move the tasks")
+	}
+	if !*wait {
+		return nil
+	}
+	return waitForRebalance(ctx, client)
+}
+
+func tenantPlacement(p *taskpb.TenantPlacement) string {
+	if p == nil || len(p.ShardIds) == 0 {
+		return "ring"
+	}
+	return "dedicated shards " + strings.Join(p.ShardIds, ",")
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"tasks/proto/taskpb"
+)
+
+// ShardStatus implements `taskctl shard-status`: it prints each shard's circuit breaker,
+// last ping and connection pool, each replica's lag and the task cache counters. It exits non-zero if a breaker
+// is open.
+func ShardStatus(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("shard-status", flag.ContinueOnError)
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	res, err := client.GetShardStatus(withAdminToken(ctx, *token), &taskpb.GetShardStatusRequest{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%5s  %-20s %-9s %-9s %8s %9s %6s %6s %9s\n", "index", "id", "state", "breaker", "failures", "ping", "open", "in use", "waits")
+	open := 0
+	for _, s := range res.Shards {
+		ping := fmt.Sprintf("%.1fms", s.PingLatencyMs)
+		if s.CheckedAt == nil {
+			ping = "-"
+		}
+		fmt.Printf("%5d  %-20s %-9s %-9s %8d %9s %6d %6d %9d\n",
+			s.Index, s.Id, s.State, s.Breaker, s.ConsecutiveFailures, ping, s.Pool.GetOpen(), s.Pool.GetInUse(), s.Pool.GetWaitCount())
+		if s.PingError != "" {
+			fmt.Printf("       ping error: %s\n", s.PingError)
+		}
+		if s.Breaker != "closed" && s.LastError != "" {
+			fmt.Printf("       last error: %s\n", s.LastError)
+		}
+		if s.Breaker == "open" {
+			open++
+		}
+	}
+	printReplicas(res.Replicas)
+	printCache(res.Cache)
+	if open > 0 {
+		return fmt.Errorf("%d shards unavailable", open)
+	}
+	return nil
+}
+
+func printReplicas(replicas []*taskpb.ReplicaStatus) {
+	for _, r := range replicas {
+		health := fmt.Sprintf("lag %.1fs", r.LagSeconds)
+		switch {
+		case r.Error != "":
+			health = "error: " + r.Error
+		case r.CheckedAt == nil:
+			health = "not checked yet"
+		case !r.Healthy:
+			health += " (too far behind, reads go to the primary)"
+		}
+		fmt.Printf("replica of %d  %s  %s\n", r.ShardIndex, r.Dsn, health)
+	}
+}
+
+func printCache(c *taskpb.CacheStatus) {
+	if c == nil {
+		return
+	}
+	redis := "available"
+	if !c.RedisAvailable {
+		redis = "unavailable"
+	}
+	fmt.Printf("redis %s  redis hits %d misses %d  coalesced %d\n", redis, c.RedisHits, c.RedisMisses, c.Coalesced)
+	if c.Capacity == 0 {
+		fmt.Println("local cache disabled")
+		return
+	}
+	fmt.Printf("local cache %d/%d  hits %d misses %d  evictions %d  invalidations %d\n",
+		c.Entries, c.Capacity, c.LocalHits, c.LocalMisses, c.Evictions, c.Invalidations)
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"tasks/proto/taskpb"
+)
+
+// ShardVerify implements `taskctl shard-verify`: it reports drift between shards, ring
+// placement and the Redis task->shard mappings, and fixes it with -repair. It fails when
+// problems were found and not repaired, so it can run from cron or CI.
+func ShardVerify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("shard-verify", flag.ContinueOnError)
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	repair := fs.Bool("repair", false, "fix every category of problems found")
+	verbose := fs.Bool("v", false, "list individual findings")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	res, err := client.VerifyShards(withAdminToken(ctx, *token), &taskpb.VerifyShardsRequest{Repair: *repair})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("scanned %d tasks on %d shards\n", res.TasksScanned, res.ShardsScanned)
+	if res.RebalancePending {
+		fmt.Println("a rebalance is running or was interrupted; duplicates and misplaced tasks may be moves in progress")
+	}
+	if res.TasksMoving > 0 {
+		fmt.Printf("%d tasks are being moved to another shard and were skipped; run again once the moves finish\n", res.TasksMoving)
+	}
+	f, r := res.Found, res.Repaired
+	fmt.Printf("%-18s %8s %8s\n", "", "found", "repaired")
+	fmt.Printf("%-18s %8d %8d\n", "duplicates", f.Duplicates, r.Duplicates)
+	fmt.Printf("%-18s %8d %8s\n", "misplaced", f.Misplaced, "-")
+	fmt.Printf("%-18s %8d %8d\n", "stale mappings", f.StaleMappings, r.StaleMappings)
+	fmt.Printf("%-18s %8d %8d\n", "missing mappings", f.MissingMappings, r.MissingMappings)
+	fmt.Printf("%-18s %8d %8d\n", "orphan observers", f.OrphanObservers, r.OrphanObservers)
+	if res.RebalanceStarted {
+		fmt.Println("started a rebalance to move the misplaced tasks; see `taskctl rebalance-status`")
+	}
+
+	if *verbose {
+		for _, d := range res.Duplicates {
+			fmt.Printf("duplicate task %d on shards %v, keeping shard %d\n", d.TaskId, d.Shards, d.KeptShard)
+		}
+		for _, m := range res.Misplaced {
+			fmt.Printf("misplaced task %d (performer %d) on shard %d, ring says %d\n", m.TaskId, m.PerformerId, m.Shard, m.ExpectedShard)
+		}
+		for _, m := range res.StaleMappings {
+			fmt.Printf("stale mapping task %d -> shard %d, actual shard %d\n", m.TaskId, m.MappedShard, m.ActualShard)
+		}
+		for _, m := range res.MissingMappings {
+			fmt.Printf("missing mapping task %d, actual shard %d\n", m.TaskId, m.ActualShard)
+		}
+		for _, o := range res.OrphanObservers {
+			fmt.Printf("orphan observer %d on shard %d (task %d)\n", o.ObserverId, o.Shard, o.TaskId)
+		}
+		for _, id := range res.MovingTasks {
+			fmt.Printf("moving task %d, skipped\n", id)
+		}
+	}
+
+	total := f.Duplicates + f.Misplaced + f.StaleMappings + f.MissingMappings + f.OrphanObservers
+	if total > 0 && !*repair {
+		return fmt.Errorf("%d problems found; run with -repair to fix them", total)
+	}
+	return nil
+}
+
+// RebuildDirectory implements `taskctl rebuild-directory`, e.g. after Redis lost its data.
+func RebuildDirectory(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rebuild-directory", flag.ContinueOnError)
+	addr := fs.String("addr", tasksAddr(), "tasks gRPC address")
+	token := fs.String("token", os.Getenv("ADMIN_TOKEN"), "admin token")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	conn, client, err := dialAdmin(*addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	res, err := client.RebuildDirectory(withAdminToken(ctx, *token), &taskpb.RebuildDirectoryRequest{})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("assigned %d tasks from %d shards\n", res.TasksAssigned, res.ShardsScanned)
+	if res.Duplicates > 0 {
+		fmt.Printf("%d tasks are on several shards; run `taskctl shard-verify -repair` to clean them up\n", res.Duplicates)
+	}
+	if !res.Durable {
+		fmt.Println("note: DIRECTORY_DB_URL is not set, so the directory lives in Redis only")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches @handle when the @ starts a word, so emails like a@b.c are ignored.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9_][A-Za-z0-9_.\-]*[A-Za-z0-9_]|[A-Za-z0-9_])`)
+
+// ParseMentions returns the distinct handles mentioned in text, lower-cased, in order of appearance.
+func ParseMentions(text string) []string {
+	matches := mentionPattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(matches))
+	handles := make([]string, 0, len(matches))
+	for _, m := range matches {
+		h := strings.ToLower(m[1])
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		handles = append(handles, h)
+	}
+	return handles
+}
+
+// NewMentions returns the IDs in current that are not in previous.
+func NewMentions(previous, current []uint) []uint {
+	old := make(map[uint]struct{}, len(previous))
+	for _, id := range previous {
+		old[id] = struct{}{}
+	}
+	var added []uint
+	for _, id := range current {
+		if _, ok := old[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	return added
+}
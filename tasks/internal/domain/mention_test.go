@@ -0,0 +1,28 @@
+package domain_test
+
+import (
+	"reflect"
+	"tasks/internal/domain"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: nil},
+		{name: "single", text: "@alice please review", want: []string{"alice"}},
+		{name: "dedup and lower-case", text: "cc @Bob, @bob and @carol.", want: []string{"bob", "carol"}},
+		{name: "email is not a mention", text: "mail bob@example.com", want: nil},
+		{name: "dotted handle", text: "(@john.doe)", want: []string{"john.doe"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := domain.ParseMentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseMentions(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
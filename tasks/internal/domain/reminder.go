@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// ReminderKind tells whether a reminder fires before or after the due date.
+type ReminderKind string
+
+const (
+	ReminderDueSoon ReminderKind = "due_soon"
+	ReminderOverdue ReminderKind = "overdue"
+)
+
+// Reminder is one notification about a task deadline. A reminder is identified by
+// (TaskID, Kind, Offset, DueAt), so moving the due date re-arms all reminders.
+type Reminder struct {
+	TaskID uint
+	Kind   ReminderKind
+	Offset time.Duration
+	DueAt  time.Time
+}
+
+// ReminderPolicy configures when reminders fire.
+// DueSoon offsets are measured before the due date, Overdue offsets after it
+// (an offset of 0 fires as soon as the task is overdue, larger ones escalate).
+// Reminders whose fire time is older than Lookback are skipped, so a long outage or a
+// task created with a past deadline does not produce a burst of stale reminders.
+type ReminderPolicy struct {
+	DueSoon  []time.Duration
+	Overdue  []time.Duration
+	Lookback time.Duration
+}
+
+// Window returns the due-date range that may have a reminder firing at now.
+func (p ReminderPolicy) Window(now time.Time) (from, to time.Time) {
+	return now.Add(-maxDuration(p.Overdue) - p.Lookback), now.Add(maxDuration(p.DueSoon))
+}
+
+// Due returns the reminders for task that should have fired by now.
+// Tasks without a due date or with status "done" never get reminders.
+func (p ReminderPolicy) Due(task Task, now time.Time) []Reminder {
+	if task.DueAt == nil || task.Status == "done" {
+		return nil
+	}
+	due := *task.DueAt
+
+	var reminders []Reminder
+	fire := func(kind ReminderKind, offset time.Duration, at time.Time) {
+		if at.After(now) || now.Sub(at) > p.Lookback {
+			return
+		}
+		reminders = append(reminders, Reminder{TaskID: task.ID, Kind: kind, Offset: offset, DueAt: due})
+	}
+	if now.Before(due) {
+		for _, offset := range p.DueSoon {
+			fire(ReminderDueSoon, offset, due.Add(-offset))
+		}
+	} else {
+		for _, offset := range p.Overdue {
+			fire(ReminderOverdue, offset, due.Add(offset))
+		}
+	}
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i].Offset < reminders[j].Offset })
+	return reminders
+}
+
+func maxDuration(ds []time.Duration) time.Duration {
+	var m time.Duration
+	for _, d := range ds {
+		if d > m {
+			m = d
+		}
+	}
+	return m
+}
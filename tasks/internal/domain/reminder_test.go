@@ -0,0 +1,44 @@
+package domain_test
+
+import (
+	"reflect"
+	"tasks/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestReminderPolicyDue(t *testing.T) {
+	policy := domain.ReminderPolicy{
+		DueSoon:  []time.Duration{24 * time.Hour, time.Hour},
+		Overdue:  []time.Duration{0, 24 * time.Hour},
+		Lookback: 2 * time.Hour,
+	}
+	due := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	task := domain.Task{ID: 7, Status: "new", DueAt: &due}
+
+	tests := []struct {
+		name string
+		task domain.Task
+		now  time.Time
+		want []domain.Reminder
+	}{
+		{name: "far from due", task: task, now: due.Add(-48 * time.Hour), want: nil},
+		{name: "one day before", task: task, now: due.Add(-23 * time.Hour),
+			want: []domain.Reminder{{TaskID: 7, Kind: domain.ReminderDueSoon, Offset: 24 * time.Hour, DueAt: due}}},
+		{name: "stale day-before reminder skipped", task: task, now: due.Add(-30 * time.Minute),
+			want: []domain.Reminder{{TaskID: 7, Kind: domain.ReminderDueSoon, Offset: time.Hour, DueAt: due}}},
+		{name: "just overdue", task: task, now: due.Add(time.Minute),
+			want: []domain.Reminder{{TaskID: 7, Kind: domain.ReminderOverdue, Offset: 0, DueAt: due}}},
+		{name: "escalation", task: task, now: due.Add(25 * time.Hour),
+			want: []domain.Reminder{{TaskID: 7, Kind: domain.ReminderOverdue, Offset: 24 * time.Hour, DueAt: due}}},
+		{name: "done tasks are quiet", task: domain.Task{ID: 7, Status: "done", DueAt: &due}, now: due.Add(time.Minute), want: nil},
+		{name: "no due date", task: domain.Task{ID: 7, Status: "new"}, now: due, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Due(tt.task, tt.now); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Due() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
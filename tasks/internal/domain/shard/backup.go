@@ -0,0 +1,475 @@
+package shard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"tasks/internal/infrastructure/backup"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/persistence"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRestoreTargetNotEmpty means a restore was refused because the shards hold tasks.
+	ErrRestoreTargetNotEmpty = errors.New("target shards already hold tasks")
+	// ErrBackupTooNew means the archive was taken on a newer schema than the target's.
+	ErrBackupTooNew = errors.New("backup was taken on a newer schema version")
+)
+
+// Backup writes every shard's tasks with their observers and sent reminders, its saved
+// views, the task->shard directory and the ID counter to out as a backup archive (see
+// package backup). It holds the rebalance lease, so no rebalance moves tasks meanwhile, and reads
+// every shard in a repeatable-read transaction begun before any rows are read, so each
+// shard is exported as of one point in time. The transactions begin one after another,
+// so the shards' points in time differ slightly, and a task that a single-task move or
+// repair copies in between can be exported twice; Restore keeps the newer copy. Tasks
+// that a recorded move is copying are exported once: from the source before the switch,
+// from the target after it. The directory and the counter are read after the shards.
+func (sm *ShardManager) Backup(ctx context.Context, out io.Writer) (backup.Manifest, error) {
+	var m backup.Manifest
+	if err := sm.CheckSchema(ctx); err != nil {
+		return m, err
+	}
+	m.SchemaVersion, m.ShardKey = sm.SchemaVersion(), string(sm.ShardKey())
+	pending, err := RebalancePending(ctx)
+	if err != nil {
+		return m, err
+	}
+	if pending {
+		return m, ErrRebalanceRunning
+	}
+	ctx, release, err := holdRebalanceLease(ctx)
+	if err != nil {
+		return m, err
+	}
+	defer release()
+
+	infos := sm.Shards()
+	snapshots := make(map[int]*gorm.DB)
+	defer func() {
+		for _, tx := range snapshots {
+			tx.Rollback()
+		}
+	}()
+	skip := make(map[int]map[uint]bool)
+	var moves []taskMove
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		tx := db.WithContext(ctx).Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if tx.Error != nil {
+			return m, fmt.Errorf("shard %d: %w", idx, tx.Error)
+		}
+		snapshots[idx] = tx
+		// the first query fixes the snapshot
+		var shardMoves []taskMove
+		if err := tx.Find(&shardMoves).Error; err != nil {
+			return m, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		moves = append(moves, shardMoves...)
+		skip[idx] = make(map[uint]bool)
+	}
+	m.CreatedAt = time.Now().UTC()
+	for _, mv := range moves {
+		// the copy the move would discard
+		discard := mv.ToShard
+		if mv.Phase == taskMoveSwitching {
+			discard = mv.FromShard
+		}
+		if s, ok := skip[discard]; ok {
+			s[mv.TaskID] = true
+		}
+	}
+
+	w, err := backup.NewWriter()
+	if err != nil {
+		return m, err
+	}
+	defer w.Discard()
+
+	indexes := make([]int, 0, len(snapshots))
+	for idx := range snapshots {
+		indexes = append(indexes, idx)
+	}
+	sort.Ints(indexes)
+	for _, idx := range indexes {
+		s := backup.Shard{Index: idx, ID: infos[idx].ID}
+		skipped, err := backupShard(snapshots[idx], w, skip[idx], &s, &m.MaxTaskID)
+		if err != nil {
+			return m, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		m.SkippedMoves += skipped
+		if err := backupViews(snapshots[idx], w, &s); err != nil {
+			return m, fmt.Errorf("shard %d: views: %w", idx, err)
+		}
+		m.Shards = append(m.Shards, s)
+	}
+
+	err = directory.Default.Each(ctx, func(taskID uint, shardIndex int) error {
+		m.DirectoryEntries++
+		return w.AddDirectoryEntry(backup.DirectoryEntry{TaskID: taskID, Shard: shardIndex})
+	})
+	if err != nil {
+		return m, fmt.Errorf("read directory: %w", err)
+	}
+	if m.IDCounter, err = cache.LastTaskID(ctx); err != nil {
+		return m, fmt.Errorf("read id counter: %w", err)
+	}
+
+	if err := w.Finish(out, m); err != nil {
+		return m, err
+	}
+	m.Format = backup.FormatVersion
+	log.Printf("[backup] %d shards, %d tasks up to id %d, %d views, %d directory entries", len(m.Shards), countTasks(m.Shards), m.MaxTaskID, countViews(m.Shards), m.DirectoryEntries)
+	return m, nil
+}
+
+func backupShard(tx *gorm.DB, w *backup.Writer, skip map[uint]bool, s *backup.Shard, maxID *uint) (int, error) {
+	skipped := 0
+	var batch []persistence.Task
+	err := tx.Unscoped().Preload("Observers").
+		FindInBatches(&batch, moveBatchSize, func(_ *gorm.DB, _ int) error {
+			ids := make([]uint, len(batch))
+			for i, t := range batch {
+				ids[i] = t.ID
+			}
+			var reminders []persistence.Reminder
+			if err := tx.Where("task_id IN ?", ids).Order("id").Find(&reminders).Error; err != nil {
+				return err
+			}
+			byTask := make(map[uint][]persistence.Reminder)
+			for _, r := range reminders {
+				byTask[r.TaskId] = append(byTask[r.TaskId], r)
+			}
+			for _, t := range batch {
+				if skip[t.ID] {
+					skipped++
+					continue
+				}
+				row := backup.Task{Task: t, Reminders: byTask[t.ID]}
+				for _, o := range t.Observers {
+					row.Observers = append(row.Observers, o.UserId)
+				}
+				row.Task.Observers = nil
+				if err := w.AddTask(s.ID, row); err != nil {
+					return err
+				}
+				s.Tasks++
+				*maxID = max(*maxID, t.ID)
+			}
+			return nil
+		}).Error
+	return skipped, err
+}
+
+func backupViews(tx *gorm.DB, w *backup.Writer, s *backup.Shard) error {
+	var views []persistence.View
+	if err := tx.Order("id").Find(&views).Error; err != nil {
+		return err
+	}
+	for _, v := range views {
+		if err := w.AddView(s.ID, v); err != nil {
+			return err
+		}
+		s.Views++
+	}
+	return nil
+}
+
+func countTasks(shards []backup.Shard) int {
+	n := 0
+	for _, s := range shards {
+		n += s.Tasks
+	}
+	return n
+}
+
+func countViews(shards []backup.Shard) int {
+	n := 0
+	for _, s := range shards {
+		n += s.Views
+	}
+	return n
+}
+
+// RestoreReport is the result of Restore.
+type RestoreReport struct {
+	Manifest backup.Manifest
+	// Tasks counts the restored tasks per shard index of this store.
+	Tasks map[int]int
+	// StaleEntries are directory entries of the archive for tasks it does not hold.
+	StaleEntries int
+	// Duplicates are tasks the archive holds more than once; the newest copy was kept.
+	Duplicates int
+	// Views counts the restored saved views.
+	Views int
+}
+
+// restoredTask is where an archived task was restored, and the version restored.
+type restoredTask struct {
+	updatedAt time.Time
+	shard     int // -1 until written
+}
+
+// Restore loads a backup archive into this store's shards, which may be more or fewer
+// than the source had: every task is placed with ResolveTask under the current topology,
+// written with its observers and reminders, and recorded in the directory. Saved views
+// go to their owner's shard, as a drain places them. The archived directory only serves
+// as a cross-check. Afterwards the ID counter, and the range of
+// every shard with a range allocator, are raised above the restored IDs.
+//
+// The shards must be on the archive's schema version or newer. Unless force is set the
+// shards must not hold tasks or views; with force, archived tasks and views replace
+// existing ones with the same ID, wherever they are. A task or view archived more than
+// once is restored from the copy with the newest updated_at. Like Backup, Restore holds the rebalance lease.
+func (sm *ShardManager) Restore(ctx context.Context, in io.Reader, force bool) (RestoreReport, error) {
+	report := RestoreReport{Tasks: make(map[int]int)}
+	r, err := backup.NewReader(in)
+	if err != nil {
+		return report, err
+	}
+	defer r.Close()
+	report.Manifest = r.Manifest()
+	if err := sm.CheckSchema(ctx); err != nil {
+		return report, err
+	}
+	if v := report.Manifest.SchemaVersion; v > sm.SchemaVersion() {
+		return report, fmt.Errorf("%w: archive %d, shards %d", ErrBackupTooNew, v, sm.SchemaVersion())
+	}
+
+	pending, err := RebalancePending(ctx)
+	if err != nil {
+		return report, err
+	}
+	if pending {
+		return report, ErrRebalanceRunning
+	}
+	ctx, release, err := holdRebalanceLease(ctx)
+	if err != nil {
+		return report, err
+	}
+	defer release()
+
+	if !force {
+		for _, idx := range sm.ShardIndexes() {
+			tasks, views, err := countRows(sm.GetShardByIndex(idx).WithContext(ctx))
+			if err != nil {
+				return report, fmt.Errorf("shard %d: %w", idx, err)
+			}
+			if tasks+views > 0 {
+				return report, fmt.Errorf("%w: shard %d; restore with force to overwrite", ErrRestoreTargetNotEmpty, idx)
+			}
+		}
+	}
+
+	restored := make(map[uint]*restoredTask)
+	views := make(map[string]time.Time)
+	var batch []backup.Task
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := sm.restoreTasks(ctx, batch, restored, force, report.Tasks)
+		batch = batch[:0]
+		return err
+	}
+	err = r.Each(
+		func(_ string, t backup.Task) error {
+			if prev, ok := restored[t.ID]; ok {
+				report.Duplicates++
+				if !t.UpdatedAt.After(prev.updatedAt) {
+					return nil
+				}
+				// the older copy is written before the newer one replaces it
+				if err := flush(); err != nil {
+					return err
+				}
+				prev.updatedAt = t.UpdatedAt
+			} else {
+				restored[t.ID] = &restoredTask{updatedAt: t.UpdatedAt, shard: -1}
+			}
+			batch = append(batch, t)
+			if len(batch) == moveBatchSize {
+				return flush()
+			}
+			return nil
+		},
+		func(_ string, v persistence.View) error {
+			if prev, ok := views[v.ID]; ok && !v.UpdatedAt.After(prev) {
+				return nil
+			}
+			views[v.ID] = v.UpdatedAt
+			return sm.restoreView(ctx, v, force)
+		},
+		func(e backup.DirectoryEntry) error {
+			if err := flush(); err != nil {
+				return err
+			}
+			if restored[e.TaskID] == nil {
+				report.StaleEntries++
+			}
+			return nil
+		})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return report, err
+	}
+
+	var maxID uint
+	for id := range restored {
+		maxID = max(maxID, id)
+	}
+	if err := cache.RaiseTaskIDCounter(ctx, max(maxID, report.Manifest.IDCounter)); err != nil {
+		return report, fmt.Errorf("raise id counter: %w", err)
+	}
+	if err := sm.raiseIDRanges(ctx, restored); err != nil {
+		return report, err
+	}
+	report.Views = len(views)
+	if report.Duplicates > 0 {
+		log.Printf("[backup] %d tasks were in the archive more than once; kept the newest copies", report.Duplicates)
+	}
+	log.Printf("[backup] restored %d tasks onto %d shards and %d views from a backup of %s", len(restored), len(report.Tasks), report.Views, report.Manifest.CreatedAt.Format(time.RFC3339))
+	return report, nil
+}
+
+// restoreTasks writes a batch of archived tasks to the shards they belong on, then
+// removes other copies of them: an older archived copy restored onto another shard and,
+// with force, any row with the same ID on another shard.
+func (sm *ShardManager) restoreTasks(ctx context.Context, batch []backup.Task, restored map[uint]*restoredTask, force bool, counts map[int]int) error {
+	tasks := make(map[int][]persistence.Task)
+	reminders := make(map[int][]persistence.Reminder)
+	for _, t := range batch {
+		task := t.Task
+		task.Observers = make([]persistence.Observer, len(t.Observers))
+		for i, userID := range t.Observers {
+			task.Observers[i] = persistence.Observer{UserId: userID, TaskId: t.ID}
+		}
+		idx := sm.ResolveTask(TaskKey{PerformerID: task.PerformerId, ProjectID: task.ProjectId, CreatorID: task.CreatorId, TenantID: task.TenantId})
+		tasks[idx] = append(tasks[idx], task)
+		reminders[idx] = append(reminders[idx], t.Reminders...)
+	}
+	for idx, list := range tasks {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			return fmt.Errorf("shard %d is not available", idx)
+		}
+		if err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return upsertTasks(tx, list, reminders[idx])
+		}); err != nil {
+			return fmt.Errorf("shard %d: %w", idx, err)
+		}
+		ids := make([]uint, len(list))
+		for i, t := range list {
+			ids[i] = t.ID
+			_ = cache.DeleteTaskCache(ctx, t.ID)
+		}
+		if err := directory.Default.Assign(ctx, idx, ids...); err != nil {
+			return fmt.Errorf("assign shard %d: %w", idx, err)
+		}
+		for _, other := range sm.ShardIndexes() {
+			if other == idx {
+				continue
+			}
+			var stale []uint
+			for _, id := range ids {
+				if force || restored[id].shard == other {
+					stale = append(stale, id)
+				}
+			}
+			if len(stale) == 0 {
+				continue
+			}
+			if err := dropTasks(ctx, sm.GetShardByIndex(other), stale); err != nil {
+				return fmt.Errorf("shard %d: remove other copies: %w", other, err)
+			}
+		}
+		for _, id := range ids {
+			if prev := restored[id].shard; prev >= 0 {
+				counts[prev]--
+			}
+			restored[id].shard = idx
+		}
+		counts[idx] += len(list)
+	}
+	return nil
+}
+
+// restoreView writes an archived view to its owner's shard and, with force, removes a
+// view with the same ID from the other shards.
+func (sm *ShardManager) restoreView(ctx context.Context, v persistence.View, force bool) error {
+	idx := sm.Resolve(v.OwnerId)
+	db := sm.GetShardByIndex(idx)
+	if db == nil {
+		return fmt.Errorf("shard %d is not available", idx)
+	}
+	if err := db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&v).Error; err != nil {
+		return fmt.Errorf("shard %d: view %s: %w", idx, v.ID, err)
+	}
+	if !force {
+		return nil
+	}
+	for _, other := range sm.ShardIndexes() {
+		if other == idx {
+			continue
+		}
+		if err := sm.GetShardByIndex(other).WithContext(ctx).Delete(&persistence.View{ID: v.ID}).Error; err != nil {
+			return fmt.Errorf("shard %d: remove other copy of view %s: %w", other, v.ID, err)
+		}
+	}
+	return nil
+}
+
+// dropTasks deletes the tasks with their observers and reminders.
+func dropTasks(ctx context.Context, db *gorm.DB, ids []uint) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("task_id IN ?", ids).Delete(&persistence.Observer{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id IN ?", ids).Delete(&persistence.Reminder{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&persistence.Task{}).Error
+	})
+}
+
+// raiseIDRanges moves the next ID of every shard with a range allocator past the
+// restored IDs within its range, so it does not hand them out again.
+func (sm *ShardManager) raiseIDRanges(ctx context.Context, restored map[uint]*restoredTask) error {
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx).WithContext(ctx)
+		var rows []IdAllocator
+		if err := db.Find(&rows).Error; err != nil {
+			return fmt.Errorf("shard %d: read id range: %w", idx, err)
+		}
+		for _, row := range rows {
+			next := row.NextID
+			for id := range restored {
+				if int64(id) > next && (row.LimitID == 0 || int64(id) <= row.LimitID) {
+					next = int64(id)
+				}
+			}
+			if next == row.NextID {
+				continue
+			}
+			err := db.Model(&IdAllocator{}).Where("id = ? AND next_id < ?", row.ID, next).Update("next_id", next).Error
+			if err != nil {
+				return fmt.Errorf("shard %d: raise id range: %w", idx, err)
+			}
+		}
+	}
+	return nil
+}
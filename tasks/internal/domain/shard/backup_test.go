@@ -0,0 +1,168 @@
+package shard_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/backup"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/persistence"
+	"tasks/internal/infrastructure/schema"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newBackupFixture is a move fixture whose shards also have views, ID ranges and a
+// schema_migrations table on the latest version.
+func newBackupFixture(t *testing.T) *moveFixture {
+	t.Helper()
+	f := newMoveFixture(t)
+	for _, db := range []*gorm.DB{f.src, f.dst} {
+		if err := db.AutoMigrate(&persistence.View{}, &shard.IdAllocator{}); err != nil {
+			t.Fatal(err)
+		}
+		err := db.Exec(`CREATE TABLE schema_migrations (
+			version integer PRIMARY KEY, name text NOT NULL, backfilled_at datetime)`).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range schema.Default.Migrations() {
+			if err := db.Exec("INSERT INTO schema_migrations VALUES (?, ?, ?)", m.Version, m.Name, time.Now()).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return f
+}
+
+// placedOn returns the shard index of f holding the task, or -1.
+func (f *moveFixture) placedOn(t *testing.T, table string, where string, arg any) int {
+	t.Helper()
+	for i, db := range []*gorm.DB{f.src, f.dst} {
+		var n int64
+		if err := db.Table(table).Where(where, arg).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		if n > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	src := newBackupFixture(t)
+	// task 1 with an observer and a reminder on shard 0, task 2 on shard 1
+	src.seed(t, src.src)
+	if err := src.dst.Create(&persistence.Task{ID: 2, Title: "stay", PerformerId: 8, CreatorId: 3, Status: "done"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := directory.Default.Assign(ctx, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	view := persistence.View{ID: "0f8e3a52-7c1d-4d0e-9b6a-3c2f1e0d9a11", Name: "mine", OwnerId: 7, Filter: `{"exclude_done":true}`, Columns: `[]`, Visibility: "private"}
+	// on the shard that does not own it, as if a drain had not moved it yet
+	owner := src.sm.Resolve(7)
+	if err := []*gorm.DB{src.src, src.dst}[1-owner].Create(&view).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	m, err := src.sm.Backup(ctx, &archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Format != backup.FormatVersion || m.MaxTaskID != 2 || m.DirectoryEntries != 1 {
+		t.Fatalf("manifest %+v", m)
+	}
+	if m.Shards[0].Tasks+m.Shards[1].Tasks != 2 || m.Shards[0].Views+m.Shards[1].Views != 1 {
+		t.Fatalf("manifest shards %+v, want 2 tasks and 1 view", m.Shards)
+	}
+
+	// a fresh store with empty shards, directory and Redis
+	dst := newBackupFixture(t)
+	report, err := dst.sm.Restore(ctx, bytes.NewReader(archive.Bytes()), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Views != 1 || report.Tasks[0]+report.Tasks[1] != 2 || report.Duplicates != 0 || report.StaleEntries != 0 {
+		t.Fatalf("report %+v", report)
+	}
+	for id, performer := range map[uint]uint{1: 7, 2: 8} {
+		want := dst.sm.Resolve(performer)
+		if got := dst.placedOn(t, "tasks", "id = ?", id); got != want {
+			t.Fatalf("task %d on shard %d, want %d", id, got, want)
+		}
+		if got, err := directory.Default.Lookup(ctx, id); err != nil || got != want {
+			t.Fatalf("directory: task %d on shard %d, %v; want %d", id, got, err, want)
+		}
+	}
+	if got := dst.placedOn(t, "observers", "task_id = ?", 1); got != dst.sm.Resolve(7) {
+		t.Fatalf("observer on shard %d", got)
+	}
+	if got := dst.placedOn(t, "task_reminders", "task_id = ?", 1); got != dst.sm.Resolve(7) {
+		t.Fatalf("reminder on shard %d", got)
+	}
+	if got := dst.placedOn(t, "views", "id = ?", view.ID); got != dst.sm.Resolve(7) {
+		t.Fatalf("view on shard %d, want its owner's shard %d", got, dst.sm.Resolve(7))
+	}
+	if last, err := cache.LastTaskID(ctx); err != nil || last < 2 {
+		t.Fatalf("id counter %d, %v; want at least 2", last, err)
+	}
+
+	// the store is no longer empty
+	_, err = dst.sm.Restore(ctx, bytes.NewReader(archive.Bytes()), false)
+	if !errors.Is(err, shard.ErrRestoreTargetNotEmpty) {
+		t.Fatalf("second restore: err = %v, want ErrRestoreTargetNotEmpty", err)
+	}
+	if _, err := dst.sm.Restore(ctx, bytes.NewReader(archive.Bytes()), true); err != nil {
+		t.Fatalf("forced restore: %v", err)
+	}
+	if n := count(t, dst.src, "views") + count(t, dst.dst, "views"); n != 1 {
+		t.Fatalf("%d views after the forced restore, want 1", n)
+	}
+}
+
+func TestRestoreKeepsNewestDuplicate(t *testing.T) {
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	w, err := backup.NewWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a task copied between the shards' snapshots is in the archive twice
+	for i, title := range []string{"newer", "older"} {
+		task := persistence.Task{ID: 5, Title: title, PerformerId: 7, Status: "new", UpdatedAt: old.Add(time.Duration(1-i) * time.Hour)}
+		if err := w.AddTask([]string{"a", "b"}[i], backup.Task{Task: task}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var archive bytes.Buffer
+	if err := w.Finish(&archive, backup.Manifest{CreatedAt: old, Shards: []backup.Shard{{Index: 0, ID: "a", Tasks: 1}, {Index: 1, ID: "b", Tasks: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := newBackupFixture(t)
+	report, err := f.sm.Restore(context.Background(), &archive, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Duplicates != 1 || report.Tasks[0]+report.Tasks[1] != 1 {
+		t.Fatalf("report %+v, want 1 duplicate and 1 task", report)
+	}
+	var tasks []persistence.Task
+	for _, db := range []*gorm.DB{f.src, f.dst} {
+		var on []persistence.Task
+		if err := db.Find(&on).Error; err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, on...)
+	}
+	if len(tasks) != 1 || tasks[0].Title != "newer" {
+		t.Fatalf("restored %+v, want only the newer copy", tasks)
+	}
+}
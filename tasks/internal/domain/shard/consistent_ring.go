@@ -2,7 +2,9 @@ package shard
 
 import (
 	"fmt"
+	"math"
 	"sort"
+	"strconv"
 	"sync"
 
 	"github.com/cespare/xxhash/v2"
@@ -11,10 +13,12 @@ import (
 // Hash space of the ring: 0 .. 2^32-1.
 const hashSpace = uint64(1 << 32)
 
-// ringNode is a point on the ring: vnode hash and physical shard index.
+// ringNode is a point on the ring: vnode hash, physical shard index and the vnode's
+// number within its shard.
 type ringNode struct {
 	hash  uint32
 	shard int
+	vnode int
 }
 
 // consistentRing is a ring with virtual nodes.
@@ -29,33 +33,57 @@ func hash32(data []byte) uint32 {
 	return uint32(xxhash.Sum64(data) % hashSpace)
 }
 
-// newConsistentRing builds the ring: for each shard adds vnodesPerShard virtual nodes.
-// Vnode identifier: "shard-{i}-vnode-{j}"; hash and place on the ring.
-func newConsistentRing(numShards, vnodesPerShard int) *consistentRing {
-	if numShards <= 0 {
-		return &consistentRing{nodes: nil}
+// ringMember is a shard placed on the ring: its slot index, stable identifier and weight.
+type ringMember struct {
+	index  int
+	id     string
+	weight float64
+}
+
+// vnodes returns the member's number of virtual nodes: vnodesPerShard scaled by its
+// weight (0 counts as 1), and at least one.
+func (m ringMember) vnodes(vnodesPerShard int) int {
+	w := m.weight
+	if w <= 0 {
+		w = 1
 	}
-	nodes := make([]ringNode, 0, numShards*vnodesPerShard)
-	for i := 0; i < numShards; i++ {
-		for j := 0; j < vnodesPerShard; j++ {
-			key := []byte(fmt.Sprintf("shard-%d-vnode-%d", i, j))
-			h := hash32(key)
-			nodes = append(nodes, ringNode{hash: h, shard: i})
-		}
+	return max(1, int(math.Round(float64(vnodesPerShard)*w)))
+}
+
+// indexMembers returns members 0..n-1 whose identifiers are their indexes,
+// which reproduces the original "shard-{i}-vnode-{j}" placement.
+func indexMembers(n int) []ringMember {
+	members := make([]ringMember, n)
+	for i := range members {
+		members[i] = ringMember{index: i, id: strconv.Itoa(i)}
 	}
-	sort.Slice(nodes, func(a, b int) bool { return nodes[a].hash < nodes[b].hash })
-	return &consistentRing{nodes: nodes}
+	return members
+}
+
+// newConsistentRing builds the ring: for each member adds vnodesPerShard virtual nodes,
+// scaled by the member's weight.
+// Vnode identifier: "shard-{id}-vnode-{j}"; hash and place on the ring. Because points
+// depend on the id and not on the position, removing a member only moves its own keys,
+// and changing a weight only adds or removes that member's highest-numbered vnodes.
+func newConsistentRing(members []ringMember, vnodesPerShard int) *consistentRing {
+	return &consistentRing{nodes: buildRingNodes(members, vnodesPerShard)}
 }
 
 // GetShard returns the shard index for the key: first shard clockwise (lower_bound).
 // If the key is greater than all points, wrap to the first (nodes[0]).
 func (r *consistentRing) GetShard(key []byte) int {
+	_, node, _ := r.locate(key)
+	return node.shard
+}
+
+// locate returns the key's hash and the vnode owning it; false if the ring is empty.
+func (r *consistentRing) locate(key []byte) (uint32, ringNode, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	h := hash32(key)
 	if len(r.nodes) == 0 {
-		return 0
+		return h, ringNode{}, false
 	}
-	h := hash32(key)
 	// lower_bound: first i such that nodes[i].hash >= h
 	i := sort.Search(len(r.nodes), func(i int) bool {
 		return r.nodes[i].hash >= h
@@ -63,24 +91,65 @@ func (r *consistentRing) GetShard(key []byte) int {
 	if i == len(r.nodes) {
 		i = 0
 	}
-	return r.nodes[i].shard
+	return h, r.nodes[i], true
 }
 
-// Rebuild rebuilds the ring with a new number of shards (when adding a shard).
-func (r *consistentRing) Rebuild(numShards, vnodesPerShard int) {
+// snapshot returns a copy of the ring's vnodes, sorted by hash.
+func (r *consistentRing) snapshot() []ringNode {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]ringNode(nil), r.nodes...)
+}
+
+// Rebuild rebuilds the ring with a new member set (after adding, draining or removing a shard).
+func (r *consistentRing) Rebuild(members []ringMember, vnodesPerShard int) {
+	nodes := buildRingNodes(members, vnodesPerShard)
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if numShards <= 0 {
-		r.nodes = nil
-		return
+	r.nodes = nodes
+}
+
+// vnodeKey is the ring key of vnode j of the shard with ID id.
+func vnodeKey(id string, j int) string {
+	return fmt.Sprintf("shard-%s-vnode-%d", id, j)
+}
+
+func buildRingNodes(members []ringMember, vnodesPerShard int) []ringNode {
+	if len(members) == 0 {
+		return nil
+	}
+	total := 0
+	for _, m := range members {
+		total += m.vnodes(vnodesPerShard)
 	}
-	nodes := make([]ringNode, 0, numShards*vnodesPerShard)
-	for i := 0; i < numShards; i++ {
-		for j := 0; j < vnodesPerShard; j++ {
-			key := []byte(fmt.Sprintf("shard-%d-vnode-%d", i, j))
-			nodes = append(nodes, ringNode{hash: hash32(key), shard: i})
+	nodes := make([]ringNode, 0, total)
+	for _, m := range members {
+		for j := 0; j < m.vnodes(vnodesPerShard); j++ {
+			nodes = append(nodes, ringNode{hash: hash32([]byte(vnodeKey(m.id, j))), shard: m.index, vnode: j})
 		}
 	}
 	sort.Slice(nodes, func(a, b int) bool { return nodes[a].hash < nodes[b].hash })
-	r.nodes = nodes
+	return nodes
+}
+
+// shares returns the fraction of the hash space each shard index owns.
+func (r *consistentRing) shares() map[int]float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	shares := make(map[int]float64)
+	switch len(r.nodes) {
+	case 0:
+		return shares
+	case 1:
+		shares[r.nodes[0].shard] = 1
+		return shares
+	}
+	// a node owns the keys after its predecessor, up to and including its own hash
+	prev := uint64(r.nodes[len(r.nodes)-1].hash)
+	for _, n := range r.nodes {
+		arc := (uint64(n.hash) + hashSpace - prev) % hashSpace
+		shares[n.shard] += float64(arc) / float64(hashSpace)
+		prev = uint64(n.hash)
+	}
+	return shares
 }
@@ -0,0 +1,99 @@
+package shard
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Shard and replica DSNs reach every replica through the topology in Redis, so they are
+// kept and shared without their passwords. Each process remembers the passwords of the
+// DSNs it was configured with (DB_SHARD_URLS, AddShard) and connects with those; for a
+// DSN it has no password for, the driver falls back to PGPASSWORD and ~/.pgpass
+// (PGPASSFILE), so shards added elsewhere need their credentials provisioned there.
+var credentials = struct {
+	sync.RWMutex
+	dsns map[string]string // DSN without password -> DSN as configured
+}{dsns: make(map[string]string)}
+
+// rememberDSN keeps the password of dsn for this process and returns dsn without it.
+func rememberDSN(dsn string) string {
+	public := stripPassword(dsn)
+	if public != dsn {
+		credentials.Lock()
+		credentials.dsns[public] = dsn
+		credentials.Unlock()
+	}
+	return public
+}
+
+// rememberDSNs is rememberDSN for a list of replica DSNs.
+func rememberDSNs(dsns []string) []string {
+	if dsns == nil {
+		return nil
+	}
+	public := make([]string, len(dsns))
+	for i, dsn := range dsns {
+		public[i] = rememberDSN(dsn)
+	}
+	return public
+}
+
+// connectDSN returns the DSN to connect with: the configured one if this process knows
+// the password of dsn, else dsn itself.
+func connectDSN(dsn string) string {
+	credentials.RLock()
+	defer credentials.RUnlock()
+	if full, ok := credentials.dsns[dsn]; ok {
+		return full
+	}
+	return dsn
+}
+
+// stripPassword removes the password from a URL-style or keyword/value DSN; the user
+// name stays.
+func stripPassword(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if u.User == nil {
+			return dsn
+		}
+		if _, ok := u.User.Password(); !ok {
+			return dsn
+		}
+		u.User = url.User(u.User.Username())
+		return u.String()
+	}
+	i := strings.Index(dsn, "password=")
+	if i < 0 || (i > 0 && dsn[i-1] != ' ') {
+		return dsn
+	}
+	// the value runs to the next space, or is quoted with backslash escapes
+	end := i + len("password=")
+	if end < len(dsn) && dsn[end] == '\'' {
+		for end++; end < len(dsn) && dsn[end] != '\''; end++ {
+			if dsn[end] == '\\' {
+				end++
+			}
+		}
+		end++
+	} else {
+		for end < len(dsn) && dsn[end] != ' ' {
+			end++
+		}
+	}
+	if end > len(dsn) {
+		end = len(dsn)
+	}
+	return strings.TrimSpace(dsn[:i] + strings.TrimLeft(dsn[end:], " "))
+}
+
+// withoutPasswords returns a copy of shards whose DSNs have no passwords.
+func withoutPasswords(shards []ShardInfo) []ShardInfo {
+	out := make([]ShardInfo, len(shards))
+	for i, s := range shards {
+		s.DSN = rememberDSN(s.DSN)
+		s.Replicas = rememberDSNs(s.Replicas)
+		out[i] = s
+	}
+	return out
+}
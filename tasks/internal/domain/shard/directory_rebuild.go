@@ -0,0 +1,80 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"tasks/internal/infrastructure/directory"
+
+	"gorm.io/gorm"
+)
+
+// RebuildReport is the result of RebuildDirectory.
+type RebuildReport struct {
+	ShardsScanned int
+	TasksAssigned int
+	// Duplicates are tasks found on several shards; the newest copy's shard was recorded.
+	Duplicates int
+	// Durable is false when the directory is kept in Redis only.
+	Durable bool
+}
+
+// RebuildDirectory scans every shard and writes the shard of each task to the directory,
+// repopulating Redis (and the durable table) after data loss. Like a shard-verify repair it
+// holds the rebalance lease, so no task moves while the directory is rewritten. Entries
+// for tasks that no shard holds are left alone; `shard-verify -repair` removes them.
+func (sm *ShardManager) RebuildDirectory(ctx context.Context) (RebuildReport, error) {
+	report := RebuildReport{Durable: directory.Default.Durable()}
+	pending, err := RebalancePending(ctx)
+	if err != nil {
+		return report, err
+	}
+	if pending {
+		return report, ErrRebalanceRunning
+	}
+	ctx, release, err := holdRebalanceLease(ctx)
+	if err != nil {
+		return report, err
+	}
+	defer release()
+
+	shards := make(map[int]*gorm.DB)
+	for _, idx := range sm.ShardIndexes() {
+		if db := sm.GetShardByIndex(idx); db != nil {
+			shards[idx] = db
+		}
+	}
+	report.ShardsScanned = len(shards)
+	// scanned in windows of task IDs, as Verify does
+	for lo := uint(0); ; {
+		hi, err := verifyWindowEnd(ctx, shards, lo)
+		if err != nil {
+			return report, err
+		}
+		locations := make(map[uint][]taskLocation)
+		for idx, db := range shards {
+			if err := scanTaskLocations(ctx, db, idx, lo, hi, locations); err != nil {
+				return report, fmt.Errorf("shard %d: %w", idx, err)
+			}
+		}
+		byShard := make(map[int][]uint)
+		for id, locs := range locations {
+			if len(locs) > 1 {
+				report.Duplicates++
+			}
+			// the newest copy, which a verify repair would keep or copy onto the kept shard
+			_, newest := sm.keptCopy(locs)
+			byShard[newest] = append(byShard[newest], id)
+		}
+		for idx, ids := range byShard {
+			if err := directory.Default.Assign(ctx, idx, ids...); err != nil {
+				return report, fmt.Errorf("assign shard %d: %w", idx, err)
+			}
+			report.TasksAssigned += len(ids)
+		}
+		if hi == 0 {
+			break
+		}
+		lo = hi
+	}
+	return report, nil
+}
@@ -0,0 +1,75 @@
+package shard
+
+import (
+	"context"
+	"log"
+	"tasks/internal/infrastructure/persistence"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// finishDrains runs after a rebalance pass: it moves saved views off draining shards
+// (tasks were already moved by the pass, since draining shards are not on the ring)
+// and removes every draining shard that is now empty.
+func finishDrains(ctx context.Context, progress *progressTracker) {
+	for _, info := range ShardMgr.Shards() {
+		if info.State != ShardDraining {
+			continue
+		}
+		db := ShardMgr.GetShardByIndex(info.Index)
+		if db == nil {
+			continue
+		}
+		if err := moveViews(db); err != nil {
+			log.Printf("[drain] shard %s: move views: %v", info.ID, err)
+			progress.shardDone(ctx, err)
+			continue
+		}
+		tasks, views, err := countRows(db)
+		if err != nil {
+			log.Printf("[drain] shard %s: count rows: %v", info.ID, err)
+			continue
+		}
+		if tasks > 0 || views > 0 {
+			// e.g. a replica that had not seen the drain yet wrote here; the next pass moves it
+			log.Printf("[drain] shard %s still has %d tasks and %d views", info.ID, tasks, views)
+			continue
+		}
+		if err := ShardMgr.removeShard(ctx, info.ID); err != nil {
+			log.Printf("[drain] shard %s: remove: %v", info.ID, err)
+		}
+	}
+}
+
+// moveViews copies each saved view to its owner's shard on the current ring and deletes the
+// original. The copy is an upsert, so a view copied by an earlier pass that failed before
+// the delete is overwritten instead of blocking the drain.
+func moveViews(from *gorm.DB) error {
+	var views []persistence.View
+	if err := from.Find(&views).Error; err != nil {
+		return err
+	}
+	for _, v := range views {
+		to := ShardMgr.GetShardByIndex(ShardMgr.Resolve(v.OwnerId))
+		if to == nil || to == from {
+			continue
+		}
+		if err := to.Clauses(clause.OnConflict{UpdateAll: true}).Create(&v).Error; err != nil {
+			return err
+		}
+		if err := from.Delete(&persistence.View{ID: v.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countRows counts all tasks (including soft-deleted ones) and views left on a shard.
+func countRows(db *gorm.DB) (tasks, views int64, err error) {
+	if err = db.Unscoped().Model(&persistence.Task{}).Count(&tasks).Error; err != nil {
+		return
+	}
+	err = db.Model(&persistence.View{}).Count(&views).Error
+	return
+}
@@ -0,0 +1,196 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/persistence"
+	"time"
+)
+
+// ErrNothingToExplain means neither a task nor a performer was given to ExplainPlacement.
+var ErrNothingToExplain = errors.New("a task id or performer id is required")
+
+// TaskCopy is a row of a task found on a shard.
+type TaskCopy struct {
+	Shard     int
+	ShardID   string
+	Key       TaskKey
+	Deleted   bool
+	UpdatedAt time.Time
+}
+
+// PlacementExplanation is how a task or performer is placed and where its rows are.
+type PlacementExplanation struct {
+	TaskID uint
+	// Key is the placement key: of the newest copy of the task, or the performer alone.
+	Key      TaskKey
+	ShardKey ShardKey
+	// RingKey, Hash and the vnode are the ring lookup under the shard key. The ring is
+	// consulted even when a tenant placement or an override decides.
+	RingKey    string
+	Hash       uint32
+	VnodeHash  uint32
+	Vnode      string
+	RingShard  int
+	RingID     string
+	Rule       string // RuleTenant, RuleOverride, RuleRing or RuleRoundRobin
+	Expected   int    // -1 for round-robin placement
+	ExpectedID string
+	Directory  int    // -1 without a directory entry
+	DirError   string // error of the directory lookup, other than not found
+	Copies     []TaskCopy
+	Moving     bool // a rebalance is pending, so a copy off the expected shard may be in flight
+	TaskFound  bool // false if no shard holds the task; only the directory entry is known then
+}
+
+// ExplainPlacement reports where a task should live and where it does: the ring hash and
+// owning vnode of its key, the rule and shard placement picks, the directory entry and
+// the shards holding a row of it (soft-deleted rows included). Without a task ID the
+// performer's placement is explained, as for a new task of that performer.
+func (sm *ShardManager) ExplainPlacement(ctx context.Context, taskID, performerID uint) (PlacementExplanation, error) {
+	e := PlacementExplanation{TaskID: taskID, Key: TaskKey{PerformerID: performerID}, Expected: -1, Directory: -1}
+	if taskID == 0 && performerID == 0 {
+		return e, ErrNothingToExplain
+	}
+	moving, err := RebalancePending(ctx)
+	if err != nil {
+		return e, err
+	}
+	e.Moving = moving
+
+	if taskID != 0 {
+		idx, err := directory.Default.Lookup(ctx, taskID)
+		switch {
+		case err == nil:
+			e.Directory = idx
+		case !errors.Is(err, directory.ErrNotFound):
+			e.DirError = err.Error()
+		}
+		if e.Copies, err = sm.findTaskCopies(ctx, taskID); err != nil {
+			return e, err
+		}
+		if len(e.Copies) > 0 {
+			newest := e.Copies[0]
+			for _, c := range e.Copies[1:] {
+				if c.UpdatedAt.After(newest.UpdatedAt) {
+					newest = c
+				}
+			}
+			e.Key, e.TaskFound = newest.Key, true
+		}
+	}
+	if taskID != 0 && !e.TaskFound && performerID == 0 {
+		return e, nil
+	}
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	e.ShardKey = sm.shardKey
+	if key, ok := ringKey(sm.shardKey, e.Key); ok {
+		e.RingKey = string(key)
+		var node ringNode
+		if e.Hash, node, ok = sm.ring.locate(key); ok {
+			e.VnodeHash, e.RingShard, e.RingID = node.hash, node.shard, sm.infos[node.shard].ID
+			e.Vnode = vnodeKey(sm.infos[node.shard].ID, node.vnode)
+		}
+	}
+	idx, rule := sm.placeTaskLocked(e.Key)
+	e.Rule = rule
+	if rule != RuleRoundRobin {
+		e.Expected, e.ExpectedID = idx, sm.infos[idx].ID
+	}
+	return e, nil
+}
+
+// findTaskCopies looks for the task on every shard, including soft-deleted rows.
+func (sm *ShardManager) findTaskCopies(ctx context.Context, taskID uint) ([]TaskCopy, error) {
+	infos := sm.Shards()
+	var copies []TaskCopy
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		var rows []persistence.Task
+		err := db.WithContext(ctx).Unscoped().
+			Select("id", "performer_id", "project_id", "creator_id", "tenant_id", "updated_at", "deleted_at").
+			Where("id = ?", taskID).Find(&rows).Error
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		for _, t := range rows {
+			copies = append(copies, TaskCopy{
+				Shard:     idx,
+				ShardID:   infos[idx].ID,
+				Key:       TaskKey{PerformerID: t.PerformerId, ProjectID: t.ProjectId, CreatorID: t.CreatorId, TenantID: t.TenantId},
+				Deleted:   t.DeletedAt.Valid,
+				UpdatedAt: t.UpdatedAt,
+			})
+		}
+	}
+	return copies, nil
+}
+
+// RingShard is a shard's part of the ring.
+type RingShard struct {
+	Index    int
+	ID       string
+	State    ShardState
+	Weight   float64
+	Vnodes   int
+	Share    float64 // fraction of the hash space
+	TenantID uint    // tenant the shard is dedicated to; such shards are off the ring
+}
+
+// RingVnode is a point on the ring.
+type RingVnode struct {
+	Hash  uint32
+	Shard int
+	Key   string
+}
+
+// RingLayout is the ring of this replica.
+type RingLayout struct {
+	TopologyVersion int64
+	ShardKey        ShardKey
+	VnodesPerShard  int
+	Shards          []RingShard
+	Vnodes          []RingVnode // sorted by hash; only if requested
+}
+
+// Ring returns the ring layout: every shard that is not removed with its vnodes and its
+// share of the hash space, and with vnodes set every vnode.
+func (sm *ShardManager) Ring(vnodes bool) RingLayout {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	layout := RingLayout{TopologyVersion: sm.topologyVersion, ShardKey: sm.shardKey, VnodesPerShard: vnodesPerShard()}
+	nodes := sm.ring.snapshot()
+	shares := sm.ring.shares()
+	counts := make(map[int]int)
+	for _, n := range nodes {
+		counts[n.shard]++
+		if vnodes {
+			layout.Vnodes = append(layout.Vnodes, RingVnode{Hash: n.hash, Shard: n.shard, Key: vnodeKey(sm.infos[n.shard].ID, n.vnode)})
+		}
+	}
+	dedicated := dedicatedShards(sm.tenantList())
+	for _, info := range sm.infos {
+		if info.State == ShardRemoved {
+			continue
+		}
+		layout.Shards = append(layout.Shards, RingShard{
+			Index:    info.Index,
+			ID:       info.ID,
+			State:    info.State,
+			Weight:   info.EffectiveWeight(),
+			Vnodes:   counts[info.Index],
+			Share:    shares[info.Index],
+			TenantID: dedicated[info.ID],
+		})
+	}
+	sort.Slice(layout.Shards, func(i, j int) bool { return layout.Shards[i].Index < layout.Shards[j].Index })
+	return layout
+}
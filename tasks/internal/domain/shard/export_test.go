@@ -0,0 +1,15 @@
+package shard
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AddCheckedReplica adds db as a read replica of shard index whose last lag check
+// measured lag and failed with err.
+func (sm *ShardManager) AddCheckedReplica(index int, db *gorm.DB, lag time.Duration, err error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.replicas[index] = append(sm.replicas[index], &replica{dsn: "test", db: db, lag: lag, err: err, checkedAt: time.Now()})
+}
@@ -0,0 +1,268 @@
+package shard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultShardQueryTimeout = 5 * time.Second
+	defaultBreakerFailures   = 5
+	defaultBreakerCooldown   = 30 * time.Second
+	defaultShardPingTimeout  = 2 * time.Second
+	breakerClosed            = "closed"
+	breakerOpen              = "open"
+	breakerHalfOpen          = "half-open"
+)
+
+// ErrShardUnavailable means a shard's circuit breaker is open: recent calls failed or
+// timed out, so calls fail fast instead of waiting for the shard.
+var ErrShardUnavailable = errors.New("shard unavailable")
+
+// healthConfig holds the per-shard deadline and breaker settings.
+type healthConfig struct {
+	timeout  time.Duration // deadline of one call to a shard
+	failures int           // consecutive failures that open the breaker
+	cooldown time.Duration // how long an open breaker rejects calls before a trial call
+}
+
+func newHealthConfig() healthConfig {
+	failures := defaultBreakerFailures
+	if s := os.Getenv("SHARD_BREAKER_FAILURES"); s != "" {
+		if v, err := strconv.Atoi(s); err == nil && v > 0 {
+			failures = v
+		}
+	}
+	return healthConfig{
+		timeout:  envDuration("SHARD_QUERY_TIMEOUT", defaultShardQueryTimeout),
+		failures: failures,
+		cooldown: envDuration("SHARD_BREAKER_COOLDOWN", defaultBreakerCooldown),
+	}
+}
+
+// shardHealth is the breaker and last ping of one shard slot.
+type shardHealth struct {
+	mu       sync.Mutex
+	failures int       // consecutive failed calls and pings
+	openedAt time.Time // zero while the breaker is closed
+	trial    bool      // a half-open trial call is running
+	lastErr  error
+
+	pingLatency time.Duration
+	pingErr     error
+	checkedAt   time.Time
+}
+
+// allow reports whether a call may go to the shard. After the cooldown an open breaker
+// lets one trial call through; its outcome closes or reopens it.
+func (h *shardHealth) allow(cfg healthConfig) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.openedAt.IsZero() {
+		return true
+	}
+	if h.trial || time.Since(h.openedAt) < cfg.cooldown {
+		return false
+	}
+	h.trial = true
+	return true
+}
+
+func (h *shardHealth) record(cfg healthConfig, err error) (opened bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.trial = false
+	if err == nil {
+		h.failures = 0
+		h.openedAt = time.Time{}
+		return false
+	}
+	h.failures++
+	h.lastErr = err
+	if h.failures >= cfg.failures {
+		wasClosed := h.openedAt.IsZero()
+		h.openedAt = time.Now()
+		return wasClosed
+	}
+	return false
+}
+
+func (h *shardHealth) endTrial() {
+	h.mu.Lock()
+	h.trial = false
+	h.mu.Unlock()
+}
+
+func (h *shardHealth) breakerState(cfg healthConfig) string {
+	switch {
+	case h.openedAt.IsZero():
+		return breakerClosed
+	case h.trial || time.Since(h.openedAt) >= cfg.cooldown:
+		return breakerHalfOpen
+	}
+	return breakerOpen
+}
+
+// healthOf returns the health record of a slot, creating it on first use.
+func (sm *ShardManager) healthOf(index int) *shardHealth {
+	h, _ := sm.health.LoadOrStore(index, &shardHealth{})
+	return h.(*shardHealth)
+}
+
+// Call runs fn against shard index with the per-shard deadline (SHARD_QUERY_TIMEOUT) and
+// feeds the outcome to the shard's circuit breaker. While the breaker is open it returns
+// ErrShardUnavailable without calling fn. Not-found results and errors reported by
+// Postgres itself (constraint violations, bad queries) do not count as failures.
+func (sm *ShardManager) Call(ctx context.Context, index int, fn func(ctx context.Context) error) error {
+	h := sm.healthOf(index)
+	if !h.allow(sm.healthCfg) {
+		return fmt.Errorf("%w: shard %d", ErrShardUnavailable, index)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, sm.healthCfg.timeout)
+	err := fn(callCtx)
+	cancel()
+	if ctx.Err() != nil && err != nil {
+		// the caller gave up; that says nothing about the shard
+		h.endTrial()
+		return err
+	}
+	if h.record(sm.healthCfg, shardFailure(err)) {
+		log.Printf("[health] shard %d: breaker opened after %d failures: %v", index, sm.healthCfg.failures, err)
+	}
+	return err
+}
+
+// Available reports whether calls to shard index are currently let through.
+func (sm *ShardManager) Available(index int) bool {
+	h := sm.healthOf(index)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.breakerState(sm.healthCfg) != breakerOpen
+}
+
+// queryErrorClasses are the SQLSTATE classes of errors caused by the query rather than
+// the shard: data exceptions (22), constraint violations (23) and syntax or access rule
+// errors (42).
+var queryErrorClasses = map[string]bool{"22": true, "23": true, "42": true}
+
+// shardFailure returns err if it says the shard is unhealthy, nil otherwise. Postgres
+// errors outside queryErrorClasses count, e.g. a shutdown (57P01), recovery (57P03),
+// too many connections (53300) or a statement timeout (57014).
+func shardFailure(err error) error {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && len(pgErr.Code) >= 2 && queryErrorClasses[pgErr.Code[:2]] {
+		return nil
+	}
+	return err
+}
+
+// CheckHealth pings every shard once. A successful ping closes the shard's breaker, a
+// failed one counts as a failed call.
+func (sm *ShardManager) CheckHealth(ctx context.Context) {
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		h := sm.healthOf(idx)
+		pingCtx, cancel := context.WithTimeout(ctx, defaultShardPingTimeout)
+		start := time.Now()
+		err := pingShard(pingCtx, db)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		h.mu.Lock()
+		h.pingLatency = time.Since(start)
+		h.pingErr = err
+		h.checkedAt = time.Now()
+		wasOpen := !h.openedAt.IsZero()
+		h.mu.Unlock()
+		if h.record(sm.healthCfg, err) {
+			log.Printf("[health] shard %d: breaker opened, ping failed: %v", idx, err)
+		} else if err == nil && wasOpen {
+			log.Printf("[health] shard %d: ping succeeded, breaker closed", idx)
+		}
+	}
+}
+
+func pingShard(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// MonitorHealth pings the shards every interval until ctx is cancelled.
+func (sm *ShardManager) MonitorHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sm.CheckHealth(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ShardHealth is the breaker state, last ping and connection pool of one shard.
+type ShardHealth struct {
+	Shard    int
+	ShardID  string
+	State    ShardState
+	Breaker  string // closed, open or half-open
+	Failures int    // consecutive failed calls and pings
+	// LastError is the last failure seen by a call or ping.
+	LastError   string
+	OpenedAt    time.Time
+	PingLatency time.Duration
+	PingError   string
+	CheckedAt   time.Time
+	Pool        sql.DBStats
+}
+
+// HealthStatuses reports the health of every shard that holds data.
+func (sm *ShardManager) HealthStatuses() []ShardHealth {
+	infos := sm.Shards()
+	var statuses []ShardHealth
+	for _, idx := range sm.ShardIndexes() {
+		st := ShardHealth{Shard: idx, ShardID: infos[idx].ID, State: infos[idx].State}
+		h := sm.healthOf(idx)
+		h.mu.Lock()
+		st.Breaker = h.breakerState(sm.healthCfg)
+		st.Failures = h.failures
+		st.OpenedAt = h.openedAt
+		st.PingLatency = h.pingLatency
+		st.CheckedAt = h.checkedAt
+		if h.lastErr != nil {
+			st.LastError = h.lastErr.Error()
+		}
+		if h.pingErr != nil {
+			st.PingError = h.pingErr.Error()
+		}
+		h.mu.Unlock()
+		if db := sm.GetShardByIndex(idx); db != nil {
+			if sqlDB, err := db.DB(); err == nil {
+				st.Pool = sqlDB.Stats()
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
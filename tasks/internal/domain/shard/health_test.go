@@ -0,0 +1,119 @@
+package shard_test
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/domain/shard"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestCallBreaker(t *testing.T) {
+	t.Setenv("SHARD_BREAKER_FAILURES", "3")
+	t.Setenv("SHARD_BREAKER_COOLDOWN", "50ms")
+	sm := shard.NewShardManagerForTesting(make([]*gorm.DB, 2))
+	ctx := context.Background()
+
+	down := errors.New("connection refused")
+	calls := 0
+	failing := func(context.Context) error { calls++; return down }
+	notFound := func(context.Context) error { calls++; return gorm.ErrRecordNotFound }
+	ok := func(context.Context) error { calls++; return nil }
+
+	// not-found results do not count as failures
+	for i := 0; i < 5; i++ {
+		if err := sm.Call(ctx, 0, notFound); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("not found call: got %v", err)
+		}
+	}
+	if !sm.Available(0) {
+		t.Fatal("breaker opened on not-found results")
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := sm.Call(ctx, 0, failing); !errors.Is(err, down) {
+			t.Fatalf("failing call %d: got %v", i, err)
+		}
+	}
+	calls = 0
+	if err := sm.Call(ctx, 0, ok); !errors.Is(err, shard.ErrShardUnavailable) {
+		t.Fatalf("open breaker: got %v, want ErrShardUnavailable", err)
+	}
+	if calls != 0 {
+		t.Fatal("open breaker called the shard")
+	}
+	if sm.Available(0) {
+		t.Fatal("Available reports an open shard")
+	}
+	if err := sm.Call(ctx, 1, ok); err != nil {
+		t.Fatalf("other shard: %v", err)
+	}
+
+	// after the cooldown one trial call goes through; a failure reopens the breaker
+	time.Sleep(60 * time.Millisecond)
+	if err := sm.Call(ctx, 0, failing); !errors.Is(err, down) {
+		t.Fatalf("trial call: got %v", err)
+	}
+	if err := sm.Call(ctx, 0, ok); !errors.Is(err, shard.ErrShardUnavailable) {
+		t.Fatalf("reopened breaker: got %v", err)
+	}
+
+	// a successful trial closes it
+	time.Sleep(60 * time.Millisecond)
+	if err := sm.Call(ctx, 0, ok); err != nil {
+		t.Fatalf("trial call: %v", err)
+	}
+	if err := sm.Call(ctx, 0, ok); err != nil {
+		t.Fatalf("closed breaker: %v", err)
+	}
+}
+
+func TestCallDeadline(t *testing.T) {
+	t.Setenv("SHARD_QUERY_TIMEOUT", "20ms")
+	sm := shard.NewShardManagerForTesting(make([]*gorm.DB, 1))
+
+	start := time.Now()
+	err := sm.Call(context.Background(), 0, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("per-shard deadline not applied")
+	}
+}
+
+func TestCallBreakerPostgresErrors(t *testing.T) {
+	tests := []struct {
+		code     string
+		wantOpen bool
+	}{
+		{code: "23505", wantOpen: false}, // unique violation
+		{code: "22P02", wantOpen: false}, // invalid text representation
+		{code: "42703", wantOpen: false}, // undefined column
+		{code: "57P01", wantOpen: true},  // admin shutdown
+		{code: "57P03", wantOpen: true},  // cannot connect now, in recovery
+		{code: "53300", wantOpen: true},  // too many connections
+		{code: "57014", wantOpen: true},  // statement timeout
+		{code: "08006", wantOpen: true},  // connection failure
+		{code: "58030", wantOpen: true},  // io error
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			t.Setenv("SHARD_BREAKER_FAILURES", "3")
+			sm := shard.NewShardManagerForTesting(make([]*gorm.DB, 1))
+			pgErr := &pgconn.PgError{Code: tt.code}
+			for i := 0; i < 3; i++ {
+				_ = sm.Call(context.Background(), 0, func(context.Context) error { return pgErr })
+			}
+			if open := !sm.Available(0); open != tt.wantOpen {
+				t.Fatalf("breaker open = %v, want %v", open, tt.wantOpen)
+			}
+		})
+	}
+}
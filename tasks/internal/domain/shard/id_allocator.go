@@ -1,39 +1,65 @@
 package shard
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrIDRangeExhausted is returned by AllocNextID when the shard has issued every ID of its range.
+var ErrIDRangeExhausted = errors.New("shard id range exhausted")
 
 // IdAllocator stores the next ID for the shard (range allocation).
-// In each shard, there is one string (id=1): next_id = start of range + number of IDs issued.
+// In each shard, there is one string (id=1): next_id = last ID issued, starting at the
+// beginning of the range; limit_id is the last ID of the range.
 type IdAllocator struct {
-	ID     int   `gorm:"primaryKey;column:id"`
-	NextID int64 `gorm:"column:next_id;not null"`
+	ID      int   `gorm:"primaryKey;column:id"`
+	NextID  int64 `gorm:"column:next_id;not null"`
+	LimitID int64 `gorm:"column:limit_id;not null;default:0"`
 }
 
 func (IdAllocator) TableName() string {
 	return "id_allocator"
 }
 
+// IDRange returns the IDs reserved for shard shardIndex: [first, last].
+// Range of shard i: [base+i*rangeSize+1, base+(i+1)*rangeSize].
+func IDRange(shardIndex int, base, rangeSize uint64) (first, last uint64) {
+	start := base + uint64(shardIndex)*rangeSize
+	return start + 1, start + rangeSize
+}
+
 // SeedIdAllocator sets the initial value of next_id for the shard with index shardIndex.
-// Range of shard i: [i*rangeSize+1, (i+1)*rangeSize].
-func SeedIdAllocator(db *gorm.DB, shardIndex int, rangeSize uint64) error {
-	start := int64(shardIndex)*int64(rangeSize) + 1
-	var count int64
-	if err := db.Table("id_allocator").Count(&count).Error; err != nil {
-		return err
-	}
-	if count > 0 {
-		return nil
-	}
-	return db.Exec("INSERT INTO id_allocator (id, next_id) VALUES (1, ?)", start).Error
+// A shard that is already seeded keeps its range, so base and rangeSize only matter the
+// first time.
+func SeedIdAllocator(ctx context.Context, db *gorm.DB, shardIndex int, base, rangeSize uint64) error {
+	first, last := IDRange(shardIndex, base, rangeSize)
+	return db.WithContext(ctx).
+		Exec("INSERT INTO id_allocator (id, next_id, limit_id) VALUES (1, ?, ?) ON CONFLICT (id) DO NOTHING", first-1, last).
+		Error
 }
 
 // AllocNextID atomically increments next_id on the shard and returns the issued ID.
-func AllocNextID(db *gorm.DB) (uint, error) {
-	var nextID int64
+// It returns 0 and no error if the shard has not been seeded.
+func AllocNextID(ctx context.Context, db *gorm.DB) (uint, error) {
+	var row IdAllocator
 	// PostgreSQL: RETURNING returns a value after UPDATE
-	err := db.Raw("UPDATE id_allocator SET next_id = next_id + 1 WHERE id = 1 RETURNING next_id").Scan(&nextID).Error
-	if err != nil {
-		return 0, err
+	res := db.WithContext(ctx).
+		Raw("UPDATE id_allocator SET next_id = next_id + 1 WHERE id = 1 AND (limit_id = 0 OR next_id < limit_id) RETURNING next_id, limit_id").
+		Scan(&row)
+	if res.Error != nil {
+		return 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		var seeded int64
+		if err := db.WithContext(ctx).Model(&IdAllocator{}).Count(&seeded).Error; err != nil {
+			return 0, err
+		}
+		if seeded > 0 {
+			return 0, ErrIDRangeExhausted
+		}
+		return 0, nil
 	}
-	return uint(nextID), nil
+	return uint(row.NextID), nil
 }
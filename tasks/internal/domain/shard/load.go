@@ -0,0 +1,210 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/persistence"
+	"time"
+)
+
+// loadWindow is the period request counts are summed over in Redis.
+const loadWindow = time.Minute
+
+type loadKey struct {
+	shard       int
+	performerID uint
+}
+
+// loadTracker counts requests per shard and performer between two reports.
+type loadTracker struct {
+	mu     sync.Mutex
+	counts map[loadKey]int64
+}
+
+func newLoadTracker() *loadTracker {
+	return &loadTracker{counts: make(map[loadKey]int64)}
+}
+
+func (t *loadTracker) add(k loadKey, n int64) {
+	t.mu.Lock()
+	t.counts[k] += n
+	t.mu.Unlock()
+}
+
+func (t *loadTracker) take() map[loadKey]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts := t.counts
+	t.counts = make(map[loadKey]int64)
+	return counts
+}
+
+// RecordRequest counts one request for the performer's tasks served by the shard.
+// Unassigned tasks (performer 0) are not tracked.
+func (sm *ShardManager) RecordRequest(shardIndex int, performerID uint) {
+	if performerID == 0 || sm.load == nil {
+		return
+	}
+	sm.load.add(loadKey{shardIndex, performerID}, 1)
+}
+
+// ReportLoad adds this replica's request counts to the shared per-minute totals every
+// interval until ctx is cancelled. Every replica runs it.
+func (sm *ShardManager) ReportLoad(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			sm.flushLoad(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			sm.flushLoad(ctx)
+		}
+	}
+}
+
+func (sm *ShardManager) flushLoad(ctx context.Context) {
+	counts := sm.load.take()
+	fields := make(map[string]int64, len(counts))
+	for k, n := range counts {
+		fields[fmt.Sprintf("%d:%d", k.shard, k.performerID)] = n
+	}
+	window := time.Now().Truncate(loadWindow)
+	if err := cache.AddShardLoad(ctx, window, 3*loadWindow, fields); err != nil {
+		log.Printf("[load] report: %v", err)
+		// keep the counts for the next report
+		for k, n := range counts {
+			sm.load.add(k, n)
+		}
+	}
+}
+
+// HotThresholds decide which performers HotPerformers reports. A performer is hot on a
+// shard if it has at least TaskShare of the shard's tasks (and MinTasks tasks) or
+// RequestShare of its requests in the last full minute (and MinRequests requests).
+type HotThresholds struct {
+	TaskShare    float64
+	MinTasks     int64
+	RequestShare float64
+	MinRequests  int64
+	// Limit caps the report; it also bounds how many performers are read per shard.
+	Limit int
+}
+
+// HotPerformer is a performer that carries a large part of one shard's rows or traffic.
+type HotPerformer struct {
+	Shard        int
+	ShardID      string
+	PerformerID  uint
+	Tasks        int64
+	TaskShare    float64
+	Requests     int64 // in the last full minute, over all replicas
+	RequestShare float64
+	// Override is the performer's current placement override, if any.
+	Override *PerformerOverride
+}
+
+// HotPerformers reports the performers above th on any shard, hottest first.
+func (sm *ShardManager) HotPerformers(ctx context.Context, th HotThresholds) ([]HotPerformer, error) {
+	if th.Limit <= 0 {
+		th.Limit = 20
+	}
+	requests, err := cache.ShardLoad(ctx, time.Now().Truncate(loadWindow).Add(-loadWindow))
+	if err != nil {
+		return nil, fmt.Errorf("read request counts: %w", err)
+	}
+	perShard := make(map[int]map[uint]int64)
+	shardRequests := make(map[int]int64)
+	for field, n := range requests {
+		shardPart, performerPart, ok := strings.Cut(field, ":")
+		idx, err1 := strconv.Atoi(shardPart)
+		performerID, err2 := strconv.ParseUint(performerPart, 10, 64)
+		if !ok || err1 != nil || err2 != nil {
+			continue
+		}
+		if perShard[idx] == nil {
+			perShard[idx] = make(map[uint]int64)
+		}
+		perShard[idx][uint(performerID)] += n
+		shardRequests[idx] += n
+	}
+
+	infos := sm.Shards()
+	var hot []HotPerformer
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		var total int64
+		if err := db.WithContext(ctx).Model(&persistence.Task{}).Count(&total).Error; err != nil {
+			return nil, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		var top []PerformerLoad
+		err := db.WithContext(ctx).Model(&persistence.Task{}).
+			Select("performer_id, COUNT(*) AS tasks").
+			Where("performer_id <> 0").
+			Group("performer_id").
+			Order("tasks DESC").
+			Limit(th.Limit).
+			Scan(&top).Error
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", idx, err)
+		}
+
+		candidates := make(map[uint]int64, len(top))
+		for _, p := range top {
+			candidates[p.PerformerID] = p.Tasks
+		}
+		for performerID := range perShard[idx] {
+			if _, ok := candidates[performerID]; !ok {
+				candidates[performerID] = -1 // row count not known yet
+			}
+		}
+
+		for performerID, tasks := range candidates {
+			h := HotPerformer{Shard: idx, ShardID: infos[idx].ID, PerformerID: performerID, Tasks: tasks}
+			if tasks < 0 {
+				if err := db.WithContext(ctx).Model(&persistence.Task{}).Where("performer_id = ?", performerID).Count(&h.Tasks).Error; err != nil {
+					return nil, fmt.Errorf("shard %d: %w", idx, err)
+				}
+			}
+			if total > 0 {
+				h.TaskShare = float64(h.Tasks) / float64(total)
+			}
+			h.Requests = perShard[idx][performerID]
+			if shardRequests[idx] > 0 {
+				h.RequestShare = float64(h.Requests) / float64(shardRequests[idx])
+			}
+			byRows := h.Tasks >= th.MinTasks && h.TaskShare >= th.TaskShare
+			byRequests := h.Requests >= th.MinRequests && h.RequestShare >= th.RequestShare
+			if !byRows && !byRequests {
+				continue
+			}
+			if o, ok := sm.Override(performerID); ok {
+				h.Override = &o
+			}
+			hot = append(hot, h)
+		}
+	}
+
+	sort.Slice(hot, func(i, j int) bool {
+		a := max(hot[i].TaskShare, hot[i].RequestShare)
+		b := max(hot[j].TaskShare, hot[j].RequestShare)
+		if a != b {
+			return a > b
+		}
+		return hot[i].PerformerID < hot[j].PerformerID
+	})
+	if len(hot) > th.Limit {
+		hot = hot[:th.Limit]
+	}
+	return hot, nil
+}
@@ -1,25 +1,188 @@
 package shard
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
-	"tasks/internal/infrastructure/persistence"
+	"os"
+	"strings"
+	"tasks/internal/infrastructure/schema"
+	"time"
+
+	"gorm.io/gorm"
 )
 
-// SyncDatabaseForShards performs AutoMigrate for each shard DB. Call after InitShardManager.
+const defaultBackfillPause = 100 * time.Millisecond
+
+// ErrSchemaMismatch means the shards are not all on the schema version this binary needs.
+var ErrSchemaMismatch = errors.New("shard schema versions do not match")
+
+// SyncDatabaseForShards brings every shard to the latest schema version, unless
+// SCHEMA_AUTO_MIGRATE=false, and then refuses to start (log.Fatal) if the shards are on
+// different versions or behind this binary. Unfinished backfills continue in the
+// background. Call after InitShardManager and SyncTopology.
 func SyncDatabaseForShards() {
 	if ShardMgr == nil {
 		log.Fatal("ShardManager not initialized")
 	}
+	ctx := context.Background()
+
+	if os.Getenv("SCHEMA_AUTO_MIGRATE") != "false" {
+		if err := ShardMgr.MigrateUp(ctx, 0); err != nil {
+			log.Fatalf("Error migrating shards: %v", err)
+		}
+	}
+	if err := ShardMgr.CheckSchema(ctx); err != nil {
+		log.Fatalf("Refusing to serve: %v", err)
+	}
+	log.Printf("all shards on schema version %d", ShardMgr.SchemaVersion())
+
+	go func() {
+		if err := ShardMgr.RunBackfills(ctx); err != nil {
+			log.Printf("[schema] backfill: %v", err)
+		}
+	}()
+}
+
+// MigrateShard applies the migrations of a single shard up to version target (the
+// latest if 0) and runs their backfills.
+func MigrateShard(ctx context.Context, db *gorm.DB, target int) error {
+	if _, err := schema.Default.Up(ctx, db, target); err != nil {
+		return err
+	}
+	_, err := schema.Default.Backfill(ctx, db, 0)
+	return err
+}
 
-	allShards := ShardMgr.GetAllShards()
+// ShardSchema is the schema state of one shard.
+type ShardSchema struct {
+	Shard   int
+	ShardID string
+	schema.Status
+}
+
+// SchemaStatus reads the schema state of every shard that holds data.
+func (sm *ShardManager) SchemaStatus(ctx context.Context) ([]ShardSchema, error) {
+	infos := sm.Shards()
+	var statuses []ShardSchema
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		st, err := schema.Default.Status(ctx, db)
+		if err != nil {
+			return nil, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		statuses = append(statuses, ShardSchema{Shard: idx, ShardID: infos[idx].ID, Status: st})
+	}
+	return statuses, nil
+}
+
+// MigrateUp applies pending migrations up to version target (the latest if 0) on every
+// shard, one shard after another. It stops at the first shard that fails, so the others
+// can be checked before retrying.
+func (sm *ShardManager) MigrateUp(ctx context.Context, target int) error {
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		applied, err := schema.Default.Up(ctx, db, target)
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", idx, err)
+		}
+		if len(applied) > 0 {
+			log.Printf("[schema] shard %d: applied migrations %v", idx, applied)
+		}
+	}
+	return nil
+}
 
-	for i, db := range allShards {
-		err := db.AutoMigrate(&persistence.Task{}, &persistence.Observer{})
+// MigrateDown reverts the migrations above version target on every shard.
+func (sm *ShardManager) MigrateDown(ctx context.Context, target int) error {
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		reverted, err := schema.Default.Down(ctx, db, target)
 		if err != nil {
-			log.Printf("Error migrating shard %d: %v", i, err)
+			return fmt.Errorf("shard %d: %w", idx, err)
+		}
+		if len(reverted) > 0 {
+			log.Printf("[schema] shard %d: reverted migrations %v", idx, reverted)
+		}
+	}
+	return nil
+}
+
+// RunBackfills runs the unfinished backfills of every shard, pausing
+// SCHEMA_BACKFILL_PAUSE (default 100ms) between batches. Shards another process is
+// backfilling are skipped.
+func (sm *ShardManager) RunBackfills(ctx context.Context) error {
+	pause := envDuration("SCHEMA_BACKFILL_PAUSE", defaultBackfillPause)
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
 			continue
 		}
-		log.Printf("Successful shard migration %d", i)
+		ran, err := schema.Default.Backfill(ctx, db, pause)
+		if err != nil {
+			return fmt.Errorf("shard %d: %w", idx, err)
+		}
+		if !ran {
+			log.Printf("[schema] shard %d: backfill running elsewhere", idx)
+		}
+	}
+	return nil
+}
+
+// CheckSchema verifies that every shard is on the same schema version, with no gaps,
+// and not behind this binary. Shards ahead of the binary are accepted: migrations are
+// written to be compatible with the previous release, which is what runs during a
+// rolling deploy. On success the version is what SchemaVersion returns.
+func (sm *ShardManager) CheckSchema(ctx context.Context) error {
+	statuses, err := sm.SchemaStatus(ctx)
+	if err != nil {
+		return err
+	}
+	var problems []string
+	version := -1
+	for _, st := range statuses {
+		for _, v := range st.Pending {
+			if v < st.Version {
+				problems = append(problems, fmt.Sprintf("shard %d is on version %d but misses migration %d", st.Shard, st.Version, v))
+				break
+			}
+		}
+		switch {
+		case version < 0:
+			version = st.Version
+		case st.Version != version:
+			problems = append(problems, fmt.Sprintf("shard %d is on version %d, shard %d on %d", statuses[0].Shard, version, st.Shard, st.Version))
+		}
+	}
+	if version >= 0 && version < schema.Default.Latest() {
+		problems = append(problems, fmt.Sprintf("shards are on version %d, this binary needs %d; run `taskctl migrate up`", version, schema.Default.Latest()))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrSchemaMismatch, strings.Join(problems, "; "))
 	}
-	log.Println("all migration successful")
+	if version > schema.Default.Latest() {
+		log.Printf("[schema] shards are on version %d, newer than this binary (%d)", version, schema.Default.Latest())
+	}
+	sm.mu.Lock()
+	sm.schemaVersion = max(version, 0)
+	sm.mu.Unlock()
+	return nil
+}
+
+// SchemaVersion is the version CheckSchema found on every shard; 0 before it ran.
+// Shards added at runtime are migrated to it, so they match the others.
+func (sm *ShardManager) SchemaVersion() int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.schemaVersion
 }
@@ -0,0 +1,267 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/leader"
+	"tasks/internal/infrastructure/persistence"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	moveBatchSize = 100
+	// verifyRounds bounds how often rows written to the source during a move are re-copied.
+	verifyRounds = 3
+)
+
+// taskCopyColumns are overwritten when a task already exists on the target shard.
+// Timestamps are copied as they are, so a repeated copy is a no-op.
+var taskCopyColumns = []string{
+	"title", "description", "performer_id", "creator_id", "project_id", "tenant_id",
+	"mention_ids", "labels", "status", "due_at", "created_at", "updated_at", "deleted_at",
+}
+
+// movePerformer runs the remaining phases of m, saving a checkpoint before each one.
+// The checkpoint is deleted once the source rows are gone. It returns the number of tasks moved.
+func movePerformer(ctx context.Context, term leader.Term, m performerMove) (int, error) {
+	from, to, err := shardPair(m)
+	if err != nil {
+		return 0, err
+	}
+	ids, err := performerTaskIDs(ctx, from, m)
+	if err != nil {
+		return 0, err
+	}
+
+	if m.Phase == phaseCopy {
+		m.Tasks = len(ids)
+		if err := saveCheckpoint(ctx, term, m); err != nil {
+			return 0, err
+		}
+		if err := copyTasks(ctx, from, to, ids); err != nil {
+			return 0, fmt.Errorf("copy: %w", err)
+		}
+		m.Phase = phaseSwitch
+	}
+	if m.Phase == phaseSwitch {
+		if err := saveCheckpoint(ctx, term, m); err != nil {
+			return 0, err
+		}
+		if err := directory.Default.Assign(ctx, m.To, ids...); err != nil {
+			return 0, fmt.Errorf("switch: %w", err)
+		}
+		for _, id := range ids {
+			_ = cache.DeleteTaskCache(ctx, id)
+		}
+		m.Phase = phaseVerify
+	}
+	if m.Phase == phaseVerify {
+		if err := saveCheckpoint(ctx, term, m); err != nil {
+			return 0, err
+		}
+		if err := verifyTasks(ctx, from, to, ids); err != nil {
+			return 0, fmt.Errorf("verify: %w", err)
+		}
+		m.Phase = phaseDelete
+	}
+	if err := saveCheckpoint(ctx, term, m); err != nil {
+		return 0, err
+	}
+	column, value := m.scope()
+	if err := deleteTasks(ctx, from, column, value, ids); err != nil {
+		return 0, fmt.Errorf("delete: %w", err)
+	}
+	return m.Tasks, cache.DeleteRebalanceCheckpoint(ctx, RebalanceLease, term.Token, m.field())
+}
+
+// performerTaskIDs lists the tasks of the move on db, including soft-deleted ones. Of the
+// tasks the move selects, only those placed on its target are listed; others in the same
+// group (a performer's tasks of a tenant with dedicated shards, say) have their own move.
+func performerTaskIDs(ctx context.Context, db *gorm.DB, m performerMove) ([]uint, error) {
+	column, value := m.scope()
+	query := db.WithContext(ctx).Unscoped().Model(&persistence.Task{}).
+		Select("id", "performer_id", "project_id", "creator_id", "tenant_id").
+		Where(column+" = ?", value)
+	if m.SplitBy != "" {
+		query = query.Where(PerformerOverride{SplitBy: m.SplitBy}.splitColumn()+" = ?", m.SplitKey)
+	}
+	var rows []persistence.Task
+	if err := query.Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(rows))
+	for _, t := range rows {
+		k := TaskKey{PerformerID: t.PerformerId, ProjectID: t.ProjectId, CreatorID: t.CreatorId, TenantID: t.TenantId}
+		if to, placed := ShardMgr.placeTask(k); !placed || to == m.To {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids, nil
+}
+
+// copyTasks upserts tasks with their observers and sent reminders into to, in batches.
+// Copies that were already written to on the target are left alone.
+func copyTasks(ctx context.Context, from, to *gorm.DB, ids []uint) error {
+	for start := 0; start < len(ids); start += moveBatchSize {
+		batch := ids[start:min(start+moveBatchSize, len(ids))]
+
+		var tasks []persistence.Task
+		if err := from.WithContext(ctx).Unscoped().Preload("Observers").Where("id IN ?", batch).Find(&tasks).Error; err != nil {
+			return err
+		}
+		target, err := taskVersions(ctx, to, batch)
+		if err != nil {
+			return err
+		}
+		tasks = slices.DeleteFunc(tasks, func(t persistence.Task) bool {
+			c, ok := target[t.ID]
+			return ok && c.UpdatedAt.After(t.UpdatedAt)
+		})
+		var reminders []persistence.Reminder
+		if err := from.WithContext(ctx).Where("task_id IN ?", batch).Find(&reminders).Error; err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			continue
+		}
+		if err := to.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return upsertTasks(tx, tasks, reminders)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func upsertTasks(tx *gorm.DB, tasks []persistence.Task, reminders []persistence.Reminder) error {
+	ids := make([]uint, len(tasks))
+	var observers []persistence.Observer
+	for i, t := range tasks {
+		ids[i] = t.ID
+		for _, o := range t.Observers {
+			observers = append(observers, persistence.Observer{UserId: o.UserId, TaskId: t.ID})
+		}
+	}
+
+	err := tx.Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns(taskCopyColumns),
+		}).
+		Create(&tasks).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("task_id IN ?", ids).Delete(&persistence.Observer{}).Error; err != nil {
+		return err
+	}
+	if len(observers) > 0 {
+		if err := tx.Create(&observers).Error; err != nil {
+			return err
+		}
+	}
+	for i := range reminders {
+		reminders[i].ID = 0
+	}
+	if len(reminders) > 0 {
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&reminders).Error
+	}
+	return nil
+}
+
+// taskVersion identifies the state of a task row; a soft delete changes only deleted_at.
+type taskVersion struct {
+	ID        uint
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+}
+
+// verifyTasks compares the copies with the source and re-copies rows that were written on
+// the source after they were copied (by replicas that had not switched yet). Copies that
+// are newer than the source were written after the switch and win.
+func verifyTasks(ctx context.Context, from, to *gorm.DB, ids []uint) error {
+	for round := 0; ; round++ {
+		stale, err := staleTasks(ctx, from, to, ids)
+		if err != nil {
+			return err
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+		if round == verifyRounds {
+			return fmt.Errorf("%d tasks still differ after %d repairs", len(stale), verifyRounds)
+		}
+		if err := copyTasks(ctx, from, to, stale); err != nil {
+			return err
+		}
+	}
+}
+
+func staleTasks(ctx context.Context, from, to *gorm.DB, ids []uint) ([]uint, error) {
+	var stale []uint
+	for start := 0; start < len(ids); start += moveBatchSize {
+		batch := ids[start:min(start+moveBatchSize, len(ids))]
+		source, err := taskVersions(ctx, from, batch)
+		if err != nil {
+			return nil, err
+		}
+		target, err := taskVersions(ctx, to, batch)
+		if err != nil {
+			return nil, err
+		}
+		for id, v := range source {
+			c, ok := target[id]
+			if !ok || v.UpdatedAt.After(c.UpdatedAt) ||
+				(v.UpdatedAt.Equal(c.UpdatedAt) && v.DeletedAt.Valid && !c.DeletedAt.Valid) {
+				stale = append(stale, id)
+			}
+		}
+	}
+	return stale, nil
+}
+
+func taskVersions(ctx context.Context, db *gorm.DB, ids []uint) (map[uint]taskVersion, error) {
+	var rows []taskVersion
+	err := db.WithContext(ctx).Unscoped().Model(&persistence.Task{}).
+		Select("id, updated_at, deleted_at").
+		Where("id IN ?", ids).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[uint]taskVersion, len(rows))
+	for _, r := range rows {
+		versions[r.ID] = r
+	}
+	return versions, nil
+}
+
+// deleteTasks removes the moved tasks with their observers and reminders from the source.
+// Only rows whose column still has value are deleted.
+func deleteTasks(ctx context.Context, from *gorm.DB, column string, value uint, ids []uint) error {
+	for start := 0; start < len(ids); start += moveBatchSize {
+		batch := ids[start:min(start+moveBatchSize, len(ids))]
+		err := from.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// child rows go only with the tasks that still match: a task whose key changed
+			// meanwhile keeps its row, so it keeps its observers and reminders too
+			removed := tx.Unscoped().Model(&persistence.Task{}).Select("id").Where("id IN ? AND "+column+" = ?", batch, value)
+			if err := tx.Unscoped().Where("task_id IN (?)", removed).Delete(&persistence.Observer{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id IN (?)", removed).Delete(&persistence.Reminder{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Where("id IN ? AND "+column+" = ?", batch, value).Delete(&persistence.Task{}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
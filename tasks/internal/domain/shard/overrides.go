@@ -0,0 +1,201 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Secondary keys a split performer's tasks are spread by.
+const (
+	SplitByProject = "project"
+	SplitByCreator = "creator"
+)
+
+// ErrInvalidOverride means a performer override is malformed.
+var ErrInvalidOverride = errors.New("invalid performer override")
+
+// PerformerOverride places a performer's tasks off the ring. With one shard the performer
+// is pinned to it; with several, each task goes to one of them by its secondary key
+// (SplitBy), so a hot performer's load is spread while every task keeps a fixed shard.
+// Overrides live in the shared topology, so every replica applies them.
+type PerformerOverride struct {
+	PerformerID uint     `json:"performer_id"`
+	Shards      []string `json:"shards"`
+	SplitBy     string   `json:"split_by,omitempty"`
+}
+
+// TaskKey holds the task fields placement depends on.
+type TaskKey struct {
+	PerformerID uint
+	ProjectID   uint
+	CreatorID   uint
+	TenantID    uint
+}
+
+func (o PerformerOverride) secondaryKey(k TaskKey) uint {
+	if o.SplitBy == SplitByCreator {
+		return k.CreatorID
+	}
+	return k.ProjectID
+}
+
+// splitColumn is the tasks column holding the override's secondary key.
+func (o PerformerOverride) splitColumn() string {
+	if o.SplitBy == SplitByCreator {
+		return "creator_id"
+	}
+	return "project_id"
+}
+
+// ResolveTask returns the shard a task belongs on: a shard dedicated to its tenant, the
+// performer's override, or its ring shard under the shard key (see ShardKey). Tasks
+// without any key are spread round-robin over the ring shards.
+func (sm *ShardManager) ResolveTask(k TaskKey) int {
+	if idx, ok := sm.placeTask(k); ok {
+		return idx
+	}
+	return sm.GetShardByPerformerIDIndex(0)
+}
+
+// PerformerShards returns every shard the performer's tasks are placed on: the shards of
+// its override, or its ring shard, plus the shards dedicated to tenants. Under another
+// shard key than the performer its tasks may be on any shard.
+func (sm *ShardManager) PerformerShards(performerID uint) []int {
+	sm.mu.RLock()
+	if sm.shardKey != ShardKeyPerformer {
+		sm.mu.RUnlock()
+		return sm.ShardIndexes()
+	}
+	o, ok := sm.overrides[performerID]
+	var indexes []int
+	if ok {
+		indexes = sm.activeShardsLocked(o.Shards)
+	}
+	var dedicated []int
+	for _, p := range sm.tenantList() {
+		dedicated = append(dedicated, sm.activeShardsLocked(p.Shards)...)
+	}
+	sm.mu.RUnlock()
+	if len(indexes) == 0 {
+		indexes = []int{sm.GetShardByPerformerIDIndex(performerID)}
+	}
+	for _, idx := range dedicated {
+		if !slices.Contains(indexes, idx) {
+			indexes = append(indexes, idx)
+		}
+	}
+	return indexes
+}
+
+// Override returns the performer's override, if any.
+func (sm *ShardManager) Override(performerID uint) (PerformerOverride, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	o, ok := sm.overrides[performerID]
+	return o, ok
+}
+
+// overrideShardLocked picks the shard for key among the override's active shards with
+// rendezvous hashing, so adding or removing one of them only moves that shard's tasks.
+// It returns false if none of them is active; the ring is used then.
+func (sm *ShardManager) overrideShardLocked(o PerformerOverride, key uint) (int, bool) {
+	best, bestScore, found := 0, uint64(0), false
+	for _, id := range o.Shards {
+		i := findShard(sm.infos, id)
+		if i < 0 || sm.infos[i].State != ShardActive {
+			continue
+		}
+		score := xxhash.Sum64String(fmt.Sprintf("performer:%d:%d:%s", o.PerformerID, key, id))
+		if !found || score > bestScore {
+			best, bestScore, found = sm.infos[i].Index, score, true
+		}
+	}
+	return best, found
+}
+
+func (sm *ShardManager) overrideList() []PerformerOverride {
+	list := make([]PerformerOverride, 0, len(sm.overrides))
+	for _, o := range sm.overrides {
+		list = append(list, o)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].PerformerID < list[j].PerformerID })
+	return list
+}
+
+func overrideMap(list []PerformerOverride) map[uint]PerformerOverride {
+	m := make(map[uint]PerformerOverride, len(list))
+	for _, o := range list {
+		m[o.PerformerID] = o
+	}
+	return m
+}
+
+// SetPerformerOverride pins (one shard) or splits (several shards) a performer, replacing
+// any previous override. With no shards the override is removed and the performer goes
+// back to its ring shard. A rebalance moves the existing tasks.
+func (sm *ShardManager) SetPerformerOverride(ctx context.Context, o PerformerOverride) (Topology, error) {
+	if o.PerformerID == 0 {
+		return Topology{}, fmt.Errorf("%w: performer id is required", ErrInvalidOverride)
+	}
+	switch o.SplitBy {
+	case "":
+		o.SplitBy = SplitByProject
+	case SplitByProject, SplitByCreator:
+	default:
+		return Topology{}, fmt.Errorf("%w: unknown split key %q", ErrInvalidOverride, o.SplitBy)
+	}
+	seen := make(map[string]bool, len(o.Shards))
+	for _, id := range o.Shards {
+		if seen[id] {
+			return Topology{}, fmt.Errorf("%w: shard %s listed twice", ErrInvalidOverride, id)
+		}
+		seen[id] = true
+	}
+
+	topo, err := sm.updateTopology(ctx, nil, func(t *Topology) error {
+		for _, id := range o.Shards {
+			i := findShard(t.Shards, id)
+			if i < 0 {
+				return fmt.Errorf("%w: %s", ErrShardNotFound, id)
+			}
+			if t.Shards[i].State != ShardActive {
+				return fmt.Errorf("%w: shard %s is %s", ErrInvalidShardState, id, t.Shards[i].State)
+			}
+			if tenant, ok := dedicatedShards(t.Tenants)[id]; ok {
+				return fmt.Errorf("%w: shard %s is dedicated to tenant %d", ErrInvalidOverride, id, tenant)
+			}
+		}
+		kept := t.Overrides[:0]
+		for _, existing := range t.Overrides {
+			if existing.PerformerID != o.PerformerID {
+				kept = append(kept, existing)
+			}
+		}
+		t.Overrides = kept
+		if len(o.Shards) > 0 {
+			t.Overrides = append(t.Overrides, o)
+		}
+		return nil
+	})
+	if err != nil {
+		return Topology{}, err
+	}
+	if len(o.Shards) == 0 {
+		log.Printf("[topology] performer %d back on the ring, version %d", o.PerformerID, topo.Version)
+	} else {
+		log.Printf("[topology] performer %d placed on shards %v by %s, version %d", o.PerformerID, o.Shards, o.SplitBy, topo.Version)
+	}
+	return topo, nil
+}
+
+// overrideField is the checkpoint suffix of a move restricted to one secondary key.
+func overrideField(splitBy string, key uint) string {
+	return splitBy + "=" + strconv.FormatUint(uint64(key), 10)
+}
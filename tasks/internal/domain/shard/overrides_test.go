@@ -0,0 +1,60 @@
+package shard_test
+
+import (
+	"slices"
+	"tasks/internal/domain/shard"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestResolveTaskWithOverrides(t *testing.T) {
+	topo := shard.Topology{
+		Shards: []shard.ShardInfo{
+			{Index: 0, ID: "a", State: shard.ShardActive},
+			{Index: 1, ID: "b", State: shard.ShardActive},
+			{Index: 2, ID: "c", State: shard.ShardActive},
+			{Index: 3, ID: "d", State: shard.ShardDraining},
+		},
+		Overrides: []shard.PerformerOverride{
+			{PerformerID: 7, Shards: []string{"c"}},
+			{PerformerID: 8, Shards: []string{"a", "b", "d"}, SplitBy: shard.SplitByProject},
+			{PerformerID: 9, Shards: []string{"d"}},
+		},
+	}
+	sm := shard.NewShardManagerFromTopology(topo, make([]*gorm.DB, 4))
+
+	for project := uint(1); project <= 50; project++ {
+		if got := sm.ResolveTask(shard.TaskKey{PerformerID: 7, ProjectID: project}); got != 2 {
+			t.Fatalf("pinned performer: project %d on shard %d, want 2", project, got)
+		}
+	}
+
+	used := make(map[int]int)
+	for project := uint(1); project <= 200; project++ {
+		k := shard.TaskKey{PerformerID: 8, ProjectID: project, CreatorID: project * 3}
+		got := sm.ResolveTask(k)
+		if got != 0 && got != 1 {
+			t.Fatalf("split performer: project %d on shard %d, want an active override shard", project, got)
+		}
+		if again := sm.ResolveTask(k); again != got {
+			t.Fatalf("split performer: project %d placed on %d and then %d", project, got, again)
+		}
+		used[got]++
+	}
+	if used[0] == 0 || used[1] == 0 {
+		t.Fatalf("split performer tasks not spread: %v", used)
+	}
+	if got := sm.PerformerShards(8); !slices.Equal(got, []int{0, 1}) {
+		t.Fatalf("PerformerShards(8) = %v, want [0 1]", got)
+	}
+
+	// an override whose only shard is draining falls back to the ring
+	ring := sm.Resolve(9)
+	if got := sm.ResolveTask(shard.TaskKey{PerformerID: 9, ProjectID: 1}); got != ring {
+		t.Fatalf("performer 9 on shard %d, want ring shard %d", got, ring)
+	}
+	if got := sm.PerformerShards(9); !slices.Equal(got, []int{ring}) {
+		t.Fatalf("PerformerShards(9) = %v, want [%d]", got, ring)
+	}
+}
@@ -0,0 +1,208 @@
+package shard
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"tasks/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const (
+	defaultMaxOpenConns     = 20
+	defaultMaxIdleConns     = 5
+	defaultConnMaxLifetime  = 30 * time.Minute
+	defaultConnMaxIdleTime  = 5 * time.Minute
+	defaultStatementTimeout = 30 * time.Second
+	defaultPrepareStmtCache = 500
+	defaultSlowQuery        = 200 * time.Millisecond
+)
+
+// PoolConfig holds the connection settings of one shard database (primary or replica).
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout is the statement_timeout of every session; 0 leaves the server's.
+	StatementTimeout time.Duration
+	// PrepareStmt caches prepared statements per connection, up to PrepareStmtCache.
+	PrepareStmt      bool
+	PrepareStmtCache int
+}
+
+// poolParams are the DSN query parameters that override the pool settings of one shard.
+// They are removed from the DSN before it is handed to the driver.
+var poolParams = []string{"max_open_conns", "max_idle_conns", "conn_max_lifetime", "conn_max_idle_time", "statement_timeout", "prepare_stmt", "prepare_stmt_cache"}
+
+// PoolConfigFromEnv reads the pool settings every shard starts from:
+//
+//	SHARD_MAX_OPEN_CONNS       open connections per shard (default 20; 0 is unlimited)
+//	SHARD_MAX_IDLE_CONNS       idle connections kept per shard (default 5)
+//	SHARD_CONN_MAX_LIFETIME    connections are replaced after this long (default 30m)
+//	SHARD_CONN_MAX_IDLE_TIME   idle connections are closed after this long (default 5m)
+//	SHARD_STATEMENT_TIMEOUT    statement_timeout of every session (default 30s; 0 disables it)
+//	SHARD_PREPARE_STMT         cache prepared statements (default false)
+//	SHARD_PREPARE_STMT_CACHE   prepared statements cached per shard (default 500)
+func PoolConfigFromEnv() (PoolConfig, error) {
+	cfg := PoolConfig{
+		MaxOpenConns:     defaultMaxOpenConns,
+		MaxIdleConns:     defaultMaxIdleConns,
+		ConnMaxLifetime:  defaultConnMaxLifetime,
+		ConnMaxIdleTime:  defaultConnMaxIdleTime,
+		StatementTimeout: defaultStatementTimeout,
+		PrepareStmtCache: defaultPrepareStmtCache,
+	}
+	for _, env := range []string{"SHARD_MAX_OPEN_CONNS", "SHARD_MAX_IDLE_CONNS", "SHARD_CONN_MAX_LIFETIME", "SHARD_CONN_MAX_IDLE_TIME", "SHARD_STATEMENT_TIMEOUT", "SHARD_PREPARE_STMT", "SHARD_PREPARE_STMT_CACHE"} {
+		if v := os.Getenv(env); v != "" {
+			if err := cfg.set(strings.ToLower(strings.TrimPrefix(env, "SHARD_")), v); err != nil {
+				return PoolConfig{}, fmt.Errorf("%s: %w", env, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// set sets the setting named by one of poolParams.
+func (c *PoolConfig) set(name, value string) error {
+	var err error
+	switch name {
+	case "max_open_conns":
+		c.MaxOpenConns, err = nonNegativeInt(value)
+	case "max_idle_conns":
+		c.MaxIdleConns, err = nonNegativeInt(value)
+	case "conn_max_lifetime":
+		c.ConnMaxLifetime, err = nonNegativeDuration(value)
+	case "conn_max_idle_time":
+		c.ConnMaxIdleTime, err = nonNegativeDuration(value)
+	case "statement_timeout":
+		c.StatementTimeout, err = nonNegativeDuration(value)
+	case "prepare_stmt":
+		c.PrepareStmt, err = strconv.ParseBool(value)
+	case "prepare_stmt_cache":
+		c.PrepareStmtCache, err = nonNegativeInt(value)
+	default:
+		err = fmt.Errorf("unknown pool setting %q", name)
+	}
+	return err
+}
+
+func nonNegativeInt(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err == nil && n < 0 {
+		err = fmt.Errorf("%d is negative", n)
+	}
+	return n, err
+}
+
+func nonNegativeDuration(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("%s is negative", s)
+	}
+	return d, err
+}
+
+// splitPoolParams removes the pool parameters from a URL-style DSN, applying them to
+// base: "postgres://host/db?max_open_conns=50&statement_timeout=10s". Keyword/value DSNs
+// are returned as they are and use base.
+func splitPoolParams(dsn string, base PoolConfig) (string, PoolConfig, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.Scheme == "" {
+		return dsn, base, nil
+	}
+	q := u.Query()
+	for _, name := range poolParams {
+		if !q.Has(name) {
+			continue
+		}
+		if err := base.set(name, q.Get(name)); err != nil {
+			return "", PoolConfig{}, fmt.Errorf("%s: %w", name, err)
+		}
+		q.Del(name)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), base, nil
+}
+
+// dbLogConfig is the GORM logging of the shards: DB_LOG_LEVEL (silent, error, warn or
+// info; default warn) and DB_SLOW_QUERY_THRESHOLD (default 200ms; 0 disables it).
+type dbLogConfig struct {
+	level gormlogger.LogLevel
+	slow  time.Duration
+}
+
+func dbLogConfigFromEnv() (dbLogConfig, error) {
+	level, err := logger.ParseGormLevel(os.Getenv("DB_LOG_LEVEL"))
+	if err != nil {
+		return dbLogConfig{}, fmt.Errorf("DB_LOG_LEVEL: %w", err)
+	}
+	slow := defaultSlowQuery
+	if s := os.Getenv("DB_SLOW_QUERY_THRESHOLD"); s != "" {
+		if slow, err = nonNegativeDuration(s); err != nil {
+			return dbLogConfig{}, fmt.Errorf("DB_SLOW_QUERY_THRESHOLD: %w", err)
+		}
+	}
+	return dbLogConfig{level: level, slow: slow}, nil
+}
+
+// openShard connects to a shard database (or a replica) with the pool settings of
+// PoolConfigFromEnv and those in its DSN. id names the shard in query logs; it may be
+// empty for a shard that has no ID yet. A DSN without password gets the one this process
+// was configured with (see rememberDSN).
+func openShard(id, dsn string) (*gorm.DB, error) {
+	dsn = connectDSN(dsn)
+	base, err := PoolConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	logCfg, err := dbLogConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	dsn, cfg, err := splitPoolParams(dsn, base)
+	if err != nil {
+		return nil, err
+	}
+	connCfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if _, set := connCfg.RuntimeParams["statement_timeout"]; !set && cfg.StatementTimeout > 0 {
+		connCfg.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+	sqlDB := stdlib.OpenDB(*connCfg)
+	applyPoolConfig(sqlDB, cfg)
+
+	var fields []zap.Field
+	if id != "" {
+		fields = append(fields, zap.String("shard", id))
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger:             logger.NewGorm(logCfg.level, logCfg.slow, fields...),
+		PrepareStmt:        cfg.PrepareStmt,
+		PrepareStmtMaxSize: cfg.PrepareStmtCache,
+	})
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func applyPoolConfig(db *sql.DB, cfg PoolConfig) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
@@ -0,0 +1,39 @@
+package shard_test
+
+import (
+	"tasks/internal/domain/shard"
+	"testing"
+	"time"
+)
+
+func TestPoolConfigFromEnv(t *testing.T) {
+	cfg, err := shard.PoolConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxOpenConns != 20 || cfg.StatementTimeout != 30*time.Second || cfg.PrepareStmt {
+		t.Fatalf("defaults = %+v", cfg)
+	}
+
+	t.Setenv("SHARD_MAX_OPEN_CONNS", "50")
+	t.Setenv("SHARD_CONN_MAX_LIFETIME", "1h")
+	t.Setenv("SHARD_STATEMENT_TIMEOUT", "0")
+	t.Setenv("SHARD_PREPARE_STMT", "true")
+	cfg, err = shard.PoolConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := shard.PoolConfig{MaxOpenConns: 50, MaxIdleConns: 5, ConnMaxLifetime: time.Hour, ConnMaxIdleTime: 5 * time.Minute, PrepareStmt: true, PrepareStmtCache: 500}
+	if cfg != want {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	for env, value := range map[string]string{"SHARD_MAX_IDLE_CONNS": "-1", "SHARD_CONN_MAX_IDLE_TIME": "5", "SHARD_PREPARE_STMT": "maybe"} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, value)
+			if _, err := shard.PoolConfigFromEnv(); err == nil {
+				t.Fatalf("%s=%s accepted", env, value)
+			}
+		})
+	}
+}
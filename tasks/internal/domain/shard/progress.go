@@ -0,0 +1,158 @@
+package shard
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"tasks/internal/infrastructure/cache"
+	"time"
+)
+
+// RebalanceProgress describes the latest rebalance run. It is stored in Redis so
+// any replica can report on a run started elsewhere.
+type RebalanceProgress struct {
+	Running         bool      `json:"running"`
+	TopologyVersion int64     `json:"topology_version"`
+	StartedAt       time.Time `json:"started_at"`
+	FinishedAt      time.Time `json:"finished_at,omitempty"`
+	ShardsTotal     int       `json:"shards_total"`
+	ShardsScanned   int       `json:"shards_scanned"`
+	// Performers whose tasks are moved in this run, including ResumedMoves.
+	PerformersTotal int `json:"performers_total"`
+	PerformersDone  int `json:"performers_done"`
+	// ResumedMoves counts moves left unfinished by an earlier run and completed by this one.
+	ResumedMoves   int    `json:"resumed_moves"`
+	TasksTotal     int    `json:"tasks_total"`
+	TasksMoved     int    `json:"tasks_moved"`
+	TasksFailed    int    `json:"tasks_failed"`
+	TasksRemaining int    `json:"tasks_remaining"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+var rebalanceRunning atomic.Bool
+
+// StartRebalance runs Run in the background unless a rebalance is already running
+// in this process. It reports whether a new run was started.
+func StartRebalance(ctx context.Context) bool {
+	if !rebalanceRunning.CompareAndSwap(false, true) {
+		return false
+	}
+	go func() {
+		defer rebalanceRunning.Store(false)
+		Run(ctx)
+	}()
+	return true
+}
+
+// GetRebalanceProgress returns the progress of the latest run, or ok=false if none was recorded.
+func GetRebalanceProgress(ctx context.Context) (RebalanceProgress, bool, error) {
+	data, err := cache.GetRebalanceProgress(ctx)
+	if cache.IsNilError(err) {
+		return RebalanceProgress{}, false, nil
+	}
+	if err != nil {
+		return RebalanceProgress{}, false, err
+	}
+	var p RebalanceProgress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return RebalanceProgress{}, false, err
+	}
+	return p, true, nil
+}
+
+type progressTracker struct {
+	mu sync.Mutex
+	p  RebalanceProgress
+}
+
+func newProgressTracker(ctx context.Context, version int64) *progressTracker {
+	t := &progressTracker{p: RebalanceProgress{
+		Running:         true,
+		TopologyVersion: version,
+		StartedAt:       time.Now(),
+		ShardsTotal:     len(ShardMgr.ShardIndexes()),
+	}}
+	t.flush(ctx)
+	return t
+}
+
+// resume accounts for moves taken over from checkpoints of an earlier run.
+func (t *progressTracker) resume(ctx context.Context, moves []performerMove) {
+	if len(moves) == 0 {
+		return
+	}
+	t.mu.Lock()
+	t.p.ResumedMoves = len(moves)
+	t.addMovesLocked(moves)
+	t.mu.Unlock()
+	t.flush(ctx)
+}
+
+// plan records the moves found by scanning the shards.
+func (t *progressTracker) plan(ctx context.Context, scanned int, moves []performerMove) {
+	t.mu.Lock()
+	t.p.ShardsScanned = scanned
+	t.addMovesLocked(moves)
+	t.mu.Unlock()
+	t.flush(ctx)
+}
+
+func (t *progressTracker) addMovesLocked(moves []performerMove) {
+	t.p.PerformersTotal += len(moves)
+	for _, m := range moves {
+		t.p.TasksTotal += m.Tasks
+	}
+}
+
+func (t *progressTracker) performerDone(ctx context.Context, moved, failed int, err error) {
+	t.mu.Lock()
+	t.p.PerformersDone++
+	t.p.TasksMoved += moved
+	t.p.TasksFailed += failed
+	if err != nil {
+		t.p.LastError = err.Error()
+	}
+	t.mu.Unlock()
+	t.flush(ctx)
+}
+
+func (t *progressTracker) shardDone(ctx context.Context, err error) {
+	t.mu.Lock()
+	if err != nil {
+		t.p.LastError = err.Error()
+	}
+	t.mu.Unlock()
+	t.flush(ctx)
+}
+
+// fail ends the run early; unfinished moves keep their checkpoints.
+func (t *progressTracker) fail(ctx context.Context, err error) {
+	t.mu.Lock()
+	t.p.LastError = err.Error()
+	t.mu.Unlock()
+	t.finish(ctx)
+}
+
+func (t *progressTracker) finish(ctx context.Context) {
+	t.mu.Lock()
+	t.p.Running = false
+	t.p.FinishedAt = time.Now()
+	t.mu.Unlock()
+	t.flush(ctx)
+}
+
+func (t *progressTracker) flush(ctx context.Context) {
+	t.mu.Lock()
+	t.p.TasksRemaining = max(t.p.TasksTotal-t.p.TasksMoved-t.p.TasksFailed, 0)
+	data, err := json.Marshal(t.p)
+	t.mu.Unlock()
+	if err != nil {
+		return
+	}
+	// the run may have been cancelled; still record where it stopped
+	if err := cache.SetRebalanceProgress(context.WithoutCancel(ctx), data); err != nil {
+		log.Printf("[rebalance] store progress: %v", err)
+	}
+}
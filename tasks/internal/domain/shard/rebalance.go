@@ -2,107 +2,285 @@ package shard
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
+	"sort"
 	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/leader"
 	"tasks/internal/infrastructure/persistence"
 	"time"
 
 	"gorm.io/gorm"
 )
 
-// Run performs background rebalancing: for each performer_id whose shard on the ring
-// changed after adding a new shard, migrates their tasks to the new shard.
+const (
+	// RebalanceLease makes sure only one replica rebalances at a time.
+	RebalanceLease    = "rebalance"
+	rebalanceLeaseTTL = time.Minute
+)
+
+// Phases of a performer move. Every step is idempotent, so an interrupted move is
+// resumed by running all steps again from the recorded phase.
+const (
+	phaseCopy   = "copy"   // upsert the performer's tasks into the target shard
+	phaseSwitch = "switch" // point task->shard mappings at the target; reads follow
+	phaseVerify = "verify" // re-copy rows that changed on the source after the copy
+	phaseDelete = "delete" // remove the rows from the source shard
+)
+
+// performerMove moves all tasks of one performer from one shard to another.
+// It is persisted in Redis as the checkpoint of the move.
+type performerMove struct {
+	PerformerID uint   `json:"performer_id"`
+	From        int    `json:"from"`
+	To          int    `json:"to"`
+	Tasks       int    `json:"tasks"`
+	Phase       string `json:"phase"`
+	// SplitBy and SplitKey restrict the move to the tasks with one secondary key, for
+	// performers with a placement override.
+	SplitBy  string `json:"split_by,omitempty"`
+	SplitKey uint   `json:"split_key,omitempty"`
+	// Key and KeyID select the tasks by another field than the performer: tasks placed by
+	// the tenant or project shard key, or of a tenant with dedicated shards.
+	Key   ShardKey `json:"key,omitempty"`
+	KeyID uint     `json:"key_id,omitempty"`
+}
+
+func (m performerMove) field() string {
+	switch {
+	case m.Key != "":
+		return fmt.Sprintf("%s=%d:%d:%d", m.Key, m.KeyID, m.From, m.To)
+	case m.SplitBy != "":
+		return fmt.Sprintf("%d:%d:%s", m.PerformerID, m.From, overrideField(m.SplitBy, m.SplitKey))
+	}
+	return fmt.Sprintf("%d:%d", m.PerformerID, m.From)
+}
+
+// scope returns the column and value every task of the move has.
+func (m performerMove) scope() (string, uint) {
+	if m.Key != "" {
+		return m.Key.column(), m.KeyID
+	}
+	return "performer_id", m.PerformerID
+}
+
+// Run performs one rebalancing job: it moves the tasks that are not on the shard they are
+// placed on (see ResolveTask) after adding or draining a shard, changing a weight, the
+// shard key or a placement. Soft-deleted tasks move too, so a drained shard ends up empty.
+//
+// Moves left unfinished by a crashed or cancelled run are completed first, from their
+// checkpoints, before the remaining work is planned. While tasks are being moved they
+// may exist on both shards; readers deduplicate by task ID.
 // Postgres is unaware; the app copies data and updates the mapping in Redis.
 func Run(ctx context.Context) {
 	if ShardMgr == nil {
 		return
 	}
-	allShards := ShardMgr.GetAllShards()
-	for currentShardIndex, shard := range allShards {
-		migratePerformerIDsFromShard(ctx, shard, currentShardIndex, allShards)
+	// a run started by the job registry already holds the lease
+	term, held := leader.TermFrom(ctx, RebalanceLease)
+	if !held {
+		leaseCtx, t, release, err := leader.NewElector(RebalanceLease, rebalanceLeaseTTL).Acquire(ctx)
+		if err != nil {
+			log.Printf("[rebalance] not started: %v", err)
+			return
+		}
+		defer release()
+		ctx, term = leaseCtx, t
 	}
-}
 
-// migratePerformerIDsFromShard for each distinct performer_id on the shard checks whether
-// the current shard matches the one given by the ring; if not, migrates all that
-// performer's tasks to the ring-assigned shard.
-func migratePerformerIDsFromShard(ctx context.Context, shard *gorm.DB, currentShardIndex int, allShards []*gorm.DB) {
-	var performerIDs []uint
-	err := shard.Model(&persistence.Task{}).Distinct("performer_id").Pluck("performer_id", &performerIDs).Error
+	progress := newProgressTracker(ctx, ShardMgr.Topology().Version)
+
+	resumed, err := loadCheckpoints(ctx, term)
 	if err != nil {
-		log.Printf("[rebalance] shard %d: list performer_ids: %v", currentShardIndex, err)
+		log.Printf("[rebalance] load checkpoints: %v", err)
+		progress.fail(ctx, err)
 		return
 	}
-
-	for _, performerID := range performerIDs {
-		newShardIndex := ShardMgr.GetShardByPerformerIDIndex(performerID)
-		if newShardIndex == currentShardIndex {
-			continue
-		}
-		// performer_id is now on a different shard according to the ring — migrate their tasks
-		newShard := ShardMgr.GetShardByIndex(newShardIndex)
-		if newShard == nil {
-			continue
+	progress.resume(ctx, resumed)
+	for _, m := range resumed {
+		if !runMove(ctx, term, m, progress) {
+			return
 		}
-		migrateTasksByPerformer(ctx, shard, newShard, performerID, currentShardIndex, newShardIndex)
 	}
-}
 
-func migrateTasksByPerformer(ctx context.Context, fromShard, toShard *gorm.DB, performerID uint, fromIndex, toIndex int) {
-	var tasks []persistence.Task
-	err := fromShard.Where("performer_id = ?", performerID).Preload("Observers").Find(&tasks).Error
+	moves, scanned, err := planMoves(ctx)
 	if err != nil {
-		log.Printf("[rebalance] performer_id %d: list tasks: %v", performerID, err)
+		log.Printf("[rebalance] plan: %v", err)
+		progress.fail(ctx, err)
 		return
 	}
+	progress.plan(ctx, scanned, moves)
+	for _, m := range moves {
+		if !runMove(ctx, term, m, progress) {
+			return
+		}
+	}
+
+	finishDrains(ctx, progress)
+	progress.finish(ctx)
+}
 
-	for _, task := range tasks {
-		if err := migrateOneTask(ctx, fromShard, toShard, task, fromIndex, toIndex); err != nil {
-			log.Printf("[rebalance] task %d: %v", task.ID, err)
+// runMove executes one move. It returns false when the run must stop (cancelled or lease
+// lost); the checkpoint then lets the next run resume the move.
+func runMove(ctx context.Context, term leader.Term, m performerMove, progress *progressTracker) bool {
+	if ctx.Err() == nil {
+		if err := term.Check(ctx); err != nil {
+			progress.fail(ctx, err)
+			return false
 		}
 	}
+	if ctx.Err() != nil {
+		progress.fail(ctx, fmt.Errorf("rebalance stopped: %w", context.Cause(ctx)))
+		return false
+	}
+	moved, err := movePerformer(ctx, term, m)
+	failed := 0
+	if err != nil {
+		log.Printf("[rebalance] move %s (shard %d -> %d, phase %s): %v", m.field(), m.From, m.To, m.Phase, err)
+		failed = m.Tasks
+	}
+	progress.performerDone(ctx, moved, failed, err)
+	return true
 }
 
-func migrateOneTask(ctx context.Context, fromShard, toShard *gorm.DB, task persistence.Task, fromIndex, toIndex int) error {
-	// Clone task to target shard (same ID)
-	if err := toShard.Create(&task).Error; err != nil {
-		return err
+// planMoves counts, per shard, the tasks by the fields placement depends on and returns a
+// move for every group that is not on its shard. Groups are merged into one move per
+// performer, secondary key, tenant or project, depending on what placed them.
+func planMoves(ctx context.Context) ([]performerMove, int, error) {
+	type placementCount struct {
+		PerformerId uint
+		ProjectId   uint
+		CreatorId   uint
+		TenantId    uint
+		Count       int
 	}
-	// Create observers on target shard without old IDs (avoid PK conflicts)
-	for _, obs := range task.Observers {
-		newObs := persistence.Observer{UserId: obs.UserId, TaskId: task.ID}
-		if err := toShard.Create(&newObs).Error; err != nil {
-			return err
+	var moves []performerMove
+	scanned := 0
+	for _, from := range ShardMgr.ShardIndexes() {
+		db := ShardMgr.GetShardByIndex(from)
+		if db == nil {
+			continue
+		}
+		var counts []placementCount
+		err := db.WithContext(ctx).Unscoped().Model(&persistence.Task{}).
+			Select("performer_id, project_id, creator_id, tenant_id, count(*) AS count").
+			Group("performer_id, project_id, creator_id, tenant_id").
+			Scan(&counts).Error
+		if err != nil {
+			return nil, scanned, fmt.Errorf("shard %d: %w", from, err)
+		}
+		scanned++
+		byField := make(map[string]int)
+		for _, c := range counts {
+			k := TaskKey{PerformerID: c.PerformerId, ProjectID: c.ProjectId, CreatorID: c.CreatorId, TenantID: c.TenantId}
+			to, placed := ShardMgr.placeTask(k)
+			if !placed {
+				// tasks without a key are spread round-robin; only move them off shards
+				// that left the ring
+				if ShardMgr.onRing(from) {
+					continue
+				}
+				to = ShardMgr.ResolveTask(k)
+			}
+			if to == from {
+				continue
+			}
+			m := ShardMgr.moveFor(k, from, to)
+			if i, ok := byField[m.field()]; ok {
+				moves[i].Tasks += c.Count
+				continue
+			}
+			m.Tasks = c.Count
+			byField[m.field()] = len(moves)
+			moves = append(moves, m)
 		}
 	}
-	// Remove from old shard (hard delete, not soft)
-	if err := fromShard.Unscoped().Where("task_id = ?", task.ID).Delete(&persistence.Observer{}).Error; err != nil {
-		return err
+	return moves, scanned, nil
+}
+
+// moveFor returns the move that takes the tasks with key k from one shard to another,
+// grouped by the rule that placed them: the tenant for a tenant with dedicated shards,
+// the performer and secondary key for an override, otherwise the shard key's field.
+func (sm *ShardManager) moveFor(k TaskKey, from, to int) performerMove {
+	m := performerMove{PerformerID: k.PerformerID, From: from, To: to, Phase: phaseCopy}
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	if _, ok := sm.tenants[k.TenantID]; ok && k.TenantID != 0 {
+		m.Key, m.KeyID = ShardKeyTenant, k.TenantID
+		return m
 	}
-	if err := fromShard.Unscoped().Delete(&task).Error; err != nil {
-		return err
+	if o, ok := sm.overrides[k.PerformerID]; ok && k.PerformerID != 0 {
+		m.SplitBy, m.SplitKey = o.SplitBy, o.secondaryKey(k)
+		return m
+	}
+	if v := sm.shardKey.value(k); v != 0 && sm.shardKey != ShardKeyPerformer {
+		m.Key, m.KeyID = sm.shardKey, v
+	}
+	return m
+}
+
+func loadCheckpoints(ctx context.Context, term leader.Term) ([]performerMove, error) {
+	raw, err := cache.ListRebalanceCheckpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	moves := make([]performerMove, 0, len(raw))
+	for field, data := range raw {
+		var m performerMove
+		if err := json.Unmarshal([]byte(data), &m); err != nil {
+			log.Printf("[rebalance] dropping unreadable checkpoint %s: %v", field, err)
+			_ = cache.DeleteRebalanceCheckpoint(ctx, RebalanceLease, term.Token, field)
+			continue
+		}
+		moves = append(moves, m)
 	}
-	// Update task_id -> shard mapping in Redis
-	if err := cache.SetTaskShard(ctx, task.ID, toIndex); err != nil {
+	sort.Slice(moves, func(i, j int) bool { return moves[i].field() < moves[j].field() })
+	return moves, nil
+}
+
+// saveCheckpoint is fenced: a replica that lost the lease cannot overwrite the new leader's checkpoints.
+func saveCheckpoint(ctx context.Context, term leader.Term, m performerMove) error {
+	data, err := json.Marshal(m)
+	if err != nil {
 		return err
 	}
-	// Invalidate task cache so next GetTask loads from the new shard
-	_ = cache.DeleteTaskCache(ctx, task.ID)
-	return nil
+	return cache.SetRebalanceCheckpoint(ctx, RebalanceLease, term.Token, m.field(), data)
 }
 
-// RunBackground runs rebalancing in the background (e.g. after adding a shard).
-// Call after updating config (new DB_SHARD_URLS) and restart, or when adding a shard
-// dynamically — then call RebuildRing() first, then RunBackground().
-func RunBackground(ctx context.Context, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
+// RebalanceJob runs Run every interval on one replica; register it with a leader.Registry.
+// Runs started by AddShard and DrainShard take the same lease.
+func RebalanceJob(interval time.Duration) leader.Job {
+	return leader.Job{
+		Name:     RebalanceLease,
+		Interval: interval,
+		TTL:      rebalanceLeaseTTL,
+		Run: func(ctx context.Context) error {
 			Run(ctx)
-		}
+			return nil
+		},
+	}
+}
+
+// ResumeInterrupted starts a rebalance in the background if a previous run left
+// unfinished moves behind. Call it once at startup.
+func ResumeInterrupted(ctx context.Context) {
+	raw, err := cache.ListRebalanceCheckpoints(ctx)
+	if err != nil {
+		log.Printf("[rebalance] check for interrupted moves: %v", err)
+		return
+	}
+	if len(raw) > 0 {
+		log.Printf("[rebalance] resuming %d interrupted moves", len(raw))
+		StartRebalance(ctx)
+	}
+}
+
+// shardPair returns the source and target databases of a move.
+func shardPair(m performerMove) (*gorm.DB, *gorm.DB, error) {
+	from, to := ShardMgr.GetShardByIndex(m.From), ShardMgr.GetShardByIndex(m.To)
+	if from == nil || to == nil {
+		return nil, nil, fmt.Errorf("shard %d or %d is not available", m.From, m.To)
 	}
+	return from, to, nil
 }
@@ -0,0 +1,251 @@
+package shard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/session"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReadYourWritesWindow = 5 * time.Second
+	defaultReplicaMaxLag        = 5 * time.Second
+)
+
+// replicaLagQuery returns how far the replica's replay is behind, 0 if it streams from
+// the primary and has replayed everything it received (an idle primary would otherwise
+// look like growing lag). A replica whose WAL receiver is not streaming has stopped
+// receiving, so it gets the age of its last replayed transaction, or NULL if it has none.
+// The receiver's status is only visible to roles with pg_read_all_stats; for other roles
+// a running receiver counts as streaming.
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() THEN 0
+	WHEN NOT EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE COALESCE(status, 'streaming') = 'streaming')
+		THEN EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// errReplicaNotStreaming is the check result of a replica that is not receiving WAL and
+// has not replayed any transaction, so its lag is unknown.
+var errReplicaNotStreaming = errors.New("replica is not streaming from its primary")
+
+// replica is a read-only copy of a shard. It serves reads only after a lag check passed.
+type replica struct {
+	dsn string
+	db  *gorm.DB
+
+	mu        sync.Mutex
+	lag       time.Duration
+	err       error
+	checkedAt time.Time
+}
+
+func (r *replica) usable(maxLag time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.checkedAt.IsZero() && r.err == nil && r.lag <= maxLag
+}
+
+func (r *replica) check(ctx context.Context) {
+	var seconds sql.NullFloat64
+	err := r.db.WithContext(ctx).Raw(replicaLagQuery).Row().Scan(&seconds)
+	if err == nil && !seconds.Valid {
+		err = errReplicaNotStreaming
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+	r.lag = time.Duration(seconds.Float64 * float64(time.Second))
+	r.checkedAt = time.Now()
+}
+
+// ReplicaStatus is the last lag check of a replica.
+type ReplicaStatus struct {
+	Shard     int
+	DSN       string // password redacted
+	Lag       time.Duration
+	Healthy   bool
+	Error     string
+	CheckedAt time.Time
+}
+
+// replicaRouting holds the read routing settings.
+type replicaRouting struct {
+	window time.Duration // reads go to the primary this long after the user's last write
+	maxLag time.Duration // replicas further behind are skipped
+	next   atomic.Uint32 // round-robin over usable replicas
+	// users who wrote through this replica, to skip the Redis lookup
+	recent sync.Map // user ID -> time.Time of the write
+}
+
+func newReplicaRouting() *replicaRouting {
+	return &replicaRouting{
+		window: envDuration("READ_YOUR_WRITES_WINDOW", defaultReadYourWritesWindow),
+		maxLag: envDuration("REPLICA_MAX_LAG", defaultReplicaMaxLag),
+	}
+}
+
+// Reader returns the database reads of shard index should use: a replica that is within
+// REPLICA_MAX_LAG, unless the request or its user wrote within READ_YOUR_WRITES_WINDOW.
+// Everything else, including every write and migration, uses GetShardByIndex. Handing
+// out a replica is recorded in the request's session (see session.ReadReplica).
+func (sm *ShardManager) Reader(ctx context.Context, index int) *gorm.DB {
+	primary := sm.GetShardByIndex(index)
+	sm.mu.RLock()
+	var usable []*replica
+	if index >= 0 && index < len(sm.replicas) {
+		for _, r := range sm.replicas[index] {
+			if r.usable(sm.routing.maxLag) {
+				usable = append(usable, r)
+			}
+		}
+	}
+	sm.mu.RUnlock()
+	if len(usable) == 0 || sm.wroteRecently(ctx) {
+		return primary
+	}
+	session.MarkReplicaRead(ctx)
+	return usable[int(sm.routing.next.Add(1))%len(usable)].db
+}
+
+// MarkWrite records a write by the request and its user, so their reads go to primaries
+// for the read-your-writes window.
+func (sm *ShardManager) MarkWrite(ctx context.Context) {
+	session.MarkWrite(ctx)
+	userID := session.UserID(ctx)
+	if userID == 0 || !sm.hasReplicas() {
+		return
+	}
+	sm.routing.recent.Store(userID, time.Now())
+	if err := cache.MarkUserWrite(ctx, userID, sm.routing.window); err != nil {
+		log.Printf("[replicas] mark write of user %d: %v", userID, err)
+	}
+}
+
+func (sm *ShardManager) wroteRecently(ctx context.Context) bool {
+	if session.Wrote(ctx) {
+		return true
+	}
+	userID := session.UserID(ctx)
+	if userID == 0 {
+		return false
+	}
+	if at, ok := sm.routing.recent.Load(userID); ok {
+		if time.Since(at.(time.Time)) < sm.routing.window {
+			return true
+		}
+		sm.routing.recent.Delete(userID)
+	}
+	// the write may have gone through another tasks replica
+	wrote, err := cache.UserWroteRecently(ctx, userID)
+	return wrote || err != nil
+}
+
+func (sm *ShardManager) hasReplicas() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	for _, rs := range sm.replicas {
+		if len(rs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckReplicas measures the lag of every replica once.
+func (sm *ShardManager) CheckReplicas(ctx context.Context) {
+	sm.mu.RLock()
+	var all []*replica
+	for _, rs := range sm.replicas {
+		all = append(all, rs...)
+	}
+	sm.mu.RUnlock()
+	for _, r := range all {
+		checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		r.check(checkCtx)
+		cancel()
+	}
+}
+
+// MonitorReplicas checks replica lag every interval until ctx is cancelled.
+func (sm *ShardManager) MonitorReplicas(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		sm.CheckReplicas(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReplicaStatuses returns the last check of every replica, by shard index.
+func (sm *ShardManager) ReplicaStatuses() []ReplicaStatus {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	var statuses []ReplicaStatus
+	for idx, rs := range sm.replicas {
+		for _, r := range rs {
+			r.mu.Lock()
+			st := ReplicaStatus{
+				Shard:     idx,
+				DSN:       redactDSN(r.dsn),
+				Lag:       r.lag,
+				Healthy:   !r.checkedAt.IsZero() && r.err == nil && r.lag <= sm.routing.maxLag,
+				CheckedAt: r.checkedAt,
+			}
+			if r.err != nil {
+				st.Error = r.err.Error()
+			}
+			r.mu.Unlock()
+			statuses = append(statuses, st)
+		}
+	}
+	return statuses
+}
+
+// openReplicas connects to the replicas of a shard, reusing connections from reuse.
+// A replica that cannot be reached is left out; its reads go to the primary.
+func openReplicas(info ShardInfo, reuse map[string]*replica) []*replica {
+	var rs []*replica
+	for _, dsn := range info.Replicas {
+		if r, ok := reuse[dsn]; ok {
+			rs = append(rs, r)
+			delete(reuse, dsn)
+			continue
+		}
+		db, err := openShard(info.ID+"-replica", dsn)
+		if err != nil {
+			log.Printf("[replicas] shard %s: connect to replica %s: %v", info.ID, redactDSN(dsn), err)
+			continue
+		}
+		rs = append(rs, &replica{dsn: dsn, db: db})
+	}
+	return rs
+}
+
+func closeReplicas(rs []*replica) {
+	for _, r := range rs {
+		closeShard(r.db)
+	}
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	if s := os.Getenv(key); s != "" {
+		if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return def
+}
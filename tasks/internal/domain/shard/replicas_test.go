@@ -0,0 +1,79 @@
+package shard_test
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/session"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestReaderRouting(t *testing.T) {
+	t.Setenv("READ_YOUR_WRITES_WINDOW", "1s")
+	t.Setenv("REPLICA_MAX_LAG", "2s")
+	f := newMoveFixture(t)
+	replica, lagging, failing := openSQLite(t, "replica"), openSQLite(t, "lagging"), openSQLite(t, "failing")
+	f.sm.AddCheckedReplica(0, replica, time.Second, nil)
+	f.sm.AddCheckedReplica(0, lagging, 3*time.Second, nil)
+	f.sm.AddCheckedReplica(0, failing, 0, errors.New("not streaming"))
+
+	tests := []struct {
+		name    string
+		prepare func(ctx context.Context)
+		shard   int
+		want    *gorm.DB
+	}{
+		{name: "usable replica", prepare: func(context.Context) {}, want: replica},
+		{name: "shard without replicas", prepare: func(context.Context) {}, shard: 1, want: f.dst},
+		{name: "request wrote", prepare: session.MarkWrite, want: f.src},
+		{name: "user wrote through this service", prepare: func(ctx context.Context) {
+			f.sm.MarkWrite(session.New(ctx, session.UserID(ctx)))
+		}, want: f.src},
+		{name: "user wrote through another service", prepare: func(ctx context.Context) {
+			if err := cache.MarkUserWrite(ctx, session.UserID(ctx), time.Second); err != nil {
+				t.Fatal(err)
+			}
+		}, want: f.src},
+		{name: "write marker unknown", prepare: func(context.Context) {
+			redisServer.SetError("down")
+			t.Cleanup(func() { redisServer.SetError("") })
+		}, want: f.src},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a user per case, as the service remembers its users' writes
+			ctx := session.New(context.Background(), uint(100+i))
+			tt.prepare(ctx)
+			got := f.sm.Reader(ctx, tt.shard)
+			if got != tt.want {
+				t.Fatalf("Reader returned the wrong database")
+			}
+			if session.ReadReplica(ctx) != (got == replica) {
+				t.Fatalf("ReadReplica = %v", session.ReadReplica(ctx))
+			}
+		})
+	}
+}
+
+func TestReaderAfterWriteWindow(t *testing.T) {
+	t.Setenv("READ_YOUR_WRITES_WINDOW", "50ms")
+	f := newMoveFixture(t)
+	replica := openSQLite(t, "replica")
+	f.sm.AddCheckedReplica(0, replica, 0, nil)
+
+	f.sm.MarkWrite(session.New(context.Background(), 7))
+	if got := f.sm.Reader(session.New(context.Background(), 7), 0); got != f.src {
+		t.Fatal("read right after the user's write did not go to the primary")
+	}
+	if got := f.sm.Reader(session.New(context.Background(), 8), 0); got != replica {
+		t.Fatal("another user's read did not go to the replica")
+	}
+	time.Sleep(60 * time.Millisecond)
+	redisServer.FastForward(60 * time.Millisecond)
+	if got := f.sm.Reader(session.New(context.Background(), 7), 0); got != replica {
+		t.Fatal("read after the window did not go to the replica")
+	}
+}
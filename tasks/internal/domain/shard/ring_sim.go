@@ -0,0 +1,141 @@
+package shard
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// PerformerLoad is a performer and the number of tasks they have.
+type PerformerLoad struct {
+	PerformerID uint
+	Tasks       int64
+}
+
+// ShardDistribution is one active shard's expected share of the ring before and after a
+// weight change. Shares are fractions of the hash space, i.e. of uniformly spread keys.
+type ShardDistribution struct {
+	Index              int
+	ID                 string
+	CurrentWeight      float64
+	ProposedWeight     float64
+	CurrentShare       float64
+	ProposedShare      float64
+	CurrentPerformers  int64
+	ProposedPerformers int64
+	CurrentTasks       int64
+	ProposedTasks      int64
+}
+
+// RingSimulation is the result of SimulateWeights.
+type RingSimulation struct {
+	Shards          []ShardDistribution
+	PerformersTotal int64
+	PerformersMoved int64
+	TasksTotal      int64
+	TasksMoved      int64
+}
+
+// SimulateWeights compares the ring of shards with the ring after applying weights
+// (shard ID -> new weight) and places every performer on both. Nothing is changed.
+func SimulateWeights(shards []ShardInfo, weights map[string]float64, vnodesPerShard int, performers []PerformerLoad) (RingSimulation, error) {
+	proposed := append([]ShardInfo{}, shards...)
+	for id, w := range weights {
+		i := findShard(proposed, id)
+		if i < 0 {
+			return RingSimulation{}, fmt.Errorf("%w: %s", ErrShardNotFound, id)
+		}
+		if proposed[i].State != ShardActive {
+			return RingSimulation{}, fmt.Errorf("%w: shard %s is %s", ErrInvalidShardState, id, proposed[i].State)
+		}
+		if err := ValidateShardWeight(w); err != nil {
+			return RingSimulation{}, err
+		}
+		proposed[i].Weight = w
+	}
+
+	before := newConsistentRing(ringMembers(shards, nil), vnodesPerShard)
+	after := newConsistentRing(ringMembers(proposed, nil), vnodesPerShard)
+	beforeShares, afterShares := before.shares(), after.shares()
+
+	byIndex := make(map[int]*ShardDistribution)
+	var sim RingSimulation
+	for i, s := range shards {
+		if s.State != ShardActive {
+			continue
+		}
+		sim.Shards = append(sim.Shards, ShardDistribution{
+			Index:          s.Index,
+			ID:             s.ID,
+			CurrentWeight:  s.EffectiveWeight(),
+			ProposedWeight: proposed[i].EffectiveWeight(),
+			CurrentShare:   beforeShares[s.Index],
+			ProposedShare:  afterShares[s.Index],
+		})
+	}
+	if len(sim.Shards) == 0 {
+		return sim, nil
+	}
+	for i := range sim.Shards {
+		byIndex[sim.Shards[i].Index] = &sim.Shards[i]
+	}
+
+	for _, p := range performers {
+		if p.PerformerID == 0 {
+			continue
+		}
+		key := performerKey(p.PerformerID)
+		was, is := byIndex[before.GetShard(key)], byIndex[after.GetShard(key)]
+		was.CurrentPerformers++
+		was.CurrentTasks += p.Tasks
+		is.ProposedPerformers++
+		is.ProposedTasks += p.Tasks
+		sim.PerformersTotal++
+		sim.TasksTotal += p.Tasks
+		if was != is {
+			sim.PerformersMoved++
+			sim.TasksMoved += p.Tasks
+		}
+	}
+	return sim, nil
+}
+
+// SimulateWeights runs SimulateWeights on the current topology with the performers found
+// on the shards.
+func (sm *ShardManager) SimulateWeights(ctx context.Context, weights map[string]float64) (RingSimulation, error) {
+	loads := make(map[uint]int64)
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		if err := countPerformerTasks(ctx, db, loads); err != nil {
+			return RingSimulation{}, fmt.Errorf("shard %d: %w", idx, err)
+		}
+	}
+	performers := make([]PerformerLoad, 0, len(loads))
+	for id, n := range loads {
+		performers = append(performers, PerformerLoad{PerformerID: id, Tasks: n})
+	}
+	sort.Slice(performers, func(i, j int) bool { return performers[i].PerformerID < performers[j].PerformerID })
+	return SimulateWeights(sm.Shards(), weights, vnodesPerShard(), performers)
+}
+
+func countPerformerTasks(ctx context.Context, db *gorm.DB, loads map[uint]int64) error {
+	var rows []PerformerLoad
+	err := db.WithContext(ctx).Unscoped().
+		Table("tasks").
+		Select("performer_id, COUNT(*) AS tasks").
+		Where("performer_id <> 0").
+		Group("performer_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		loads[r.PerformerID] += r.Tasks
+	}
+	return nil
+}
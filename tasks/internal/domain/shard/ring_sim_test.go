@@ -0,0 +1,57 @@
+package shard_test
+
+import (
+	"errors"
+	"math"
+	"tasks/internal/domain/shard"
+	"testing"
+)
+
+func TestSimulateWeights(t *testing.T) {
+	shards := []shard.ShardInfo{
+		{Index: 0, ID: "0", State: shard.ShardActive},
+		{Index: 1, ID: "1", State: shard.ShardActive},
+		{Index: 2, ID: "2", State: shard.ShardActive},
+	}
+	var performers []shard.PerformerLoad
+	for id := uint(1); id <= 3000; id++ {
+		performers = append(performers, shard.PerformerLoad{PerformerID: id, Tasks: 2})
+	}
+
+	sim, err := shard.SimulateWeights(shards, map[string]float64{"2": 2}, 256, performers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.PerformersTotal != 3000 || sim.TasksTotal != 6000 {
+		t.Fatalf("totals = %d performers, %d tasks", sim.PerformersTotal, sim.TasksTotal)
+	}
+
+	var current, proposed float64
+	for _, d := range sim.Shards {
+		current += d.CurrentShare
+		proposed += d.ProposedShare
+		if d.ID == "2" {
+			// weight 2 of a total of 4: about half the ring
+			if math.Abs(d.ProposedShare-0.5) > 0.06 {
+				t.Errorf("shard 2 proposed share = %.3f, want about 0.5", d.ProposedShare)
+			}
+			if d.ProposedPerformers-d.CurrentPerformers != sim.PerformersMoved {
+				t.Errorf("shard 2 gained %d performers, but %d moved", d.ProposedPerformers-d.CurrentPerformers, sim.PerformersMoved)
+			}
+		}
+	}
+	if math.Abs(current-1) > 1e-9 || math.Abs(proposed-1) > 1e-9 {
+		t.Errorf("shares sum to %.6f and %.6f, want 1", current, proposed)
+	}
+	if sim.PerformersMoved == 0 || sim.TasksMoved != 2*sim.PerformersMoved {
+		t.Errorf("moved %d performers with %d tasks", sim.PerformersMoved, sim.TasksMoved)
+	}
+}
+
+func TestSimulateWeightsRejectsUnknownShard(t *testing.T) {
+	shards := []shard.ShardInfo{{Index: 0, ID: "0", State: shard.ShardActive}}
+	_, err := shard.SimulateWeights(shards, map[string]float64{"9": 2}, 256, nil)
+	if !errors.Is(err, shard.ErrShardNotFound) {
+		t.Fatalf("err = %v, want ErrShardNotFound", err)
+	}
+}
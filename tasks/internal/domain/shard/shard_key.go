@@ -0,0 +1,328 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// ShardKey is the task field that places tasks on the ring. Tasks with the same value
+// share a shard, so a tenant key keeps an organization's data together and a project key
+// a project's. A task without a value for the key is placed by its performer.
+type ShardKey string
+
+const (
+	ShardKeyPerformer ShardKey = "performer"
+	ShardKeyTenant    ShardKey = "tenant"
+	ShardKeyProject   ShardKey = "project"
+)
+
+var (
+	// ErrInvalidShardKey means a shard key strategy is unknown.
+	ErrInvalidShardKey = errors.New("invalid shard key")
+	// ErrInvalidTenantPlacement means a tenant placement is malformed or would leave the
+	// ring without shards.
+	ErrInvalidTenantPlacement = errors.New("invalid tenant placement")
+)
+
+// ParseShardKey parses a shard key strategy; empty means the performer.
+func ParseShardKey(s string) (ShardKey, error) {
+	switch k := ShardKey(s); k {
+	case "":
+		return ShardKeyPerformer, nil
+	case ShardKeyPerformer, ShardKeyTenant, ShardKeyProject:
+		return k, nil
+	}
+	return "", fmt.Errorf("%w: %q is not performer, tenant or project", ErrInvalidShardKey, s)
+}
+
+// column is the tasks column holding the key.
+func (k ShardKey) column() string {
+	switch k {
+	case ShardKeyTenant:
+		return "tenant_id"
+	case ShardKeyProject:
+		return "project_id"
+	}
+	return "performer_id"
+}
+
+func (k ShardKey) value(t TaskKey) uint {
+	switch k {
+	case ShardKeyTenant:
+		return t.TenantID
+	case ShardKeyProject:
+		return t.ProjectID
+	}
+	return t.PerformerID
+}
+
+// ringKey returns the ring key of t under k, falling back to the performer when t has no
+// value for k. It returns false for a task without performer either; those are spread
+// round-robin.
+func ringKey(k ShardKey, t TaskKey) ([]byte, bool) {
+	if v := k.value(t); v != 0 && k != ShardKeyPerformer {
+		return []byte(fmt.Sprintf("%s:%d", k, v)), true
+	}
+	if t.PerformerID == 0 {
+		return nil, false
+	}
+	return performerKey(t.PerformerID), true
+}
+
+// TenantPlacement dedicates shards to a tenant. The shards leave the ring and hold only
+// the tenant's tasks, which are spread over them by performer. Placements live in the
+// shared topology, so every replica applies them.
+type TenantPlacement struct {
+	TenantID uint     `json:"tenant_id"`
+	Shards   []string `json:"shards"`
+}
+
+func tenantMap(list []TenantPlacement) map[uint]TenantPlacement {
+	m := make(map[uint]TenantPlacement, len(list))
+	for _, p := range list {
+		m[p.TenantID] = p
+	}
+	return m
+}
+
+func (sm *ShardManager) tenantList() []TenantPlacement {
+	list := make([]TenantPlacement, 0, len(sm.tenants))
+	for _, p := range sm.tenants {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].TenantID < list[j].TenantID })
+	return list
+}
+
+// dedicatedShards maps the IDs of dedicated shards to their tenant.
+func dedicatedShards(tenants []TenantPlacement) map[string]uint {
+	m := make(map[string]uint)
+	for _, p := range tenants {
+		for _, id := range p.Shards {
+			m[id] = p.TenantID
+		}
+	}
+	return m
+}
+
+// ShardKey returns the shard key strategy this replica routes with.
+func (sm *ShardManager) ShardKey() ShardKey {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.shardKey
+}
+
+// Placement rules, in the order placeTask applies them.
+const (
+	RuleTenant     = "tenant"
+	RuleOverride   = "override"
+	RuleRing       = "ring"
+	RuleRoundRobin = "round-robin"
+)
+
+// placeTask returns the shard k belongs on: a shard dedicated to its tenant, its
+// performer's override, or its ring shard under the shard key. It returns false for a
+// task without any key, which goes to any ring shard.
+func (sm *ShardManager) placeTask(k TaskKey) (int, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	idx, rule := sm.placeTaskLocked(k)
+	return idx, rule != RuleRoundRobin
+}
+
+// placeTaskLocked is placeTask, also returning the rule that placed k.
+func (sm *ShardManager) placeTaskLocked(k TaskKey) (int, string) {
+	if p, ok := sm.tenants[k.TenantID]; ok && k.TenantID != 0 {
+		if idx, ok := sm.tenantShardLocked(p, k.PerformerID); ok {
+			return idx, RuleTenant
+		}
+	}
+	if o, ok := sm.overrides[k.PerformerID]; ok && k.PerformerID != 0 {
+		if idx, ok := sm.overrideShardLocked(o, o.secondaryKey(k)); ok {
+			return idx, RuleOverride
+		}
+	}
+	key, ok := ringKey(sm.shardKey, k)
+	if !ok || len(sm.active) == 0 {
+		return 0, RuleRoundRobin
+	}
+	return sm.ring.GetShard(key), RuleRing
+}
+
+// tenantShardLocked picks the shard for a performer's tasks among the tenant's active
+// dedicated shards with rendezvous hashing. It returns false if none of them is active;
+// the tenant's tasks go to the ring then.
+func (sm *ShardManager) tenantShardLocked(p TenantPlacement, performerID uint) (int, bool) {
+	best, bestScore, found := 0, uint64(0), false
+	for _, idx := range sm.activeShardsLocked(p.Shards) {
+		score := xxhash.Sum64String(fmt.Sprintf("tenant:%d:%d:%s", p.TenantID, performerID, sm.infos[idx].ID))
+		if !found || score > bestScore {
+			best, bestScore, found = idx, score, true
+		}
+	}
+	return best, found
+}
+
+// activeShardsLocked returns the indexes of the active shards among ids.
+func (sm *ShardManager) activeShardsLocked(ids []string) []int {
+	var indexes []int
+	for _, id := range ids {
+		if i := findShard(sm.infos, id); i >= 0 && sm.infos[i].State == ShardActive {
+			indexes = append(indexes, sm.infos[i].Index)
+		}
+	}
+	return indexes
+}
+
+// onRing reports whether the shard at index is an active shard on the ring, i.e. one
+// that takes tasks without a key.
+func (sm *ShardManager) onRing(index int) bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return slices.Contains(sm.active, index)
+}
+
+// TenantShards returns the shards a tenant's tasks are on (see PlacedTenantShards).
+// While a rebalance is moving tasks they may be anywhere and every shard is returned.
+func (sm *ShardManager) TenantShards(ctx context.Context, tenantID uint) []int {
+	if moving, err := RebalancePending(ctx); err != nil || moving {
+		return sm.ShardIndexes()
+	}
+	return sm.PlacedTenantShards(tenantID)
+}
+
+// PlacedTenantShards returns the shards placement puts a tenant's tasks on: its
+// dedicated shards, or under the tenant key its ring shard plus the shards of every
+// performer override, which take precedence over the ring key. Under another key the
+// tasks may be on any shard and every shard is returned.
+func (sm *ShardManager) PlacedTenantShards(tenantID uint) []int {
+	sm.mu.RLock()
+	var indexes, overridden []int
+	if p, ok := sm.tenants[tenantID]; ok {
+		indexes = sm.activeShardsLocked(p.Shards)
+	}
+	key := sm.shardKey
+	for _, o := range sm.overrides {
+		overridden = append(overridden, sm.activeShardsLocked(o.Shards)...)
+	}
+	sm.mu.RUnlock()
+	if len(indexes) > 0 {
+		return indexes
+	}
+	if key != ShardKeyTenant {
+		return sm.ShardIndexes()
+	}
+	indexes = []int{sm.ResolveTask(TaskKey{TenantID: tenantID})}
+	for _, idx := range overridden {
+		if !slices.Contains(indexes, idx) {
+			indexes = append(indexes, idx)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// SetShardKey changes the shard key strategy of every replica. Existing tasks stay where
+// they are until a rebalance moves them.
+func (sm *ShardManager) SetShardKey(ctx context.Context, key ShardKey) (Topology, error) {
+	key, err := ParseShardKey(string(key))
+	if err != nil {
+		return Topology{}, err
+	}
+	topo, err := sm.updateTopology(ctx, nil, func(t *Topology) error {
+		if t.ShardKey.orDefault() == key {
+			return errNoChange
+		}
+		t.ShardKey = key
+		return nil
+	})
+	if err != nil {
+		return Topology{}, err
+	}
+	log.Printf("[topology] shard key %s, version %d", key, topo.Version)
+	return topo, nil
+}
+
+// orDefault maps the empty key of topologies written before shard keys existed to the performer.
+func (k ShardKey) orDefault() ShardKey {
+	if k == "" {
+		return ShardKeyPerformer
+	}
+	return k
+}
+
+// SetTenantPlacement dedicates shards to a tenant, replacing any previous placement.
+// With no shards the placement is removed and the tenant's tasks go back to the ring.
+// A shard can be dedicated to one tenant only, must not be used by performer overrides,
+// and at least one active shard must stay on the ring. A rebalance moves the tasks.
+func (sm *ShardManager) SetTenantPlacement(ctx context.Context, p TenantPlacement) (Topology, error) {
+	if p.TenantID == 0 {
+		return Topology{}, fmt.Errorf("%w: tenant id is required", ErrInvalidTenantPlacement)
+	}
+	seen := make(map[string]bool, len(p.Shards))
+	for _, id := range p.Shards {
+		if seen[id] {
+			return Topology{}, fmt.Errorf("%w: shard %s listed twice", ErrInvalidTenantPlacement, id)
+		}
+		seen[id] = true
+	}
+
+	topo, err := sm.updateTopology(ctx, nil, func(t *Topology) error {
+		kept := make([]TenantPlacement, 0, len(t.Tenants)+1)
+		for _, existing := range t.Tenants {
+			if existing.TenantID != p.TenantID {
+				kept = append(kept, existing)
+			}
+		}
+		if len(p.Shards) > 0 {
+			kept = append(kept, p)
+		}
+		if err := validateTenantShards(t, kept, p); err != nil {
+			return err
+		}
+		t.Tenants = kept
+		return nil
+	})
+	if err != nil {
+		return Topology{}, err
+	}
+	if len(p.Shards) == 0 {
+		log.Printf("[topology] tenant %d back on the ring, version %d", p.TenantID, topo.Version)
+	} else {
+		log.Printf("[topology] tenant %d placed on dedicated shards %v, version %d", p.TenantID, p.Shards, topo.Version)
+	}
+	return topo, nil
+}
+
+func validateTenantShards(t *Topology, tenants []TenantPlacement, p TenantPlacement) error {
+	dedicated := dedicatedShards(tenants)
+	for _, id := range p.Shards {
+		i := findShard(t.Shards, id)
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrShardNotFound, id)
+		}
+		if t.Shards[i].State != ShardActive {
+			return fmt.Errorf("%w: shard %s is %s", ErrInvalidShardState, id, t.Shards[i].State)
+		}
+		for _, other := range tenants {
+			if other.TenantID != p.TenantID && slices.Contains(other.Shards, id) {
+				return fmt.Errorf("%w: shard %s is dedicated to tenant %d", ErrInvalidTenantPlacement, id, other.TenantID)
+			}
+		}
+		for _, o := range t.Overrides {
+			if slices.Contains(o.Shards, id) {
+				return fmt.Errorf("%w: shard %s is used by the override of performer %d", ErrInvalidTenantPlacement, id, o.PerformerID)
+			}
+		}
+	}
+	if len(ringMembers(t.Shards, dedicated)) == 0 {
+		return fmt.Errorf("%w: no active shard would be left on the ring", ErrInvalidTenantPlacement)
+	}
+	return nil
+}
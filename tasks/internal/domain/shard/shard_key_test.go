@@ -0,0 +1,150 @@
+package shard_test
+
+import (
+	"slices"
+	"strings"
+	"tasks/internal/domain/shard"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestResolveTaskWithShardKey(t *testing.T) {
+	shards := []shard.ShardInfo{
+		{Index: 0, ID: "a", State: shard.ShardActive},
+		{Index: 1, ID: "b", State: shard.ShardActive},
+		{Index: 2, ID: "c", State: shard.ShardActive},
+		{Index: 3, ID: "big", State: shard.ShardActive},
+	}
+	tenants := []shard.TenantPlacement{{TenantID: 42, Shards: []string{"big"}}}
+
+	tests := []struct {
+		name      string
+		key       shard.ShardKey
+		overrides []shard.PerformerOverride
+	}{
+		{name: "performer", key: shard.ShardKeyPerformer},
+		{name: "tenant", key: shard.ShardKeyTenant},
+		{name: "project", key: shard.ShardKeyProject},
+		{name: "tenant with override", key: shard.ShardKeyTenant, overrides: []shard.PerformerOverride{{PerformerID: 500, Shards: []string{"a", "c"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topo := shard.Topology{Shards: shards, ShardKey: tt.key, Tenants: tenants, Overrides: tt.overrides}
+			sm := shard.NewShardManagerFromTopology(topo, make([]*gorm.DB, 4))
+
+			for p := uint(1); p <= 100; p++ {
+				k := shard.TaskKey{PerformerID: p, ProjectID: p % 7, TenantID: p % 5}
+				got := sm.ResolveTask(k)
+				if got == 3 {
+					t.Fatalf("task %+v on the dedicated shard", k)
+				}
+				// a task sharing the key's value, or the performer when the task has none
+				same := shard.TaskKey{PerformerID: p, ProjectID: 99, TenantID: 3}
+				switch {
+				case tt.key == shard.ShardKeyTenant && k.TenantID != 0:
+					same = shard.TaskKey{PerformerID: p + 1000, TenantID: k.TenantID}
+				case tt.key == shard.ShardKeyProject && k.ProjectID != 0:
+					same = shard.TaskKey{PerformerID: p + 1000, ProjectID: k.ProjectID, TenantID: 1}
+				case tt.key != shard.ShardKeyPerformer:
+					same = shard.TaskKey{PerformerID: p}
+				}
+				if again := sm.ResolveTask(same); again != got {
+					t.Fatalf("%+v on shard %d, %+v on %d; want the same shard", k, got, same, again)
+				}
+			}
+
+			for p := uint(1); p <= 20; p++ {
+				if got := sm.ResolveTask(shard.TaskKey{PerformerID: p, TenantID: 42}); got != 3 {
+					t.Fatalf("dedicated tenant: performer %d on shard %d, want 3", p, got)
+				}
+			}
+
+			// an overridden performer's tasks leave the tenant's ring shard, so the
+			// tenant's shards must include them
+			for _, o := range tt.overrides {
+				for tenant := uint(1); tenant <= 5; tenant++ {
+					for project := uint(1); project <= 10; project++ {
+						k := shard.TaskKey{PerformerID: o.PerformerID, ProjectID: project, TenantID: tenant}
+						got := sm.ResolveTask(k)
+						if got != 0 && got != 2 {
+							t.Fatalf("override: %+v on shard %d, want 0 or 2", k, got)
+						}
+						if placed := sm.PlacedTenantShards(tenant); !slices.Contains(placed, got) {
+							t.Fatalf("override: %+v on shard %d, tenant shards %v", k, got, placed)
+						}
+					}
+				}
+				if got := sm.ResolveTask(shard.TaskKey{PerformerID: o.PerformerID, TenantID: 42}); got != 3 {
+					t.Fatalf("dedicated tenant: overridden performer on shard %d, want 3", got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseShardKey(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    shard.ShardKey
+		wantErr bool
+	}{
+		{in: "", want: shard.ShardKeyPerformer},
+		{in: "tenant", want: shard.ShardKeyTenant},
+		{in: "project", want: shard.ShardKeyProject},
+		{in: "creator", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := shard.ParseShardKey(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Fatalf("ParseShardKey(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestRingLayout(t *testing.T) {
+	shards := []shard.ShardInfo{
+		{Index: 0, ID: "a", State: shard.ShardActive},
+		{Index: 1, ID: "b", State: shard.ShardActive, Weight: 2},
+		{Index: 2, ID: "big", State: shard.ShardActive},
+		{Index: 3, ID: "old", State: shard.ShardRemoved},
+	}
+	tenants := []shard.TenantPlacement{{TenantID: 42, Shards: []string{"big"}}}
+	sm := shard.NewShardManagerFromTopology(shard.Topology{Shards: shards, Tenants: tenants}, make([]*gorm.DB, 4))
+
+	layout := sm.Ring(true)
+	if len(layout.Shards) != 3 {
+		t.Fatalf("got %d shards, want 3 (removed left out)", len(layout.Shards))
+	}
+	per := layout.VnodesPerShard
+	want := []struct {
+		vnodes int
+		tenant uint
+	}{{per, 0}, {2 * per, 0}, {0, 42}}
+	total := 0.0
+	for i, s := range layout.Shards {
+		if s.Vnodes != want[i].vnodes || s.TenantID != want[i].tenant {
+			t.Fatalf("shard %s: %d vnodes, tenant %d; want %d, %d", s.ID, s.Vnodes, s.TenantID, want[i].vnodes, want[i].tenant)
+		}
+		total += s.Share
+	}
+	if total < 0.999 || total > 1.001 {
+		t.Fatalf("shares add up to %f", total)
+	}
+	if b := layout.Shards[1].Share; b < 0.55 || b > 0.78 {
+		t.Fatalf("weight 2 shard owns %.2f of the ring", b)
+	}
+	if len(layout.Vnodes) != 3*per {
+		t.Fatalf("got %d vnodes, want %d", len(layout.Vnodes), 3*per)
+	}
+	for i := 1; i < len(layout.Vnodes); i++ {
+		if layout.Vnodes[i].Hash < layout.Vnodes[i-1].Hash {
+			t.Fatal("vnodes not sorted by hash")
+		}
+	}
+	for _, v := range layout.Vnodes {
+		if !strings.HasPrefix(v.Key, "shard-"+shards[v.Shard].ID+"-vnode-") {
+			t.Fatalf("vnode key %q does not name shard %d", v.Key, v.Shard)
+		}
+	}
+}
@@ -1,73 +1,203 @@
 package shard
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 // Number of virtual nodes per physical shard (100–1000 for even distribution).
 const defaultVnodesPerShard = 256
 
+// MaxShardWeight bounds shard weights, and with them the size of the ring.
+const MaxShardWeight = 16
+
+// ShardState is the lifecycle state of a shard slot.
+type ShardState string
+
+const (
+	// ShardActive shards are on the ring and receive new writes.
+	ShardActive ShardState = "active"
+	// ShardDraining shards are off the ring but still readable; rebalancing moves their data away.
+	ShardDraining ShardState = "draining"
+	// ShardRemoved marks an emptied slot. Its index is never reused, so task->shard
+	// mappings in Redis can never point at a different database.
+	ShardRemoved ShardState = "removed"
+)
+
+// ShardInfo describes one slot of the shard list. Index is the position used by the
+// task->shard mapping and the ID allocator; ID is the stable name used for ring placement.
+// Weight scales the shard's share of the ring; 0 means the default weight 1.
+// Replicas are DSNs of read-only copies that serve reads (see ShardManager.Reader).
+type ShardInfo struct {
+	Index    int        `json:"index"`
+	ID       string     `json:"id"`
+	DSN      string     `json:"dsn,omitempty"`
+	State    ShardState `json:"state"`
+	Weight   float64    `json:"weight,omitempty"`
+	Replicas []string   `json:"replicas,omitempty"`
+}
+
+// EffectiveWeight returns the weight used on the ring.
+func (s ShardInfo) EffectiveWeight() float64 {
+	if s.Weight <= 0 {
+		return 1
+	}
+	return s.Weight
+}
+
 type ShardManager struct {
-	shards []*gorm.DB
+	shards []*gorm.DB  // index-aligned with infos; nil for removed slots
+	infos  []ShardInfo // infos[i] describes shards[i]
+	active []int       // indexes of active shards, for round-robin
 	ring   *consistentRing
-	mu     sync.RWMutex
+	// replicas[i] are the read replicas of shards[i]
+	replicas [][]*replica
+	routing  *replicaRouting
+	// breaker and last ping by shard index
+	health    sync.Map
+	healthCfg healthConfig
+	// placement overrides by performer ID
+	overrides map[uint]PerformerOverride
+	// task field placing tasks on the ring, and shards dedicated to tenants
+	shardKey ShardKey
+	tenants  map[uint]TenantPlacement
+	// requests per shard and performer since the last load report
+	load *loadTracker
+	mu   sync.RWMutex
 	// round-robin when performer_id == 0 (fallback)
 	nextShardIndex uint32
+	// version of the shared topology record this replica has applied
+	topologyVersion int64
+	// schema version every shard was found on at startup
+	schemaVersion int
 }
 
 var ShardMgr *ShardManager
 
+// shardIDPattern limits explicit shard IDs to characters that are safe in vnode keys.
+var shardIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// shardPrefixPattern matches the "id", "id:weight" or ":weight" prefix of a DB_SHARD_URLS entry.
+var shardPrefixPattern = regexp.MustCompile(`^([A-Za-z0-9_-]*)(?::([0-9]+(?:\.[0-9]+)?))?$`)
+
 func InitShardManager() {
 	shardURLs := os.Getenv("DB_SHARD_URLS")
 	if shardURLs == "" {
 		log.Fatal("DB_SHARD_URLS not set")
 	}
 
-	urls := strings.Split(shardURLs, ",")
-	shards := make([]*gorm.DB, 0, len(urls))
-
-	for i, url := range urls {
-		url = strings.TrimSpace(url)
-		if url == "" {
-			continue
-		}
+	infos, err := ParseShardURLs(shardURLs)
+	if err != nil {
+		log.Fatalf("DB_SHARD_URLS: %v", err)
+	}
+	if len(infos) == 0 {
+		log.Fatal("No shards configured")
+	}
 
-		db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+	shards := make([]*gorm.DB, len(infos))
+	for i, info := range infos {
+		db, err := openShard(info.ID, info.DSN)
 		if err != nil {
-			log.Fatalf("Failed to connect to shard %d: %v", i, err)
+			log.Fatalf("Failed to connect to shard %d (%s): %v", i, info.ID, err)
 		}
+		shards[i] = db
+		log.Printf("Connected to shard %d (%s)", i, info.ID)
+	}
 
-		shards = append(shards, db)
-		log.Printf("Connected to shard %d", i)
+	key, err := ParseShardKey(os.Getenv("SHARD_KEY"))
+	if err != nil {
+		log.Fatalf("SHARD_KEY: %v", err)
 	}
 
-	if len(shards) == 0 {
-		log.Fatal("No shards configured")
+	ShardMgr = NewShardManagerFromTopology(Topology{Shards: infos, ShardKey: key}, shards)
+	ShardMgr.mu.Lock()
+	for i, info := range ShardMgr.infos {
+		ShardMgr.replicas[i] = openReplicas(info, nil)
 	}
+	ShardMgr.mu.Unlock()
+	ShardMgr.CheckReplicas(context.Background())
+	log.Printf("ShardManager initialized with %d shards, %d vnodes/shard (consistent ring)", len(shards), vnodesPerShard())
+}
 
-	vnodes := defaultVnodesPerShard
-	if s := os.Getenv("VNODES_PER_SHARD"); s != "" {
-		if n, err := strconv.Atoi(s); err == nil && n > 0 {
-			vnodes = n
+// ParseShardURLs parses DB_SHARD_URLS: comma-separated DSNs, each optionally prefixed
+// with a stable shard ID as "id=postgres://...". Without a prefix the ID is the position
+// in the list, which keeps the ring placement of existing deployments unchanged.
+// A weight may follow the ID as "id:2=postgres://..." (or ":2=..." with a positional ID).
+// Read replicas follow the primary DSN, separated by "|": "id=postgres://primary|postgres://replica".
+func ParseShardURLs(s string) ([]ShardInfo, error) {
+	var infos []ShardInfo
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		info := ShardInfo{Index: len(infos), ID: strconv.Itoa(len(infos)), DSN: entry, State: ShardActive}
+		if eq := strings.Index(entry, "="); eq > 0 && strings.Contains(entry[eq+1:], "://") {
+			if m := shardPrefixPattern.FindStringSubmatch(entry[:eq]); m != nil {
+				if m[1] != "" {
+					info.ID = m[1]
+				}
+				if m[2] != "" {
+					w, _ := strconv.ParseFloat(m[2], 64)
+					if err := ValidateShardWeight(w); err != nil {
+						return nil, fmt.Errorf("shard %s: %w", info.ID, err)
+					}
+					info.Weight = w
+				}
+				info.DSN = strings.TrimSpace(entry[eq+1:])
+			}
+		}
+		if dsns := strings.Split(info.DSN, "|"); len(dsns) > 1 {
+			info.DSN = strings.TrimSpace(dsns[0])
+			for _, dsn := range dsns[1:] {
+				if dsn = strings.TrimSpace(dsn); dsn != "" {
+					info.Replicas = append(info.Replicas, dsn)
+				}
+			}
 		}
+		if seen[info.ID] {
+			return nil, fmt.Errorf("duplicate shard id %q", info.ID)
+		}
+		seen[info.ID] = true
+		infos = append(infos, info)
 	}
+	return infos, nil
+}
 
-	ring := newConsistentRing(len(shards), vnodes)
+// NewShardManagerFromInfos builds a manager over already opened shards.
+// shards must be index-aligned with infos (nil for removed slots).
+func NewShardManagerFromInfos(infos []ShardInfo, shards []*gorm.DB) *ShardManager {
+	return NewShardManagerFromTopology(Topology{Shards: infos}, shards)
+}
 
-	ShardMgr = &ShardManager{
-		shards: shards,
-		ring:   ring,
+// NewShardManagerFromTopology builds a manager over already opened shards that routes
+// with topo, including its shard key and placements. shards must be index-aligned with topo.Shards.
+func NewShardManagerFromTopology(topo Topology, shards []*gorm.DB) *ShardManager {
+	sm := &ShardManager{
+		shards:          shards,
+		replicas:        make([][]*replica, len(shards)),
+		routing:         newReplicaRouting(),
+		healthCfg:       newHealthConfig(),
+		infos:           withoutPasswords(topo.Shards),
+		overrides:       overrideMap(topo.Overrides),
+		shardKey:        topo.ShardKey.orDefault(),
+		tenants:         tenantMap(topo.Tenants),
+		ring:            newConsistentRing(nil, 0),
+		load:            newLoadTracker(),
+		topologyVersion: topo.Version,
 	}
-	log.Printf("ShardManager initialized with %d shards, %d vnodes/shard (consistent ring)", len(shards), vnodes)
+	sm.rebuildRingLocked()
+	return sm
 }
 
 // GetShardByPerformerID returns the shard for performer_id (ring key: performer:{id}).
@@ -78,29 +208,35 @@ func (sm *ShardManager) GetShardByPerformerID(performerID uint) *gorm.DB {
 }
 
 // GetShardByPerformerIDIndex returns the shard index for performer_id (first shard clockwise on the ring).
-// When performerID == 0, uses round-robin.
+// When performerID == 0, uses round-robin over active shards.
 func (sm *ShardManager) GetShardByPerformerIDIndex(performerID uint) int {
 	sm.mu.RLock()
-	n := len(sm.shards)
+	active := sm.active
 	sm.mu.RUnlock()
-	if n == 0 {
+	if len(active) == 0 {
 		return 0
 	}
 	if performerID == 0 {
 		i := atomic.AddUint32(&sm.nextShardIndex, 1)
-		return int(i-1) % n
+		return active[int(i-1)%len(active)]
 	}
-	key := []byte(fmt.Sprintf("performer:%d", performerID))
-	sm.mu.RLock()
-	idx := sm.ring.GetShard(key)
-	sm.mu.RUnlock()
-	if idx < 0 || idx >= n {
-		return 0
+	return sm.ring.GetShard(performerKey(performerID))
+}
+
+// performerKey is the ring key of a performer.
+func performerKey(performerID uint) []byte {
+	return []byte(fmt.Sprintf("performer:%d", performerID))
+}
+
+// ValidateShardWeight checks that w is a usable shard weight.
+func ValidateShardWeight(w float64) error {
+	if !(w > 0 && w <= MaxShardWeight) {
+		return fmt.Errorf("%w: %g is not in (0, %d]", ErrInvalidShardWeight, w, MaxShardWeight)
 	}
-	return idx
+	return nil
 }
 
-// GetShardByIndex returns the shard by index (0-based).
+// GetShardByIndex returns the shard by index (0-based), or nil for unknown and removed slots.
 func (sm *ShardManager) GetShardByIndex(index int) *gorm.DB {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
@@ -110,6 +246,7 @@ func (sm *ShardManager) GetShardByIndex(index int) *gorm.DB {
 	return sm.shards[index]
 }
 
+// GetAllShards returns all slots by index; removed slots are nil.
 func (sm *ShardManager) GetAllShards() []*gorm.DB {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
@@ -118,38 +255,88 @@ func (sm *ShardManager) GetAllShards() []*gorm.DB {
 	return shards
 }
 
+// GetShardCount returns the number of slots, including removed ones.
+// Use ShardIndexes to iterate over shards that hold data.
 func (sm *ShardManager) GetShardCount() int {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 	return len(sm.shards)
 }
 
-// Resolve is a convenience wrapper that returns the shard index for a performer ID.
-// It keeps existing call sites that expect a Resolve method.
+// ShardIndexes returns the indexes of active and draining shards.
+func (sm *ShardManager) ShardIndexes() []int {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	indexes := make([]int, 0, len(sm.infos))
+	for _, info := range sm.infos {
+		if info.State != ShardRemoved {
+			indexes = append(indexes, info.Index)
+		}
+	}
+	return indexes
+}
+
+// Shards describes every slot, including draining and removed ones.
+func (sm *ShardManager) Shards() []ShardInfo {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	infos := make([]ShardInfo, len(sm.infos))
+	copy(infos, sm.infos)
+	return infos
+}
+
+// Resolve is a convenience wrapper that returns the ring shard index for a performer ID.
+// It keeps existing call sites that expect a Resolve method. Tasks are placed with
+// ResolveTask, which also applies performer overrides.
 func (sm *ShardManager) Resolve(performerID uint) int {
 	return sm.GetShardByPerformerIDIndex(performerID)
 }
 
-// RebuildRing rebuilds the ring with the current number of shards (after adding a shard).
+// RebuildRing rebuilds the ring from the active shards.
 // On restart with new DB_SHARD_URLS, InitShardManager already builds a new ring.
 func (sm *ShardManager) RebuildRing() {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	n := len(sm.shards)
-	vnodes := defaultVnodesPerShard
+	sm.rebuildRingLocked()
+}
+
+func (sm *ShardManager) rebuildRingLocked() {
+	members := ringMembers(sm.infos, dedicatedShards(sm.tenantList()))
+	active := make([]int, 0, len(members))
+	for _, m := range members {
+		active = append(active, m.index)
+	}
+	vnodes := vnodesPerShard()
+	sm.ring.Rebuild(members, vnodes)
+	sm.active = active
+	log.Printf("Ring rebuilt with %d active shards, %d vnodes/shard, shard key %s", len(members), vnodes, sm.shardKey)
+}
+
+// ringMembers returns the active shards of infos as ring members, leaving out the shards
+// dedicated to a tenant.
+func ringMembers(infos []ShardInfo, dedicated map[string]uint) []ringMember {
+	members := make([]ringMember, 0, len(infos))
+	for _, info := range infos {
+		if _, ok := dedicated[info.ID]; !ok && info.State == ShardActive {
+			members = append(members, ringMember{index: info.Index, id: info.ID, weight: info.Weight})
+		}
+	}
+	return members
+}
+
+func vnodesPerShard() int {
 	if s := os.Getenv("VNODES_PER_SHARD"); s != "" {
 		if v, err := strconv.Atoi(s); err == nil && v > 0 {
-			vnodes = v
+			return v
 		}
 	}
-	sm.ring.Rebuild(n, vnodes)
-	log.Printf("Ring rebuilt with %d shards, %d vnodes/shard", n, vnodes)
+	return defaultVnodesPerShard
 }
 
 func NewShardManagerForTesting(shards []*gorm.DB) *ShardManager {
-	ring := newConsistentRing(len(shards), defaultVnodesPerShard)
-	return &ShardManager{
-		shards: shards,
-		ring:   ring,
+	infos := make([]ShardInfo, len(shards))
+	for i, m := range indexMembers(len(shards)) {
+		infos[i] = ShardInfo{Index: m.index, ID: m.id, State: ShardActive}
 	}
+	return NewShardManagerFromInfos(infos, shards)
 }
@@ -0,0 +1,101 @@
+package shard_test
+
+import (
+	"reflect"
+	"tasks/internal/domain/shard"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestParseShardURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []shard.ShardInfo
+		wantErr bool
+	}{
+		{
+			name: "positional ids",
+			in:   "postgres://a/db, postgres://b/db",
+			want: []shard.ShardInfo{
+				{Index: 0, ID: "0", DSN: "postgres://a/db", State: shard.ShardActive},
+				{Index: 1, ID: "1", DSN: "postgres://b/db", State: shard.ShardActive},
+			},
+		},
+		{
+			name: "explicit ids",
+			in:   "eu-1=postgres://a/db?sslmode=disable,postgres://b/db",
+			want: []shard.ShardInfo{
+				{Index: 0, ID: "eu-1", DSN: "postgres://a/db?sslmode=disable", State: shard.ShardActive},
+				{Index: 1, ID: "1", DSN: "postgres://b/db", State: shard.ShardActive},
+			},
+		},
+		{
+			name: "key-value dsn is not an id",
+			in:   "host=a user=x dbname=tasks",
+			want: []shard.ShardInfo{
+				{Index: 0, ID: "0", DSN: "host=a user=x dbname=tasks", State: shard.ShardActive},
+			},
+		},
+		{
+			name: "weights",
+			in:   "big:2.5=postgres://a/db,:0.5=postgres://b/db",
+			want: []shard.ShardInfo{
+				{Index: 0, ID: "big", DSN: "postgres://a/db", State: shard.ShardActive, Weight: 2.5},
+				{Index: 1, ID: "1", DSN: "postgres://b/db", State: shard.ShardActive, Weight: 0.5},
+			},
+		},
+		{
+			name: "replicas",
+			in:   "a=postgres://a/db|postgres://a-ro1/db|postgres://a-ro2/db,postgres://b/db",
+			want: []shard.ShardInfo{
+				{Index: 0, ID: "a", DSN: "postgres://a/db", State: shard.ShardActive, Replicas: []string{"postgres://a-ro1/db", "postgres://a-ro2/db"}},
+				{Index: 1, ID: "1", DSN: "postgres://b/db", State: shard.ShardActive},
+			},
+		},
+		{name: "duplicate ids", in: "1=postgres://a/db,postgres://b/db", wantErr: true},
+		{name: "weight out of range", in: "a:100=postgres://a/db", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shard.ParseShardURLs(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrainingShardOnlyMovesItsPerformers(t *testing.T) {
+	infos := []shard.ShardInfo{
+		{Index: 0, ID: "0", State: shard.ShardActive},
+		{Index: 1, ID: "1", State: shard.ShardActive},
+		{Index: 2, ID: "2", State: shard.ShardActive},
+	}
+	before := shard.NewShardManagerFromInfos(infos, make([]*gorm.DB, 3))
+
+	draining := append([]shard.ShardInfo{}, infos...)
+	draining[1].State = shard.ShardDraining
+	after := shard.NewShardManagerFromInfos(draining, make([]*gorm.DB, 3))
+
+	moved := 0
+	for performer := uint(1); performer <= 2000; performer++ {
+		was, is := before.Resolve(performer), after.Resolve(performer)
+		if is == 1 {
+			t.Fatalf("performer %d still routed to the draining shard", performer)
+		}
+		if was != 1 && was != is {
+			t.Fatalf("performer %d moved from shard %d to %d although its shard is not draining", performer, was, is)
+		}
+		if was == 1 {
+			moved++
+		}
+	}
+	if moved == 0 {
+		t.Fatal("expected some performers on the drained shard")
+	}
+}
@@ -0,0 +1,104 @@
+package shard
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Snowflake IDs are 63-bit, time-ordered and carry the shard they were created on:
+//
+//	| 41 bits ms since 2024-01-01 | 10 bits shard index | 5 bits worker | 7 bits sequence |
+//
+// The worker number keeps replicas apart; each replica issues up to 128 IDs per millisecond.
+const (
+	SnowflakeShardBits  = 10
+	SnowflakeWorkerBits = 5
+	snowflakeSeqBits    = 7
+
+	// MaxSnowflakeShards and MaxSnowflakeWorkers bound the shard index and worker number.
+	MaxSnowflakeShards  = 1 << SnowflakeShardBits
+	MaxSnowflakeWorkers = 1 << SnowflakeWorkerBits
+
+	snowflakeMaxSeq = 1<<snowflakeSeqBits - 1
+)
+
+// snowflakeEpoch is 2024-01-01T00:00:00Z; 41 bits of milliseconds last until 2093.
+var snowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates IDs for one worker. It never goes backwards: if the clock is set
+// back, or more than 128 IDs are requested within a millisecond, it borrows from the
+// next millisecond instead of waiting.
+type Snowflake struct {
+	mu     sync.Mutex
+	worker uint64
+	now    func() time.Time
+	lastMs int64
+	seq    uint64
+}
+
+// NewSnowflake creates a generator for worker (0..MaxSnowflakeWorkers-1). now is the clock; nil uses time.Now.
+func NewSnowflake(worker int, now func() time.Time) (*Snowflake, error) {
+	if worker < 0 || worker >= MaxSnowflakeWorkers {
+		return nil, fmt.Errorf("snowflake worker %d out of range [0, %d)", worker, MaxSnowflakeWorkers)
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &Snowflake{worker: uint64(worker), now: now, lastMs: -1}, nil
+}
+
+// Next returns a new ID for a task stored on shardIndex.
+func (s *Snowflake) Next(shardIndex int) (uint, error) {
+	if shardIndex < 0 || shardIndex >= MaxSnowflakeShards {
+		return 0, fmt.Errorf("shard index %d does not fit in a snowflake id", shardIndex)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := s.now().Sub(snowflakeEpoch).Milliseconds()
+	switch {
+	case ms > s.lastMs:
+		s.lastMs, s.seq = ms, 0
+	case s.seq < snowflakeMaxSeq:
+		s.seq++
+	default:
+		s.lastMs, s.seq = s.lastMs+1, 0
+	}
+	id := uint64(s.lastMs)<<(SnowflakeShardBits+SnowflakeWorkerBits+snowflakeSeqBits) |
+		uint64(shardIndex)<<(SnowflakeWorkerBits+snowflakeSeqBits) |
+		s.worker<<snowflakeSeqBits |
+		s.seq
+	return uint(id), nil
+}
+
+// Last returns the millisecond of the last ID issued, which may be ahead of the clock.
+func (s *Snowflake) Last() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return snowflakeEpoch.Add(time.Duration(s.lastMs) * time.Millisecond)
+}
+
+// SnowflakeParts is a decoded snowflake ID.
+type SnowflakeParts struct {
+	Time   time.Time
+	Shard  int
+	Worker int
+	Seq    int
+}
+
+// DecodeSnowflake splits id into its fields. It is only meaningful for IDs made by Snowflake.
+func DecodeSnowflake(id uint) SnowflakeParts {
+	v := uint64(id)
+	return SnowflakeParts{
+		Time:   snowflakeEpoch.Add(time.Duration(v>>(SnowflakeShardBits+SnowflakeWorkerBits+snowflakeSeqBits)) * time.Millisecond),
+		Shard:  int(v >> (SnowflakeWorkerBits + snowflakeSeqBits) & (MaxSnowflakeShards - 1)),
+		Worker: int(v >> snowflakeSeqBits & (MaxSnowflakeWorkers - 1)),
+		Seq:    int(v & snowflakeMaxSeq),
+	}
+}
+
+// FirstSnowflakeID is the smallest ID Snowflake can issue at t.
+func FirstSnowflakeID(t time.Time) uint {
+	return uint(uint64(t.Sub(snowflakeEpoch).Milliseconds()) << (SnowflakeShardBits + SnowflakeWorkerBits + snowflakeSeqBits))
+}
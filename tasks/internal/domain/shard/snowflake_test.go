@@ -0,0 +1,69 @@
+package shard_test
+
+import (
+	"tasks/internal/domain/shard"
+	"testing"
+	"time"
+)
+
+func TestSnowflakeEmbedsShardAndWorker(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	gen, err := shard.NewSnowflake(7, func() time.Time { return now })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := gen.Next(513)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := shard.DecodeSnowflake(id)
+	if got.Shard != 513 || got.Worker != 7 || !got.Time.Equal(now) {
+		t.Fatalf("decoded %+v, want shard 513, worker 7, time %s", got, now)
+	}
+	if id < shard.FirstSnowflakeID(now) {
+		t.Fatalf("id %d below FirstSnowflakeID", id)
+	}
+}
+
+func TestSnowflakeIsMonotonic(t *testing.T) {
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	gen, err := shard.NewSnowflake(0, func() time.Time { return clock })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[uint]bool)
+	var last uint
+	for i := 0; i < 1000; i++ {
+		switch i {
+		case 400:
+			clock = clock.Add(-time.Second) // clock set back
+		case 700:
+			clock = clock.Add(2 * time.Second)
+		}
+		id, err := gen.Next(i % 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id %d at %d", id, i)
+		}
+		seen[id] = true
+		// ids from the same worker increase regardless of the shard they embed
+		if shard.DecodeSnowflake(id).Time.Before(shard.DecodeSnowflake(last).Time) {
+			t.Fatalf("id %d at %d went back in time", id, i)
+		}
+		last = id
+	}
+}
+
+func TestSnowflakeRejectsOutOfRange(t *testing.T) {
+	if _, err := shard.NewSnowflake(shard.MaxSnowflakeWorkers, nil); err == nil {
+		t.Error("expected error for worker out of range")
+	}
+	gen, _ := shard.NewSnowflake(0, nil)
+	if _, err := gen.Next(shard.MaxSnowflakeShards); err == nil {
+		t.Error("expected error for shard out of range")
+	}
+}
@@ -0,0 +1,243 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/leader"
+	"tasks/internal/infrastructure/persistence"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Phases of a task move. Before the switch a failed move is rolled back; from the
+// switch on it is only ever completed.
+const (
+	taskMoveCopying   = "copying"
+	taskMoveSwitching = "switching"
+)
+
+const (
+	// TaskMoveLease is the lease of the recovery job.
+	TaskMoveLease = "task-moves"
+	// taskMoveGrace is how long a move may stay unchanged before recovery assumes the
+	// replica running it is gone.
+	taskMoveGrace = time.Minute
+	// taskMoveCompleteAttempts is how often MoveTask tries the steps from the switch on
+	// before leaving them to recovery.
+	taskMoveCompleteAttempts = 3
+)
+
+// taskMoveRetryPause is the pause before the second completion attempt; it grows with
+// each attempt.
+var taskMoveRetryPause = 100 * time.Millisecond
+
+// ErrTaskMoving means another move of the same task has not finished yet.
+var ErrTaskMoving = errors.New("task is being moved to another shard")
+
+// ErrTaskMovePending means the updated task was written to its new shard, but the move
+// could not be finished: until RecoverTaskMoves completes it, reads may return the task
+// as it was before the update.
+var ErrTaskMovePending = errors.New("task update saved on its new shard, but the move is still pending")
+
+// taskMove is the intent row of a move, stored on the source shard.
+type taskMove struct {
+	TaskID      uint `gorm:"primaryKey"`
+	PerformerID uint // performer of the source row, which the delete step matches on
+	FromShard   int
+	ToShard     int
+	Phase       string
+	Error       string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (taskMove) TableName() string {
+	return "task_moves"
+}
+
+// MoveTask moves a task that an update placed on another shard. task holds the updated
+// fields and observers; sourcePerformer is the performer of the row on shard from. The
+// move is a saga recorded in task_moves on the source shard:
+//
+//  1. intent: the move is recorded in phase "copying"
+//  2. copy: the task, its observers and sent reminders are upserted on shard to
+//  3. switch: the phase becomes "switching", then the directory points at shard to
+//  4. delete: the source rows and the intent are deleted
+//
+// Each step runs under the circuit breaker and query timeout of the shard it touches
+// (see Call). If a step before the switch fails, the copy is removed again and the task
+// stays where it was. Once switching, the move is only completed: the steps are retried a few times,
+// then left to RecoverTaskMoves, which also handles moves cut short by a crash, and
+// ErrTaskMovePending is returned.
+func (sm *ShardManager) MoveTask(ctx context.Context, task *persistence.Task, sourcePerformer uint, from, to int) error {
+	src, dst := sm.GetShardByIndex(from), sm.GetShardByIndex(to)
+	if src == nil || dst == nil {
+		return fmt.Errorf("shard %d or %d is not available", from, to)
+	}
+	m := taskMove{TaskID: task.ID, PerformerID: sourcePerformer, FromShard: from, ToShard: to, Phase: taskMoveCopying}
+	err := sm.Call(ctx, from, func(ctx context.Context) error {
+		return src.WithContext(ctx).Create(&m).Error
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("%w: task %d", ErrTaskMoving, task.ID)
+		}
+		return fmt.Errorf("record move intent: %w", err)
+	}
+
+	var reminders []persistence.Reminder
+	err = sm.Call(ctx, from, func(ctx context.Context) error {
+		return src.WithContext(ctx).Where("task_id = ?", task.ID).Find(&reminders).Error
+	})
+	if err == nil {
+		err = sm.Call(ctx, to, func(ctx context.Context) error {
+			return copyTask(ctx, dst, task, reminders)
+		})
+	}
+	if err == nil {
+		err = sm.Call(ctx, from, func(ctx context.Context) error {
+			return setTaskMovePhase(ctx, src, &m, taskMoveSwitching, "")
+		})
+	}
+	if err != nil {
+		_ = sm.rollbackTaskMove(context.WithoutCancel(ctx), src, dst, m, err)
+		return fmt.Errorf("move task %d to shard %d: %w", task.ID, to, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = sm.completeTaskMove(ctx, src, m)
+		if err == nil {
+			return nil
+		}
+		if attempt == taskMoveCompleteAttempts || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(attempt) * taskMoveRetryPause):
+		}
+	}
+	// the task is on the target; recovery points the directory at it and deletes what
+	// is left on the source
+	log.Printf("[task-move] task %d: finish move to shard %d: %v", m.TaskID, m.ToShard, err)
+	_ = setTaskMovePhase(context.WithoutCancel(ctx), src, &m, taskMoveSwitching, err.Error())
+	return fmt.Errorf("%w: task %d to shard %d: %v", ErrTaskMovePending, m.TaskID, m.ToShard, err)
+}
+
+// copyTask upserts the task with its observers and the source's sent reminders on dst.
+func copyTask(ctx context.Context, dst *gorm.DB, task *persistence.Task, reminders []persistence.Reminder) error {
+	task.UpdatedAt = time.Now()
+	return dst.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tasks := []persistence.Task{*task}
+		return upsertTasks(tx, tasks, reminders)
+	})
+}
+
+func setTaskMovePhase(ctx context.Context, src *gorm.DB, m *taskMove, phase, failure string) error {
+	m.Phase, m.Error = phase, failure
+	return src.WithContext(ctx).Model(m).Select("phase", "error", "updated_at").Updates(m).Error
+}
+
+// completeTaskMove runs the steps from the switch on: point the directory at the target,
+// delete the source rows, drop the intent. Every step may be repeated.
+func (sm *ShardManager) completeTaskMove(ctx context.Context, src *gorm.DB, m taskMove) error {
+	if err := directory.Default.Assign(ctx, m.ToShard, m.TaskID); err != nil {
+		return fmt.Errorf("switch directory: %w", err)
+	}
+	_ = cache.DeleteTaskCache(ctx, m.TaskID)
+	return sm.Call(ctx, m.FromShard, func(ctx context.Context) error {
+		if err := deleteTasks(ctx, src, "performer_id", m.PerformerID, []uint{m.TaskID}); err != nil {
+			return fmt.Errorf("delete source: %w", err)
+		}
+		return src.WithContext(ctx).Delete(&taskMove{}, m.TaskID).Error
+	})
+}
+
+// rollbackTaskMove removes the target copy of a move that failed before the switch and
+// drops the intent. If that fails too, the intent stays for RecoverTaskMoves and the
+// error is returned.
+func (sm *ShardManager) rollbackTaskMove(ctx context.Context, src, dst *gorm.DB, m taskMove, cause error) error {
+	err := sm.Call(ctx, m.ToShard, func(ctx context.Context) error {
+		return dst.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Where("task_id = ?", m.TaskID).Delete(&persistence.Observer{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("task_id = ?", m.TaskID).Delete(&persistence.Reminder{}).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Where("id = ?", m.TaskID).Delete(&persistence.Task{}).Error
+		})
+	})
+	if err == nil {
+		err = sm.Call(ctx, m.FromShard, func(ctx context.Context) error {
+			return src.WithContext(ctx).Delete(&taskMove{}, m.TaskID).Error
+		})
+	}
+	if err != nil {
+		log.Printf("[task-move] task %d: roll back move to shard %d: %v", m.TaskID, m.ToShard, err)
+		if cause != nil {
+			_ = setTaskMovePhase(ctx, src, &m, taskMoveCopying, cause.Error())
+		}
+	}
+	return err
+}
+
+// RecoverTaskMoves finishes or rolls back the moves that have not changed for a minute,
+// i.e. whose replica died or gave up: moves still copying are rolled back, moves that
+// were switching are completed. It returns how many moves it completed and rolled back.
+func (sm *ShardManager) RecoverTaskMoves(ctx context.Context) (completed, rolledBack int, err error) {
+	for _, idx := range sm.ShardIndexes() {
+		src := sm.GetShardByIndex(idx)
+		if src == nil {
+			continue
+		}
+		var moves []taskMove
+		if err := src.WithContext(ctx).Where("updated_at < ?", time.Now().Add(-taskMoveGrace)).Order("task_id").Find(&moves).Error; err != nil {
+			return completed, rolledBack, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		for _, m := range moves {
+			if m.Phase == taskMoveSwitching {
+				if err := sm.completeTaskMove(ctx, src, m); err != nil {
+					log.Printf("[task-move] task %d: complete move to shard %d: %v", m.TaskID, m.ToShard, err)
+					continue
+				}
+				completed++
+				continue
+			}
+			dst := sm.GetShardByIndex(m.ToShard)
+			if dst == nil {
+				log.Printf("[task-move] task %d: target shard %d is gone", m.TaskID, m.ToShard)
+				continue
+			}
+			// rollbackTaskMove logs its failure; the intent stays for the next run
+			if err := sm.rollbackTaskMove(ctx, src, dst, m, nil); err != nil {
+				continue
+			}
+			rolledBack++
+		}
+	}
+	if completed+rolledBack > 0 {
+		log.Printf("[task-move] recovered moves: %d completed, %d rolled back", completed, rolledBack)
+	}
+	return completed, rolledBack, nil
+}
+
+// TaskMoveRecoveryJob runs RecoverTaskMoves every interval on one replica; register it
+// with a leader.Registry.
+func TaskMoveRecoveryJob(interval time.Duration) leader.Job {
+	return leader.Job{
+		Name:     TaskMoveLease,
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, _, err := ShardMgr.RecoverTaskMoves(ctx)
+			return err
+		},
+	}
+}
@@ -0,0 +1,310 @@
+package shard_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/persistence"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	redisOnce   sync.Once
+	redisServer *miniredis.Miniredis
+)
+
+// startRedis points the cache at an in-memory Redis shared by the package's tests and
+// empties it.
+func startRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	redisOnce.Do(func() {
+		mr, err := miniredis.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		redisServer = mr
+		t.Setenv("REDIS_URL", "redis://"+mr.Addr())
+		cache.InitRedisFromEnv()
+	})
+	if redisServer == nil {
+		t.Fatal("redis not started")
+	}
+	redisServer.FlushAll()
+	return redisServer
+}
+
+// moveFixture is a pair of shards on SQLite with a durable directory and an in-memory Redis.
+type moveFixture struct {
+	sm       *shard.ShardManager
+	src, dst *gorm.DB
+	dir      *gorm.DB
+}
+
+// sqliteDriver is SQLite with a to_regclass that reports every table as present, so the
+// schema checks, which ask Postgres whether schema_migrations exists, can run.
+const sqliteDriver = "sqlite3_regclass"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+		return conn.RegisterFunc("to_regclass", func(name string) string { return name }, true)
+	}})
+}
+
+func openSQLite(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), name+".db")
+	db, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: sqliteDriver, DSN: dsn}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newMoveFixture(t *testing.T) *moveFixture {
+	t.Helper()
+	startRedis(t)
+
+	f := &moveFixture{src: openSQLite(t, "a"), dst: openSQLite(t, "b"), dir: openSQLite(t, "directory")}
+	for _, db := range []*gorm.DB{f.src, f.dst} {
+		if err := db.AutoMigrate(&persistence.Task{}, &persistence.Observer{}, &persistence.Reminder{}); err != nil {
+			t.Fatal(err)
+		}
+		// as in migration 0002
+		err := db.Exec(`CREATE TABLE task_moves (
+			task_id integer PRIMARY KEY, performer_id integer NOT NULL,
+			from_shard integer NOT NULL, to_shard integer NOT NULL,
+			phase text NOT NULL, error text NOT NULL DEFAULT '',
+			created_at datetime, updated_at datetime)`).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.dir.AutoMigrate(&persistence.TaskLocation{}); err != nil {
+		t.Fatal(err)
+	}
+	previous := directory.Default
+	directory.Default = directory.New(f.dir, time.Hour)
+	t.Cleanup(func() { directory.Default = previous })
+
+	shards := []shard.ShardInfo{{Index: 0, ID: "a", State: shard.ShardActive}, {Index: 1, ID: "b", State: shard.ShardActive}}
+	f.sm = shard.NewShardManagerFromTopology(shard.Topology{Shards: shards}, []*gorm.DB{f.src, f.dst})
+	return f
+}
+
+// seed stores task 1 of performer 7 with an observer and a sent reminder on db.
+func (f *moveFixture) seed(t *testing.T, db *gorm.DB) persistence.Task {
+	t.Helper()
+	task := persistence.Task{ID: 1, Title: "move me", PerformerId: 7, CreatorId: 3, Status: "pending",
+		Observers: []persistence.Observer{{UserId: 11}}}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	reminder := persistence.Reminder{TaskId: 1, Kind: "due", DueAt: time.Now(), SentAt: time.Now()}
+	if err := db.Create(&reminder).Error; err != nil {
+		t.Fatal(err)
+	}
+	return task
+}
+
+func count(t *testing.T, db *gorm.DB, table string) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Table(table).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// ageMoves makes the recorded moves look abandoned to recovery.
+func ageMoves(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.Table("task_moves").Where("1 = 1").Update("updated_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *moveFixture) wantMoved(t *testing.T) {
+	t.Helper()
+	var moved persistence.Task
+	if err := f.dst.Preload("Observers").Take(&moved, 1).Error; err != nil {
+		t.Fatalf("task not on the target: %v", err)
+	}
+	if moved.PerformerId != 8 || len(moved.Observers) != 1 {
+		t.Fatalf("target copy: performer %d, %d observers; want 8 and 1", moved.PerformerId, len(moved.Observers))
+	}
+	if n := count(t, f.dst, "task_reminders"); n != 1 {
+		t.Fatalf("%d reminders on the target, want 1", n)
+	}
+	for _, table := range []string{"tasks", "observers", "task_reminders", "task_moves"} {
+		if n := count(t, f.src, table); n != 0 {
+			t.Fatalf("%d rows left in %s on the source", n, table)
+		}
+	}
+	if idx, err := directory.Default.Lookup(context.Background(), 1); err != nil || idx != 1 {
+		t.Fatalf("directory: shard %d, %v; want 1", idx, err)
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	f := newMoveFixture(t)
+	task := f.seed(t, f.src)
+
+	task.PerformerId = 8
+	if err := f.sm.MoveTask(context.Background(), &task, 7, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	f.wantMoved(t)
+}
+
+func TestMoveTaskRollsBackFailedCopy(t *testing.T) {
+	f := newMoveFixture(t)
+	task := f.seed(t, f.src)
+	err := f.dst.Callback().Create().Before("gorm:create").Register("test:fail_tasks", func(tx *gorm.DB) {
+		if tx.Statement.Table == "tasks" {
+			_ = tx.AddError(errors.New("disk full"))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task.PerformerId = 8
+	err = f.sm.MoveTask(context.Background(), &task, 7, 0, 1)
+	if err == nil || errors.Is(err, shard.ErrTaskMovePending) {
+		t.Fatalf("MoveTask: err = %v, want the copy error", err)
+	}
+	for _, table := range []string{"tasks", "observers", "task_reminders"} {
+		if n := count(t, f.dst, table); n != 0 {
+			t.Fatalf("%d rows left in %s on the target", n, table)
+		}
+	}
+	if n := count(t, f.src, "task_moves"); n != 0 {
+		t.Fatal("intent left after the rollback")
+	}
+	var kept persistence.Task
+	if err := f.src.Take(&kept, 1).Error; err != nil || kept.PerformerId != 7 {
+		t.Fatalf("source task: performer %d, %v; want it unchanged", kept.PerformerId, err)
+	}
+}
+
+func TestMoveTaskPendingUntilRecovered(t *testing.T) {
+	f := newMoveFixture(t)
+	task := f.seed(t, f.src)
+	// the directory cannot be switched
+	if err := f.dir.Migrator().DropTable(&persistence.TaskLocation{}); err != nil {
+		t.Fatal(err)
+	}
+
+	task.PerformerId = 8
+	err := f.sm.MoveTask(context.Background(), &task, 7, 0, 1)
+	if !errors.Is(err, shard.ErrTaskMovePending) {
+		t.Fatalf("MoveTask: err = %v, want ErrTaskMovePending", err)
+	}
+	var phase string
+	if err := f.src.Table("task_moves").Where("task_id = ?", 1).Pluck("phase", &phase).Error; err != nil || phase != "switching" {
+		t.Fatalf("intent phase %q, %v; want switching", phase, err)
+	}
+
+	// not yet abandoned
+	if completed, rolledBack, err := f.sm.RecoverTaskMoves(context.Background()); err != nil || completed+rolledBack != 0 {
+		t.Fatalf("early recovery: %d completed, %d rolled back, %v", completed, rolledBack, err)
+	}
+	if err := f.dir.AutoMigrate(&persistence.TaskLocation{}); err != nil {
+		t.Fatal(err)
+	}
+	ageMoves(t, f.src)
+	completed, rolledBack, err := f.sm.RecoverTaskMoves(context.Background())
+	if err != nil || completed != 1 || rolledBack != 0 {
+		t.Fatalf("recovery: %d completed, %d rolled back, %v; want 1 completed", completed, rolledBack, err)
+	}
+	f.wantMoved(t)
+}
+
+func TestRecoverTaskMovesRollsBackCopying(t *testing.T) {
+	f := newMoveFixture(t)
+	f.seed(t, f.src)
+	// a replica crashed after copying the task, before the switch
+	copied := persistence.Task{ID: 1, Title: "move me", PerformerId: 8, CreatorId: 3, Status: "pending"}
+	if err := f.dst.Create(&copied).Error; err != nil {
+		t.Fatal(err)
+	}
+	err := f.src.Exec("INSERT INTO task_moves (task_id, performer_id, from_shard, to_shard, phase) VALUES (1, 7, 0, 1, 'copying')").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	ageMoves(t, f.src)
+
+	completed, rolledBack, err := f.sm.RecoverTaskMoves(context.Background())
+	if err != nil || completed != 0 || rolledBack != 1 {
+		t.Fatalf("recovery: %d completed, %d rolled back, %v; want 1 rolled back", completed, rolledBack, err)
+	}
+	if n := count(t, f.dst, "tasks"); n != 0 {
+		t.Fatal("copy left on the target")
+	}
+	if n := count(t, f.src, "task_moves"); n != 0 {
+		t.Fatal("intent left after the rollback")
+	}
+	if n := count(t, f.src, "tasks"); n != 1 {
+		t.Fatal("source task lost")
+	}
+}
+
+func TestRecoverTaskMovesKeepsReassignedSource(t *testing.T) {
+	f := newMoveFixture(t)
+	f.seed(t, f.src)
+	// reassigned on the source by a replica that had not seen the switch
+	if err := f.src.Model(&persistence.Task{}).Where("id = ?", 1).Update("performer_id", 9).Error; err != nil {
+		t.Fatal(err)
+	}
+	err := f.src.Exec("INSERT INTO task_moves (task_id, performer_id, from_shard, to_shard, phase) VALUES (1, 7, 0, 1, 'switching')").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	ageMoves(t, f.src)
+
+	completed, _, err := f.sm.RecoverTaskMoves(context.Background())
+	if err != nil || completed != 1 {
+		t.Fatalf("recovery: %d completed, %v; want 1", completed, err)
+	}
+	for _, table := range []string{"tasks", "observers", "task_reminders"} {
+		if n := count(t, f.src, table); n != 1 {
+			t.Fatalf("%d rows in %s on the source, want the reassigned task's 1", n, table)
+		}
+	}
+}
+
+func TestRecoverTaskMovesCountsFailedRollback(t *testing.T) {
+	f := newMoveFixture(t)
+	f.seed(t, f.src)
+	err := f.src.Exec("INSERT INTO task_moves (task_id, performer_id, from_shard, to_shard, phase) VALUES (1, 7, 0, 1, 'copying')").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	ageMoves(t, f.src)
+	err = f.dst.Callback().Delete().Before("gorm:delete").Register("test:fail_delete", func(tx *gorm.DB) {
+		_ = tx.AddError(errors.New("disk full"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	completed, rolledBack, err := f.sm.RecoverTaskMoves(context.Background())
+	if err != nil || completed+rolledBack != 0 {
+		t.Fatalf("recovery: %d completed, %d rolled back, %v; want none", completed, rolledBack, err)
+	}
+	if n := count(t, f.src, "task_moves"); n != 1 {
+		t.Fatal("intent dropped after a failed rollback")
+	}
+}
@@ -0,0 +1,499 @@
+package shard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"tasks/internal/infrastructure/cache"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxTopologyRetries bounds the compare-and-set loop when replicas change the topology concurrently.
+const maxTopologyRetries = 5
+
+var (
+	// ErrTopologyMismatch means the shared topology does not extend this replica's shard list,
+	// e.g. DB_SHARD_URLS differs between replicas.
+	ErrTopologyMismatch = errors.New("shard topology does not match local shards")
+	// ErrTopologyBusy means the topology kept changing while it was being updated.
+	ErrTopologyBusy = errors.New("shard topology changed concurrently, retry")
+	// ErrShardNotFound means no slot has the requested shard ID.
+	ErrShardNotFound = errors.New("shard not found")
+	// ErrShardIDTaken means a new shard was given an ID that is already in use.
+	ErrShardIDTaken = errors.New("shard id already in use")
+	// ErrInvalidShardState means the shard is not in a state that allows the transition.
+	ErrInvalidShardState = errors.New("invalid shard state for this operation")
+	// ErrLastActiveShard means draining the shard would leave no shard to write to.
+	ErrLastActiveShard = errors.New("cannot drain the last active shard")
+	// ErrInvalidShardWeight means a shard weight is out of range.
+	ErrInvalidShardWeight = errors.New("invalid shard weight")
+)
+
+// Topology is the shard list shared by all replicas through Redis.
+// Slots are only ever appended; removing a shard turns its slot into a tombstone.
+type Topology struct {
+	Version int64       `json:"version"`
+	Shards  []ShardInfo `json:"shards"`
+	// Overrides place hot performers' tasks off the ring; see PerformerOverride.
+	Overrides []PerformerOverride `json:"overrides,omitempty"`
+	// ShardKey is the task field placing tasks on the ring; empty means the performer.
+	ShardKey ShardKey `json:"shard_key,omitempty"`
+	// Tenants dedicate shards to large tenants; see TenantPlacement.
+	Tenants []TenantPlacement `json:"tenants,omitempty"`
+	// DSNs is the pre-shard-ID format; it is read for compatibility and never written.
+	DSNs []string `json:"dsns,omitempty"`
+}
+
+// Topology returns the shard list this replica currently routes with.
+func (sm *ShardManager) Topology() Topology {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	infos := make([]ShardInfo, len(sm.infos))
+	copy(infos, sm.infos)
+	return Topology{
+		Version:   sm.topologyVersion,
+		Shards:    infos,
+		Overrides: sm.overrideList(),
+		ShardKey:  sm.shardKey,
+		Tenants:   sm.tenantList(),
+	}
+}
+
+// SyncTopology loads the shared topology and applies shards other replicas added,
+// drained or removed. The first replica to start seeds the record from its DB_SHARD_URLS.
+func (sm *ShardManager) SyncTopology(ctx context.Context) error {
+	topo, err := loadTopology(ctx)
+	if err != nil {
+		return err
+	}
+	if topo.Version == 0 {
+		seed := sm.Topology()
+		seed.Version = 1
+		ok, err := storeTopology(ctx, 0, seed)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// another replica seeded first
+			return sm.SyncTopology(ctx)
+		}
+		topo = seed
+	}
+	return sm.applyTopology(topo, nil)
+}
+
+// AddShard connects to spec.DSN, migrates its schema, appends it to the shared topology
+// and rebuilds the local ring. The topology holds the DSNs without passwords; other
+// replicas need the credentials through PGPASSWORD or ~/.pgpass. Other replicas pick the change up via WatchTopology.
+// spec.ID is the stable shard ID; when empty the new slot index is used. Weight 0 means 1.
+// spec.Replicas are read replicas of the new shard; Index and State are ignored.
+// Adding a DSN that is already part of the topology is a no-op returning its slot.
+func (sm *ShardManager) AddShard(ctx context.Context, spec ShardInfo) (ShardInfo, Topology, error) {
+	id, dsn, replicas := spec.ID, rememberDSN(spec.DSN), rememberDSNs(spec.Replicas)
+	if id != "" && !shardIDPattern.MatchString(id) {
+		return ShardInfo{}, Topology{}, fmt.Errorf("invalid shard id %q", id)
+	}
+	if spec.Weight != 0 {
+		if err := ValidateShardWeight(spec.Weight); err != nil {
+			return ShardInfo{}, Topology{}, err
+		}
+	}
+	// a shard this replica already routes to is not connected to or migrated again
+	current := sm.Topology()
+	if s, ok := findShardDSN(current.Shards, dsn); ok {
+		return s, current, nil
+	}
+	db, err := openShard(id, dsn)
+	if err != nil {
+		return ShardInfo{}, Topology{}, fmt.Errorf("connect to shard: %w", err)
+	}
+	// the new shard must be on the same schema version as the others
+	if err := MigrateShard(ctx, db, sm.SchemaVersion()); err != nil {
+		closeShard(db)
+		return ShardInfo{}, Topology{}, fmt.Errorf("migrate shard: %w", err)
+	}
+
+	var added ShardInfo
+	topo, err := sm.updateTopology(ctx, map[string]*gorm.DB{dsn: db}, func(t *Topology) error {
+		if s, ok := findShardDSN(t.Shards, dsn); ok {
+			added = s
+			return errNoChange
+		}
+		added = ShardInfo{Index: len(t.Shards), ID: id, DSN: dsn, State: ShardActive, Weight: spec.Weight, Replicas: replicas}
+		if added.ID == "" {
+			added.ID = strconv.Itoa(added.Index)
+		}
+		for _, s := range t.Shards {
+			if s.ID == added.ID {
+				return fmt.Errorf("%w: %s", ErrShardIDTaken, added.ID)
+			}
+		}
+		t.Shards = append(t.Shards, added)
+		return nil
+	})
+	// db is kept only if the shard list took it: not when the update failed, or when
+	// another replica added the DSN meanwhile and this one already had it, or a
+	// concurrent apply connected first
+	if err != nil || sm.GetShardByIndex(added.Index) != db {
+		closeShard(db)
+	}
+	if err != nil {
+		return ShardInfo{}, Topology{}, err
+	}
+	log.Printf("[topology] added shard %d (%s), version %d", added.Index, added.ID, topo.Version)
+	return added, topo, nil
+}
+
+// DrainShard takes the shard off the ring so it gets no new writes. It stays readable
+// until a rebalance pass has moved everything away and then removes it.
+func (sm *ShardManager) DrainShard(ctx context.Context, id string) (ShardInfo, Topology, error) {
+	var drained ShardInfo
+	topo, err := sm.updateTopology(ctx, nil, func(t *Topology) error {
+		i := findShard(t.Shards, id)
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrShardNotFound, id)
+		}
+		switch t.Shards[i].State {
+		case ShardDraining:
+			drained = t.Shards[i]
+			return errNoChange
+		case ShardRemoved:
+			return fmt.Errorf("%w: shard %s is removed", ErrInvalidShardState, id)
+		}
+		dedicated := dedicatedShards(t.Tenants)
+		if _, ok := dedicated[id]; !ok && len(ringMembers(t.Shards, dedicated)) <= 1 {
+			return ErrLastActiveShard
+		}
+		t.Shards[i].State = ShardDraining
+		drained = t.Shards[i]
+		return nil
+	})
+	if err != nil {
+		return ShardInfo{}, Topology{}, err
+	}
+	log.Printf("[topology] draining shard %d (%s), version %d", drained.Index, drained.ID, topo.Version)
+	return drained, topo, nil
+}
+
+// SetShardWeight changes the weight of a shard. The ring of every replica changes with
+// it, so performers whose ring position changed must be moved by a rebalance.
+func (sm *ShardManager) SetShardWeight(ctx context.Context, id string, weight float64) (ShardInfo, Topology, error) {
+	if err := ValidateShardWeight(weight); err != nil {
+		return ShardInfo{}, Topology{}, err
+	}
+	var changed ShardInfo
+	topo, err := sm.updateTopology(ctx, nil, func(t *Topology) error {
+		i := findShard(t.Shards, id)
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrShardNotFound, id)
+		}
+		if t.Shards[i].State == ShardRemoved {
+			return fmt.Errorf("%w: shard %s is removed", ErrInvalidShardState, id)
+		}
+		if t.Shards[i].EffectiveWeight() == weight {
+			changed = t.Shards[i]
+			return errNoChange
+		}
+		t.Shards[i].Weight = weight
+		changed = t.Shards[i]
+		return nil
+	})
+	if err != nil {
+		return ShardInfo{}, Topology{}, err
+	}
+	log.Printf("[topology] shard %d (%s) weight %g, version %d", changed.Index, changed.ID, weight, topo.Version)
+	return changed, topo, nil
+}
+
+// removeShard turns a drained shard into a tombstone and closes its connection.
+func (sm *ShardManager) removeShard(ctx context.Context, id string) error {
+	_, err := sm.updateTopology(ctx, nil, func(t *Topology) error {
+		i := findShard(t.Shards, id)
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrShardNotFound, id)
+		}
+		if t.Shards[i].State != ShardDraining {
+			return fmt.Errorf("%w: shard %s is %s", ErrInvalidShardState, id, t.Shards[i].State)
+		}
+		t.Shards[i].State = ShardRemoved
+		t.Shards[i].DSN = ""
+		return nil
+	})
+	if err == nil {
+		log.Printf("[topology] removed shard %s", id)
+	}
+	return err
+}
+
+// WatchTopology re-syncs the topology whenever another replica announces a change,
+// and every pollInterval in case an announcement was missed.
+func (sm *ShardManager) WatchTopology(ctx context.Context, pollInterval time.Duration) {
+	changed := cache.SubscribeShardTopology(ctx)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-changed:
+			if !ok {
+				changed = nil
+				continue
+			}
+		case <-ticker.C:
+		}
+		if err := sm.SyncTopology(ctx); err != nil {
+			log.Printf("[topology] sync: %v", err)
+		}
+	}
+}
+
+// errNoChange lets an updateTopology mutation report that the topology already has the desired shape.
+var errNoChange = errors.New("no change")
+
+// updateTopology applies mutate to the latest shared topology with compare-and-set,
+// retrying when another replica wins the race, and then applies the result locally.
+func (sm *ShardManager) updateTopology(ctx context.Context, opened map[string]*gorm.DB, mutate func(*Topology) error) (Topology, error) {
+	for attempt := 0; attempt < maxTopologyRetries; attempt++ {
+		current, err := loadTopology(ctx)
+		if err != nil {
+			return Topology{}, err
+		}
+		if current.Version == 0 {
+			current = sm.Topology()
+		}
+
+		next := Topology{
+			Version:   current.Version + 1,
+			Shards:    append([]ShardInfo{}, current.Shards...),
+			Overrides: append([]PerformerOverride{}, current.Overrides...),
+			ShardKey:  current.ShardKey,
+			Tenants:   append([]TenantPlacement{}, current.Tenants...),
+		}
+		if err := mutate(&next); err != nil {
+			if errors.Is(err, errNoChange) {
+				return current, sm.applyTopology(current, opened)
+			}
+			return Topology{}, err
+		}
+
+		ok, err := storeTopology(ctx, current.Version, next)
+		if err != nil {
+			return Topology{}, err
+		}
+		if ok {
+			return next, sm.applyTopology(next, opened)
+		}
+	}
+	return Topology{}, ErrTopologyBusy
+}
+
+// applyTopology connects to new shards, closes removed ones and swaps the shard list and
+// ring under one lock, so routing never sees a ring that points at a missing shard.
+// opened holds connections the caller already made.
+func (sm *ShardManager) applyTopology(topo Topology, opened map[string]*gorm.DB) error {
+	local := sm.Topology()
+	if topo.Version <= local.Version {
+		return nil
+	}
+	if local.Version == 0 {
+		return sm.adoptTopology(topo, opened)
+	}
+	if len(topo.Shards) < len(local.Shards) {
+		return ErrTopologyMismatch
+	}
+	for i := range local.Shards {
+		if local.Shards[i].ID != topo.Shards[i].ID {
+			return fmt.Errorf("%w: shard %d is %q here and %q in the topology", ErrTopologyMismatch, i, local.Shards[i].ID, topo.Shards[i].ID)
+		}
+	}
+
+	added := make([]*gorm.DB, 0, len(topo.Shards)-len(local.Shards))
+	addedReplicas := make([][]*replica, 0, len(topo.Shards)-len(local.Shards))
+	for _, info := range topo.Shards[len(local.Shards):] {
+		if info.State == ShardRemoved {
+			added = append(added, nil)
+			addedReplicas = append(addedReplicas, nil)
+			continue
+		}
+		addedReplicas = append(addedReplicas, openReplicas(info, nil))
+		db := opened[info.DSN]
+		if db == nil {
+			var err error
+			if db, err = openShard(info.ID, info.DSN); err != nil {
+				return fmt.Errorf("connect to shard %s: %w", info.ID, err)
+			}
+		}
+		added = append(added, db)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	if sm.topologyVersion >= topo.Version || len(sm.shards) != len(local.Shards) {
+		// a concurrent apply got here first
+		for i, rs := range addedReplicas {
+			closeReplicas(rs)
+			// connections the caller opened are the caller's to close
+			if db := added[i]; db != nil && opened[topo.Shards[len(local.Shards)+i].DSN] != db {
+				closeShard(db)
+			}
+		}
+		return nil
+	}
+	sm.shards = append(sm.shards, added...)
+	sm.replicas = append(sm.replicas, addedReplicas...)
+	for i, info := range topo.Shards {
+		if info.State == ShardRemoved && sm.shards[i] != nil {
+			closeShard(sm.shards[i])
+			sm.shards[i] = nil
+			closeReplicas(sm.replicas[i])
+			sm.replicas[i] = nil
+		}
+	}
+	sm.infos = append([]ShardInfo{}, topo.Shards...)
+	sm.overrides = overrideMap(topo.Overrides)
+	sm.shardKey = topo.ShardKey.orDefault()
+	sm.tenants = tenantMap(topo.Tenants)
+	sm.topologyVersion = topo.Version
+	sm.rebuildRingLocked()
+	return nil
+}
+
+// adoptTopology replaces the shard list built from DB_SHARD_URLS with the shared one on
+// the first sync. Once a topology exists it is authoritative, so DB_SHARD_URLS only needs
+// to be correct for the very first replica (and may keep listing since-removed shards).
+func (sm *ShardManager) adoptTopology(topo Topology, opened map[string]*gorm.DB) error {
+	sm.mu.RLock()
+	byDSN := make(map[string]*gorm.DB, len(sm.infos))
+	localReplicas := make(map[string][]string, len(sm.infos))
+	reuse := make(map[string]*replica)
+	for i, info := range sm.infos {
+		byDSN[info.DSN] = sm.shards[i]
+		localReplicas[info.DSN] = info.Replicas
+		for _, r := range sm.replicas[i] {
+			reuse[r.dsn] = r
+		}
+	}
+	sm.mu.RUnlock()
+	for dsn, db := range opened {
+		if byDSN[dsn] == nil {
+			byDSN[dsn] = db
+		}
+	}
+
+	shards := make([]*gorm.DB, len(topo.Shards))
+	replicas := make([][]*replica, len(topo.Shards))
+	for i, info := range topo.Shards {
+		if info.State == ShardRemoved {
+			continue
+		}
+		if len(info.Replicas) == 0 {
+			// replicas declared in DB_SHARD_URLS serve shards the topology lists none for
+			info.Replicas = localReplicas[info.DSN]
+			topo.Shards[i].Replicas = info.Replicas
+		}
+		replicas[i] = openReplicas(info, reuse)
+		if db, ok := byDSN[info.DSN]; ok && db != nil {
+			shards[i] = db
+			delete(byDSN, info.DSN)
+			continue
+		}
+		db, err := openShard(info.ID, info.DSN)
+		if err != nil {
+			return fmt.Errorf("connect to shard %s: %w", info.ID, err)
+		}
+		shards[i] = db
+	}
+	for dsn, db := range byDSN {
+		if db != nil {
+			log.Printf("[topology] DB_SHARD_URLS entry is not in the shared topology, ignoring it: %s", redactDSN(dsn))
+			closeShard(db)
+		}
+	}
+	for _, r := range reuse {
+		closeShard(r.db)
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	sm.shards = shards
+	sm.replicas = replicas
+	sm.infos = append([]ShardInfo{}, topo.Shards...)
+	sm.overrides = overrideMap(topo.Overrides)
+	sm.shardKey = topo.ShardKey.orDefault()
+	sm.tenants = tenantMap(topo.Tenants)
+	sm.topologyVersion = topo.Version
+	sm.rebuildRingLocked()
+	return nil
+}
+
+// redactDSN hides the password of a URL-style DSN for logging.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return "<dsn>"
+}
+
+func closeShard(db *gorm.DB) {
+	if sqlDB, err := db.DB(); err == nil {
+		_ = sqlDB.Close()
+	}
+}
+
+// findShardDSN returns the slot that is not removed and connects to dsn.
+func findShardDSN(shards []ShardInfo, dsn string) (ShardInfo, bool) {
+	for _, s := range shards {
+		if s.DSN == dsn && s.State != ShardRemoved {
+			return s, true
+		}
+	}
+	return ShardInfo{}, false
+}
+
+func findShard(shards []ShardInfo, id string) int {
+	for i, s := range shards {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func loadTopology(ctx context.Context) (Topology, error) {
+	version, data, err := cache.GetShardTopology(ctx)
+	if err != nil || version == 0 {
+		return Topology{}, err
+	}
+	var topo Topology
+	if err := json.Unmarshal(data, &topo); err != nil {
+		return Topology{}, fmt.Errorf("decode shard topology: %w", err)
+	}
+	if len(topo.Shards) == 0 {
+		for i, dsn := range topo.DSNs {
+			topo.Shards = append(topo.Shards, ShardInfo{Index: i, ID: strconv.Itoa(i), DSN: dsn, State: ShardActive})
+		}
+	}
+	// records written before passwords were stripped still carry them
+	topo.Shards = withoutPasswords(topo.Shards)
+	topo.DSNs = nil
+	topo.Version = version
+	return topo, nil
+}
+
+// storeTopology writes topo if the shared record is still at version prev. DSNs are
+// written without passwords.
+func storeTopology(ctx context.Context, prev int64, topo Topology) (bool, error) {
+	topo.Shards = withoutPasswords(topo.Shards)
+	data, err := json.Marshal(topo)
+	if err != nil {
+		return false, err
+	}
+	return cache.CompareAndSetShardTopology(ctx, prev, topo.Version, data)
+}
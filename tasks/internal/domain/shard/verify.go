@@ -0,0 +1,520 @@
+package shard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/leader"
+	"tasks/internal/infrastructure/persistence"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxVerifyFindings caps each category of a VerifyReport; the counts are always complete.
+const maxVerifyFindings = 1000
+
+// ErrRebalanceRunning means repairs were refused because a rebalance is running or was
+// interrupted; its in-flight copies would look like duplicates.
+var ErrRebalanceRunning = errors.New("a rebalance is running or has unfinished moves; let it finish before repairing")
+
+// VerifyCounts counts findings (or repairs) per category.
+type VerifyCounts struct {
+	Duplicates      int
+	Misplaced       int
+	StaleMappings   int
+	MissingMappings int
+	OrphanObservers int
+}
+
+// DuplicateTask is a task ID with rows on several shards. Kept is the copy that is authoritative.
+type DuplicateTask struct {
+	TaskID uint
+	Shards []int
+	Kept   int
+}
+
+// MisplacedTask lives on a shard other than the one ResolveTask picks for it (the ring's
+// shard for its performer, or its performer's override).
+// Expected is -1 for unassigned tasks, which may live on any active shard.
+type MisplacedTask struct {
+	TaskID      uint
+	PerformerID uint
+	Shard       int
+	Expected    int
+}
+
+// MappingIssue is a task->shard directory entry that is missing (Mapped -1) or points
+// at a shard that does not hold the task (Actual -1 if no shard does).
+type MappingIssue struct {
+	TaskID uint
+	Mapped int
+	Actual int
+}
+
+// OrphanObserver is an observer row whose task is not on the same shard.
+type OrphanObserver struct {
+	Shard      int
+	ObserverID uint
+	TaskID     uint
+}
+
+// VerifyReport is the result of Verify. The lists hold at most maxVerifyFindings entries each.
+type VerifyReport struct {
+	ShardsScanned int
+	TasksScanned  int
+	// TasksMoving counts tasks with a single-task move in progress (a task_moves row on
+	// some shard). Their copies are expected to differ, so they are neither checked nor
+	// repaired; Moving lists them.
+	TasksMoving     int
+	Moving          []uint
+	Found           VerifyCounts
+	Repaired        VerifyCounts
+	Duplicates      []DuplicateTask
+	Misplaced       []MisplacedTask
+	StaleMappings   []MappingIssue
+	MissingMappings []MappingIssue
+	OrphanObservers []OrphanObserver
+	// RebalancePending is set when a rebalance was running or interrupted during the scan,
+	// so some duplicates and misplaced tasks may be moves in progress.
+	RebalancePending bool
+	// RebalanceStarted is set when repair started a rebalance to move misplaced tasks.
+	RebalanceStarted bool
+}
+
+type taskLocation struct {
+	shard     int
+	key       TaskKey
+	updatedAt time.Time
+}
+
+// verifyWindow bounds how many tasks of each shard Verify holds in memory at once.
+const verifyWindow = 5000
+
+// Verify scans every shard and the task->shard directory and reports what has
+// drifted apart. With repair it also fixes every category:
+//   - duplicates: the newest copy is kept. If it is off its placed shard but another copy
+//     is on it, that copy is refreshed from the newest and kept instead. The others are deleted.
+//   - misplaced tasks: a rebalance is started, which moves them to the ring shard
+//   - stale and missing mappings: set to the kept copy's shard, or deleted if no shard has the task
+//   - orphan observers: deleted
+//
+// Tasks that a single-task move (MoveTask) is copying or switching are skipped: the move
+// or its recovery settles them, and a repair meanwhile could delete the copy a rollback
+// keeps.
+//
+// Tasks are scanned in windows of task IDs holding at most verifyWindow tasks per shard,
+// then the directory in batches, so memory does not grow with the number of tasks.
+// Repair holds the rebalance lease, so it never runs alongside a rebalance.
+func (sm *ShardManager) Verify(ctx context.Context, repair bool) (report VerifyReport, err error) {
+	pending, err := RebalancePending(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.RebalancePending = pending
+
+	if repair {
+		if pending {
+			return report, ErrRebalanceRunning
+		}
+		leaseCtx, release, err := holdRebalanceLease(ctx)
+		if err != nil {
+			return report, err
+		}
+		parent := ctx
+		ctx = leaseCtx
+		defer func() {
+			release()
+			if err == nil && report.Found.Misplaced > 0 {
+				// the rebalance takes the lease itself, so it must not inherit this term
+				report.RebalanceStarted = StartRebalance(context.WithoutCancel(parent))
+			}
+		}()
+	}
+
+	shards := make(map[int]*gorm.DB)
+	for _, idx := range sm.ShardIndexes() {
+		if db := sm.GetShardByIndex(idx); db != nil {
+			shards[idx] = db
+		}
+	}
+	for lo := uint(0); ; {
+		hi, err := verifyWindowEnd(ctx, shards, lo)
+		if err != nil {
+			return report, err
+		}
+		locations := make(map[uint][]taskLocation)
+		for idx, db := range shards {
+			if err := scanTaskLocations(ctx, db, idx, lo, hi, locations); err != nil {
+				return report, fmt.Errorf("shard %d: %w", idx, err)
+			}
+		}
+		if err := sm.checkTaskWindow(ctx, shards, locations, repair, &report); err != nil {
+			return report, err
+		}
+		if hi == 0 {
+			break
+		}
+		lo = hi
+	}
+
+	// entries of tasks that are on a shard were checked with the tasks; what is left
+	// maps tasks that no shard holds
+	var batch []uint
+	mapped := make(map[uint]int)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		held, err := heldTasks(ctx, shards, batch)
+		if err != nil {
+			return err
+		}
+		var stale []uint
+		for _, id := range batch {
+			if held[id] {
+				continue
+			}
+			report.Found.StaleMappings++
+			if len(report.StaleMappings) < maxVerifyFindings {
+				report.StaleMappings = append(report.StaleMappings, MappingIssue{TaskID: id, Mapped: mapped[id], Actual: -1})
+			}
+			stale = append(stale, id)
+		}
+		batch, mapped = batch[:0], make(map[uint]int)
+		if !repair || len(stale) == 0 {
+			return nil
+		}
+		if err := directory.Default.Remove(ctx, stale...); err != nil {
+			return fmt.Errorf("remove mappings: %w", err)
+		}
+		for _, id := range stale {
+			_ = cache.DeleteTaskCache(ctx, id)
+		}
+		report.Repaired.StaleMappings += len(stale)
+		return nil
+	}
+	err = directory.Default.Each(ctx, func(taskID uint, shardIndex int) error {
+		batch = append(batch, taskID)
+		mapped[taskID] = shardIndex
+		if len(batch) < verifyWindow {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return report, fmt.Errorf("scan mappings: %w", err)
+	}
+
+	orphans := make(map[int][]uint)
+	for _, idx := range sm.ShardIndexes() {
+		db := shards[idx]
+		if db == nil {
+			continue
+		}
+		found, err := orphanObservers(ctx, db)
+		if err != nil {
+			return report, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		for _, o := range found {
+			report.Found.OrphanObservers++
+			if len(report.OrphanObservers) < maxVerifyFindings {
+				report.OrphanObservers = append(report.OrphanObservers, OrphanObserver{Shard: idx, ObserverID: o.ID, TaskID: o.TaskId})
+			}
+			orphans[idx] = append(orphans[idx], o.ID)
+		}
+		report.ShardsScanned++
+	}
+
+	if !repair {
+		return report, nil
+	}
+	for idx, observerIDs := range orphans {
+		res := shards[idx].WithContext(ctx).Unscoped().Where("id IN ?", observerIDs).Delete(&persistence.Observer{})
+		if res.Error != nil {
+			return report, fmt.Errorf("delete orphan observers on shard %d: %w", idx, res.Error)
+		}
+		report.Repaired.OrphanObservers += int(res.RowsAffected)
+	}
+	if report.Found.Misplaced > 0 {
+		// moved by the rebalance started once the lease is released
+		log.Printf("[verify] %d misplaced tasks, starting a rebalance", report.Found.Misplaced)
+	}
+	return report, nil
+}
+
+// checkTaskWindow checks the tasks of one window against each other and the directory, and
+// repairs them with repair set.
+func (sm *ShardManager) checkTaskWindow(ctx context.Context, shards map[int]*gorm.DB, locations map[uint][]taskLocation, repair bool, report *VerifyReport) error {
+	report.TasksScanned += len(locations)
+	ids := make([]uint, 0, len(locations))
+	for id := range locations {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	moving, err := movingTasks(ctx, shards, ids)
+	if err != nil {
+		return err
+	}
+	if len(moving) > 0 {
+		ids = slices.DeleteFunc(ids, func(id uint) bool {
+			if !moving[id] {
+				return false
+			}
+			report.TasksMoving++
+			if len(report.Moving) < maxVerifyFindings {
+				report.Moving = append(report.Moving, id)
+			}
+			return true
+		})
+	}
+	mappings, err := directory.Default.Entries(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("read mappings: %w", err)
+	}
+
+	// newest copies to copy onto the kept shard, and extra copies to delete by shard
+	// and performer
+	type copyPair struct{ from, to int }
+	refresh := make(map[copyPair][]uint)
+	type copyKey struct {
+		shard       int
+		performerID uint
+	}
+	extra := make(map[copyKey][]uint)
+	type mappingFix struct {
+		shard   int
+		missing bool
+	}
+	fixes := make(map[uint]mappingFix)
+
+	for _, id := range ids {
+		locs := locations[id]
+		kept, newest := sm.keptCopy(locs)
+		if len(locs) > 1 {
+			report.Found.Duplicates++
+			d := DuplicateTask{TaskID: id, Kept: kept.shard}
+			for _, l := range locs {
+				d.Shards = append(d.Shards, l.shard)
+				if l.shard != kept.shard {
+					k := copyKey{l.shard, l.key.PerformerID}
+					extra[k] = append(extra[k], id)
+				}
+			}
+			if newest != kept.shard {
+				p := copyPair{newest, kept.shard}
+				refresh[p] = append(refresh[p], id)
+			}
+			if len(report.Duplicates) < maxVerifyFindings {
+				report.Duplicates = append(report.Duplicates, d)
+			}
+		}
+
+		if expected, ok := sm.placement(kept); !ok {
+			report.Found.Misplaced++
+			if len(report.Misplaced) < maxVerifyFindings {
+				report.Misplaced = append(report.Misplaced, MisplacedTask{TaskID: id, PerformerID: kept.key.PerformerID, Shard: kept.shard, Expected: expected})
+			}
+		}
+
+		mapped, ok := mappings[id]
+		switch {
+		case !ok:
+			report.Found.MissingMappings++
+			if len(report.MissingMappings) < maxVerifyFindings {
+				report.MissingMappings = append(report.MissingMappings, MappingIssue{TaskID: id, Mapped: -1, Actual: kept.shard})
+			}
+			fixes[id] = mappingFix{shard: kept.shard, missing: true}
+		case mapped != kept.shard:
+			report.Found.StaleMappings++
+			if len(report.StaleMappings) < maxVerifyFindings {
+				report.StaleMappings = append(report.StaleMappings, MappingIssue{TaskID: id, Mapped: mapped, Actual: kept.shard})
+			}
+			fixes[id] = mappingFix{shard: kept.shard}
+		}
+	}
+	if !repair {
+		return nil
+	}
+
+	// the newest rows are copied before any copy is deleted
+	for p, ids := range refresh {
+		if err := verifyTasks(ctx, shards[p.from], shards[p.to], ids); err != nil {
+			return fmt.Errorf("copy newest duplicates from shard %d to %d: %w", p.from, p.to, err)
+		}
+	}
+	for k, ids := range extra {
+		if err := deleteTasks(ctx, shards[k.shard], "performer_id", k.performerID, ids); err != nil {
+			return fmt.Errorf("delete duplicates on shard %d: %w", k.shard, err)
+		}
+		report.Repaired.Duplicates += len(ids)
+		for _, id := range ids {
+			_ = cache.DeleteTaskCache(ctx, id)
+		}
+	}
+	for id, fix := range fixes {
+		if err := directory.Default.Assign(ctx, fix.shard, id); err != nil {
+			return fmt.Errorf("fix mapping of task %d: %w", id, err)
+		}
+		_ = cache.DeleteTaskCache(ctx, id)
+		if fix.missing {
+			report.Repaired.MissingMappings++
+		} else {
+			report.Repaired.StaleMappings++
+		}
+	}
+	return nil
+}
+
+// keptCopy picks the authoritative copy of a task, the most recently updated one (a copy
+// on its placed shard wins a tie), and returns it with the shard of that newest copy.
+// When the newest copy is off its placed shard but another copy is on it, that copy is
+// kept instead, with the newest copy's key and time: repair refreshes it from the newest
+// before deleting the others.
+func (sm *ShardManager) keptCopy(locs []taskLocation) (taskLocation, int) {
+	newest := locs[0]
+	_, newestPlaced := sm.placement(newest)
+	for _, l := range locs[1:] {
+		_, placed := sm.placement(l)
+		if l.updatedAt.After(newest.updatedAt) || (l.updatedAt.Equal(newest.updatedAt) && placed && !newestPlaced) {
+			newest, newestPlaced = l, placed
+		}
+	}
+	expected, placed := sm.placement(newest)
+	if placed || expected < 0 {
+		return newest, newest.shard
+	}
+	for _, l := range locs {
+		if l.shard == expected {
+			kept := newest
+			kept.shard = expected
+			return kept, newest.shard
+		}
+	}
+	return newest, newest.shard
+}
+
+// placement returns the shard the copy belongs on and whether the copy is on it.
+func (sm *ShardManager) placement(l taskLocation) (int, bool) {
+	expected, placed := sm.placeTask(l.key)
+	if !placed {
+		// tasks without a key are spread round-robin over the ring shards
+		return -1, sm.onRing(l.shard)
+	}
+	return expected, expected == l.shard
+}
+
+// verifyWindowEnd returns the end of the window of task IDs after lo: the lowest
+// verifyWindow-th ID after lo over the shards, or 0 when every shard has fewer left.
+func verifyWindowEnd(ctx context.Context, shards map[int]*gorm.DB, lo uint) (uint, error) {
+	var hi uint
+	for idx, db := range shards {
+		var ids []uint
+		err := db.WithContext(ctx).Unscoped().Model(&persistence.Task{}).
+			Where("id > ?", lo).Order("id").Offset(verifyWindow-1).Limit(1).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return 0, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		if len(ids) > 0 && (hi == 0 || ids[0] < hi) {
+			hi = ids[0]
+		}
+	}
+	return hi, nil
+}
+
+// scanTaskLocations adds the tasks with lo < id <= hi (no upper bound for a zero hi).
+func scanTaskLocations(ctx context.Context, db *gorm.DB, idx int, lo, hi uint, locations map[uint][]taskLocation) error {
+	var batch []persistence.Task
+	q := db.WithContext(ctx).Unscoped().
+		Select("id", "performer_id", "project_id", "creator_id", "tenant_id", "updated_at").
+		Where("id > ?", lo)
+	if hi != 0 {
+		q = q.Where("id <= ?", hi)
+	}
+	return q.FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+		for _, t := range batch {
+			key := TaskKey{PerformerID: t.PerformerId, ProjectID: t.ProjectId, CreatorID: t.CreatorId, TenantID: t.TenantId}
+			locations[t.ID] = append(locations[t.ID], taskLocation{shard: idx, key: key, updatedAt: t.UpdatedAt})
+		}
+		return nil
+	}).Error
+}
+
+// movingTasks reports which of ids have a single-task move recorded on some shard.
+func movingTasks(ctx context.Context, shards map[int]*gorm.DB, ids []uint) (map[uint]bool, error) {
+	moving := make(map[uint]bool)
+	for idx, db := range shards {
+		var found []uint
+		if err := db.WithContext(ctx).Model(&taskMove{}).Where("task_id IN ?", ids).Pluck("task_id", &found).Error; err != nil {
+			return nil, fmt.Errorf("shard %d: read task moves: %w", idx, err)
+		}
+		for _, id := range found {
+			moving[id] = true
+		}
+	}
+	return moving, nil
+}
+
+// heldTasks reports which of ids have a row on some shard, soft-deleted rows included.
+func heldTasks(ctx context.Context, shards map[int]*gorm.DB, ids []uint) (map[uint]bool, error) {
+	held := make(map[uint]bool, len(ids))
+	for idx, db := range shards {
+		var found []uint
+		if err := db.WithContext(ctx).Unscoped().Model(&persistence.Task{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+			return nil, fmt.Errorf("shard %d: %w", idx, err)
+		}
+		for _, id := range found {
+			held[id] = true
+		}
+	}
+	return held, nil
+}
+
+func orphanObservers(ctx context.Context, db *gorm.DB) ([]persistence.Observer, error) {
+	var observers []persistence.Observer
+	err := db.WithContext(ctx).Unscoped().
+		Table("observers AS o").
+		Select("o.id, o.task_id").
+		Joins("LEFT JOIN tasks AS t ON t.id = o.task_id").
+		Where("t.id IS NULL").
+		Scan(&observers).Error
+	return observers, err
+}
+
+// holdRebalanceLease takes the rebalance lease for maintenance that must not overlap a rebalance.
+func holdRebalanceLease(ctx context.Context) (context.Context, func(), error) {
+	leaseCtx, _, release, err := leader.NewElector(RebalanceLease, rebalanceLeaseTTL).Acquire(ctx)
+	if errors.Is(err, leader.ErrNotLeader) {
+		return nil, nil, ErrRebalanceRunning
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return leaseCtx, release, nil
+}
+
+// RebalancePending reports whether a rebalance holds its lease or left checkpoints behind,
+// i.e. whether some tasks may not be on the shard placement says.
+func RebalancePending(ctx context.Context) (bool, error) {
+	token, err := cache.LeaseToken(ctx, RebalanceLease)
+	if err != nil {
+		return false, err
+	}
+	if token != 0 {
+		return true, nil
+	}
+	checkpoints, err := cache.ListRebalanceCheckpoints(ctx)
+	if err != nil {
+		return false, err
+	}
+	return len(checkpoints) > 0, nil
+}
@@ -0,0 +1,141 @@
+package shard_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/persistence"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// performerOn returns a performer whose tasks the ring places on shard idx.
+func performerOn(t *testing.T, sm *shard.ShardManager, idx int) uint {
+	t.Helper()
+	for p := uint(1); p < 1000; p++ {
+		if sm.ResolveTask(shard.TaskKey{PerformerID: p}) == idx {
+			return p
+		}
+	}
+	t.Fatalf("no performer placed on shard %d", idx)
+	return 0
+}
+
+func createTask(t *testing.T, db *gorm.DB, task persistence.Task) {
+	t.Helper()
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// seedDuplicate stores task 1 of a performer placed on the source: an old copy there,
+// and a newer one on the target, which the directory points at.
+func seedDuplicate(t *testing.T, f *moveFixture) uint {
+	t.Helper()
+	performer := performerOn(t, f.sm, 0)
+	now := time.Now().Truncate(time.Second)
+	createTask(t, f.src, persistence.Task{ID: 1, Title: "old", PerformerId: performer, Status: "pending", UpdatedAt: now.Add(-time.Hour)})
+	createTask(t, f.dst, persistence.Task{ID: 1, Title: "new", PerformerId: performer, Status: "pending", UpdatedAt: now})
+	if err := directory.Default.Assign(context.Background(), 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	return performer
+}
+
+func TestVerifyRepairsDuplicate(t *testing.T) {
+	f := newMoveFixture(t)
+	seedDuplicate(t, f)
+
+	report, err := f.sm.Verify(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := shard.VerifyCounts{Duplicates: 1, StaleMappings: 1}
+	if report.Found != want || report.Repaired != (shard.VerifyCounts{}) {
+		t.Fatalf("found %+v, repaired %+v; want %+v found and nothing repaired", report.Found, report.Repaired, want)
+	}
+	if len(report.Duplicates) != 1 || report.Duplicates[0].Kept != 0 {
+		t.Fatalf("duplicates %+v, want task 1 kept on shard 0", report.Duplicates)
+	}
+
+	report, err = f.sm.Verify(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Repaired != want {
+		t.Fatalf("repaired %+v, want %+v", report.Repaired, want)
+	}
+	// the newest copy was refreshed onto the placed shard before the other was deleted
+	var kept persistence.Task
+	if err := f.src.Take(&kept, 1).Error; err != nil || kept.Title != "new" {
+		t.Fatalf("kept copy %q, %v; want the newest", kept.Title, err)
+	}
+	if n := count(t, f.dst, "tasks"); n != 0 {
+		t.Fatal("duplicate left on the target")
+	}
+	if idx, err := directory.Default.Lookup(context.Background(), 1); err != nil || idx != 0 {
+		t.Fatalf("directory: shard %d, %v; want 0", idx, err)
+	}
+
+	report, err = f.sm.Verify(context.Background(), false)
+	if err != nil || report.Found != (shard.VerifyCounts{}) {
+		t.Fatalf("after repair: found %+v, %v", report.Found, err)
+	}
+}
+
+func TestVerifySkipsMovingTasks(t *testing.T) {
+	f := newMoveFixture(t)
+	performer := seedDuplicate(t, f)
+	err := f.src.Exec("INSERT INTO task_moves (task_id, performer_id, from_shard, to_shard, phase) VALUES (1, ?, 0, 1, 'copying')", performer).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := f.sm.Verify(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Found != (shard.VerifyCounts{}) || report.TasksMoving != 1 || !reflect.DeepEqual(report.Moving, []uint{1}) {
+		t.Fatalf("found %+v, moving %d %v; want only task 1 moving", report.Found, report.TasksMoving, report.Moving)
+	}
+	// a rollback of the move keeps the source copy, so repair must not delete it
+	for _, db := range []*gorm.DB{f.src, f.dst} {
+		if n := count(t, db, "tasks"); n != 1 {
+			t.Fatal("repair touched a moving task")
+		}
+	}
+}
+
+func TestVerifyRepairsMappingsAndOrphans(t *testing.T) {
+	f := newMoveFixture(t)
+	createTask(t, f.dst, persistence.Task{ID: 2, Title: "unmapped", PerformerId: performerOn(t, f.sm, 1), Status: "pending"})
+	// no shard holds task 99
+	if err := directory.Default.Assign(context.Background(), 1, 99); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.dst.Create(&persistence.Observer{UserId: 4, TaskId: 50}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := f.sm.Verify(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := shard.VerifyCounts{StaleMappings: 1, MissingMappings: 1, OrphanObservers: 1}
+	if report.Found != want || report.Repaired != want {
+		t.Fatalf("found %+v, repaired %+v; want %+v", report.Found, report.Repaired, want)
+	}
+	if idx, err := directory.Default.Lookup(context.Background(), 2); err != nil || idx != 1 {
+		t.Fatalf("task 2: shard %d, %v; want 1", idx, err)
+	}
+	if _, err := directory.Default.Lookup(context.Background(), 99); !errors.Is(err, directory.ErrNotFound) {
+		t.Fatalf("task 99: %v, want ErrNotFound", err)
+	}
+	if n := count(t, f.dst, "observers"); n != 0 {
+		t.Fatal("orphan observer left")
+	}
+}
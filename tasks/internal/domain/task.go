@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"slices"
+	"strings"
 	"tasks/internal/infrastructure/persistence"
 	"time"
 
@@ -13,13 +15,30 @@ type Task struct {
 	Description string
 	PerformerId uint
 	CreatorId   uint
+	ProjectId   uint
+	TenantId    uint
 	Observers   []persistence.Observer
+	Mentions    []uint
+	Labels      []string
 	Status      string
+	DueAt       *time.Time
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   gorm.DeletedAt
 }
 
+// NormalizeLabels trims labels and drops empty and repeated ones, keeping their order.
+func NormalizeLabels(labels []string) []string {
+	var out []string
+	for _, l := range labels {
+		l = strings.TrimSpace(l)
+		if l != "" && !slices.Contains(out, l) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
 // NewTask constructs a domain Task value from parameters.
 func NewTask(id uint, title, description string, creatorID, performerID uint) Task {
 	return Task{
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ChangeType describes what happened to a task.
+type ChangeType int
+
+const (
+	ChangeCreated ChangeType = iota + 1
+	ChangeUpdated
+	ChangeDeleted
+)
+
+// TaskChange is a single task mutation as seen by watchers.
+// Seq is assigned by the hub and increases monotonically within one process.
+type TaskChange struct {
+	Seq  uint64
+	Type ChangeType
+	Task Task
+	At   time.Time
+}
+
+var (
+	// ErrSequenceTooOld means the requested resume point is no longer buffered; the client must resync.
+	ErrSequenceTooOld = errors.New("watch sequence is no longer available")
+	// ErrWatchLagged means the watcher did not keep up and was disconnected.
+	ErrWatchLagged = errors.New("watcher fell too far behind")
+)
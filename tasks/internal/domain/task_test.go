@@ -0,0 +1,17 @@
+package domain_test
+
+import (
+	"reflect"
+	"tasks/internal/domain"
+	"testing"
+)
+
+func TestNormalizeLabels(t *testing.T) {
+	got := domain.NormalizeLabels([]string{" bug", "ui", "", "bug ", "  ", "UI"})
+	if want := []string{"bug", "ui", "UI"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("NormalizeLabels = %q, want %q", got, want)
+	}
+	if got := domain.NormalizeLabels(nil); got != nil {
+		t.Fatalf("NormalizeLabels(nil) = %q, want nil", got)
+	}
+}
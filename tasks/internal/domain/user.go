@@ -0,0 +1,8 @@
+package domain
+
+// User is the subset of an auth service account the tasks service needs.
+type User struct {
+	ID    uint
+	Email string
+	Name  string
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Visibility of a saved view.
+const (
+	ViewPrivate = "private"
+	ViewProject = "project"
+)
+
+var (
+	ErrViewNotFound  = errors.New("view not found")
+	ErrViewForbidden = errors.New("view belongs to another user")
+	ErrInvalidView   = errors.New("invalid view")
+)
+
+// ViewFilter is the stored part of a GetTasks query. DueAfter is inclusive and DueBefore
+// exclusive; ExcludeDone leaves out tasks with status "done".
+type ViewFilter struct {
+	Title       string     `json:"title,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	PerformerID uint       `json:"performer_id,omitempty"`
+	CreatorID   uint       `json:"creator_id,omitempty"`
+	ProjectID   uint       `json:"project_id,omitempty"`
+	DueAfter    *time.Time `json:"due_after,omitempty"`
+	DueBefore   *time.Time `json:"due_before,omitempty"`
+	ExcludeDone bool       `json:"exclude_done,omitempty"`
+}
+
+// View is a named, saved task query. Private views are visible to their owner only;
+// project views also to the users who can see ProjectID (see ports.ProjectAccess).
+type View struct {
+	ID         string
+	Name       string
+	OwnerID    uint
+	Filter     ViewFilter
+	SortBy     string
+	SortDesc   bool
+	Columns    []string
+	Visibility string
+	ProjectID  uint
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// VisibleTo reports whether userID may read the view: it is userID's own, or a project
+// view and inProject says userID can see the view's project.
+func (v View) VisibleTo(userID uint, inProject bool) bool {
+	return v.OwnerID == userID || (v.Visibility == ViewProject && inProject)
+}
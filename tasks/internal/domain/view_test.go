@@ -0,0 +1,33 @@
+package domain_test
+
+import (
+	"tasks/internal/domain"
+	"testing"
+)
+
+func TestViewVisibleTo(t *testing.T) {
+	private := domain.View{OwnerID: 1, Visibility: domain.ViewPrivate, ProjectID: 5}
+	shared := domain.View{OwnerID: 1, Visibility: domain.ViewProject, ProjectID: 5}
+
+	tests := []struct {
+		name      string
+		view      domain.View
+		userID    uint
+		inProject bool
+		want      bool
+	}{
+		{name: "own private view", view: private, userID: 1, want: true},
+		{name: "other's private view", view: private, userID: 2, want: false},
+		{name: "other's private view in the project", view: private, userID: 2, inProject: true, want: false},
+		{name: "own project view", view: shared, userID: 1, want: true},
+		{name: "project view outside the project", view: shared, userID: 2, want: false},
+		{name: "project view in the project", view: shared, userID: 2, inProject: true, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.view.VisibleTo(tt.userID, tt.inProject); got != tt.want {
+				t.Fatalf("VisibleTo(%d, %t) = %t, want %t", tt.userID, tt.inProject, got, tt.want)
+			}
+		})
+	}
+}
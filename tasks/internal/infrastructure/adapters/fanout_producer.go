@@ -0,0 +1,60 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+)
+
+// FanoutProducer forwards every event to all wrapped producers (e.g. Kafka and the watch hub).
+// All producers are called even if one fails; the errors are joined.
+type FanoutProducer struct {
+	producers []ports.EventProducer
+}
+
+func NewFanoutProducer(producers ...ports.EventProducer) *FanoutProducer {
+	return &FanoutProducer{producers: producers}
+}
+
+func (f *FanoutProducer) PublishCreated(ctx context.Context, task domain.Task) error {
+	var errs []error
+	for _, p := range f.producers {
+		errs = append(errs, p.PublishCreated(ctx, task))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *FanoutProducer) PublishUpdated(ctx context.Context, task domain.Task) error {
+	var errs []error
+	for _, p := range f.producers {
+		errs = append(errs, p.PublishUpdated(ctx, task))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *FanoutProducer) PublishDeleted(ctx context.Context, task domain.Task) error {
+	var errs []error
+	for _, p := range f.producers {
+		errs = append(errs, p.PublishDeleted(ctx, task))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *FanoutProducer) PublishMentioned(ctx context.Context, task domain.Task, userID uint) error {
+	var errs []error
+	for _, p := range f.producers {
+		errs = append(errs, p.PublishMentioned(ctx, task, userID))
+	}
+	return errors.Join(errs...)
+}
+
+func (f *FanoutProducer) PublishReminder(ctx context.Context, task domain.Task, reminder domain.Reminder) error {
+	var errs []error
+	for _, p := range f.producers {
+		errs = append(errs, p.PublishReminder(ctx, task, reminder))
+	}
+	return errors.Join(errs...)
+}
+
+var _ ports.EventProducer = (*FanoutProducer)(nil)
@@ -0,0 +1,71 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/config"
+	"tasks/internal/ports"
+	"time"
+)
+
+// NewIDAllocator builds the allocator selected by cfg. It refuses to start one whose IDs
+// could collide with IDs already issued by the Redis counter or by shard ranges.
+// close releases what the allocator holds and must be called on shutdown.
+func NewIDAllocator(ctx context.Context, sm *shard.ShardManager, cfg config.IDAllocator) (ports.IDAllocator, func(), error) {
+	switch cfg.Kind {
+	case config.IDAllocatorRange:
+		last, err := cache.LastTaskID(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read task id counter: %w", err)
+		}
+		if uint64(last) > cfg.RangeBase {
+			return nil, nil, fmt.Errorf("ID_RANGE_BASE %d is below the last counter id %d", cfg.RangeBase, last)
+		}
+		return NewRangeIDAllocator(sm, cfg.RangeBase, cfg.RangeSize), func() {}, nil
+	case config.IDAllocatorSnowflake:
+		floor := uint64(shard.FirstSnowflakeID(time.Now()))
+		last, err := cache.LastTaskID(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("read task id counter: %w", err)
+		}
+		if uint64(last) >= floor {
+			return nil, nil, fmt.Errorf("task id counter %d overlaps snowflake ids", last)
+		}
+		if err := checkRangesBelow(ctx, sm, floor); err != nil {
+			return nil, nil, err
+		}
+		a, err := NewSnowflakeIDAllocator(ctx, cfg.WorkerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return a, a.Close, nil
+	default:
+		return NewRedisIDAllocator(), func() {}, nil
+	}
+}
+
+// checkRangesBelow fails if a shard's id range reaches floor.
+func checkRangesBelow(ctx context.Context, sm *shard.ShardManager, floor uint64) error {
+	for _, idx := range sm.ShardIndexes() {
+		db := sm.GetShardByIndex(idx)
+		if db == nil {
+			continue
+		}
+		var ranges []shard.IdAllocator
+		if err := db.WithContext(ctx).Find(&ranges).Error; err != nil {
+			return fmt.Errorf("shard %d: read id range: %w", idx, err)
+		}
+		for _, r := range ranges {
+			limit := r.LimitID
+			if limit == 0 {
+				limit = r.NextID
+			}
+			if uint64(limit) >= floor {
+				return fmt.Errorf("shard %d: id range overlaps snowflake ids", idx)
+			}
+		}
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package adapters
 
 import (
+	"context"
 	"log"
 	"tasks/internal/domain/shard"
 	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
 	"tasks/internal/infrastructure/kafke"
 )
 
@@ -18,15 +20,27 @@ func InitializeInfrastructure() *shard.ShardManager {
 	// Initialize cache (redis)
 	cache.InitRedisFromEnv()
 
+	// durable task->shard directory; Redis caches it
+	directory.InitFromEnv()
+
 	// Initialize kafka producer
 	kafke.InitProducer()
 
-	// run migrations/sync for shards if available
-	shard.SyncDatabaseForShards()
-
 	if shard.ShardMgr == nil {
 		log.Fatalf("shard manager not initialized")
 	}
+
+	// pick up shards added, drained or removed at runtime by other replicas
+	if err := shard.ShardMgr.SyncTopology(context.Background()); err != nil {
+		log.Fatalf("sync shard topology: %v", err)
+	}
+
+	// run migrations/sync for shards if available
+	shard.SyncDatabaseForShards()
+
+	// finish performer moves left behind by a replica that died mid-rebalance
+	shard.ResumeInterrupted(context.Background())
+
 	return shard.ShardMgr
 }
 
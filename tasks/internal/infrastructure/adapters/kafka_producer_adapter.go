@@ -79,8 +79,12 @@ func (a *KafkaProducerAdapter) PublishUpdated(ctx context.Context, task domain.T
 		"description":   task.Description,
 		"performer_id":  task.PerformerId,
 		"creator_id":    task.CreatorId,
+		"project_id":    task.ProjectId,
+		"tenant_id":     task.TenantId,
 		"observers_ids": observerIDs(task.Observers),
+		"labels":        task.Labels,
 		"status":        task.Status,
+		"due_at":        task.DueAt,
 		"created_at":    task.CreatedAt,
 		"updated_at":    task.UpdatedAt,
 	}
@@ -103,6 +107,70 @@ func (a *KafkaProducerAdapter) PublishUpdated(ctx context.Context, task domain.T
 	return nil
 }
 
+func (a *KafkaProducerAdapter) PublishMentioned(ctx context.Context, task domain.Task, userID uint) error {
+	message := map[string]interface{}{
+		"event":             "UserMentioned",
+		"task_id":           task.ID,
+		"title":             task.Title,
+		"description":       task.Description,
+		"performer_id":      task.PerformerId,
+		"creator_id":        task.CreatorId,
+		"project_id":        task.ProjectId,
+		"tenant_id":         task.TenantId,
+		"mentioned_user_id": userID,
+		"status":            task.Status,
+		"created_at":        task.CreatedAt,
+		"updated_at":        task.UpdatedAt,
+	}
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	go func(data []byte) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic while sending kafka message: %v", r)
+			}
+		}()
+		if err := kafke.SendMessageToKafka(data); err != nil {
+			log.Printf("kafka send error: %v", err)
+		}
+	}(b)
+
+	return nil
+}
+
+// PublishReminder sends TaskDueSoon or TaskOverdue. Unlike the other events it is sent
+// synchronously so the scheduler can release its claim if Kafka rejects the message.
+func (a *KafkaProducerAdapter) PublishReminder(ctx context.Context, task domain.Task, reminder domain.Reminder) error {
+	event := "TaskDueSoon"
+	if reminder.Kind == domain.ReminderOverdue {
+		event = "TaskOverdue"
+	}
+	message := map[string]interface{}{
+		"event":          event,
+		"task_id":        task.ID,
+		"title":          task.Title,
+		"description":    task.Description,
+		"performer_id":   task.PerformerId,
+		"creator_id":     task.CreatorId,
+		"project_id":     task.ProjectId,
+		"tenant_id":      task.TenantId,
+		"observers_ids":  observerIDs(task.Observers),
+		"status":         task.Status,
+		"due_at":         reminder.DueAt,
+		"offset_seconds": int64(reminder.Offset.Seconds()),
+		"created_at":     task.CreatedAt,
+		"updated_at":     task.UpdatedAt,
+	}
+	b, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return kafke.SendMessageToKafka(b)
+}
+
 func observerIDs(observers []persistence.Observer) []uint {
 	if len(observers) == 0 {
 		return nil
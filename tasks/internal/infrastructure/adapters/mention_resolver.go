@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/domain"
+	"tasks/internal/infrastructure/auth"
+	"tasks/internal/ports"
+)
+
+// MentionResolver resolves @handles against user names in the auth service.
+type MentionResolver struct {
+	users ports.UserDirectory
+}
+
+func NewMentionResolver(users ports.UserDirectory) *MentionResolver {
+	return &MentionResolver{users: users}
+}
+
+func (r *MentionResolver) Resolve(ctx context.Context, text string) ([]uint, error) {
+	handles := domain.ParseMentions(text)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint, 0, len(handles))
+	for _, handle := range handles {
+		user, err := r.users.FindByName(ctx, handle)
+		if errors.Is(err, auth.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, user.ID)
+	}
+	return ids, nil
+}
+
+var _ ports.MentionResolver = (*MentionResolver)(nil)
@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/persistence"
+	"tasks/internal/ports"
+)
+
+// PostgresProjectAccess answers ports.ProjectAccess from the tasks themselves. A user's
+// performed tasks are on their shards, but created and observed tasks can be anywhere,
+// so every shard is asked until one has a match.
+type PostgresProjectAccess struct {
+	ShardManager *shard.ShardManager
+}
+
+func NewPostgresProjectAccess(sm *shard.ShardManager) *PostgresProjectAccess {
+	return &PostgresProjectAccess{ShardManager: sm}
+}
+
+// CanSeeProject reports whether userID performs, created or observes a task of projectID.
+// A shard that fails only makes it an error if no other shard has a match.
+func (a *PostgresProjectAccess) CanSeeProject(ctx context.Context, userID, projectID uint) (bool, error) {
+	var failed error
+	for _, idx := range a.ShardManager.ShardIndexes() {
+		db := a.ShardManager.Reader(ctx, idx)
+		if db == nil {
+			continue
+		}
+		var n int64
+		err := a.ShardManager.Call(ctx, idx, func(ctx context.Context) error {
+			observed := db.WithContext(ctx).Model(&persistence.Observer{}).Select("task_id").Where("user_id = ?", userID)
+			return db.WithContext(ctx).Model(&persistence.Task{}).
+				Where("project_id = ?", projectID).
+				Where(db.Where("performer_id = ?", userID).Or("creator_id = ?", userID).Or("id IN (?)", observed)).
+				Count(&n).Error
+		})
+		if err != nil {
+			failed = err
+			continue
+		}
+		if n > 0 {
+			return true, nil
+		}
+	}
+	return false, failed
+}
+
+var _ ports.ProjectAccess = (*PostgresProjectAccess)(nil)
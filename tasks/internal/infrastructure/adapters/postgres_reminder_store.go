@@ -0,0 +1,82 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/domain"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/persistence"
+	"tasks/internal/ports"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostgresReminderStore struct {
+	ShardManager *shard.ShardManager
+}
+
+func NewPostgresReminderStore(sm *shard.ShardManager) *PostgresReminderStore {
+	return &PostgresReminderStore{ShardManager: sm}
+}
+
+func (s *PostgresReminderStore) DueTasks(ctx context.Context, shardIndex int, from, to time.Time) ([]domain.Task, error) {
+	db, err := s.shard(shardIndex)
+	if err != nil {
+		return nil, err
+	}
+	var rows []persistence.Task
+	err = db.WithContext(ctx).Preload("Observers").
+		Where("due_at BETWEEN ? AND ?", from, to).
+		Order("due_at").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]domain.Task, len(rows))
+	for i := range rows {
+		tasks[i] = *persistenceToDomainTask(rows[i])
+	}
+	return tasks, nil
+}
+
+func (s *PostgresReminderStore) Claim(ctx context.Context, shardIndex int, r domain.Reminder) (bool, error) {
+	db, err := s.shard(shardIndex)
+	if err != nil {
+		return false, err
+	}
+	row := persistence.Reminder{
+		TaskId:        r.TaskID,
+		Kind:          string(r.Kind),
+		OffsetSeconds: int64(r.Offset / time.Second),
+		DueAt:         r.DueAt,
+		SentAt:        time.Now(),
+	}
+	res := db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (s *PostgresReminderStore) Release(ctx context.Context, shardIndex int, r domain.Reminder) error {
+	db, err := s.shard(shardIndex)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).
+		Where("task_id = ? AND kind = ? AND offset_seconds = ? AND due_at = ?",
+			r.TaskID, string(r.Kind), int64(r.Offset/time.Second), r.DueAt).
+		Delete(&persistence.Reminder{}).Error
+}
+
+func (s *PostgresReminderStore) shard(index int) (*gorm.DB, error) {
+	db := s.ShardManager.GetShardByIndex(index)
+	if db == nil {
+		return nil, errors.New("shard not found")
+	}
+	return db, nil
+}
+
+var _ ports.ReminderStore = (*PostgresReminderStore)(nil)
@@ -3,14 +3,17 @@ package adapters
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"tasks/internal/domain"
 	"tasks/internal/domain/shard"
 	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
 	"tasks/internal/infrastructure/persistence"
 	"tasks/internal/ports"
 	"tasks/logger"
 
-	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 	glogger "gorm.io/gorm/logger"
 )
@@ -35,61 +38,195 @@ func (r *PostgresRepository) Save(ctx context.Context, t domain.Task, shardIndex
 		Description: t.Description,
 		PerformerId: t.PerformerId,
 		CreatorId:   t.CreatorId,
+		ProjectId:   t.ProjectId,
+		TenantId:    t.TenantId,
 		Status:      t.Status,
+		Observers:   observersFromUintIDs(observerIDs(t.Observers)),
+		MentionIds:  t.Mentions,
+		Labels:      t.Labels,
+		DueAt:       t.DueAt,
 	}
-	return db.Create(&p).Error
+	err := r.ShardManager.Call(ctx, shardIndex, func(ctx context.Context) error {
+		return db.WithContext(ctx).Create(&p).Error
+	})
+	if err != nil {
+		return err
+	}
+	r.ShardManager.MarkWrite(ctx)
+	r.ShardManager.RecordRequest(shardIndex, p.PerformerId)
+	if err := directory.Default.Assign(ctx, shardIndex, p.ID); err != nil {
+		// the task is written; lookups fall back to probing the shards
+		logger.Warn(ctx, "directory assign failed", logger.ZapUint("task_id", p.ID), logger.ZapError(err))
+	}
+	return nil
 }
 
 // Find queries tasks in the specified shard index using the provided filter.
-// If shardIndex is negative, returns an error (caller should iterate shards itself).
+// If shardIndex is negative, the filter must have a performer: Find then queries every
+// shard the performer's tasks are placed on, which is several for a split performer.
 func (r *PostgresRepository) Find(ctx context.Context, filter ports.TaskFilter, shardIndex int) ([]domain.Task, error) {
 	if shardIndex < 0 {
-		return nil, errors.New("shard index required")
+		if filter.PerformerID == 0 {
+			return nil, errors.New("shard index or performer required")
+		}
+		return r.findPerformerTasks(ctx, filter)
 	}
-	db := r.ShardManager.GetShardByIndex(shardIndex)
+	if filter.PerformerID != 0 {
+		r.ShardManager.RecordRequest(shardIndex, filter.PerformerID)
+	}
+	db := r.ShardManager.Reader(ctx, shardIndex)
 	if db == nil {
 		return nil, errors.New("shard not found")
 	}
 
 	var models []persistence.Task
-	query := db
+	err := r.ShardManager.Call(ctx, shardIndex, func(ctx context.Context) error {
+		return applyTaskFilter(db.WithContext(ctx), filter).Find(&models).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]domain.Task, 0, len(models))
+	for _, m := range models {
+		result = append(result, *persistenceToDomainTask(m))
+	}
+
+	return result, nil
+}
+
+func applyTaskFilter(query *gorm.DB, filter ports.TaskFilter) *gorm.DB {
 	if filter.Title != "" {
 		query = query.Where("title = ?", filter.Title)
 	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
 	if filter.CreatorID != 0 {
 		query = query.Where("creator_id = ?", filter.CreatorID)
 	}
 	if filter.PerformerID != 0 {
 		query = query.Where("performer_id = ?", filter.PerformerID)
 	}
+	if filter.ProjectID != 0 {
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	if filter.TenantID != 0 {
+		query = query.Where("tenant_id = ?", filter.TenantID)
+	}
+	if filter.DueAfter != nil {
+		query = query.Where("due_at >= ?", *filter.DueAfter)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_at < ?", *filter.DueBefore)
+	}
+	if filter.ExcludeDone {
+		query = query.Where("status <> ?", "done")
+	}
+	return query
+}
+
+// statsColumns is the SQL of each of ports.StatsDimensions, as text.
+var statsColumns = map[string]string{
+	"status":       "status",
+	"performer":    "performer_id::text",
+	"creator":      "creator_id::text",
+	"label":        "COALESCE(task_labels.label, '')",
+	"project":      "project_id::text",
+	"tenant":       "tenant_id::text",
+	"created_week": "to_char(date_trunc('week', created_at), 'YYYY-MM-DD')",
+}
+
+// taskLabelsJoin gives each task one row per label, and one with a NULL label if it has none.
+const taskLabelsJoin = "LEFT JOIN LATERAL jsonb_array_elements_text(COALESCE(tasks.labels, '[]')) AS task_labels(label) ON true"
 
-	if err := query.Find(&models).Error; err != nil {
+// CountTasks counts the shard's tasks matching the filter with a GROUP BY query, so only
+// one row per group leaves the database. Soft-deleted tasks are not counted.
+func (r *PostgresRepository) CountTasks(ctx context.Context, filter ports.TaskFilter, groupBy []string, shardIndex int) ([]ports.TaskCount, error) {
+	db := r.ShardManager.Reader(ctx, shardIndex)
+	if db == nil {
+		return nil, errors.New("shard not found")
+	}
+	selects := make([]string, 0, len(groupBy)+1)
+	groups := make([]string, 0, len(groupBy))
+	for i, dim := range groupBy {
+		col, ok := statsColumns[dim]
+		if !ok {
+			return nil, fmt.Errorf("unknown stats dimension %q", dim)
+		}
+		selects = append(selects, fmt.Sprintf("%s AS k%d", col, i))
+		groups = append(groups, fmt.Sprintf("k%d", i))
+	}
+	selects = append(selects, "count(*) AS n")
+
+	var counts []ports.TaskCount
+	err := r.ShardManager.Call(ctx, shardIndex, func(ctx context.Context) error {
+		query := applyTaskFilter(db.WithContext(ctx).Model(&persistence.Task{}), filter).
+			Select(strings.Join(selects, ", "))
+		if slices.Contains(groupBy, "label") {
+			query = query.Joins(taskLabelsJoin)
+		}
+		if len(groups) > 0 {
+			query = query.Group(strings.Join(groups, ", "))
+		}
+		rows, err := query.Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		counts = counts[:0]
+		for rows.Next() {
+			c := ports.TaskCount{Keys: make([]string, len(groupBy))}
+			dest := make([]any, 0, len(groupBy)+1)
+			for i := range c.Keys {
+				dest = append(dest, &c.Keys[i])
+			}
+			if err := rows.Scan(append(dest, &c.Count)...); err != nil {
+				return err
+			}
+			counts = append(counts, c)
+		}
+		return rows.Err()
+	})
+	if err != nil {
 		return nil, err
 	}
+	return counts, nil
+}
 
-	result := make([]domain.Task, 0, len(models))
-	for _, m := range models {
-		result = append(result, domain.Task{
-			ID:          m.ID,
-			Title:       m.Title,
-			Description: m.Description,
-			PerformerId: m.PerformerId,
-			CreatorId:   m.CreatorId,
-			Observers:   m.Observers,
-			Status:      m.Status,
-			CreatedAt:   m.CreatedAt,
-			UpdatedAt:   m.UpdatedAt,
-			DeletedAt:   m.DeletedAt,
-		})
+// findPerformerTasks runs the filter on the performer's shards. While a rebalance is
+// moving tasks they may still be on their old shard, so every shard is queried then.
+func (r *PostgresRepository) findPerformerTasks(ctx context.Context, filter ports.TaskFilter) ([]domain.Task, error) {
+	indexes := r.ShardManager.PerformerShards(filter.PerformerID)
+	if moving, err := shard.RebalancePending(ctx); err != nil || moving {
+		indexes = r.ShardManager.ShardIndexes()
 	}
-
-	return result, nil
+	pos := make(map[uint]int)
+	var all []domain.Task
+	for _, idx := range indexes {
+		tasks, err := r.Find(ctx, filter, idx)
+		if err != nil {
+			return nil, err
+		}
+		// a task on two shards is mid-move; keep the newer copy
+		for _, t := range tasks {
+			i, seen := pos[t.ID]
+			switch {
+			case !seen:
+				pos[t.ID] = len(all)
+				all = append(all, t)
+			case t.UpdatedAt.After(all[i].UpdatedAt):
+				all[i] = t
+			}
+		}
+	}
+	return all, nil
 }
 
 func (r *PostgresRepository) Delete(ctx context.Context, taskID uint) error {
-	shardIndex, err := cache.GetTaskShard(ctx, taskID)
+	shardIndex, err := directory.Default.Lookup(ctx, taskID)
 	if err != nil {
-		if !cache.IsNilError(err) {
+		if !errors.Is(err, directory.ErrNotFound) {
 			return err
 		}
 
@@ -97,111 +234,133 @@ func (r *PostgresRepository) Delete(ctx context.Context, taskID uint) error {
 		if err != nil {
 			return err
 		}
-		_ = cache.SetTaskShard(ctx, taskID, shardIndex)
+		_ = directory.Default.Assign(ctx, shardIndex, taskID)
 	}
 	db := r.ShardManager.GetShardByIndex(shardIndex)
 	if db == nil {
 		return errors.New("shard not found")
 	}
 
-	return db.Delete(&persistence.Task{ID: taskID}, taskID).Error
+	err = r.ShardManager.Call(ctx, shardIndex, func(ctx context.Context) error {
+		return db.WithContext(ctx).Delete(&persistence.Task{ID: taskID}, taskID).Error
+	})
+	if err != nil {
+		return err
+	}
+	r.ShardManager.MarkWrite(ctx)
+	return nil
 }
 
 func (r *PostgresRepository) GetByID(ctx context.Context, taskID uint) (*domain.Task, error) {
-	shardIndex, err := cache.GetTaskShard(ctx, taskID)
+	shardIndex, err := directory.Default.Lookup(ctx, taskID)
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			for idx, db := range r.ShardManager.GetAllShards() {
-				var task persistence.Task
-				err := db.WithContext(ctx).
-					Preload("Observers").
-					First(&task, taskID).Error
-				if err == nil {
-					_ = cache.SetTaskShard(ctx, task.ID, idx)
-					return &domain.Task{
-						ID:          task.ID,
-						Title:       task.Title,
-						Description: task.Description,
-						PerformerId: task.PerformerId,
-						CreatorId:   task.CreatorId,
-						Status:      task.Status,
-						Observers:   task.Observers,
-						CreatedAt:   task.CreatedAt,
-						UpdatedAt:   task.UpdatedAt,
-					}, nil
-				}
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					continue
-				}
-				return nil, err
-			}
-			return nil, gorm.ErrRecordNotFound // not found in any shard
-
-		} else {
-			return nil, err // real infrastructure failure
+		if errors.Is(err, directory.ErrNotFound) {
+			return r.scanShardsForTask(ctx, taskID, -1)
 		}
+		return nil, err // real infrastructure failure
 	}
-	db := r.ShardManager.GetShardByIndex(shardIndex)
+	db := r.ShardManager.Reader(ctx, shardIndex)
 	if db == nil {
 		return nil, errors.New("shard not found")
 	}
 
 	var task persistence.Task
 
-	if err := db.WithContext(ctx).
-		Preload("Observers").
-		First(&task, taskID).Error; err != nil {
+	err = r.ShardManager.Call(ctx, shardIndex, func(ctx context.Context) error {
+		err := db.WithContext(ctx).
+			Preload("Observers").
+			First(&task, taskID).Error
+		if primary := r.ShardManager.GetShardByIndex(shardIndex); errors.Is(err, gorm.ErrRecordNotFound) && db != primary {
+			// a lagging replica may not have the task yet
+			err = primary.WithContext(ctx).
+				Preload("Observers").
+				First(&task, taskID).Error
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// the mapping may be ahead of a rebalance copy or behind a finished move
+			return r.scanShardsForTask(ctx, taskID, shardIndex)
+		}
 		return nil, err
 	}
+	r.ShardManager.RecordRequest(shardIndex, task.PerformerId)
 
-	return &domain.Task{
-		ID:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		PerformerId: task.PerformerId,
-		CreatorId:   task.CreatorId,
-		Status:      task.Status,
-		Observers:   task.Observers,
-		CreatedAt:   task.CreatedAt,
-		UpdatedAt:   task.UpdatedAt,
-	}, nil
+	return persistenceToDomainTask(task), nil
+}
+
+// scanShardsForTask looks for the task on every shard except skip and remembers where it was found.
+func (r *PostgresRepository) scanShardsForTask(ctx context.Context, taskID uint, skip int) (*domain.Task, error) {
+	var task persistence.Task
+	idx, err := r.findOnShards(ctx, taskID, skip, &task, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Observers")
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = directory.Default.Assign(ctx, idx, task.ID)
+	return persistenceToDomainTask(task), nil
 }
 
 func (r *PostgresRepository) findShardIndexByTaskID(ctx context.Context, taskID uint) (int, error) {
+	var task persistence.Task
+	return r.findOnShards(ctx, taskID, -1, &task, func(db *gorm.DB) *gorm.DB {
+		return db.Session(&gorm.Session{Logger: glogger.Default.LogMode(glogger.Silent)}).Select("id")
+	})
+}
+
+// findOnShards loads the task into task from the first shard except skip that has it,
+// with query applied, and returns that shard. Shards whose breaker is open are skipped;
+// if no other shard has the task, it may be on one of them, so ErrShardUnavailable is
+// returned instead of gorm.ErrRecordNotFound.
+func (r *PostgresRepository) findOnShards(
+	ctx context.Context,
+	taskID uint,
+	skip int,
+	task *persistence.Task,
+	query func(db *gorm.DB) *gorm.DB,
+) (int, error) {
+	var unavailable error
 	for idx, db := range r.ShardManager.GetAllShards() {
-		if db == nil {
+		if db == nil || idx == skip {
 			continue
 		}
-
-		var task persistence.Task
-		err := db.WithContext(ctx).
-			Session(&gorm.Session{Logger: glogger.Default.LogMode(glogger.Silent)}).
-			Select("id").
-			First(&task, taskID).Error
+		err := r.ShardManager.Call(ctx, idx, func(ctx context.Context) error {
+			return query(db.WithContext(ctx)).First(task, taskID).Error
+		})
 		if err == nil {
 			return idx, nil
 		}
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			continue
 		}
-
+		if errors.Is(err, shard.ErrShardUnavailable) {
+			unavailable = err
+			continue
+		}
 		return -1, err
 	}
-
-	return -1, gorm.ErrRecordNotFound
+	if unavailable != nil {
+		return -1, unavailable
+	}
+	return -1, gorm.ErrRecordNotFound // not found in any shard
 }
 
 func (r *PostgresRepository) Update(ctx context.Context, input ports.UpdateTaskInput) (*domain.Task, error) {
 	taskID := input.ID
 
-	currentShardIndex, err := cache.GetTaskShard(ctx, taskID)
+	currentShardIndex, err := directory.Default.Lookup(ctx, taskID)
 	var fromShard *gorm.DB
 	var task persistence.Task
 
 	if err == nil {
 		fromShard = r.ShardManager.GetShardByIndex(currentShardIndex)
 		if fromShard != nil {
-			if err := fromShard.First(&task, taskID).Error; err != nil {
+			err := r.ShardManager.Call(ctx, currentShardIndex, func(ctx context.Context) error {
+				return fromShard.WithContext(ctx).First(&task, taskID).Error
+			})
+			if err != nil {
 				if errors.Is(err, gorm.ErrRecordNotFound) {
 					return nil, gorm.ErrRecordNotFound
 				}
@@ -209,78 +368,81 @@ func (r *PostgresRepository) Update(ctx context.Context, input ports.UpdateTaskI
 			}
 		}
 	} else {
-		if cache.IsNilError(err) {
-			logger.Warn(ctx, "cache shard mapping miss for update", logger.ZapUint("task_id", taskID))
+		if errors.Is(err, directory.ErrNotFound) {
+			logger.Warn(ctx, "shard directory miss for update", logger.ZapUint("task_id", taskID))
 		} else {
-			logger.Warn(ctx, "cache error on get shard for update", logger.ZapError(err))
+			logger.Warn(ctx, "shard directory error on update", logger.ZapError(err))
 		}
 	}
 
 	if fromShard == nil {
-		allShards := r.ShardManager.GetAllShards()
-		found := false
-		for idx, sh := range allShards {
-			if sh == nil {
-				continue
-			}
-
-			var t persistence.Task
-			if err := sh.Session(&gorm.Session{Logger: glogger.Default.LogMode(glogger.Silent)}).First(&t, taskID).Error; err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					continue
-				}
-				return nil, err
-			}
-
-			fromShard = sh
-			currentShardIndex = idx
-			task = t
-			found = true
-			break
-		}
-		if !found {
-			return nil, gorm.ErrRecordNotFound
+		idx, err := r.findOnShards(ctx, taskID, -1, &task, func(db *gorm.DB) *gorm.DB {
+			return db.Session(&gorm.Session{Logger: glogger.Default.LogMode(glogger.Silent)})
+		})
+		if err != nil {
+			return nil, err
 		}
+		fromShard = r.ShardManager.GetShardByIndex(idx)
+		currentShardIndex = idx
 	}
 
-	oldPerformerID := task.PerformerId
-
-	if err := fromShard.Where("task_id = ?", task.ID).Delete(&persistence.Observer{}).Error; err != nil {
-		return nil, err
-	}
+	oldKey := shard.TaskKey{PerformerID: task.PerformerId, ProjectID: task.ProjectId, CreatorID: task.CreatorId, TenantID: task.TenantId}
+	r.ShardManager.RecordRequest(currentShardIndex, task.PerformerId)
 
 	task.Title = input.Title
 	task.Description = input.Description
 	task.PerformerId = input.PerformerID
 	task.CreatorId = input.CreatorID
+	task.ProjectId = input.ProjectID
 	task.Observers = observersFromUintIDs(input.ObserverIDs)
+	if input.MentionIDs != nil {
+		task.MentionIds = *input.MentionIDs
+	}
+	task.Labels = input.Labels
 	task.Status = input.Status
+	task.DueAt = input.DueAt
 
-	newShardIndex := r.ShardManager.GetShardByPerformerIDIndex(task.PerformerId)
-	needMigrate := oldPerformerID != task.PerformerId && newShardIndex != currentShardIndex
+	newKey := shard.TaskKey{PerformerID: task.PerformerId, ProjectID: task.ProjectId, CreatorID: task.CreatorId, TenantID: task.TenantId}
+	newShardIndex := r.ShardManager.ResolveTask(newKey)
+	needMigrate := oldKey != newKey && newShardIndex != currentShardIndex
 
 	if needMigrate {
-		toShard := r.ShardManager.GetShardByIndex(newShardIndex)
-		if toShard == nil {
+		if r.ShardManager.GetShardByIndex(newShardIndex) == nil {
 			return nil, errors.New("target shard not found")
 		}
+		if !r.ShardManager.Available(currentShardIndex) {
+			return nil, fmt.Errorf("%w: shard %d", shard.ErrShardUnavailable, currentShardIndex)
+		}
 
-		if err := migrateTaskToShard(ctx, &task, fromShard, toShard, newShardIndex); err != nil {
+		// each step runs under its own shard's breaker and timeout
+		err := r.ShardManager.MoveTask(ctx, &task, oldKey.PerformerID, currentShardIndex, newShardIndex)
+		if err != nil {
 			return nil, err
 		}
 	} else {
-		if err := fromShard.Save(&task).Error; err != nil {
-			return nil, err
-		}
+		err := r.ShardManager.Call(ctx, currentShardIndex, func(ctx context.Context) error {
+			db := fromShard.WithContext(ctx)
+			if err := db.Where("task_id = ?", task.ID).Delete(&persistence.Observer{}).Error; err != nil {
+				return err
+			}
+			if err := db.Save(&task).Error; err != nil {
+				return err
+			}
 
-		for _, obs := range task.Observers {
-			newObs := persistence.Observer{UserId: obs.UserId, TaskId: task.ID}
-			if err := fromShard.Create(&newObs).Error; err != nil {
-				return nil, err
+			for _, obs := range task.Observers {
+				newObs := persistence.Observer{UserId: obs.UserId, TaskId: task.ID}
+				if err := db.Create(&newObs).Error; err != nil {
+					return err
+				}
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	r.ShardManager.MarkWrite(ctx)
 	if err := cache.DeleteTaskCache(ctx, task.ID); err != nil {
 		logger.Warn(ctx, "cache delete failed", logger.ZapError(err))
 	}
@@ -288,39 +450,6 @@ func (r *PostgresRepository) Update(ctx context.Context, input ports.UpdateTaskI
 	return persistenceToDomainTask(task), nil
 }
 
-func migrateTaskToShard(
-	ctx context.Context,
-	task *persistence.Task,
-	fromShard *gorm.DB,
-	toShard *gorm.DB,
-	toIndex int,
-) error {
-	if err := toShard.Create(task).Error; err != nil {
-		return err
-	}
-
-	for _, obs := range task.Observers {
-		newObs := persistence.Observer{UserId: obs.UserId, TaskId: task.ID}
-		if err := toShard.Create(&newObs).Error; err != nil {
-			return err
-		}
-	}
-
-	if err := fromShard.Unscoped().Where("task_id = ?", task.ID).Delete(&persistence.Observer{}).Error; err != nil {
-		return err
-	}
-	if err := fromShard.Unscoped().Delete(task).Error; err != nil {
-		return err
-	}
-
-	if err := cache.SetTaskShard(ctx, task.ID, toIndex); err != nil {
-		return err
-	}
-	_ = cache.DeleteTaskCache(ctx, task.ID)
-
-	return nil
-}
-
 func observersFromUintIDs(ids []uint) []persistence.Observer {
 	if len(ids) == 0 {
 		return nil
@@ -340,8 +469,13 @@ func persistenceToDomainTask(task persistence.Task) *domain.Task {
 		Description: task.Description,
 		PerformerId: task.PerformerId,
 		CreatorId:   task.CreatorId,
+		ProjectId:   task.ProjectId,
+		TenantId:    task.TenantId,
 		Status:      task.Status,
 		Observers:   task.Observers,
+		Mentions:    task.MentionIds,
+		Labels:      task.Labels,
+		DueAt:       task.DueAt,
 		CreatedAt:   task.CreatedAt,
 		UpdatedAt:   task.UpdatedAt,
 		DeletedAt:   task.DeletedAt,
@@ -0,0 +1,165 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"tasks/internal/domain"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/persistence"
+	"tasks/internal/ports"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PostgresViewRepository stores views on the owner's shard at creation time.
+// Reads scan every shard, so views stay reachable after the ring changes.
+type PostgresViewRepository struct {
+	ShardManager *shard.ShardManager
+}
+
+func NewPostgresViewRepository(sm *shard.ShardManager) *PostgresViewRepository {
+	return &PostgresViewRepository{ShardManager: sm}
+}
+
+func (r *PostgresViewRepository) Save(ctx context.Context, v domain.View, shardIndex int) error {
+	db := r.ShardManager.GetShardByIndex(shardIndex)
+	if db == nil {
+		return errors.New("shard not found")
+	}
+	m, err := viewToPersistence(v)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Create(&m).Error
+}
+
+func (r *PostgresViewRepository) GetByID(ctx context.Context, id string) (*domain.View, error) {
+	_, m, err := r.locate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return persistenceToDomainView(m)
+}
+
+func (r *PostgresViewRepository) List(ctx context.Context, filter ports.ViewListFilter) ([]domain.View, error) {
+	var result []domain.View
+	for _, db := range r.ShardManager.GetAllShards() {
+		if db == nil {
+			continue
+		}
+		query := db.WithContext(ctx).Where("owner_id = ?", filter.OwnerID)
+		if filter.ProjectID != 0 {
+			query = query.Or("visibility = ? AND project_id = ?", domain.ViewProject, filter.ProjectID)
+		}
+
+		var models []persistence.View
+		if err := query.Find(&models).Error; err != nil {
+			return nil, err
+		}
+		for _, m := range models {
+			v, err := persistenceToDomainView(m)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, *v)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (r *PostgresViewRepository) Update(ctx context.Context, v domain.View) error {
+	db, _, err := r.locate(ctx, v.ID)
+	if err != nil {
+		return err
+	}
+	m, err := viewToPersistence(v)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Save(&m).Error
+}
+
+func (r *PostgresViewRepository) Delete(ctx context.Context, id string) error {
+	db, _, err := r.locate(ctx, id)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Delete(&persistence.View{ID: id}).Error
+}
+
+// locate finds the shard holding the view. An ID that is not a UUID names no view; it is
+// rejected before any shard is queried, where the uuid column would fail the cast.
+func (r *PostgresViewRepository) locate(ctx context.Context, id string) (*gorm.DB, persistence.View, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, persistence.View{}, domain.ErrViewNotFound
+	}
+	for _, db := range r.ShardManager.GetAllShards() {
+		if db == nil {
+			continue
+		}
+		var m persistence.View
+		err := db.WithContext(ctx).Where("id = ?", id).Take(&m).Error
+		if err == nil {
+			return db, m, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, m, err
+		}
+	}
+	return nil, persistence.View{}, domain.ErrViewNotFound
+}
+
+func viewToPersistence(v domain.View) (persistence.View, error) {
+	filter, err := json.Marshal(v.Filter)
+	if err != nil {
+		return persistence.View{}, err
+	}
+	columns := v.Columns
+	if columns == nil {
+		columns = []string{}
+	}
+	cols, err := json.Marshal(columns)
+	if err != nil {
+		return persistence.View{}, err
+	}
+	return persistence.View{
+		ID:         v.ID,
+		Name:       v.Name,
+		OwnerId:    v.OwnerID,
+		Filter:     string(filter),
+		SortBy:     v.SortBy,
+		SortDesc:   v.SortDesc,
+		Columns:    string(cols),
+		Visibility: v.Visibility,
+		ProjectId:  v.ProjectID,
+		CreatedAt:  v.CreatedAt,
+		UpdatedAt:  v.UpdatedAt,
+	}, nil
+}
+
+func persistenceToDomainView(m persistence.View) (*domain.View, error) {
+	v := &domain.View{
+		ID:         m.ID,
+		Name:       m.Name,
+		OwnerID:    m.OwnerId,
+		SortBy:     m.SortBy,
+		SortDesc:   m.SortDesc,
+		Visibility: m.Visibility,
+		ProjectID:  m.ProjectId,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+	if err := json.Unmarshal([]byte(m.Filter), &v.Filter); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(m.Columns), &v.Columns); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+var _ ports.ViewRepository = (*PostgresViewRepository)(nil)
@@ -0,0 +1,182 @@
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"tasks/internal/domain"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/adapters"
+	"tasks/internal/infrastructure/persistence"
+	"tasks/internal/ports"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLiteShards is a manager over two SQLite shards with the task and view tables.
+func newSQLiteShards(t *testing.T) (*shard.ShardManager, []*gorm.DB) {
+	t.Helper()
+	var dbs []*gorm.DB
+	for _, name := range []string{"a", "b"} {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), name+".db")), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AutoMigrate(&persistence.Task{}, &persistence.Observer{}, &persistence.View{}); err != nil {
+			t.Fatal(err)
+		}
+		dbs = append(dbs, db)
+	}
+	shards := []shard.ShardInfo{{Index: 0, ID: "a", State: shard.ShardActive}, {Index: 1, ID: "b", State: shard.ShardActive}}
+	return shard.NewShardManagerFromTopology(shard.Topology{Shards: shards}, dbs), dbs
+}
+
+func TestPostgresViewRepository(t *testing.T) {
+	sm, _ := newSQLiteShards(t)
+	repo := adapters.NewPostgresViewRepository(sm)
+	ctx := context.Background()
+
+	due := time.Date(2026, 10, 26, 0, 0, 0, 0, time.UTC)
+	own := domain.View{ID: uuid.NewString(), Name: "mine", OwnerID: 1, Visibility: domain.ViewPrivate,
+		Filter: domain.ViewFilter{PerformerID: 1, DueBefore: &due, ExcludeDone: true}, Columns: []string{"title"}, CreatedAt: due.Add(-2 * time.Hour)}
+	shared := domain.View{ID: uuid.NewString(), Name: "team", OwnerID: 2, Visibility: domain.ViewProject, ProjectID: 5, CreatedAt: due.Add(-time.Hour)}
+	other := domain.View{ID: uuid.NewString(), Name: "theirs", OwnerID: 2, Visibility: domain.ViewPrivate, ProjectID: 5, CreatedAt: due}
+	for i, v := range []domain.View{own, shared, other} {
+		if err := repo.Save(ctx, v, i%2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := repo.GetByID(ctx, own.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Filter.DueBefore == nil || !got.Filter.DueBefore.Equal(due) || !got.Filter.ExcludeDone || got.Filter.PerformerID != 1 {
+		t.Fatalf("stored filter %+v, want %+v", got.Filter, own.Filter)
+	}
+	if _, err := repo.GetByID(ctx, "not-a-uuid"); !errors.Is(err, domain.ErrViewNotFound) {
+		t.Fatalf("GetByID(not-a-uuid): err = %v, want ErrViewNotFound", err)
+	}
+
+	listTests := []struct {
+		filter ports.ViewListFilter
+		want   []string
+	}{
+		{filter: ports.ViewListFilter{OwnerID: 1}, want: []string{"mine"}},
+		{filter: ports.ViewListFilter{OwnerID: 1, ProjectID: 5}, want: []string{"mine", "team"}},
+		{filter: ports.ViewListFilter{OwnerID: 2}, want: []string{"team", "theirs"}},
+	}
+	for _, tt := range listTests {
+		views, err := repo.List(ctx, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, v := range views {
+			names = append(names, v.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Fatalf("List(%+v) = %q, want %q", tt.filter, names, tt.want)
+		}
+	}
+
+	shared.Name = "renamed"
+	if err := repo.Update(ctx, shared); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.GetByID(ctx, shared.ID); err != nil || got.Name != "renamed" {
+		t.Fatalf("after Update: %+v, %v", got, err)
+	}
+	if err := repo.Delete(ctx, shared.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetByID(ctx, shared.ID); !errors.Is(err, domain.ErrViewNotFound) {
+		t.Fatalf("after Delete: err = %v, want ErrViewNotFound", err)
+	}
+}
+
+func TestPostgresProjectAccess(t *testing.T) {
+	sm, dbs := newSQLiteShards(t)
+	task := persistence.Task{ID: 1, Title: "t", PerformerId: 3, CreatorId: 4, ProjectId: 5, Status: "new",
+		Observers: []persistence.Observer{{UserId: 6}}}
+	if err := dbs[1].Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	access := adapters.NewPostgresProjectAccess(sm)
+
+	tests := []struct {
+		name      string
+		user      uint
+		project   uint
+		wantAllow bool
+	}{
+		{name: "performer", user: 3, project: 5, wantAllow: true},
+		{name: "creator", user: 4, project: 5, wantAllow: true},
+		{name: "observer", user: 6, project: 5, wantAllow: true},
+		{name: "stranger", user: 7, project: 5, wantAllow: false},
+		{name: "other project", user: 3, project: 6, wantAllow: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := access.CanSeeProject(context.Background(), tt.user, tt.project)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantAllow {
+				t.Fatalf("CanSeeProject(%d, %d) = %t, want %t", tt.user, tt.project, ok, tt.wantAllow)
+			}
+		})
+	}
+}
+
+func TestPostgresRepositoryFindDueWindow(t *testing.T) {
+	sm, dbs := newSQLiteShards(t)
+	weekStart := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		d := weekStart.AddDate(0, 0, days)
+		return &d
+	}
+	tasks := []persistence.Task{
+		{ID: 1, Title: "this week", Status: "new", DueAt: at(2)},
+		{ID: 2, Title: "done this week", Status: "done", DueAt: at(3)},
+		{ID: 3, Title: "next week", Status: "new", DueAt: at(7)},
+		{ID: 4, Title: "last week", Status: "in_progress", DueAt: at(-1)},
+		{ID: 5, Title: "no due date", Status: "new"},
+	}
+	if err := dbs[0].Create(&tasks).Error; err != nil {
+		t.Fatal(err)
+	}
+	repo := adapters.NewPostgresRepository(sm)
+
+	tests := []struct {
+		name   string
+		filter ports.TaskFilter
+		want   []uint
+	}{
+		{name: "window", filter: ports.TaskFilter{DueAfter: at(0), DueBefore: at(7)}, want: []uint{1, 2}},
+		{name: "window without done", filter: ports.TaskFilter{DueAfter: at(0), DueBefore: at(7), ExcludeDone: true}, want: []uint{1}},
+		{name: "overdue", filter: ports.TaskFilter{DueBefore: at(0)}, want: []uint{4}},
+		{name: "not done", filter: ports.TaskFilter{ExcludeDone: true}, want: []uint{1, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.Find(context.Background(), tt.filter, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []uint
+			for _, task := range got {
+				ids = append(ids, task.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Fatalf("found %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
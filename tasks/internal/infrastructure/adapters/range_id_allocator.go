@@ -0,0 +1,39 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"tasks/internal/domain/shard"
+)
+
+// RangeIDAllocator issues IDs from a range reserved for each shard in its id_allocator
+// table, so creating a task only touches the shard it is stored on.
+type RangeIDAllocator struct {
+	sm        *shard.ShardManager
+	base      uint64
+	rangeSize uint64
+}
+
+func NewRangeIDAllocator(sm *shard.ShardManager, base, rangeSize uint64) *RangeIDAllocator {
+	return &RangeIDAllocator{sm: sm, base: base, rangeSize: rangeSize}
+}
+
+// NextID takes the next ID of the shard's range, seeding the range on first use.
+func (a *RangeIDAllocator) NextID(ctx context.Context, shardIndex int) (uint, error) {
+	db := a.sm.GetShardByIndex(shardIndex)
+	if db == nil {
+		return 0, fmt.Errorf("shard %d not available", shardIndex)
+	}
+	id, err := shard.AllocNextID(ctx, db)
+	if err != nil || id != 0 {
+		return id, err
+	}
+	if _, last := shard.IDRange(shardIndex, a.base, a.rangeSize); last > math.MaxInt64 {
+		return 0, fmt.Errorf("id range of shard %d ends past the largest id", shardIndex)
+	}
+	if err := shard.SeedIdAllocator(ctx, db, shardIndex, a.base, a.rangeSize); err != nil {
+		return 0, fmt.Errorf("seed id range of shard %d: %w", shardIndex, err)
+	}
+	return shard.AllocNextID(ctx, db)
+}
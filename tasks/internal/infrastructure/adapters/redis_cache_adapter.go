@@ -18,7 +18,12 @@ func (a *RedisCacheAdapter) SetTask(ctx context.Context, task domain.Task) error
 		Description: task.Description,
 		PerformerId: task.PerformerId,
 		CreatorId:   task.CreatorId,
+		ProjectId:   task.ProjectId,
+		TenantId:    task.TenantId,
+		MentionIds:  task.Mentions,
+		Labels:      task.Labels,
 		Status:      task.Status,
+		DueAt:       task.DueAt,
 	}
 	return cache.SetTask(ctx, p)
 }
@@ -34,6 +39,11 @@ func (a *RedisCacheAdapter) GetTask(ctx context.Context, taskID uint) (domain.Ta
 		Description: p.Description,
 		PerformerId: p.PerformerId,
 		CreatorId:   p.CreatorId,
+		ProjectId:   p.ProjectId,
+		TenantId:    p.TenantId,
+		Mentions:    p.MentionIds,
+		Labels:      p.Labels,
 		Status:      p.Status,
+		DueAt:       p.DueAt,
 	}, nil
 }
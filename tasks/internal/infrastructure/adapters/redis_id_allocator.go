@@ -5,10 +5,11 @@ import (
 	"tasks/internal/infrastructure/cache"
 )
 
+// RedisIDAllocator takes IDs from the global Redis counter, whatever the shard.
 type RedisIDAllocator struct{}
 
 func NewRedisIDAllocator() *RedisIDAllocator { return &RedisIDAllocator{} }
 
-func (a *RedisIDAllocator) NextID(ctx context.Context) (uint, error) {
+func (a *RedisIDAllocator) NextID(ctx context.Context, _ int) (uint, error) {
 	return cache.AllocTaskID(ctx)
 }
@@ -0,0 +1,162 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/leader"
+	"time"
+)
+
+// snowflakeWorkerTTL is the TTL of a leased worker number.
+const snowflakeWorkerTTL = 30 * time.Second
+
+// snowflakeLeaseRetry is how often a lost worker number is leased again.
+const snowflakeLeaseRetry = 2 * time.Second
+
+// ErrSnowflakeWorkerLost means the leased worker number was lost and no other one is
+// held yet, so IDs from this replica could collide with another holder's.
+var ErrSnowflakeWorkerLost = errors.New("snowflake worker lease lost")
+
+// SnowflakeLease leases a free worker number. lost is cancelled when the lease is lost;
+// release gives it back.
+type SnowflakeLease func(ctx context.Context) (worker int, lost context.Context, release func(), err error)
+
+// SnowflakeIDAllocator generates time-ordered IDs that embed the shard index, without
+// any round trip. Each replica needs its own worker number.
+type SnowflakeIDAllocator struct {
+	mu      sync.Mutex
+	gen     *shard.Snowflake // nil while no worker number is held
+	lease   context.Context
+	release func()
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// NewSnowflakeIDAllocator uses worker if it is not negative, otherwise it leases the first
+// free worker number from Redis and keeps one until Close.
+func NewSnowflakeIDAllocator(ctx context.Context, worker int) (*SnowflakeIDAllocator, error) {
+	if worker >= 0 {
+		gen, err := shard.NewSnowflake(worker, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &SnowflakeIDAllocator{gen: gen, lease: context.Background(), release: func() {}}, nil
+	}
+	return NewLeasedSnowflakeIDAllocator(ctx, leaseSnowflakeWorker, snowflakeLeaseRetry)
+}
+
+// NewLeasedSnowflakeIDAllocator takes a worker number from lease. When the lease is lost
+// it refuses IDs and leases a number again every retry until it gets one.
+func NewLeasedSnowflakeIDAllocator(ctx context.Context, lease SnowflakeLease, retry time.Duration) (*SnowflakeIDAllocator, error) {
+	worker, lost, release, err := lease(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := shard.NewSnowflake(worker, nil)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	runCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	a := &SnowflakeIDAllocator{gen: gen, lease: lost, release: release, stop: stop, done: make(chan struct{})}
+	go a.keepWorker(runCtx, lease, worker, retry)
+	return a, nil
+}
+
+func (a *SnowflakeIDAllocator) NextID(_ context.Context, shardIndex int) (uint, error) {
+	a.mu.Lock()
+	gen, lease := a.gen, a.lease
+	a.mu.Unlock()
+	if gen == nil || lease.Err() != nil {
+		return 0, ErrSnowflakeWorkerLost
+	}
+	return gen.Next(shardIndex)
+}
+
+// keepWorker waits for the lease to be lost and leases a worker number again.
+func (a *SnowflakeIDAllocator) keepWorker(ctx context.Context, lease SnowflakeLease, worker int, retry time.Duration) {
+	defer close(a.done)
+	for {
+		a.mu.Lock()
+		lost := a.lease
+		a.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-lost.Done():
+		}
+
+		a.mu.Lock()
+		last, release := a.gen.Last(), a.release
+		a.gen = nil
+		a.mu.Unlock()
+		release()
+		log.Printf("snowflake worker %d lost; leasing a worker number again", worker)
+
+		ticker := time.NewTicker(retry)
+		for {
+			next, lost, release, err := lease(ctx)
+			if err == nil {
+				gen, err := shard.NewSnowflake(next, nil)
+				if err != nil {
+					release()
+					ticker.Stop()
+					log.Printf("snowflake worker: %v", err)
+					return
+				}
+				// the same number must not reissue a millisecond this replica already used
+				if next == worker {
+					time.Sleep(time.Until(last.Add(time.Millisecond)))
+				}
+				a.mu.Lock()
+				a.gen, a.lease, a.release = gen, lost, release
+				a.mu.Unlock()
+				worker = next
+				log.Printf("snowflake worker %d leased", worker)
+				break
+			}
+			select {
+			case <-ctx.Done():
+				ticker.Stop()
+				return
+			case <-ticker.C:
+			}
+		}
+		ticker.Stop()
+	}
+}
+
+// Close gives back a leased worker number.
+func (a *SnowflakeIDAllocator) Close() {
+	if a.stop != nil {
+		a.stop()
+		<-a.done
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.gen != nil {
+		a.release()
+		a.gen = nil
+	}
+}
+
+func leaseSnowflakeWorker(ctx context.Context) (int, context.Context, func(), error) {
+	for n := 0; n < shard.MaxSnowflakeWorkers; n++ {
+		elector := leader.NewElector(fmt.Sprintf("snowflake-worker-%d", n), snowflakeWorkerTTL)
+		// the lease must outlive the startup context
+		leaseCtx, _, release, err := elector.Acquire(context.WithoutCancel(ctx))
+		if errors.Is(err, leader.ErrNotLeader) {
+			continue
+		}
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("lease snowflake worker: %w", err)
+		}
+		return n, leaseCtx, release, nil
+	}
+	return 0, nil, nil, fmt.Errorf("all %d snowflake worker numbers are leased", shard.MaxSnowflakeWorkers)
+}
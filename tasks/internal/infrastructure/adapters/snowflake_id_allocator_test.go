@@ -0,0 +1,85 @@
+package adapters_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/adapters"
+	"testing"
+	"time"
+)
+
+// fakeWorkerLeases hands out worker 3, fails the first attempt after a loss, and
+// records releases.
+type fakeWorkerLeases struct {
+	mu       sync.Mutex
+	attempts int
+	cancels  []context.CancelFunc
+	released int
+}
+
+func (f *fakeWorkerLeases) lease(ctx context.Context) (int, context.Context, func(), error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.attempts == 2 {
+		return 0, nil, nil, errors.New("redis unavailable")
+	}
+	lost, cancel := context.WithCancel(context.Background())
+	f.cancels = append(f.cancels, cancel)
+	return 3, lost, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.released++
+	}, nil
+}
+
+func (f *fakeWorkerLeases) lose() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cancels[len(f.cancels)-1]()
+}
+
+func TestSnowflakeIDAllocatorLeasesWorkerAgain(t *testing.T) {
+	leases := &fakeWorkerLeases{}
+	a, err := adapters.NewLeasedSnowflakeIDAllocator(context.Background(), leases.lease, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := a.NextID(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leases.lose()
+	if _, err := a.NextID(context.Background(), 1); !errors.Is(err, adapters.ErrSnowflakeWorkerLost) {
+		t.Fatalf("NextID after loss: err = %v, want ErrSnowflakeWorkerLost", err)
+	}
+
+	var next uint
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		next, err = a.NextID(context.Background(), 1)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("worker not leased again: %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if next <= first {
+		t.Fatalf("id %d after re-lease is not above %d", next, first)
+	}
+	if p := shard.DecodeSnowflake(next); p.Worker != 3 || p.Shard != 1 {
+		t.Fatalf("decoded %+v, want worker 3 on shard 1", p)
+	}
+
+	a.Close()
+	leases.mu.Lock()
+	defer leases.mu.Unlock()
+	if leases.attempts != 3 || leases.released != 2 {
+		t.Fatalf("attempts %d, releases %d; want 3 and 2", leases.attempts, leases.released)
+	}
+}
@@ -0,0 +1,168 @@
+package adapters
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"tasks/internal/domain"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/config"
+	"tasks/internal/infrastructure/lru"
+	"tasks/internal/ports"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// TieredCacheAdapter keeps recently read tasks in process, in front of a shared cache
+// (RedisCacheAdapter). Concurrent misses for the same task share one fetch from the
+// shared cache. A task changed anywhere is dropped here once DeleteTaskCache announces
+// it; run Watch to hear about changes made by other replicas.
+type TieredCacheAdapter struct {
+	shared ports.Cache
+	local  *lru.Cache[uint, domain.Task] // nil when disabled
+	loads  singleflight.Group
+
+	// mu orders invalidations against storing fetched tasks. fetching holds the tasks
+	// being fetched; a fetch whose task was invalidated meanwhile is not stored.
+	mu       sync.Mutex
+	fetching map[uint]*fetch
+
+	localHits, localMisses   atomic.Uint64
+	sharedHits, sharedMisses atomic.Uint64
+	coalesced, invalidations atomic.Uint64
+}
+
+// NewTieredCacheAdapter puts an in-process cache of cfg.Size tasks in front of shared.
+// With a zero size every lookup goes to shared, and is still counted.
+func NewTieredCacheAdapter(shared ports.Cache, cfg config.LocalCache) *TieredCacheAdapter {
+	a := &TieredCacheAdapter{shared: shared, fetching: make(map[uint]*fetch)}
+	if cfg.Size > 0 {
+		a.local = lru.New[uint, domain.Task](cfg.Size, cfg.TTL)
+	}
+	cache.OnTaskInvalidated(a.Invalidate)
+	// invalidations were missed while Redis was unreachable
+	cache.OnReconnect(func(context.Context) { a.purge() })
+	return a
+}
+
+func (a *TieredCacheAdapter) GetTask(ctx context.Context, taskID uint) (domain.Task, error) {
+	if a.local != nil {
+		if task, ok := a.local.Get(taskID); ok {
+			a.localHits.Add(1)
+			return task, nil
+		}
+		a.localMisses.Add(1)
+	}
+
+	f := a.startFetch(taskID)
+	fetched := false
+	v, err, _ := a.loads.Do(strconv.FormatUint(uint64(taskID), 10), func() (interface{}, error) {
+		fetched = true
+		// the fetch is shared, so one caller's cancellation must not fail the others
+		return a.shared.GetTask(context.WithoutCancel(ctx), taskID)
+	})
+	// Do reports the result as shared to the caller that fetched it too
+	if !fetched {
+		a.coalesced.Add(1)
+	}
+	if err != nil {
+		a.endFetch(taskID, f, nil)
+		a.sharedMisses.Add(1)
+		return domain.Task{}, err
+	}
+	a.sharedHits.Add(1)
+	task := v.(domain.Task)
+	a.endFetch(taskID, f, &task)
+	return task, nil
+}
+
+// SetTask writes the shared cache only. The caller read task at some earlier point,
+// possibly before a change that was announced meanwhile, so the local copy is dropped
+// rather than replaced; the next GetTask fetches the task again.
+func (a *TieredCacheAdapter) SetTask(ctx context.Context, task domain.Task) error {
+	if a.local != nil {
+		a.local.Remove(task.ID)
+	}
+	return a.shared.SetTask(ctx, task)
+}
+
+// fetch tracks the callers fetching one task from the shared cache.
+type fetch struct {
+	callers int
+	stale   bool
+}
+
+func (a *TieredCacheAdapter) startFetch(taskID uint) *fetch {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	f, ok := a.fetching[taskID]
+	if !ok {
+		f = &fetch{}
+		a.fetching[taskID] = f
+	}
+	f.callers++
+	return f
+}
+
+// endFetch stores task locally, unless the task was invalidated since f started.
+func (a *TieredCacheAdapter) endFetch(taskID uint, f *fetch, task *domain.Task) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if task != nil && a.local != nil && !f.stale {
+		a.local.Add(taskID, *task)
+	}
+	if f.callers--; f.callers == 0 {
+		delete(a.fetching, taskID)
+	}
+}
+
+// Invalidate drops the local copy of the task, and keeps fetches of it that are under
+// way from storing what they read.
+func (a *TieredCacheAdapter) Invalidate(taskID uint) {
+	a.invalidations.Add(1)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if f, ok := a.fetching[taskID]; ok {
+		f.stale = true
+	}
+	if a.local != nil {
+		a.local.Remove(taskID)
+	}
+}
+
+func (a *TieredCacheAdapter) purge() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, f := range a.fetching {
+		f.stale = true
+	}
+	if a.local != nil {
+		a.local.Purge()
+	}
+}
+
+// Watch drops local copies of tasks announced by any replica until ctx is cancelled.
+// If the subscription ends early, copies expire after the configured TTL at the latest.
+func (a *TieredCacheAdapter) Watch(ctx context.Context) {
+	for taskID := range cache.SubscribeTaskInvalidations(ctx) {
+		a.Invalidate(taskID)
+	}
+}
+
+func (a *TieredCacheAdapter) CacheStats() ports.CacheStats {
+	st := ports.CacheStats{
+		LocalHits:     a.localHits.Load(),
+		LocalMisses:   a.localMisses.Load(),
+		RedisHits:     a.sharedHits.Load(),
+		RedisMisses:   a.sharedMisses.Load(),
+		Coalesced:     a.coalesced.Load(),
+		Invalidations: a.invalidations.Load(),
+	}
+	if a.local != nil {
+		st.Evictions = a.local.Evictions()
+		st.Entries = a.local.Len()
+		st.Capacity = a.local.Size()
+	}
+	return st
+}
@@ -0,0 +1,156 @@
+package adapters_test
+
+import (
+	"context"
+	"sync"
+	"tasks/internal/domain"
+	"tasks/internal/infrastructure/adapters"
+	"tasks/internal/infrastructure/config"
+	"testing"
+	"time"
+)
+
+// fakeSharedCache counts lookups. With hold set, each lookup reports on started and
+// waits for hold to be closed.
+type fakeSharedCache struct {
+	mu      sync.Mutex
+	tasks   map[uint]domain.Task
+	calls   int
+	started chan struct{}
+	hold    chan struct{}
+}
+
+func newFakeSharedCache(tasks ...domain.Task) *fakeSharedCache {
+	f := &fakeSharedCache{tasks: make(map[uint]domain.Task), started: make(chan struct{}, 16)}
+	for _, task := range tasks {
+		f.tasks[task.ID] = task
+	}
+	return f
+}
+
+func (f *fakeSharedCache) SetTask(_ context.Context, task domain.Task) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tasks[task.ID] = task
+	return nil
+}
+
+func (f *fakeSharedCache) GetTask(_ context.Context, taskID uint) (domain.Task, error) {
+	f.mu.Lock()
+	f.calls++
+	task, hold := f.tasks[taskID], f.hold
+	f.mu.Unlock()
+	f.started <- struct{}{}
+	if hold != nil {
+		<-hold
+	}
+	return task, nil
+}
+
+func (f *fakeSharedCache) holdLookups() chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hold = make(chan struct{})
+	return f.hold
+}
+
+func (f *fakeSharedCache) lookups() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+var localCache = config.LocalCache{Size: 16, TTL: time.Hour}
+
+func TestTieredCacheAdapterCoalescesMisses(t *testing.T) {
+	shared := newFakeSharedCache(domain.Task{ID: 1, Title: "one"})
+	a := adapters.NewTieredCacheAdapter(shared, localCache)
+	hold := shared.holdLookups()
+
+	const callers = 5
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if task, err := a.GetTask(context.Background(), 1); err != nil || task.Title != "one" {
+				t.Errorf("GetTask = %+v, %v", task, err)
+			}
+		}()
+	}
+	<-shared.started
+	// let the other callers join the fetch under way
+	time.Sleep(50 * time.Millisecond)
+	close(hold)
+	wg.Wait()
+
+	if n := shared.lookups(); n != 1 {
+		t.Fatalf("%d shared lookups, want 1", n)
+	}
+	if _, err := a.GetTask(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	st := a.CacheStats()
+	if shared.lookups() != 1 || st.LocalHits != 1 {
+		t.Fatalf("after the fetch: %d shared lookups, %d local hits; want 1 and 1", shared.lookups(), st.LocalHits)
+	}
+	if st.Coalesced != callers-1 {
+		t.Fatalf("coalesced %d, want %d", st.Coalesced, callers-1)
+	}
+}
+
+func TestTieredCacheAdapterInvalidationDuringFetch(t *testing.T) {
+	tests := []struct {
+		name        string
+		invalidated uint
+		wantLookups int
+	}{
+		{name: "same task", invalidated: 1, wantLookups: 2},
+		{name: "other task", invalidated: 2, wantLookups: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shared := newFakeSharedCache(domain.Task{ID: 1}, domain.Task{ID: 2})
+			a := adapters.NewTieredCacheAdapter(shared, localCache)
+			hold := shared.holdLookups()
+
+			done := make(chan error)
+			go func() {
+				_, err := a.GetTask(context.Background(), 1)
+				done <- err
+			}()
+			<-shared.started
+			a.Invalidate(tt.invalidated)
+			close(hold)
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := a.GetTask(context.Background(), 1); err != nil {
+				t.Fatal(err)
+			}
+			if n := shared.lookups(); n != tt.wantLookups {
+				t.Fatalf("%d shared lookups, want %d", n, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestTieredCacheAdapterSetTaskKeepsLocalEmpty(t *testing.T) {
+	shared := newFakeSharedCache(domain.Task{ID: 1, Title: "old"})
+	a := adapters.NewTieredCacheAdapter(shared, localCache)
+	if _, err := a.GetTask(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.SetTask(context.Background(), domain.Task{ID: 1, Title: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	task, err := a.GetTask(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Title != "new" || shared.lookups() != 2 {
+		t.Fatalf("got %q after %d shared lookups, want \"new\" from a second lookup", task.Title, shared.lookups())
+	}
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+)
+
+// ErrUserNotFound is returned when the auth service has no matching user.
+var ErrUserNotFound = errors.New("user not found")
+
+// UserDirectory looks users up through the auth service /users endpoint.
+// Results are memoized for the lifetime of the value.
+type UserDirectory struct {
+	baseURL string
+	token   string
+	client  *http.Client
+
+	mu      sync.Mutex
+	byEmail map[string]domain.User
+	byName  map[string]domain.User
+}
+
+func NewUserDirectory(baseURL, token string) *UserDirectory {
+	return &UserDirectory{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{},
+		byEmail: make(map[string]domain.User),
+		byName:  make(map[string]domain.User),
+	}
+}
+
+func (d *UserDirectory) FindByEmail(ctx context.Context, email string) (domain.User, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	d.mu.Lock()
+	user, ok := d.byEmail[email]
+	d.mu.Unlock()
+	if ok {
+		return user, nil
+	}
+
+	users, err := d.search(ctx, email)
+	if err != nil {
+		return domain.User{}, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Email, email) {
+			d.mu.Lock()
+			d.byEmail[email] = u
+			d.mu.Unlock()
+			return u, nil
+		}
+	}
+	return domain.User{}, fmt.Errorf("%w: %s", ErrUserNotFound, email)
+}
+
+func (d *UserDirectory) FindByName(ctx context.Context, name string) (domain.User, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	d.mu.Lock()
+	user, ok := d.byName[name]
+	d.mu.Unlock()
+	if ok {
+		return user, nil
+	}
+
+	users, err := d.search(ctx, name)
+	if err != nil {
+		return domain.User{}, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Name, name) {
+			d.mu.Lock()
+			d.byName[name] = u
+			d.mu.Unlock()
+			return u, nil
+		}
+	}
+	return domain.User{}, fmt.Errorf("%w: %s", ErrUserNotFound, name)
+}
+
+// search calls GET /users?search=<term>; the auth service matches name or email with LIKE.
+func (d *UserDirectory) search(ctx context.Context, term string) ([]domain.User, error) {
+	u := fmt.Sprintf("%s/users?search=%s", d.baseURL, url.QueryEscape(term))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", d.token)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("users search status: %d", resp.StatusCode)
+	}
+
+	var raw []struct {
+		ID    uint   `json:"id"`
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	users := make([]domain.User, 0, len(raw))
+	for _, item := range raw {
+		users = append(users, domain.User{ID: item.ID, Email: item.Email, Name: item.Name})
+	}
+	return users, nil
+}
+
+var _ ports.UserDirectory = (*UserDirectory)(nil)
@@ -0,0 +1,305 @@
+// Package backup reads and writes backup archives of the sharded task store.
+//
+// An archive is a gzipped tar with, in this order:
+//
+//	manifest.json             format version, schema version, shards, ID counter
+//	shards/{id}/tasks.jsonl   one Task per line, ordered by ID
+//	shards/{id}/views.jsonl   the shard's saved views (format 2 on)
+//	directory.jsonl           the task->shard directory
+//
+// The manifest comes first, so a reader can refuse an archive before reading any rows.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"tasks/internal/infrastructure/persistence"
+	"time"
+)
+
+// FormatVersion is the archive layout this package writes. Readers accept it and older ones.
+// Format 2 added the saved views; builds that read format 1 only would skip them.
+const FormatVersion = 2
+
+const (
+	manifestName  = "manifest.json"
+	directoryName = "directory.jsonl"
+	tasksName     = "tasks.jsonl"
+	viewsName     = "views.jsonl"
+)
+
+// ErrInvalidArchive means the input is not a backup archive this package can read.
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// Manifest describes an archive.
+type Manifest struct {
+	Format        int       `json:"format"`
+	CreatedAt     time.Time `json:"created_at"`
+	SchemaVersion int       `json:"schema_version"`
+	ShardKey      string    `json:"shard_key,omitempty"`
+	Shards        []Shard   `json:"shards"`
+	// IDCounter is the Redis task ID counter when the backup was taken; MaxTaskID is the
+	// largest task ID in the archive.
+	IDCounter        uint `json:"id_counter"`
+	MaxTaskID        uint `json:"max_task_id"`
+	DirectoryEntries int  `json:"directory_entries"`
+	// SkippedMoves counts copies of tasks that were being moved between shards; only the
+	// copy the move would keep is in the archive.
+	SkippedMoves int `json:"skipped_moves,omitempty"`
+}
+
+// Shard is a shard of the source store.
+type Shard struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Tasks int    `json:"tasks"`
+	Views int    `json:"views,omitempty"`
+}
+
+// Task is a task row with its observers' user IDs and its sent reminders.
+type Task struct {
+	persistence.Task
+	Observers []uint
+	Reminders []persistence.Reminder `json:",omitempty"`
+}
+
+// DirectoryEntry is a task->shard mapping; Shard is an index of the source store.
+type DirectoryEntry struct {
+	TaskID uint `json:"task_id"`
+	Shard  int  `json:"shard"`
+}
+
+// Writer collects the rows of an archive in a temporary directory; Finish writes them
+// behind the manifest, whose counts are only known at the end.
+type Writer struct {
+	dir   string
+	files map[string]*jsonlFile
+	names []string
+}
+
+type jsonlFile struct {
+	f   *os.File
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+// NewWriter creates a writer. Call Finish or Discard to remove its temporary files.
+func NewWriter() (*Writer, error) {
+	dir, err := os.MkdirTemp("", "tasks-backup-")
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{dir: dir, files: make(map[string]*jsonlFile)}, nil
+}
+
+// AddTask appends a task of shard shardID.
+func (w *Writer) AddTask(shardID string, t Task) error {
+	return w.write(path.Join("shards", shardID, tasksName), t)
+}
+
+// AddView appends a saved view of shard shardID.
+func (w *Writer) AddView(shardID string, v persistence.View) error {
+	return w.write(path.Join("shards", shardID, viewsName), v)
+}
+
+// AddDirectoryEntry appends a directory entry.
+func (w *Writer) AddDirectoryEntry(e DirectoryEntry) error {
+	return w.write(directoryName, e)
+}
+
+func (w *Writer) write(name string, v any) error {
+	f, ok := w.files[name]
+	if !ok {
+		file, err := os.Create(filepath.Join(w.dir, fmt.Sprintf("%d.jsonl", len(w.names))))
+		if err != nil {
+			return err
+		}
+		buf := bufio.NewWriter(file)
+		f = &jsonlFile{f: file, buf: buf, enc: json.NewEncoder(buf)}
+		w.files[name] = f
+		w.names = append(w.names, name)
+	}
+	return f.enc.Encode(v)
+}
+
+// Finish writes the archive with manifest m to out and removes the temporary files.
+// The directory is written last, whatever the order of the calls.
+func (w *Writer) Finish(out io.Writer, m Manifest) error {
+	defer w.Discard()
+	m.Format = FormatVersion
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0o644, Size: int64(len(data)), ModTime: m.CreatedAt}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(data); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(w.names))
+	for _, name := range w.names {
+		if name != directoryName {
+			names = append(names, name)
+		}
+	}
+	if _, ok := w.files[directoryName]; ok {
+		names = append(names, directoryName)
+	}
+	for _, name := range names {
+		if err := w.copyFile(tw, name, m.CreatedAt); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (w *Writer) copyFile(tw *tar.Writer, name string, modTime time.Time) error {
+	f := w.files[name]
+	if err := f.buf.Flush(); err != nil {
+		return err
+	}
+	info, err := f.f.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := f.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: info.Size(), ModTime: modTime}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f.f)
+	return err
+}
+
+// Discard removes the temporary files without writing an archive.
+func (w *Writer) Discard() {
+	for _, f := range w.files {
+		f.f.Close()
+	}
+	w.files = map[string]*jsonlFile{}
+	os.RemoveAll(w.dir)
+}
+
+// Reader reads an archive front to back.
+type Reader struct {
+	gz       *gzip.Reader
+	tr       *tar.Reader
+	manifest Manifest
+}
+
+// NewReader reads the manifest of the archive in r and checks its format.
+func NewReader(r io.Reader) (*Reader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	tr := tar.NewReader(gz)
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if hdr.Name != manifestName {
+		return nil, fmt.Errorf("%w: starts with %s, not %s", ErrInvalidArchive, hdr.Name, manifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: manifest: %v", ErrInvalidArchive, err)
+	}
+	if m.Format < 1 || m.Format > FormatVersion {
+		return nil, fmt.Errorf("%w: format %d, this build reads up to %d", ErrInvalidArchive, m.Format, FormatVersion)
+	}
+	return &Reader{gz: gz, tr: tr, manifest: m}, nil
+}
+
+// Manifest returns the archive's manifest.
+func (r *Reader) Manifest() Manifest {
+	return r.manifest
+}
+
+// Each calls task for every task and view for every saved view, with the ID of the shard
+// it was backed up from, and then entry for every directory entry. Any of them may be nil
+// to skip those rows.
+func (r *Reader) Each(task func(shardID string, t Task) error, view func(shardID string, v persistence.View) error, entry func(e DirectoryEntry) error) error {
+	for {
+		hdr, err := r.tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		switch {
+		case hdr.Name == directoryName:
+			err = eachLine(r.tr, func(data []byte) error {
+				var e DirectoryEntry
+				if err := json.Unmarshal(data, &e); err != nil || entry == nil {
+					return err
+				}
+				return entry(e)
+			})
+		case strings.HasPrefix(hdr.Name, "shards/") && path.Base(hdr.Name) == tasksName:
+			shardID := path.Base(path.Dir(hdr.Name))
+			err = eachLine(r.tr, func(data []byte) error {
+				var t Task
+				if err := json.Unmarshal(data, &t); err != nil || task == nil {
+					return err
+				}
+				return task(shardID, t)
+			})
+		case strings.HasPrefix(hdr.Name, "shards/") && path.Base(hdr.Name) == viewsName:
+			shardID := path.Base(path.Dir(hdr.Name))
+			err = eachLine(r.tr, func(data []byte) error {
+				var v persistence.View
+				if err := json.Unmarshal(data, &v); err != nil || view == nil {
+					return err
+				}
+				return view(shardID, v)
+			})
+		default:
+			// unknown entries are skipped
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+	}
+}
+
+// Close releases the reader; it does not close the underlying reader.
+func (r *Reader) Close() error {
+	return r.gz.Close()
+}
+
+func eachLine(r io.Reader, fn func(data []byte) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		if err := fn(sc.Bytes()); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,134 @@
+package backup_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"tasks/internal/infrastructure/backup"
+	"tasks/internal/infrastructure/persistence"
+	"testing"
+	"time"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	due := created.Add(48 * time.Hour)
+	tasks := map[string][]backup.Task{
+		"a": {
+			{Task: persistence.Task{ID: 1, Title: "Write docs", PerformerId: 2, CreatorId: 1, TenantId: 7, Status: "pending", CreatedAt: created, UpdatedAt: created}, Observers: []uint{3, 4}},
+			{Task: persistence.Task{ID: 4, Title: "Ship", PerformerId: 2, CreatorId: 1, DueAt: &due, MentionIds: []uint{5}, CreatedAt: created, UpdatedAt: created},
+				Reminders: []persistence.Reminder{{ID: 9, TaskId: 4, Kind: "before", OffsetSeconds: 3600, DueAt: due, SentAt: created}}},
+		},
+		"b": {
+			{Task: persistence.Task{ID: 2, Title: "Review", PerformerId: 3, CreatorId: 1, CreatedAt: created, UpdatedAt: created}},
+		},
+	}
+	entries := []backup.DirectoryEntry{{TaskID: 1, Shard: 0}, {TaskID: 2, Shard: 1}, {TaskID: 4, Shard: 0}}
+	views := map[string][]persistence.View{
+		"b": {{ID: "5b0f6c1e-4bde-4a43-9c4b-0d2f6a1e9a01", Name: "Mine", OwnerId: 3, Filter: `{"performer_id":3}`, Columns: `[]`,
+			Visibility: "private", CreatedAt: created, UpdatedAt: created}},
+	}
+
+	w, err := backup.NewWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// directory entries first: the archive still lists them after the tasks
+	for _, e := range entries {
+		if err := w.AddDirectoryEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"a", "b"} {
+		for _, task := range tasks[id] {
+			if err := w.AddTask(id, task); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, v := range views[id] {
+			if err := w.AddView(id, v); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	m := backup.Manifest{CreatedAt: created, SchemaVersion: 3, ShardKey: "tenant", IDCounter: 10, MaxTaskID: 4, DirectoryEntries: len(entries),
+		Shards: []backup.Shard{{Index: 0, ID: "a", Tasks: 2}, {Index: 1, ID: "b", Tasks: 1, Views: 1}}}
+	var buf bytes.Buffer
+	if err := w.Finish(&buf, m); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := backup.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	m.Format = backup.FormatVersion
+	if got := r.Manifest(); !reflect.DeepEqual(got, m) {
+		t.Fatalf("manifest = %+v, want %+v", got, m)
+	}
+
+	gotTasks := make(map[string][]backup.Task)
+	gotViews := make(map[string][]persistence.View)
+	var gotEntries []backup.DirectoryEntry
+	err = r.Each(
+		func(shardID string, task backup.Task) error {
+			if len(gotEntries) > 0 {
+				t.Fatalf("task %d after directory entries", task.ID)
+			}
+			gotTasks[shardID] = append(gotTasks[shardID], task)
+			return nil
+		},
+		func(shardID string, v persistence.View) error {
+			if len(gotEntries) > 0 {
+				t.Fatalf("view %s after directory entries", v.ID)
+			}
+			gotViews[shardID] = append(gotViews[shardID], v)
+			return nil
+		},
+		func(e backup.DirectoryEntry) error {
+			gotEntries = append(gotEntries, e)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotEntries, entries) {
+		t.Fatalf("entries = %+v, want %+v", gotEntries, entries)
+	}
+	if !reflect.DeepEqual(gotViews, views) {
+		t.Fatalf("views = %+v, want %+v", gotViews, views)
+	}
+	for id, want := range tasks {
+		got := gotTasks[id]
+		if len(got) != len(want) {
+			t.Fatalf("shard %s: %d tasks, want %d", id, len(got), len(want))
+		}
+		for i := range want {
+			g, w := got[i], want[i]
+			if g.ID != w.ID || g.Title != w.Title || g.TenantId != w.TenantId || !g.UpdatedAt.Equal(w.UpdatedAt) ||
+				!reflect.DeepEqual(g.Observers, w.Observers) || !reflect.DeepEqual(g.MentionIds, w.MentionIds) ||
+				len(g.Reminders) != len(w.Reminders) || (w.DueAt != nil && !g.DueAt.Equal(*w.DueAt)) {
+				t.Fatalf("shard %s task %d = %+v, want %+v", id, w.ID, g, w)
+			}
+		}
+	}
+}
+
+func TestNewReaderRejectsOtherInput(t *testing.T) {
+	w, err := backup.NewWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := w.Finish(&buf, backup.Manifest{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backup.NewReader(&buf); err != nil {
+		t.Fatalf("empty archive: %v", err)
+	}
+
+	if _, err := backup.NewReader(bytes.NewReader([]byte("id,title\n1,x\n"))); !errors.Is(err, backup.ErrInvalidArchive) {
+		t.Fatalf("csv input: err = %v, want ErrInvalidArchive", err)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"tasks/internal/infrastructure/persistence"
@@ -14,23 +16,60 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-var redisClient *redis.Client
+var (
+	redisClient redis.UniversalClient
+	stopProbe   context.CancelFunc
+)
 
+// Task keys carry the task ID as a hash tag, task:{id} and task:shard:{id}, so that in a
+// cluster a task's cached copy and its shard entry are in the same slot.
 const (
 	taskIDCounterKey = "task:id_counter"
-	taskShardKeyFmt  = "task:shard:%d"
+	taskShardKeyFmt  = "task:shard:{%d}"
 )
 
+// InitRedisFromEnv connects to Redis as configured by RedisOptionsFromEnv. Bad settings
+// are fatal; an unreachable Redis is not. After REDIS_DOWN_AFTER (default 3) connection
+// errors in a row the service runs without it, skipping the cache, and reconnects once
+// it is back (probed every REDIS_PROBE_INTERVAL, default 2s).
 func InitRedisFromEnv() {
-	url := os.Getenv("REDIS_URL")
-	opts, err := redis.ParseURL(url)
+	opts, mode, err := RedisOptionsFromEnv()
 	if err != nil {
-		panic(err)
+		log.Fatalf("redis: %v", err)
+	}
+	interval := defaultProbeInterval
+	if s := os.Getenv("REDIS_PROBE_INTERVAL"); s != "" {
+		if interval, err = time.ParseDuration(s); err != nil || interval <= 0 {
+			log.Fatalf("REDIS_PROBE_INTERVAL: invalid duration %q", s)
+		}
+	}
+	redisHealth.downAfter = defaultDownAfter
+	if s := os.Getenv("REDIS_DOWN_AFTER"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			log.Fatalf("REDIS_DOWN_AFTER: invalid count %q", s)
+		}
+		redisHealth.downAfter = int32(n)
+	}
+
+	redisClient = newRedisClient(opts, mode)
+	redisClient.AddHook(redisHealth)
+	var ctx context.Context
+	ctx, stopProbe = context.WithCancel(context.Background())
+	go redisHealth.probe(ctx, redisClient, interval)
+
+	// tasks updated here during an outage could not be evicted
+	OnReconnect(evictMissed)
+	if mode != RedisCluster {
+		OnReconnect(renameLegacyKeys)
 	}
 
-	redisClient = redis.NewClient(opts)
-	if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
-		panic(err)
+	if err := redisClient.Ping(ctx).Err(); err != nil {
+		log.Printf("redis (%s) not reachable at startup, running without it: %v", mode, err)
+		return
+	}
+	if mode != RedisCluster {
+		renameLegacyKeys(ctx)
 	}
 }
 
@@ -38,6 +77,7 @@ func CloseRedis() error {
 	if redisClient == nil {
 		return nil
 	}
+	stopProbe()
 	return redisClient.Close()
 }
 
@@ -47,7 +87,7 @@ func IsNilError(err error) bool {
 }
 
 func CacheKey(taskID uint) string {
-	return fmt.Sprintf("task:%d", taskID)
+	return fmt.Sprintf("task:{%d}", taskID)
 }
 
 // AllocTaskID returns the next global task ID (Redis INCR).
@@ -59,9 +99,35 @@ func AllocTaskID(ctx context.Context) (uint, error) {
 	return uint(n), nil
 }
 
+// LastTaskID returns the last ID handed out by AllocTaskID, or 0 if it was never used.
+func LastTaskID(ctx context.Context) (uint, error) {
+	n, err := redisClient.Get(ctx, taskIDCounterKey).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return uint(n), nil
+}
+
+// raiseCounterScript sets KEYS[1] to ARGV[1] unless it already holds a larger value.
+var raiseCounterScript = redis.NewScript(`
+local current = tonumber(redis.call("GET", KEYS[1]) or "0")
+if current < tonumber(ARGV[1]) then
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+return 1`)
+
+// RaiseTaskIDCounter makes sure AllocTaskID hands out IDs above n, e.g. after a restore.
+func RaiseTaskIDCounter(ctx context.Context, n uint) error {
+	return raiseCounterScript.Run(ctx, redisClient, []string{taskIDCounterKey}, n).Err()
+}
+
 // SetTaskShard stores the task_id -> shard_index mapping (for GetTask/Update/Delete).
-func SetTaskShard(ctx context.Context, taskID uint, shardIndex int) error {
-	return redisClient.Set(ctx, fmt.Sprintf(taskShardKeyFmt, taskID), shardIndex, 0).Err()
+// A zero ttl keeps it forever.
+func SetTaskShard(ctx context.Context, taskID uint, shardIndex int, ttl time.Duration) error {
+	return redisClient.Set(ctx, fmt.Sprintf(taskShardKeyFmt, taskID), shardIndex, ttl).Err()
 }
 
 // GetTaskShard returns the shard index where the task lives. Returns error if not found.
@@ -77,6 +143,32 @@ func GetTaskShard(ctx context.Context, taskID uint) (int, error) {
 	return i, nil
 }
 
+// GetTaskShards returns the shard indexes of the tasks that have a mapping, in one
+// pipeline. Unparsable entries are returned as -1.
+func GetTaskShards(ctx context.Context, taskIDs []uint) (map[uint]int, error) {
+	pipe := redisClient.Pipeline()
+	gets := make([]*redis.StringCmd, len(taskIDs))
+	for i, id := range taskIDs {
+		gets[i] = pipe.Get(ctx, fmt.Sprintf(taskShardKeyFmt, id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	shards := make(map[uint]int, len(taskIDs))
+	for i, id := range taskIDs {
+		val, err := gets[i].Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		idx, err := strconv.Atoi(val)
+		if err != nil {
+			idx = -1
+		}
+		shards[id] = idx
+	}
+	return shards, nil
+}
+
 // DelTaskShard removes the mapping (on task delete or after migration update).
 func DelTaskShard(ctx context.Context, taskID uint) error {
 	return redisClient.Del(ctx, fmt.Sprintf(taskShardKeyFmt, taskID)).Err()
@@ -102,7 +194,216 @@ func GetTask(ctx context.Context, taskID uint) (*persistence.Task, error) {
 	return &task, nil
 }
 
-// DeleteTaskCache removes the cached serialized task by key.
+// taskInvalidatedChannel carries the IDs of tasks whose cached copies are stale, so
+// that every replica can drop its in-process copy.
+const taskInvalidatedChannel = "task:invalidated"
+
+var (
+	invalidationMu sync.RWMutex
+	invalidated    []func(taskID uint)
+)
+
+// OnTaskInvalidated registers fn to run in this process whenever DeleteTaskCache is
+// called here; other replicas learn of it through SubscribeTaskInvalidations.
+func OnTaskInvalidated(fn func(taskID uint)) {
+	invalidationMu.Lock()
+	defer invalidationMu.Unlock()
+	invalidated = append(invalidated, fn)
+}
+
+// DeleteTaskCache removes the cached serialized task and announces it on the
+// invalidation channel. Local copies are dropped even when Redis is unreachable.
 func DeleteTaskCache(ctx context.Context, taskID uint) error {
-	return redisClient.Del(ctx, CacheKey(taskID)).Err()
+	invalidationMu.RLock()
+	for _, fn := range invalidated {
+		fn(taskID)
+	}
+	invalidationMu.RUnlock()
+	_, err := redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, CacheKey(taskID))
+		pipe.Publish(ctx, taskInvalidatedChannel, taskID)
+		return nil
+	})
+	if err != nil {
+		missEviction(taskID)
+	}
+	return err
+}
+
+// maxMissed bounds the task IDs kept whose cached copy could not be deleted; if more
+// are missed, the whole task cache is flushed on reconnect.
+const maxMissed = 100_000
+
+// missed holds the tasks this replica changed without deleting their cached copy, so
+// that only those are evicted when Redis is back.
+var missed struct {
+	sync.Mutex
+	ids  map[uint]struct{}
+	lost bool
+}
+
+func missEviction(taskID uint) {
+	missed.Lock()
+	defer missed.Unlock()
+	if missed.ids == nil {
+		missed.ids = make(map[uint]struct{})
+	}
+	if len(missed.ids) >= maxMissed {
+		missed.lost = true
+		return
+	}
+	missed.ids[taskID] = struct{}{}
+}
+
+// evictMissed deletes the cached copies of the tasks whose eviction failed, or every
+// cached task if too many failed. Evictions that fail again are kept for the next time.
+func evictMissed(ctx context.Context) {
+	missed.Lock()
+	ids, lost := missed.ids, missed.lost
+	missed.ids, missed.lost = nil, false
+	missed.Unlock()
+	if lost {
+		if err := FlushTaskCache(ctx); err != nil {
+			log.Printf("redis: flush task cache after outage: %v", err)
+			missed.Lock()
+			missed.lost = true
+			missed.Unlock()
+		}
+		return
+	}
+	keys := make([]string, 0, len(ids))
+	for id := range ids {
+		keys = append(keys, CacheKey(id))
+	}
+	for start := 0; start < len(keys); start += 500 {
+		batch := keys[start:min(start+500, len(keys))]
+		// one DEL per key: in a cluster the keys of a batch are in different slots
+		_, err := redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range batch {
+				pipe.Del(ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("redis: evict tasks changed during outage: %v", err)
+			for id := range ids {
+				missEviction(id)
+			}
+			return
+		}
+	}
+}
+
+// SubscribeTaskInvalidations delivers the IDs announced by DeleteTaskCache on any
+// replica, this one included. Announcements made while the subscription reconnects are
+// lost. The subscription ends when ctx is cancelled.
+func SubscribeTaskInvalidations(ctx context.Context) <-chan uint {
+	sub := redisClient.Subscribe(ctx, taskInvalidatedChannel)
+	out := make(chan uint, 256)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+				id, err := strconv.ParseUint(msg.Payload, 10, 64)
+				if err != nil {
+					continue
+				}
+				select {
+				case out <- uint(id):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// ScanTaskShards calls fn for every task_id -> shard_index mapping. Unparsable entries
+// are reported with shard index -1.
+func ScanTaskShards(ctx context.Context, fn func(taskID uint, shardIndex int) error) error {
+	return scanKeys(ctx, "task:shard:{*}", func(ctx context.Context, node redis.UniversalClient, keys []string) error {
+		// one GET per key: in a cluster the keys of a batch are in different slots
+		pipe := node.Pipeline()
+		gets := make([]*redis.StringCmd, len(keys))
+		for i, key := range keys {
+			gets[i] = pipe.Get(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for i, key := range keys {
+			val, err := gets[i].Result()
+			if errors.Is(err, redis.Nil) {
+				continue // deleted since the scan saw it
+			}
+			var taskID uint
+			if _, err := fmt.Sscanf(key, taskShardKeyFmt, &taskID); err != nil {
+				continue
+			}
+			idx, err := strconv.Atoi(val)
+			if err != nil {
+				idx = -1
+			}
+			if err := fn(taskID, idx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FlushTaskCache deletes every cached task. It is for copies that may have gone stale
+// while Redis was unreachable and too many changes were missed to evict them one by one.
+func FlushTaskCache(ctx context.Context) error {
+	return deleteKeys(ctx, "task:{*}")
+}
+
+// FlushTaskShards deletes every task -> shard entry. Only for a directory whose durable
+// copy reloads them.
+func FlushTaskShards(ctx context.Context) error {
+	return deleteKeys(ctx, "task:shard:{*}")
+}
+
+func deleteKeys(ctx context.Context, pattern string) error {
+	return scanKeys(ctx, pattern, func(ctx context.Context, node redis.UniversalClient, keys []string) error {
+		pipe := node.Pipeline()
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+}
+
+// scanKeys calls fn with batches of the keys matching pattern, on every node holding keys.
+func scanKeys(ctx context.Context, pattern string, fn func(ctx context.Context, node redis.UniversalClient, keys []string) error) error {
+	return forEachNode(ctx, func(ctx context.Context, node redis.UniversalClient) error {
+		iter := node.Scan(ctx, 0, pattern, 500).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+			if len(keys) == 500 {
+				if err := fn(ctx, node, keys); err != nil {
+					return err
+				}
+				keys = keys[:0]
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+		return fn(ctx, node, keys)
+	})
 }
@@ -0,0 +1,318 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrUnavailable is returned without contacting Redis while it is known to be down.
+// Callers treat it like a cache miss where they can.
+var ErrUnavailable = errors.New("redis unavailable")
+
+// RedisMode is how the tasks service reaches Redis.
+type RedisMode string
+
+const (
+	RedisStandalone RedisMode = "standalone"
+	RedisSentinel   RedisMode = "sentinel"
+	RedisCluster    RedisMode = "cluster"
+)
+
+const (
+	defaultProbeInterval = 2 * time.Second
+	defaultDownAfter     = 3
+)
+
+// RedisOptionsFromEnv reads the Redis connection settings:
+//
+//	REDIS_MODE               standalone, sentinel or cluster (default standalone, or
+//	                         sentinel when REDIS_MASTER_NAME is set)
+//	REDIS_URL                standalone: redis://[user:password@]host:port[/db][?dial_timeout=1s...]
+//	                         cluster: the same, with more seed nodes as ?addr=host:port
+//	                         sentinel: password, db and timeouts of the master; the host is ignored
+//	REDIS_SENTINEL_ADDRS     sentinel: comma-separated sentinel host:port list
+//	REDIS_MASTER_NAME        sentinel: name of the monitored master
+//	REDIS_SENTINEL_PASSWORD  sentinel: password of the sentinels, if they require one
+func RedisOptionsFromEnv() (*redis.UniversalOptions, RedisMode, error) {
+	mode := RedisMode(os.Getenv("REDIS_MODE"))
+	if mode == "" {
+		mode = RedisStandalone
+		if os.Getenv("REDIS_MASTER_NAME") != "" {
+			mode = RedisSentinel
+		}
+	}
+	url := os.Getenv("REDIS_URL")
+	switch mode {
+	case RedisStandalone:
+		o, err := redis.ParseURL(url)
+		if err != nil {
+			return nil, "", fmt.Errorf("REDIS_URL: %w", err)
+		}
+		return universalOptions(o), mode, nil
+	case RedisCluster:
+		o, err := redis.ParseClusterURL(url)
+		if err != nil {
+			return nil, "", fmt.Errorf("REDIS_URL: %w", err)
+		}
+		return &redis.UniversalOptions{
+			Addrs:           o.Addrs,
+			ClientName:      o.ClientName,
+			Username:        o.Username,
+			Password:        o.Password,
+			MaxRetries:      o.MaxRetries,
+			MinRetryBackoff: o.MinRetryBackoff,
+			MaxRetryBackoff: o.MaxRetryBackoff,
+			DialTimeout:     o.DialTimeout,
+			ReadTimeout:     o.ReadTimeout,
+			WriteTimeout:    o.WriteTimeout,
+			PoolFIFO:        o.PoolFIFO,
+			PoolSize:        o.PoolSize,
+			PoolTimeout:     o.PoolTimeout,
+			MinIdleConns:    o.MinIdleConns,
+			MaxIdleConns:    o.MaxIdleConns,
+			ConnMaxIdleTime: o.ConnMaxIdleTime,
+			ConnMaxLifetime: o.ConnMaxLifetime,
+			TLSConfig:       o.TLSConfig,
+			MaxRedirects:    o.MaxRedirects,
+			ReadOnly:        o.ReadOnly,
+			RouteByLatency:  o.RouteByLatency,
+			RouteRandomly:   o.RouteRandomly,
+		}, mode, nil
+	case RedisSentinel:
+		master := os.Getenv("REDIS_MASTER_NAME")
+		if master == "" {
+			return nil, "", errors.New("REDIS_MASTER_NAME is required in sentinel mode")
+		}
+		var sentinels []string
+		for _, addr := range strings.Split(os.Getenv("REDIS_SENTINEL_ADDRS"), ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				sentinels = append(sentinels, addr)
+			}
+		}
+		if len(sentinels) == 0 {
+			return nil, "", errors.New("REDIS_SENTINEL_ADDRS is required in sentinel mode")
+		}
+		opts := &redis.UniversalOptions{}
+		if url != "" {
+			o, err := redis.ParseURL(url)
+			if err != nil {
+				return nil, "", fmt.Errorf("REDIS_URL: %w", err)
+			}
+			opts = universalOptions(o)
+		}
+		opts.Addrs = sentinels
+		opts.MasterName = master
+		opts.SentinelPassword = os.Getenv("REDIS_SENTINEL_PASSWORD")
+		return opts, mode, nil
+	}
+	return nil, "", fmt.Errorf("REDIS_MODE: unknown mode %q; want standalone, sentinel or cluster", mode)
+}
+
+// Configured reports whether Redis settings are present in the environment.
+func Configured() bool {
+	return os.Getenv("REDIS_URL") != "" || os.Getenv("REDIS_SENTINEL_ADDRS") != ""
+}
+
+func universalOptions(o *redis.Options) *redis.UniversalOptions {
+	return &redis.UniversalOptions{
+		Addrs:           []string{o.Addr},
+		ClientName:      o.ClientName,
+		DB:              o.DB,
+		Protocol:        o.Protocol,
+		Username:        o.Username,
+		Password:        o.Password,
+		MaxRetries:      o.MaxRetries,
+		MinRetryBackoff: o.MinRetryBackoff,
+		MaxRetryBackoff: o.MaxRetryBackoff,
+		DialTimeout:     o.DialTimeout,
+		ReadTimeout:     o.ReadTimeout,
+		WriteTimeout:    o.WriteTimeout,
+		PoolFIFO:        o.PoolFIFO,
+		PoolSize:        o.PoolSize,
+		PoolTimeout:     o.PoolTimeout,
+		MinIdleConns:    o.MinIdleConns,
+		MaxIdleConns:    o.MaxIdleConns,
+		ConnMaxIdleTime: o.ConnMaxIdleTime,
+		ConnMaxLifetime: o.ConnMaxLifetime,
+		TLSConfig:       o.TLSConfig,
+	}
+}
+
+func newRedisClient(opts *redis.UniversalOptions, mode RedisMode) redis.UniversalClient {
+	switch mode {
+	case RedisCluster:
+		return redis.NewClusterClient(opts.Cluster())
+	case RedisSentinel:
+		return redis.NewFailoverClient(opts.Failover())
+	}
+	return redis.NewClient(opts.Simple())
+}
+
+// health tracks whether Redis is reachable. downAfter consecutive connection errors mark
+// it down, so one dropped connection does not; from then on commands fail with
+// ErrUnavailable at once, so requests skip the cache instead of waiting for timeouts,
+// until the probe reaches Redis again.
+type health struct {
+	down       atomic.Bool
+	failures   atomic.Int32 // consecutive connection errors
+	downAfter  int32
+	mu         sync.Mutex
+	reconnects []func(ctx context.Context)
+}
+
+var redisHealth = &health{downAfter: defaultDownAfter}
+
+type probeKey struct{}
+
+func (h *health) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (h *health) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if h.down.Load() && ctx.Value(probeKey{}) == nil {
+			cmd.SetErr(ErrUnavailable)
+			return ErrUnavailable
+		}
+		err := next(ctx, cmd)
+		h.observe(ctx, err)
+		return err
+	}
+}
+
+func (h *health) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if h.down.Load() && ctx.Value(probeKey{}) == nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrUnavailable)
+			}
+			return ErrUnavailable
+		}
+		err := next(ctx, cmds)
+		h.observe(ctx, err)
+		return err
+	}
+}
+
+// observe counts errors that mean Redis could not be reached and marks it down after
+// downAfter of them in a row. Any answer from Redis, including missing keys and server
+// error replies, resets the count; the caller's own cancellation and waits for a busy
+// connection pool are ignored.
+func (h *health) observe(ctx context.Context, err error) {
+	var reply redis.Error
+	if err == nil || errors.Is(err, redis.Nil) || errors.As(err, &reply) {
+		h.failures.Store(0)
+		return
+	}
+	if isPoolTimeout(err) || ctx.Err() != nil {
+		return
+	}
+	if h.failures.Add(1) < h.downAfter {
+		return
+	}
+	if h.down.CompareAndSwap(false, true) {
+		log.Printf("[redis] unreachable, running without it: %v", err)
+	}
+}
+
+// isPoolTimeout reports a wait for a free pooled connection that timed out. go-redis
+// keeps that error in an internal package, so it is matched by its message.
+func isPoolTimeout(err error) bool {
+	return err.Error() == "redis: connection pool timeout"
+}
+
+// probe pings Redis while it is down and, once it answers, runs the reconnect callbacks
+// and lets commands through again.
+func (h *health) probe(ctx context.Context, client redis.UniversalClient, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !h.down.Load() {
+			continue
+		}
+		pctx, cancel := context.WithTimeout(context.WithValue(ctx, probeKey{}, true), interval)
+		err := client.Ping(pctx).Err()
+		cancel()
+		if err != nil {
+			continue
+		}
+		h.mu.Lock()
+		callbacks := h.reconnects
+		h.mu.Unlock()
+		for _, fn := range callbacks {
+			fn(context.WithValue(ctx, probeKey{}, true))
+		}
+		h.failures.Store(0)
+		h.down.Store(false)
+		log.Printf("[redis] reachable again")
+	}
+}
+
+// Available reports whether Redis is believed to be reachable.
+func Available() bool {
+	return redisClient != nil && !redisHealth.down.Load()
+}
+
+// OnReconnect registers fn to run when Redis is reachable again after an outage, before
+// other commands are let through, e.g. to drop cache entries that went stale meanwhile.
+func OnReconnect(fn func(ctx context.Context)) {
+	redisHealth.mu.Lock()
+	defer redisHealth.mu.Unlock()
+	redisHealth.reconnects = append(redisHealth.reconnects, fn)
+}
+
+// forEachNode calls fn with every node holding keys: each master of a cluster, or the client.
+func forEachNode(ctx context.Context, fn func(ctx context.Context, node redis.UniversalClient) error) error {
+	if cluster, ok := redisClient.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return fn(ctx, node)
+		})
+	}
+	return fn(ctx, redisClient)
+}
+
+// keyLayoutKey records that the keys of the layout before hash tags were renamed.
+const keyLayoutKey = "cache:key_layout"
+
+func renameLegacyKeys(ctx context.Context) {
+	if err := migrateLegacyKeys(ctx); err != nil {
+		log.Printf("redis: rename keys of the previous layout: %v", err)
+	}
+}
+
+// migrateLegacyKeys renames the durable keys of the layout without hash tags, the
+// rebalance checkpoints and task:shard:{id} entries, once per Redis. Cached tasks and
+// leases are short-lived and left to expire.
+func migrateLegacyKeys(ctx context.Context) error {
+	if n, err := redisClient.Exists(ctx, keyLayoutKey).Result(); err != nil || n > 0 {
+		return err
+	}
+	err := redisClient.RenameNX(ctx, "shard:rebalance:checkpoints", rebalanceCheckpointsKey).Err()
+	if err != nil && !strings.Contains(err.Error(), "no such key") {
+		return err
+	}
+	err = scanKeys(ctx, "task:shard:[0-9]*", func(ctx context.Context, node redis.UniversalClient, keys []string) error {
+		pipe := node.Pipeline()
+		for _, key := range keys {
+			pipe.RenameNX(ctx, key, "task:shard:{"+strings.TrimPrefix(key, "task:shard:")+"}")
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return redisClient.Set(ctx, keyLayoutKey, 2, 0).Err()
+}
@@ -0,0 +1,54 @@
+package cache_test
+
+import (
+	"slices"
+	"tasks/internal/infrastructure/cache"
+	"testing"
+	"time"
+)
+
+func TestRedisOptionsFromEnv(t *testing.T) {
+	t.Setenv("REDIS_URL", "redis://:secret@redis:6379/2?dial_timeout=1s")
+	opts, mode, err := cache.RedisOptionsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode != cache.RedisStandalone || !slices.Equal(opts.Addrs, []string{"redis:6379"}) || opts.DB != 2 || opts.Password != "secret" || opts.DialTimeout != time.Second {
+		t.Fatalf("standalone: mode %s, options %+v", mode, opts)
+	}
+
+	t.Setenv("REDIS_MASTER_NAME", "tasks")
+	t.Setenv("REDIS_SENTINEL_ADDRS", "s1:26379, s2:26379")
+	opts, mode, err = cache.RedisOptionsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode != cache.RedisSentinel || !slices.Equal(opts.Addrs, []string{"s1:26379", "s2:26379"}) || opts.MasterName != "tasks" || opts.DB != 2 || opts.Password != "secret" {
+		t.Fatalf("sentinel: mode %s, options %+v", mode, opts)
+	}
+
+	t.Setenv("REDIS_MODE", "cluster")
+	t.Setenv("REDIS_URL", "redis://node1:6379?addr=node2:6379&addr=node3:6379")
+	opts, mode, err = cache.RedisOptionsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode != cache.RedisCluster || !slices.Equal(opts.Addrs, []string{"node1:6379", "node2:6379", "node3:6379"}) || opts.MasterName != "" {
+		t.Fatalf("cluster: mode %s, options %+v", mode, opts)
+	}
+
+	for name, env := range map[string]map[string]string{
+		"unknown mode":      {"REDIS_MODE": "proxy"},
+		"sentinel no addrs": {"REDIS_MODE": "sentinel", "REDIS_SENTINEL_ADDRS": ""},
+		"cluster bad url":   {"REDIS_MODE": "cluster", "REDIS_URL": "http://node1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range env {
+				t.Setenv(k, v)
+			}
+			if _, _, err := cache.RedisOptionsFromEnv(); err == nil {
+				t.Fatal("accepted")
+			}
+		})
+	}
+}
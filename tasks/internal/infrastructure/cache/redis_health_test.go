@@ -0,0 +1,86 @@
+package cache_test
+
+import (
+	"context"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/persistence"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisOutage(t *testing.T) {
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_URL", "redis://"+mr.Addr())
+	t.Setenv("REDIS_PROBE_INTERVAL", "10ms")
+	t.Setenv("REDIS_DOWN_AFTER", "3")
+	cache.InitRedisFromEnv()
+	t.Cleanup(func() { _ = cache.CloseRedis() })
+	ctx := context.Background()
+
+	for _, id := range []uint{1, 2} {
+		if err := cache.SetTask(ctx, persistence.Task{ID: id, Title: "cached"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mr.Close()
+	for i := 1; i <= 3; i++ {
+		if _, err := cache.GetTask(ctx, 2); err == nil {
+			t.Fatal("GetTask succeeded with redis stopped")
+		}
+		if down := !cache.Available(); down != (i == 3) {
+			t.Fatalf("after %d failed calls: down = %v", i, down)
+		}
+	}
+	// task 1 changes during the outage; its cached copy cannot be evicted
+	if err := cache.DeleteTaskCache(ctx, 1); err == nil {
+		t.Fatal("DeleteTaskCache succeeded with redis down")
+	}
+
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !cache.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("redis not marked reachable after restart")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if mr.Exists(cache.CacheKey(1)) {
+		t.Fatal("cached copy of the task changed during the outage was kept")
+	}
+	if !mr.Exists(cache.CacheKey(2)) {
+		t.Fatal("cached copy of an unchanged task was flushed")
+	}
+}
+
+func TestRedisReplyResetsFailures(t *testing.T) {
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_URL", "redis://"+mr.Addr()+"?max_retries=-1")
+	t.Setenv("REDIS_DOWN_AFTER", "2")
+	cache.InitRedisFromEnv()
+	t.Cleanup(func() { _ = cache.CloseRedis() })
+	ctx := context.Background()
+
+	// connection errors separated by an answer from Redis are not in a row
+	mr.Close()
+	if _, err := cache.GetTask(ctx, 1); err == nil {
+		t.Fatal("GetTask succeeded with redis stopped")
+	}
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetTask(ctx, 1); !cache.IsNilError(err) {
+		t.Fatalf("GetTask of a missing task: %v", err)
+	}
+	mr.Close()
+	if _, err := cache.GetTask(ctx, 1); err == nil {
+		t.Fatal("GetTask succeeded with redis stopped")
+	}
+	if !cache.Available() {
+		t.Fatal("redis marked down after single failures")
+	}
+}
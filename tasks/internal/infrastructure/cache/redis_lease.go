@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrFenced is returned by fenced writes when the caller's lease token is no longer current.
+var ErrFenced = errors.New("lease lost: fencing token is stale")
+
+// A lease is stored as "owner|token" under leader:{name}. The token comes from the
+// counter leader:{name}:fence and grows with every new holder, so a writer that lost
+// its lease (e.g. paused past the TTL) can be told apart from the current one. The name
+// is a hash tag: in a cluster the lease, its counter and the data it fences (tagged
+// the same way) share a slot, as the scripts below need.
+func leaseKey(name string) string { return "leader:{" + name + "}" }
+
+func leaseFenceKey(name string) string { return "leader:{" + name + "}:fence" }
+
+// acquireLeaseScript returns the holder's token, taking the lease with a new token if it
+// is free and extending it if ARGV[1] already holds it. It returns 0 if someone else holds it.
+var acquireLeaseScript = redis.NewScript(`
+local cur = redis.call("GET", KEYS[1])
+if cur then
+	local owner, token = string.match(cur, "^(.*)|(%d+)$")
+	if owner == ARGV[1] then
+		redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		return tonumber(token)
+	end
+	return 0
+end
+local token = redis.call("INCR", KEYS[2])
+redis.call("SET", KEYS[1], ARGV[1] .. "|" .. token, "PX", ARGV[2])
+return token`)
+
+var releaseLeaseScript = redis.NewScript(`
+local cur = redis.call("GET", KEYS[1])
+if cur and string.match(cur, "^(.*)|%d+$") == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// fencedHashScript runs HSET (ARGV[2] = "set") or HDEL on KEYS[2] only while the lease
+// in KEYS[1] still carries token ARGV[1]. It returns -1 when the token is stale.
+var fencedHashScript = redis.NewScript(`
+local cur = redis.call("GET", KEYS[1])
+if not cur or string.match(cur, "|(%d+)$") ~= ARGV[1] then
+	return -1
+end
+if ARGV[2] == "set" then
+	return redis.call("HSET", KEYS[2], ARGV[3], ARGV[4])
+end
+return redis.call("HDEL", KEYS[2], ARGV[3])`)
+
+// AcquireLease takes or extends the lease name for owner and returns its fencing token,
+// or 0 if another owner holds the lease.
+func AcquireLease(ctx context.Context, name, owner string, ttl time.Duration) (int64, error) {
+	return acquireLeaseScript.Run(ctx, redisClient,
+		[]string{leaseKey(name), leaseFenceKey(name)}, owner, ttl.Milliseconds()).Int64()
+}
+
+// ReleaseLease drops the lease name if owner holds it.
+func ReleaseLease(ctx context.Context, name, owner string) error {
+	return releaseLeaseScript.Run(ctx, redisClient, []string{leaseKey(name)}, owner).Err()
+}
+
+// LeaseToken returns the token of the current holder of lease name, or 0 if it is free.
+func LeaseToken(ctx context.Context, name string) (int64, error) {
+	v, err := redisClient.Get(ctx, leaseKey(name)).Result()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	for i := len(v) - 1; i >= 0; i-- {
+		if v[i] == '|' {
+			return strconv.ParseInt(v[i+1:], 10, 64)
+		}
+	}
+	return 0, nil
+}
+
+func fencedHash(ctx context.Context, lease string, token int64, op, key, field string, data []byte) error {
+	n, err := fencedHashScript.Run(ctx, redisClient,
+		[]string{leaseKey(lease), key}, strconv.FormatInt(token, 10), op, field, data).Int()
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return ErrFenced
+	}
+	return nil
+}
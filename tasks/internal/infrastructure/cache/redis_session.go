@@ -0,0 +1,20 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const userWriteKeyFmt = "session:wrote:%d"
+
+// MarkUserWrite records that the user wrote; the mark expires after window.
+func MarkUserWrite(ctx context.Context, userID uint, window time.Duration) error {
+	return redisClient.Set(ctx, fmt.Sprintf(userWriteKeyFmt, userID), 1, window).Err()
+}
+
+// UserWroteRecently reports whether the user wrote within the window of the last mark.
+func UserWroteRecently(ctx context.Context, userID uint) (bool, error) {
+	n, err := redisClient.Exists(ctx, fmt.Sprintf(userWriteKeyFmt, userID)).Result()
+	return n > 0, err
+}
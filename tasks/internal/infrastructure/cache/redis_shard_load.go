@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Request counts per shard and performer are summed over all replicas in one hash per
+// window, shard:load:{window start unix}, with fields "{shard}:{performer}".
+func shardLoadKey(window time.Time) string {
+	return "shard:load:" + strconv.FormatInt(window.Unix(), 10)
+}
+
+// AddShardLoad adds counts to the window's totals. The hash expires ttl after the write.
+func AddShardLoad(ctx context.Context, window time.Time, ttl time.Duration, counts map[string]int64) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	key := shardLoadKey(window)
+	pipe := redisClient.Pipeline()
+	for field, n := range counts {
+		pipe.HIncrBy(ctx, key, field, n)
+	}
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ShardLoad returns the window's totals; a window nobody reported in is empty.
+func ShardLoad(ctx context.Context, window time.Time) (map[string]int64, error) {
+	raw, err := redisClient.HGetAll(ctx, shardLoadKey(window)).Result()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(raw))
+	for field, v := range raw {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		counts[field] = n
+	}
+	return counts, nil
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	shardTopologyKey     = "shard:topology"
+	shardTopologyChannel = "shard:topology:changed"
+	rebalanceProgressKey = "shard:rebalance:progress"
+)
+
+// casTopologyScript replaces the topology only if its version still equals ARGV[1],
+// then announces the new version so replicas can reload without waiting for a poll.
+// The channel is an argument, not a key: a cluster only allows keys of one slot.
+var casTopologyScript = redis.NewScript(`
+local current = redis.call("HGET", KEYS[1], "version")
+if (current or "0") ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "version", ARGV[2], "data", ARGV[3])
+redis.call("PUBLISH", ARGV[4], ARGV[2])
+return 1`)
+
+// GetShardTopology returns the shared shard topology record and its version.
+// A missing record yields version 0 and nil data.
+func GetShardTopology(ctx context.Context) (int64, []byte, error) {
+	vals, err := redisClient.HMGet(ctx, shardTopologyKey, "version", "data").Result()
+	if err != nil {
+		return 0, nil, err
+	}
+	v, _ := vals[0].(string)
+	data, _ := vals[1].(string)
+	if v == "" {
+		return 0, nil, nil
+	}
+	version, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, nil, err
+	}
+	return version, []byte(data), nil
+}
+
+// CompareAndSetShardTopology stores data as version next if the stored version is still prev.
+func CompareAndSetShardTopology(ctx context.Context, prev, next int64, data []byte) (bool, error) {
+	n, err := casTopologyScript.Run(ctx, redisClient,
+		[]string{shardTopologyKey},
+		strconv.FormatInt(prev, 10), strconv.FormatInt(next, 10), string(data), shardTopologyChannel).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// SubscribeShardTopology signals on the returned channel whenever the topology changes.
+// The subscription ends when ctx is cancelled.
+func SubscribeShardTopology(ctx context.Context) <-chan struct{} {
+	sub := redisClient.Subscribe(ctx, shardTopologyChannel)
+	out := make(chan struct{}, 1)
+	go func() {
+		defer close(out)
+		defer sub.Close()
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-msgs:
+				if !ok {
+					return
+				}
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return out
+}
+
+// SetRebalanceProgress stores the serialized rebalance progress for any replica to read.
+func SetRebalanceProgress(ctx context.Context, data []byte) error {
+	return redisClient.Set(ctx, rebalanceProgressKey, data, 0).Err()
+}
+
+// GetRebalanceProgress returns the serialized rebalance progress, or redis.Nil if none was recorded.
+func GetRebalanceProgress(ctx context.Context) ([]byte, error) {
+	return redisClient.Get(ctx, rebalanceProgressKey).Bytes()
+}
+
+// rebalanceCheckpointsKey is tagged like the rebalance lease (leader:{rebalance}) that
+// fences its writes.
+const rebalanceCheckpointsKey = "shard:rebalance:checkpoints:{rebalance}"
+
+// SetRebalanceCheckpoint stores the checkpoint of one performer move under field.
+// It fails with ErrFenced unless token is the current token of the lease.
+func SetRebalanceCheckpoint(ctx context.Context, lease string, token int64, field string, data []byte) error {
+	return fencedHash(ctx, lease, token, "set", rebalanceCheckpointsKey, field, data)
+}
+
+// ListRebalanceCheckpoints returns all unfinished performer moves by field.
+func ListRebalanceCheckpoints(ctx context.Context) (map[string]string, error) {
+	return redisClient.HGetAll(ctx, rebalanceCheckpointsKey).Result()
+}
+
+// DeleteRebalanceCheckpoint forgets a finished performer move. Like SetRebalanceCheckpoint it is fenced.
+func DeleteRebalanceCheckpoint(ctx context.Context, lease string, token int64, field string) error {
+	return fencedHash(ctx, lease, token, "del", rebalanceCheckpointsKey, field, nil)
+}
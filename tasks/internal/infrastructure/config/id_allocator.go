@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ID allocator kinds accepted in ID_ALLOCATOR.
+const (
+	IDAllocatorRedis     = "redis"
+	IDAllocatorRange     = "range"
+	IDAllocatorSnowflake = "snowflake"
+)
+
+// IDAllocator selects how new task IDs are generated.
+type IDAllocator struct {
+	Kind string
+	// RangeBase and RangeSize place shard i's IDs in (RangeBase+i*RangeSize, RangeBase+(i+1)*RangeSize].
+	RangeBase uint64
+	RangeSize uint64
+	// WorkerID is the snowflake worker number, or -1 to lease a free one from Redis.
+	WorkerID int
+}
+
+// IDAllocatorFromEnv reads the allocator settings:
+//
+//	ID_ALLOCATOR         redis (default), range or snowflake
+//	ID_RANGE_BASE        range: IDs start above this; must exceed every ID already issued
+//	ID_RANGE_SIZE        range: IDs per shard (default 1000000000000)
+//	SNOWFLAKE_WORKER_ID  snowflake: fixed worker number 0-31 (default: leased per replica)
+func IDAllocatorFromEnv() (IDAllocator, error) {
+	cfg := IDAllocator{Kind: strings.ToLower(strings.TrimSpace(os.Getenv("ID_ALLOCATOR"))), WorkerID: -1}
+	if cfg.Kind == "" {
+		cfg.Kind = IDAllocatorRedis
+	}
+	var err error
+	switch cfg.Kind {
+	case IDAllocatorRedis:
+	case IDAllocatorRange:
+		if cfg.RangeBase, err = uintEnv("ID_RANGE_BASE", "0"); err != nil {
+			return IDAllocator{}, err
+		}
+		if cfg.RangeSize, err = uintEnv("ID_RANGE_SIZE", "1000000000000"); err != nil {
+			return IDAllocator{}, err
+		}
+		if cfg.RangeSize == 0 {
+			return IDAllocator{}, fmt.Errorf("ID_RANGE_SIZE must be positive")
+		}
+	case IDAllocatorSnowflake:
+		if s := strings.TrimSpace(os.Getenv("SNOWFLAKE_WORKER_ID")); s != "" {
+			if cfg.WorkerID, err = strconv.Atoi(s); err != nil {
+				return IDAllocator{}, fmt.Errorf("SNOWFLAKE_WORKER_ID: %w", err)
+			}
+		}
+	default:
+		return IDAllocator{}, fmt.Errorf("ID_ALLOCATOR: unknown allocator %q", cfg.Kind)
+	}
+	return cfg, nil
+}
+
+func uintEnv(key, def string) (uint64, error) {
+	s := strings.TrimSpace(os.Getenv(key))
+	if s == "" {
+		s = def
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return n, nil
+}
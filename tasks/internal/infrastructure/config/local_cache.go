@@ -0,0 +1,33 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// LocalCache configures the in-process task cache in front of Redis.
+type LocalCache struct {
+	// Size is the number of tasks kept; 0 disables the local cache.
+	Size int
+	// TTL bounds how stale a copy can get when an invalidation is missed.
+	TTL time.Duration
+}
+
+// LocalCacheFromEnv reads the local cache settings:
+//
+//	TASK_CACHE_LOCAL_SIZE  tasks kept per replica (default 10000; 0 disables it)
+//	TASK_CACHE_LOCAL_TTL   how long a copy is kept (default 30s)
+func LocalCacheFromEnv() (LocalCache, error) {
+	size, err := uintEnv("TASK_CACHE_LOCAL_SIZE", "10000")
+	if err != nil {
+		return LocalCache{}, err
+	}
+	ttl, err := duration("TASK_CACHE_LOCAL_TTL", "30s")
+	if err != nil {
+		return LocalCache{}, err
+	}
+	if ttl <= 0 {
+		return LocalCache{}, fmt.Errorf("TASK_CACHE_LOCAL_TTL must be positive")
+	}
+	return LocalCache{Size: int(size), TTL: ttl}, nil
+}
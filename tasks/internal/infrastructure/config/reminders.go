@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"tasks/internal/domain"
+	"time"
+)
+
+// Reminders configures the due-date reminder scheduler.
+type Reminders struct {
+	Policy   domain.ReminderPolicy
+	Interval time.Duration
+	// LeaseTTL is the TTL of the "reminders" leader lease.
+	LeaseTTL time.Duration
+}
+
+// RemindersFromEnv reads the scheduler settings:
+//
+//	REMINDER_DUE_SOON_OFFSETS  comma-separated durations before the due date (default "24h,1h")
+//	REMINDER_OVERDUE_OFFSETS   comma-separated durations after the due date (default "0s,24h,72h")
+//	REMINDER_LOOKBACK          skip reminders that should have fired longer ago (default 1h)
+//	REMINDER_INTERVAL          how often to scan the shards (default 1m)
+//
+// The leader lease lives for three intervals, so a crashed leader is replaced quickly.
+func RemindersFromEnv() (Reminders, error) {
+	dueSoon, err := durationList("REMINDER_DUE_SOON_OFFSETS", "24h,1h")
+	if err != nil {
+		return Reminders{}, err
+	}
+	overdue, err := durationList("REMINDER_OVERDUE_OFFSETS", "0s,24h,72h")
+	if err != nil {
+		return Reminders{}, err
+	}
+	lookback, err := duration("REMINDER_LOOKBACK", "1h")
+	if err != nil {
+		return Reminders{}, err
+	}
+	interval, err := duration("REMINDER_INTERVAL", "1m")
+	if err != nil {
+		return Reminders{}, err
+	}
+	if interval <= 0 {
+		return Reminders{}, fmt.Errorf("REMINDER_INTERVAL must be positive")
+	}
+	if lookback < interval {
+		// a scan must not miss reminders that became due since the previous one
+		lookback = interval
+	}
+	return Reminders{
+		Policy:   domain.ReminderPolicy{DueSoon: dueSoon, Overdue: overdue, Lookback: lookback},
+		Interval: interval,
+		LeaseTTL: 3 * interval,
+	}, nil
+}
+
+func duration(key, def string) (time.Duration, error) {
+	s := os.Getenv(key)
+	if s == "" {
+		s = def
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return d, nil
+}
+
+func durationList(key, def string) ([]time.Duration, error) {
+	s := os.Getenv(key)
+	if s == "" {
+		s = def
+	}
+	var ds []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("%s: offset %s is negative", key, part)
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
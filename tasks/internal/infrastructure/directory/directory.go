@@ -0,0 +1,283 @@
+// Package directory keeps track of which shard holds each task.
+package directory
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/persistence"
+	"tasks/internal/infrastructure/schema"
+	"tasks/logger"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultCacheTTL = 24 * time.Hour
+	batchSize       = 500
+	// maxPending bounds the entries kept to be written or dropped once Redis is back.
+	maxPending = 100_000
+)
+
+// ErrNotFound means the directory has no entry for the task; callers fall back to probing the shards.
+var ErrNotFound = errors.New("task not in shard directory")
+
+// Directory maps task IDs to the index of the shard holding them. The durable copy is the
+// task_directory table; Redis (task:shard:{id}) is a read-through cache in front of it.
+// Without a database the Redis keys are the only copy, as before the directory existed.
+type Directory struct {
+	db       *gorm.DB
+	cacheTTL time.Duration
+
+	// Entries that could not be written to Redis wait in pending (-1 for a removal, and
+	// for any cached entry with a database) until it is back; see reconnected.
+	mu          sync.Mutex
+	pending     map[uint]int
+	pendingLost bool
+}
+
+func init() {
+	cache.OnReconnect(func(ctx context.Context) { Default.reconnected(ctx) })
+}
+
+// Default is used by the repository and the rebalancer. InitFromEnv replaces it.
+var Default = New(nil, 0)
+
+// New creates a directory backed by db. A nil db keeps the mapping in Redis only, without TTL.
+func New(db *gorm.DB, cacheTTL time.Duration) *Directory {
+	if db == nil {
+		cacheTTL = 0
+	}
+	return &Directory{db: db, cacheTTL: cacheTTL}
+}
+
+// InitFromEnv opens DIRECTORY_DB_URL, applies the directory migrations (schema.Directory)
+// and makes it the Default directory. DIRECTORY_CACHE_TTL (default 24h) bounds how long Redis caches an entry.
+// When DIRECTORY_DB_URL is not set the mapping stays in Redis only.
+func InitFromEnv() {
+	dsn := os.Getenv("DIRECTORY_DB_URL")
+	if dsn == "" {
+		log.Println("DIRECTORY_DB_URL not set: task->shard directory is kept in Redis only")
+		return
+	}
+	ttl := defaultCacheTTL
+	if s := os.Getenv("DIRECTORY_CACHE_TTL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			log.Fatalf("DIRECTORY_CACHE_TTL: %v", err)
+		}
+		ttl = d
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to connect to directory database: %v", err)
+	}
+	if _, err := schema.Directory.Up(context.Background(), db, 0); err != nil {
+		log.Fatalf("Failed to migrate directory database: %v", err)
+	}
+	Default = New(db, ttl)
+	log.Printf("Task directory initialized (cache ttl %s)", ttl)
+}
+
+// Durable reports whether entries survive a Redis restart.
+func (d *Directory) Durable() bool {
+	return d.db != nil
+}
+
+// Lookup returns the shard index of the task, or ErrNotFound.
+// If Redis is unavailable the durable directory still answers.
+func (d *Directory) Lookup(ctx context.Context, taskID uint) (int, error) {
+	idx, err := cache.GetTaskShard(ctx, taskID)
+	if err == nil {
+		return idx, nil
+	}
+	if d.db == nil {
+		// while Redis is down callers probe the shards, as for a task without an entry
+		if cache.IsNilError(err) || errors.Is(err, cache.ErrUnavailable) {
+			return -1, ErrNotFound
+		}
+		return -1, err
+	}
+	if !cache.IsNilError(err) && !errors.Is(err, cache.ErrUnavailable) {
+		logger.Warn(ctx, "directory: redis lookup failed, reading database", logger.ZapUint("task_id", taskID), logger.ZapError(err))
+	}
+
+	var loc persistence.TaskLocation
+	err = d.db.WithContext(ctx).Where("task_id = ?", taskID).Take(&loc).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return -1, ErrNotFound
+	}
+	if err != nil {
+		return -1, err
+	}
+	_ = cache.SetTaskShard(ctx, taskID, loc.ShardIndex, d.cacheTTL)
+	return loc.ShardIndex, nil
+}
+
+// Assign records that the tasks live on shardIndex. The durable entry is written first;
+// if Redis cannot be updated its entry is dropped so that it cannot point at the old shard.
+func (d *Directory) Assign(ctx context.Context, shardIndex int, taskIDs ...uint) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	if d.db != nil {
+		for start := 0; start < len(taskIDs); start += batchSize {
+			batch := taskIDs[start:min(start+batchSize, len(taskIDs))]
+			locs := make([]persistence.TaskLocation, len(batch))
+			for i, id := range batch {
+				locs[i] = persistence.TaskLocation{TaskId: id, ShardIndex: shardIndex}
+			}
+			err := d.db.WithContext(ctx).
+				Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "task_id"}},
+					DoUpdates: clause.AssignmentColumns([]string{"shard_index", "updated_at"}),
+				}).
+				Create(&locs).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, id := range taskIDs {
+		if err := cache.SetTaskShard(ctx, id, shardIndex, d.cacheTTL); err != nil {
+			if d.db == nil {
+				if errors.Is(err, cache.ErrUnavailable) {
+					d.queue(id, shardIndex)
+					continue
+				}
+				return err
+			}
+			if err := cache.DelTaskShard(ctx, id); err != nil {
+				d.queue(id, -1)
+			}
+			continue
+		}
+		d.unqueue(id)
+	}
+	return nil
+}
+
+// Remove forgets the tasks, e.g. when no shard holds them anymore.
+func (d *Directory) Remove(ctx context.Context, taskIDs ...uint) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	if d.db != nil {
+		if err := d.db.WithContext(ctx).Where("task_id IN ?", taskIDs).Delete(&persistence.TaskLocation{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, id := range taskIDs {
+		err := cache.DelTaskShard(ctx, id)
+		switch {
+		case err == nil:
+			d.unqueue(id)
+		case d.db != nil || errors.Is(err, cache.ErrUnavailable):
+			d.queue(id, -1)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// queue keeps a Redis-only entry to be written when Redis is back.
+func (d *Directory) queue(taskID uint, shardIndex int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending == nil {
+		d.pending = make(map[uint]int)
+	}
+	if _, ok := d.pending[taskID]; !ok && len(d.pending) >= maxPending {
+		d.pendingLost = true
+		return
+	}
+	d.pending[taskID] = shardIndex
+}
+
+// unqueue drops a queued entry that a later write to Redis replaced.
+func (d *Directory) unqueue(taskID uint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.pending, taskID)
+}
+
+// reconnected runs when Redis is reachable again. The entries this replica changed while
+// Redis was down are replayed: with a database their cached copies are dropped and
+// reloaded on lookup. If more changed than pending holds, a database lets every cached
+// entry be dropped; without one the lost entries need a rebuild.
+func (d *Directory) reconnected(ctx context.Context) {
+	d.mu.Lock()
+	pending, lost := d.pending, d.pendingLost
+	d.pending, d.pendingLost = nil, false
+	d.mu.Unlock()
+	if lost && d.db != nil {
+		if err := cache.FlushTaskShards(ctx); err != nil {
+			log.Printf("directory: flush cached entries after redis outage: %v", err)
+		}
+		return
+	}
+	for id, idx := range pending {
+		var err error
+		if idx < 0 {
+			err = cache.DelTaskShard(ctx, id)
+		} else {
+			err = cache.SetTaskShard(ctx, id, idx, 0)
+		}
+		if err != nil {
+			// Redis failed again; the rest waits for the next reconnect
+			log.Printf("directory: replay entry of task %d after redis outage: %v", id, err)
+			for id, idx := range pending {
+				d.queue(id, idx)
+			}
+			break
+		}
+		delete(pending, id)
+	}
+	if lost {
+		log.Printf("directory: entries written while redis was down were lost; run `taskctl rebuild-directory`")
+	}
+}
+
+// Entries returns the entries of the authoritative copy, the table or Redis without one,
+// for taskIDs. Tasks without an entry are missing from the map.
+func (d *Directory) Entries(ctx context.Context, taskIDs []uint) (map[uint]int, error) {
+	if d.db == nil {
+		return cache.GetTaskShards(ctx, taskIDs)
+	}
+	entries := make(map[uint]int, len(taskIDs))
+	for start := 0; start < len(taskIDs); start += batchSize {
+		var locs []persistence.TaskLocation
+		batch := taskIDs[start:min(start+batchSize, len(taskIDs))]
+		if err := d.db.WithContext(ctx).Where("task_id IN ?", batch).Find(&locs).Error; err != nil {
+			return nil, err
+		}
+		for _, loc := range locs {
+			entries[loc.TaskId] = loc.ShardIndex
+		}
+	}
+	return entries, nil
+}
+
+// Each calls fn for every entry of the authoritative copy: the table, or Redis without one.
+func (d *Directory) Each(ctx context.Context, fn func(taskID uint, shardIndex int) error) error {
+	if d.db == nil {
+		return cache.ScanTaskShards(ctx, fn)
+	}
+	var batch []persistence.TaskLocation
+	return d.db.WithContext(ctx).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			for _, loc := range batch {
+				if err := fn(loc.TaskId, loc.ShardIndex); err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
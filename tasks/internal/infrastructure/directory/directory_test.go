@@ -0,0 +1,162 @@
+package directory_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/directory"
+	"tasks/internal/infrastructure/persistence"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setup starts Redis, which is marked down at the first connection error and probed
+// every 10ms, and makes d the Default directory, whose hook replays entries on reconnect.
+func setup(t *testing.T, durable bool) (*directory.Directory, *gorm.DB, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_URL", "redis://"+mr.Addr())
+	t.Setenv("REDIS_PROBE_INTERVAL", "10ms")
+	t.Setenv("REDIS_DOWN_AFTER", "1")
+	cache.InitRedisFromEnv()
+	t.Cleanup(func() { _ = cache.CloseRedis() })
+
+	var db *gorm.DB
+	if durable {
+		var err error
+		db, err = gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "directory.db")), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AutoMigrate(&persistence.TaskLocation{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := directory.New(db, time.Hour)
+	previous := directory.Default
+	directory.Default = d
+	t.Cleanup(func() { directory.Default = previous })
+	return d, db, mr
+}
+
+// outage stops Redis, runs fn once it is marked down and waits until it is back.
+func outage(t *testing.T, mr *miniredis.Miniredis, fn func()) {
+	t.Helper()
+	mr.Close()
+	_, _ = cache.GetTaskShard(context.Background(), 0)
+	if cache.Available() {
+		t.Fatal("redis not marked down")
+	}
+	fn()
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !cache.Available() {
+		if time.Now().After(deadline) {
+			t.Fatal("redis not marked reachable after restart")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func wantLookup(t *testing.T, d *directory.Directory, taskID uint, want int) {
+	t.Helper()
+	got, err := d.Lookup(context.Background(), taskID)
+	if want < 0 {
+		if !errors.Is(err, directory.ErrNotFound) {
+			t.Fatalf("Lookup(%d) = %d, %v; want ErrNotFound", taskID, got, err)
+		}
+		return
+	}
+	if err != nil || got != want {
+		t.Fatalf("Lookup(%d) = %d, %v; want %d", taskID, got, err, want)
+	}
+}
+
+func TestDurableReadThrough(t *testing.T) {
+	d, _, mr := setup(t, true)
+	ctx := context.Background()
+	if err := d.Assign(ctx, 2, 10); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("task:shard:{10}"); ttl <= 0 || ttl > time.Hour {
+		t.Fatalf("cached entry TTL %s, want up to the cache TTL", ttl)
+	}
+
+	// an expired cache entry is reloaded from the table and cached again
+	mr.Del("task:shard:{10}")
+	wantLookup(t, d, 10, 2)
+	if v, err := mr.Get("task:shard:{10}"); err != nil || v != "2" {
+		t.Fatalf("entry not cached again: %q, %v", v, err)
+	}
+	wantLookup(t, d, 11, -1)
+
+	if err := d.Remove(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	wantLookup(t, d, 10, -1)
+
+	// the table answers while Redis is down
+	if err := d.Assign(ctx, 1, 12); err != nil {
+		t.Fatal(err)
+	}
+	outage(t, mr, func() { wantLookup(t, d, 12, 1) })
+}
+
+func TestDurableAssignDuringOutage(t *testing.T) {
+	d, db, mr := setup(t, true)
+	ctx := context.Background()
+	if err := d.Assign(ctx, 0, 5, 6); err != nil {
+		t.Fatal(err)
+	}
+	outage(t, mr, func() {
+		// task 5 moves; its cached entry cannot be updated or dropped meanwhile
+		if err := d.Assign(ctx, 1, 5); err != nil {
+			t.Fatalf("Assign with redis down: %v", err)
+		}
+		var loc persistence.TaskLocation
+		if err := db.Where("task_id = ?", 5).Take(&loc).Error; err != nil || loc.ShardIndex != 1 {
+			t.Fatalf("table entry %+v, %v; want shard 1", loc, err)
+		}
+	})
+	// on reconnect only the stale entry is dropped
+	if mr.Exists("task:shard:{5}") {
+		t.Fatal("stale cached entry kept after the outage")
+	}
+	if !mr.Exists("task:shard:{6}") {
+		t.Fatal("unchanged cached entry dropped after the outage")
+	}
+	wantLookup(t, d, 5, 1)
+	wantLookup(t, d, 6, 0)
+}
+
+func TestRedisOnlyReplaysPendingEntries(t *testing.T) {
+	d, _, mr := setup(t, false)
+	ctx := context.Background()
+	if err := d.Assign(ctx, 0, 7, 8); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("task:shard:{7}"); ttl != 0 {
+		t.Fatalf("entry TTL %s, want none without a table", ttl)
+	}
+	outage(t, mr, func() {
+		// lookups miss, so callers probe the shards
+		wantLookup(t, d, 7, -1)
+		if err := d.Assign(ctx, 3, 7, 9); err != nil {
+			t.Fatalf("Assign with redis down: %v", err)
+		}
+		if err := d.Remove(ctx, 8); err != nil {
+			t.Fatalf("Remove with redis down: %v", err)
+		}
+	})
+	wantLookup(t, d, 7, 3)
+	wantLookup(t, d, 9, 3)
+	wantLookup(t, d, 8, -1)
+}
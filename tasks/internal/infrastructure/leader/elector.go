@@ -0,0 +1,119 @@
+// Package leader elects one tasks replica to run each background job, using leases in
+// Redis with fencing tokens.
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"tasks/internal/infrastructure/cache"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotLeader is returned by Acquire when another replica holds the lease.
+var ErrNotLeader = errors.New("leader: lease held by another replica")
+
+// Owner identifies this process in every lease it takes.
+var Owner = func() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s-%s", host, uuid.NewString())
+}()
+
+// Term is one tenure of a lease. Token increases with every new holder; writes to shared
+// state should be fenced with it (see cache.SetRebalanceCheckpoint) or preceded by Check.
+type Term struct {
+	Name  string
+	Token int64
+}
+
+// Check returns cache.ErrFenced if the term is no longer the current holder of the lease.
+func (t Term) Check(ctx context.Context) error {
+	token, err := cache.LeaseToken(ctx, t.Name)
+	if err != nil {
+		return err
+	}
+	if token != t.Token {
+		return cache.ErrFenced
+	}
+	return nil
+}
+
+type termKey struct{ name string }
+
+// WithTerm returns a context carrying t, so code running under a job can reuse its lease.
+func WithTerm(ctx context.Context, t Term) context.Context {
+	return context.WithValue(ctx, termKey{t.Name}, t)
+}
+
+// TermFrom returns the term of lease name held by the caller, if any.
+func TermFrom(ctx context.Context, name string) (Term, bool) {
+	t, ok := ctx.Value(termKey{name}).(Term)
+	return t, ok
+}
+
+// Elector campaigns for one named lease.
+type Elector struct {
+	name string
+	ttl  time.Duration
+}
+
+// NewElector creates an elector for lease name. A holder that stops renewing loses the
+// lease after ttl.
+func NewElector(name string, ttl time.Duration) *Elector {
+	return &Elector{name: name, ttl: ttl}
+}
+
+// Acquire takes the lease and keeps renewing it until release is called. The returned
+// context carries the term and is cancelled as soon as the lease is lost, so work
+// running under it stops before another replica takes over.
+func (e *Elector) Acquire(ctx context.Context) (context.Context, Term, func(), error) {
+	token, err := cache.AcquireLease(ctx, e.name, Owner, e.ttl)
+	if err != nil {
+		return nil, Term{}, nil, err
+	}
+	if token == 0 {
+		return nil, Term{}, nil, ErrNotLeader
+	}
+	term := Term{Name: e.name, Token: token}
+
+	leaseCtx, cancel := context.WithCancel(WithTerm(ctx, term))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.keepAlive(leaseCtx, term, cancel)
+	}()
+
+	release := func() {
+		cancel()
+		<-done
+		_ = cache.ReleaseLease(context.WithoutCancel(ctx), e.name, Owner)
+	}
+	return leaseCtx, term, release, nil
+}
+
+// keepAlive renews the lease every third of its TTL and cancels the term when it is taken
+// over, or when Redis could not be reached for a whole TTL.
+func (e *Elector) keepAlive(ctx context.Context, term Term, cancel context.CancelFunc) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			token, err := cache.AcquireLease(ctx, e.name, Owner, e.ttl)
+			switch {
+			case err != nil && time.Since(renewed) < e.ttl:
+				continue
+			case err != nil || token != term.Token:
+				cancel()
+				return
+			}
+			renewed = time.Now()
+		}
+	}
+}
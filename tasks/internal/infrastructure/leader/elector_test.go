@@ -0,0 +1,119 @@
+package leader_test
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/leader"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func startRedis(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_URL", "redis://"+mr.Addr())
+	cache.InitRedisFromEnv()
+	t.Cleanup(func() { _ = cache.CloseRedis() })
+	return mr
+}
+
+func TestElectorTakeoverAfterTTL(t *testing.T) {
+	mr := startRedis(t)
+	ctx := context.Background()
+	// another replica holds the lease
+	other, err := cache.AcquireLease(ctx, "job", "other", time.Minute)
+	if err != nil || other == 0 {
+		t.Fatalf("AcquireLease = %d, %v", other, err)
+	}
+	e := leader.NewElector("job", time.Minute)
+	if _, _, _, err := e.Acquire(ctx); !errors.Is(err, leader.ErrNotLeader) {
+		t.Fatalf("Acquire while held: err = %v, want ErrNotLeader", err)
+	}
+	otherTerm := leader.Term{Name: "job", Token: other}
+	if err := otherTerm.Check(ctx); err != nil {
+		t.Fatalf("Check of the holder: %v", err)
+	}
+
+	// it stops renewing
+	mr.FastForward(time.Minute)
+	leaseCtx, term, release, err := e.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire after the TTL: %v", err)
+	}
+	defer release()
+	if term.Token <= other {
+		t.Fatalf("token %d after takeover, want more than %d", term.Token, other)
+	}
+	if got, ok := leader.TermFrom(leaseCtx, "job"); !ok || got != term {
+		t.Fatalf("TermFrom = %+v, %v; want %+v", got, ok, term)
+	}
+	if err := term.Check(ctx); err != nil {
+		t.Fatalf("Check of the new holder: %v", err)
+	}
+	if err := otherTerm.Check(ctx); !errors.Is(err, cache.ErrFenced) {
+		t.Fatalf("Check of the old holder: err = %v, want ErrFenced", err)
+	}
+}
+
+func TestElectorCancelsOnLoss(t *testing.T) {
+	mr := startRedis(t)
+	ctx := context.Background()
+	e := leader.NewElector("job", 30*time.Millisecond)
+	leaseCtx, term, release, err := e.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	// renewals keep the lease past its TTL
+	time.Sleep(60 * time.Millisecond)
+	if err := leaseCtx.Err(); err != nil {
+		t.Fatalf("lease context ended while renewed: %v", err)
+	}
+	if err := term.Check(ctx); err != nil {
+		t.Fatalf("Check while renewed: %v", err)
+	}
+
+	// the lease expired during a pause and another replica took it
+	mr.Del("leader:{job}")
+	if token, err := cache.AcquireLease(ctx, "job", "other", time.Minute); err != nil || token <= term.Token {
+		t.Fatalf("takeover: token %d, %v", token, err)
+	}
+	select {
+	case <-leaseCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("lease context not cancelled after the lease was lost")
+	}
+	if err := term.Check(ctx); !errors.Is(err, cache.ErrFenced) {
+		t.Fatalf("Check after loss: err = %v, want ErrFenced", err)
+	}
+}
+
+func TestElectorRelease(t *testing.T) {
+	startRedis(t)
+	ctx := context.Background()
+	e := leader.NewElector("job", time.Minute)
+	leaseCtx, term, release, err := e.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if leaseCtx.Err() == nil {
+		t.Fatal("lease context still open after release")
+	}
+	if token, err := cache.LeaseToken(ctx, "job"); err != nil || token != 0 {
+		t.Fatalf("LeaseToken after release = %d, %v; want 0", token, err)
+	}
+	// the next holder gets a new token
+	_, next, release, err := e.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if next.Token <= term.Token {
+		t.Fatalf("token %d after release, want more than %d", next.Token, term.Token)
+	}
+}
@@ -0,0 +1,90 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"tasks/logger"
+	"time"
+)
+
+// Job is periodic work that must run on one replica at a time.
+type Job struct {
+	// Name is also the lease name, e.g. "rebalance" or "reminders".
+	Name     string
+	Interval time.Duration
+	// TTL of the lease; defaults to three intervals.
+	TTL time.Duration
+	// Run does one pass. Its context carries the Term and is cancelled if the lease is lost.
+	Run func(ctx context.Context) error
+}
+
+// Registry runs registered jobs on whichever replica wins each job's lease. Every replica
+// registers the same jobs; on each tick the replicas race for the lease and the winner
+// runs one pass. Different jobs can run on different replicas.
+type Registry struct {
+	mu   sync.Mutex
+	jobs []Job
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a job. Jobs registered after Run has started are ignored.
+func (r *Registry) Register(job Job) {
+	if job.TTL <= 0 {
+		job.TTL = 3 * job.Interval
+	}
+	r.mu.Lock()
+	r.jobs = append(r.jobs, job)
+	r.mu.Unlock()
+}
+
+// Run runs every job on its interval until ctx is cancelled.
+func (r *Registry) Run(ctx context.Context) {
+	r.mu.Lock()
+	jobs := append([]Job(nil), r.jobs...)
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			runJob(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func runJob(ctx context.Context, job Job) {
+	elector := NewElector(job.Name, job.TTL)
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runOnce(ctx, elector, job)
+		}
+	}
+}
+
+func runOnce(ctx context.Context, elector *Elector, job Job) {
+	jobCtx, term, release, err := elector.Acquire(ctx)
+	if errors.Is(err, ErrNotLeader) {
+		return
+	}
+	if err != nil {
+		logger.Warn(ctx, "leader: campaign failed", logger.ZapString("job", job.Name), logger.ZapError(err))
+		return
+	}
+	defer release()
+
+	if err := job.Run(jobCtx); err != nil {
+		logger.Warn(ctx, "leader: job failed", logger.ZapString("job", job.Name),
+			logger.ZapInt("term", int(term.Token)), logger.ZapError(err))
+	}
+}
@@ -0,0 +1,78 @@
+package leader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/infrastructure/leader"
+	"testing"
+	"time"
+)
+
+func TestRegistryRunsUnderLease(t *testing.T) {
+	mr := startRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// another replica holds the lease at first
+	if _, err := cache.AcquireLease(ctx, "job", "other", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	var runs atomic.Int32
+	fails := make(chan string, 10)
+	fail := func(msg string) {
+		select {
+		case fails <- msg:
+		default:
+		}
+	}
+	r := leader.NewRegistry()
+	r.Register(leader.Job{Name: "job", Interval: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		term, ok := leader.TermFrom(ctx, "job")
+		switch {
+		case !ok:
+			fail("no term in the job context")
+		case term.Check(ctx) != nil:
+			fail("job runs without holding the lease")
+		case mr.TTL("leader:{job}") > 30*time.Millisecond:
+			fail("lease TTL is not three intervals")
+		}
+		runs.Add(1)
+		return nil
+	}})
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if n := runs.Load(); n != 0 {
+		t.Fatalf("job ran %d times while another replica held the lease", n)
+	}
+	if err := cache.ReleaseLease(ctx, "job", "other"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for runs.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("job ran %d times after the lease was free, want 2", runs.Load())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+	close(fails)
+	for msg := range fails {
+		t.Error(msg)
+	}
+	// each pass releases the lease
+	if token, err := cache.LeaseToken(context.Background(), "job"); err != nil || token != 0 {
+		t.Fatalf("LeaseToken after Run = %d, %v; want 0", token, err)
+	}
+}
@@ -0,0 +1,48 @@
+package lru_test
+
+import (
+	"tasks/internal/infrastructure/lru"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := lru.New[int, string](2, time.Hour)
+	c.Add(1, "a")
+	c.Add(2, "b")
+	if v, ok := c.Get(1); !ok || v != "a" {
+		t.Fatalf("Get(1) = %q, %v", v, ok)
+	}
+	// 2 is now the least recently used
+	c.Add(3, "c")
+	if _, ok := c.Get(2); ok {
+		t.Fatal("2 was not evicted")
+	}
+	if c.Len() != 2 || c.Evictions() != 1 {
+		t.Fatalf("len %d, evictions %d", c.Len(), c.Evictions())
+	}
+
+	c.Add(1, "a2")
+	if v, _ := c.Get(1); v != "a2" {
+		t.Fatalf("Get(1) after replace = %q", v)
+	}
+	if !c.Remove(1) || c.Remove(1) {
+		t.Fatal("Remove(1) should report true once")
+	}
+	c.Purge()
+	if _, ok := c.Get(3); ok || c.Len() != 0 {
+		t.Fatal("entries left after Purge")
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c := lru.New[int, string](10, 20*time.Millisecond)
+	c.Add(1, "a")
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := c.Get(1); ok {
+		t.Fatal("expired entry returned")
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expired entry kept, len %d", c.Len())
+	}
+}
@@ -0,0 +1,15 @@
+package persistence
+
+import "time"
+
+// TaskLocation is the durable task -> shard mapping. It lives in the directory database,
+// not on the shards, so it survives both Redis restarts and shard moves.
+type TaskLocation struct {
+	TaskId     uint `gorm:"primaryKey;autoIncrement:false"`
+	ShardIndex int  `gorm:"not null;index"`
+	UpdatedAt  time.Time
+}
+
+func (TaskLocation) TableName() string {
+	return "task_directory"
+}
@@ -0,0 +1,18 @@
+package persistence
+
+import "time"
+
+// Reminder records a reminder that was already sent. It lives on the task's shard and
+// the unique index makes claiming a reminder an idempotent insert.
+type Reminder struct {
+	ID            uint      `gorm:"primaryKey"`
+	TaskId        uint      `gorm:"not null;uniqueIndex:idx_task_reminder"`
+	Kind          string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_task_reminder"`
+	OffsetSeconds int64     `gorm:"not null;uniqueIndex:idx_task_reminder"`
+	DueAt         time.Time `gorm:"not null;uniqueIndex:idx_task_reminder"`
+	SentAt        time.Time `gorm:"not null"`
+}
+
+func (Reminder) TableName() string {
+	return "task_reminders"
+}
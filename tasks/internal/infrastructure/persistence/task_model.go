@@ -14,8 +14,13 @@ type Task struct {
 	Description string     `gorm:"type:text"`
 	PerformerId uint       `gorm:"not null;index"`
 	CreatorId   uint       `gorm:"not null;index"`
+	ProjectId   uint       `gorm:"not null;default:0;index"`
+	TenantId    uint       `gorm:"not null;default:0;index"`
 	Observers   []Observer `gorm:"foreignKey:TaskId;references:ID"`
+	MentionIds  []uint     `gorm:"serializer:json;type:jsonb"`
+	Labels      []string   `gorm:"serializer:json;type:jsonb"`
 	Status      string     `gorm:"type:varchar(50);not null;default:'pending'"`
+	DueAt       *time.Time `gorm:"index"`
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	DeletedAt   gorm.DeletedAt `gorm:"index"`
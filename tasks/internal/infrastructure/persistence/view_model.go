@@ -0,0 +1,21 @@
+package persistence
+
+import (
+	"time"
+)
+
+// View is a saved task query. Filter and Columns are stored as JSON so that new filter
+// fields do not need a schema change.
+type View struct {
+	ID         string `gorm:"type:uuid;primaryKey"`
+	Name       string `gorm:"type:varchar(255);not null"`
+	OwnerId    uint   `gorm:"not null;index"`
+	Filter     string `gorm:"type:jsonb;not null;default:'{}'"`
+	SortBy     string `gorm:"type:varchar(50)"`
+	SortDesc   bool   `gorm:"not null;default:false"`
+	Columns    string `gorm:"type:jsonb;not null;default:'[]'"`
+	Visibility string `gorm:"type:varchar(16);not null;default:'private'"`
+	ProjectId  uint   `gorm:"not null;default:0;index"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,9 @@
+-- Baseline: the task_directory table GORM AutoMigrate created before versioned
+-- migrations. Every statement is idempotent, so directory databases created by
+-- AutoMigrate are recorded as version 1.
+CREATE TABLE IF NOT EXISTS task_directory (
+    task_id     bigint PRIMARY KEY,
+    shard_index bigint NOT NULL,
+    updated_at  timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_task_directory_shard_index ON task_directory (shard_index);
@@ -0,0 +1,72 @@
+-- Baseline: the schema GORM AutoMigrate produced before versioned migrations.
+-- Every statement is idempotent, so shards created by AutoMigrate (including those that
+-- predate later columns) are brought to the same state and recorded as version 1.
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id           bigserial PRIMARY KEY,
+    title        varchar(255) NOT NULL,
+    description  text,
+    performer_id bigint NOT NULL,
+    creator_id   bigint NOT NULL,
+    project_id   bigint NOT NULL DEFAULT 0,
+    mention_ids  jsonb,
+    status       varchar(50) NOT NULL DEFAULT 'pending',
+    due_at       timestamptz,
+    created_at   timestamptz,
+    updated_at   timestamptz,
+    deleted_at   timestamptz
+);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id bigint NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS mention_ids jsonb;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at timestamptz;
+CREATE INDEX IF NOT EXISTS idx_tasks_performer_id ON tasks (performer_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_creator_id ON tasks (creator_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks (project_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_at ON tasks (due_at);
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks (deleted_at);
+
+CREATE TABLE IF NOT EXISTS observers (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint NOT NULL,
+    task_id    bigint NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    CONSTRAINT fk_tasks_observers FOREIGN KEY (task_id) REFERENCES tasks (id)
+);
+CREATE INDEX IF NOT EXISTS idx_observers_user_id ON observers (user_id);
+CREATE INDEX IF NOT EXISTS idx_observers_task_id ON observers (task_id);
+CREATE INDEX IF NOT EXISTS idx_observers_deleted_at ON observers (deleted_at);
+
+CREATE TABLE IF NOT EXISTS views (
+    id         uuid PRIMARY KEY,
+    name       varchar(255) NOT NULL,
+    owner_id   bigint NOT NULL,
+    filter     jsonb NOT NULL DEFAULT '{}',
+    sort_by    varchar(50),
+    sort_desc  boolean NOT NULL DEFAULT false,
+    columns    jsonb NOT NULL DEFAULT '[]',
+    visibility varchar(16) NOT NULL DEFAULT 'private',
+    project_id bigint NOT NULL DEFAULT 0,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_views_owner_id ON views (owner_id);
+CREATE INDEX IF NOT EXISTS idx_views_project_id ON views (project_id);
+
+CREATE TABLE IF NOT EXISTS task_reminders (
+    id             bigserial PRIMARY KEY,
+    task_id        bigint NOT NULL,
+    kind           varchar(20) NOT NULL,
+    offset_seconds bigint NOT NULL,
+    due_at         timestamptz NOT NULL,
+    sent_at        timestamptz NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_reminder ON task_reminders (task_id, kind, offset_seconds, due_at);
+
+CREATE TABLE IF NOT EXISTS id_allocator (
+    id       bigserial PRIMARY KEY,
+    next_id  bigint NOT NULL,
+    limit_id bigint NOT NULL DEFAULT 0
+);
+ALTER TABLE id_allocator ADD COLUMN IF NOT EXISTS limit_id bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- Intents of cross-shard task moves (shard.MoveTask), kept on the source shard until the
+-- move is finished or rolled back.
+CREATE TABLE IF NOT EXISTS task_moves (
+    task_id      bigint PRIMARY KEY,
+    performer_id bigint NOT NULL,
+    from_shard   integer NOT NULL,
+    to_shard     integer NOT NULL,
+    phase        varchar(16) NOT NULL,
+    error        text NOT NULL DEFAULT '',
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now()
+);
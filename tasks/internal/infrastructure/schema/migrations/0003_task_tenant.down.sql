@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_tenant_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS tenant_id;
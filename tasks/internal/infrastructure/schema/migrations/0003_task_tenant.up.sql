@@ -0,0 +1,3 @@
+-- Tasks belong to a tenant (organization); 0 for tasks created before tenants existed.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tenant_id bigint NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_tasks_tenant_id ON tasks (tenant_id);
@@ -0,0 +1,2 @@
+-- Free-form labels of a task, as a JSON array of strings; NULL for none.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS labels jsonb;
@@ -0,0 +1,377 @@
+// Package schema applies the versioned SQL migrations of a shard database, and those of
+// the task directory database.
+//
+// Migrations are embedded files named NNNN_name.up.sql, with an optional
+// NNNN_name.down.sql that reverts them and an optional NNNN_name.backfill.sql. A
+// backfill is one batch of an online data migration (an UPDATE ... LIMIT n, say); it is
+// repeated outside any transaction until it changes no rows, while the service keeps
+// serving. An up file whose first line is "-- migrate:no-transaction" runs outside a
+// transaction, e.g. for CREATE INDEX CONCURRENTLY; it must hold a single statement that
+// is safe to repeat.
+//
+// Applied migrations are recorded per database in the schema_migrations table.
+package schema
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Advisory lock keys; each shard and the directory is its own database, so they only
+// need to be unique per database.
+const (
+	migrateLockKey  = 7_310_001
+	backfillLockKey = 7_310_002
+)
+
+const noTransactionMarker = "-- migrate:no-transaction"
+
+const versionTableDDL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version       bigint PRIMARY KEY,
+    name          text NOT NULL,
+    applied_at    timestamptz NOT NULL DEFAULT now(),
+    backfilled_at timestamptz
+)`
+
+var (
+	// ErrIrreversible means a migration to revert has no down file.
+	ErrIrreversible = errors.New("migration cannot be reverted")
+	// ErrUnknownVersion means the database has a migration this binary does not know.
+	ErrUnknownVersion = errors.New("database has a migration this binary does not know")
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down|backfill)\.sql$`)
+
+//go:embed migrations/*.sql directory_migrations/*.sql
+var files embed.FS
+
+// Default holds the shard migrations embedded in the binary.
+var Default = mustLoad(files, "migrations")
+
+// Directory holds the migrations of the task directory database (DIRECTORY_DB_URL).
+var Directory = mustLoad(files, "directory_migrations")
+
+// Migration is one schema version.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	// Down reverts Up; empty if the migration cannot be reverted.
+	Down string
+	// Backfill is one batch of an online backfill; empty if there is none.
+	Backfill      string
+	NoTransaction bool
+}
+
+// Migrator applies a set of migrations.
+type Migrator struct {
+	migrations []Migration // by version, starting at 1 without gaps
+}
+
+// Load reads the migrations in dir of fsys.
+func Load(fsys fs.FS, dir string) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		m := fileNamePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name is not NNNN_name.(up|down|backfill).sql", e.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		sql := strings.TrimSpace(string(body))
+		switch m[3] {
+		case "up":
+			mig.Up = sql
+			mig.NoTransaction = strings.HasPrefix(sql, noTransactionMarker)
+		case "down":
+			mig.Down = sql
+		case "backfill":
+			mig.Backfill = sql
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must start at 1 without gaps; found %d after %d", m.Version, i)
+		}
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no up file", m.Version, m.Name)
+		}
+	}
+	return &Migrator{migrations: migrations}, nil
+}
+
+func mustLoad(fsys fs.FS, dir string) *Migrator {
+	m, err := Load(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Migrations returns the known migrations by version.
+func (m *Migrator) Migrations() []Migration {
+	return append([]Migration(nil), m.migrations...)
+}
+
+// Latest returns the highest known version.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Status is the schema state of one database.
+type Status struct {
+	// Version is the highest applied version, 0 if none.
+	Version int
+	// Pending are known versions that are not applied.
+	Pending []int
+	// Backfilling are applied versions whose backfill has not finished.
+	Backfilling []int
+}
+
+type appliedRow struct {
+	Version      int
+	Name         string
+	BackfilledAt *time.Time
+}
+
+// Status reads the applied migrations of db. It does not create the version table.
+func (m *Migrator) Status(ctx context.Context, db *gorm.DB) (Status, error) {
+	applied, err := m.applied(ctx, db)
+	if err != nil {
+		return Status{}, err
+	}
+	var st Status
+	for _, row := range applied {
+		st.Version = max(st.Version, row.Version)
+		if row.BackfilledAt == nil {
+			st.Backfilling = append(st.Backfilling, row.Version)
+		}
+	}
+	sort.Ints(st.Backfilling)
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			st.Pending = append(st.Pending, mig.Version)
+		}
+	}
+	return st, nil
+}
+
+func (m *Migrator) applied(ctx context.Context, db *gorm.DB) (map[int]appliedRow, error) {
+	var exists bool
+	if err := db.WithContext(ctx).Raw("SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]appliedRow)
+	if !exists {
+		return applied, nil
+	}
+	var rows []appliedRow
+	if err := db.WithContext(ctx).Raw("SELECT version, name, backfilled_at FROM schema_migrations").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// Up applies the pending migrations up to target (the latest if target <= 0) in
+// version order, each in its own transaction together with its version row. It returns
+// the versions applied. Backfills are left to Backfill.
+func (m *Migrator) Up(ctx context.Context, db *gorm.DB, target int) ([]int, error) {
+	if target <= 0 || target > m.Latest() {
+		target = m.Latest()
+	}
+	var done []int
+	err := withLock(ctx, db, migrateLockKey, func(conn *gorm.DB) error {
+		if err := conn.Exec(versionTableDDL).Error; err != nil {
+			return err
+		}
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations[:target] {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := apply(conn, mig); err != nil {
+				return fmt.Errorf("migration %d (%s): %w", mig.Version, mig.Name, err)
+			}
+			done = append(done, mig.Version)
+		}
+		return nil
+	})
+	return done, err
+}
+
+func apply(conn *gorm.DB, mig Migration) error {
+	record := func(tx *gorm.DB) error {
+		var backfilledAt *time.Time
+		if mig.Backfill == "" {
+			now := time.Now()
+			backfilledAt = &now
+		}
+		return tx.Exec("INSERT INTO schema_migrations (version, name, backfilled_at) VALUES (?, ?, ?)",
+			mig.Version, mig.Name, backfilledAt).Error
+	}
+	if mig.NoTransaction {
+		if err := conn.Exec(mig.Up).Error; err != nil {
+			return err
+		}
+		return record(conn)
+	}
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(mig.Up).Error; err != nil {
+			return err
+		}
+		return record(tx)
+	})
+}
+
+// Down reverts the applied migrations above target, newest first, and returns the
+// versions reverted. It stops at the first migration without a down file.
+func (m *Migrator) Down(ctx context.Context, db *gorm.DB, target int) ([]int, error) {
+	target = max(target, 0)
+	var done []int
+	err := withLock(ctx, db, migrateLockKey, func(conn *gorm.DB) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int, 0, len(applied))
+		for v := range applied {
+			if v > target {
+				versions = append(versions, v)
+			}
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+		for _, v := range versions {
+			if v > m.Latest() {
+				return fmt.Errorf("%w: version %d (%s)", ErrUnknownVersion, v, applied[v].Name)
+			}
+			mig := m.migrations[v-1]
+			if mig.Down == "" {
+				return fmt.Errorf("%w: %d (%s) has no down file", ErrIrreversible, v, mig.Name)
+			}
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(mig.Down).Error; err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", v).Error
+			})
+			if err != nil {
+				return fmt.Errorf("revert migration %d (%s): %w", v, mig.Name, err)
+			}
+			done = append(done, v)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Backfill runs the unfinished backfills of db, oldest first, pausing between batches,
+// and marks each one finished once a batch changes no rows. It returns false without
+// doing anything if another process is backfilling db. A cancelled backfill resumes
+// where it stopped, since each batch only picks rows that still need it.
+func (m *Migrator) Backfill(ctx context.Context, db *gorm.DB, pause time.Duration) (bool, error) {
+	var locked bool
+	err := db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", backfillLockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		defer unlock(ctx, conn, backfillLockKey)
+
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			row, ok := applied[mig.Version]
+			if !ok || row.BackfilledAt != nil {
+				continue
+			}
+			if err := runBackfill(ctx, conn, mig, pause); err != nil {
+				return fmt.Errorf("backfill %d (%s): %w", mig.Version, mig.Name, err)
+			}
+		}
+		return nil
+	})
+	return locked, err
+}
+
+func runBackfill(ctx context.Context, conn *gorm.DB, mig Migration, pause time.Duration) error {
+	for mig.Backfill != "" {
+		res := conn.Exec(mig.Backfill)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pause):
+		}
+	}
+	return conn.Exec("UPDATE schema_migrations SET backfilled_at = now() WHERE version = ?", mig.Version).Error
+}
+
+// withLock runs fn on one connection of db while holding a session advisory lock, so
+// two processes never migrate the same database at once. The session's statement_timeout
+// is lifted meanwhile: waiting for the lock and building indexes may take longer.
+func withLock(ctx context.Context, db *gorm.DB, key int64, fn func(conn *gorm.DB) error) error {
+	return db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SET statement_timeout = 0").Error; err != nil {
+			return err
+		}
+		defer conn.WithContext(context.WithoutCancel(ctx)).Exec("RESET statement_timeout")
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", key).Error; err != nil {
+			return err
+		}
+		defer unlock(ctx, conn, key)
+		return fn(conn)
+	})
+}
+
+// unlock releases a session lock even if ctx is cancelled; the connection goes back to
+// the pool and would keep the lock otherwise.
+func unlock(ctx context.Context, conn *gorm.DB, key int64) {
+	conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT pg_advisory_unlock(?)", key)
+}
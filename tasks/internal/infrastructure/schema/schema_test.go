@@ -0,0 +1,65 @@
+package schema_test
+
+import (
+	"tasks/internal/infrastructure/schema"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		latest  int
+		wantErr bool
+	}{
+		{
+			name: "up, down and backfill",
+			files: fstest.MapFS{
+				"m/0001_baseline.up.sql":        file("CREATE TABLE a (id int);"),
+				"m/0002_add_b.up.sql":           file("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY i ON a (id);"),
+				"m/0002_add_b.down.sql":         file("DROP INDEX i;"),
+				"m/0002_add_b.backfill.sql":     file("UPDATE a SET id = 1 WHERE id IS NULL;"),
+				"m/0003_rename_column.up.sql":   file("ALTER TABLE a RENAME id TO pk;"),
+				"m/0003_rename_column.down.sql": file("ALTER TABLE a RENAME pk TO id;"),
+			},
+			latest: 3,
+		},
+		{name: "gap", files: fstest.MapFS{"m/0001_a.up.sql": file("x"), "m/0003_c.up.sql": file("x")}, wantErr: true},
+		{name: "not starting at 1", files: fstest.MapFS{"m/0002_b.up.sql": file("x")}, wantErr: true},
+		{name: "down without up", files: fstest.MapFS{"m/0001_a.down.sql": file("x")}, wantErr: true},
+		{name: "two names", files: fstest.MapFS{"m/0001_a.up.sql": file("x"), "m/0001_b.down.sql": file("x")}, wantErr: true},
+		{name: "bad name", files: fstest.MapFS{"m/1-a.sql": file("x")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := schema.Load(tt.files, "m")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if m.Latest() != tt.latest {
+				t.Fatalf("Latest() = %d, want %d", m.Latest(), tt.latest)
+			}
+			migs := m.Migrations()
+			if !migs[1].NoTransaction || migs[0].NoTransaction {
+				t.Fatal("no-transaction marker not detected")
+			}
+			if migs[1].Backfill == "" || migs[0].Down != "" || migs[2].Down == "" {
+				t.Fatalf("files not assigned: %+v", migs)
+			}
+		})
+	}
+}
+
+func TestDefaultMigrations(t *testing.T) {
+	if schema.Default.Latest() < 1 {
+		t.Fatal("no embedded migrations")
+	}
+	if schema.Directory.Latest() < 1 {
+		t.Fatal("no embedded directory migrations")
+	}
+}
@@ -0,0 +1,56 @@
+// Package session carries per-request state that storage needs for read-your-writes:
+// the calling user, whether the request has written anything yet, and whether it read
+// from a replica.
+package session
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type sessionKey struct{}
+
+type session struct {
+	userID      uint
+	wrote       atomic.Bool
+	replicaRead atomic.Bool
+}
+
+// New returns a context for one request by userID (0 if unknown).
+func New(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{userID: userID})
+}
+
+// UserID returns the calling user, or 0.
+func UserID(ctx context.Context) uint {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		return s.userID
+	}
+	return 0
+}
+
+// MarkWrite records that the request wrote, so its later reads see the write.
+func MarkWrite(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+}
+
+// Wrote reports whether the request has written.
+func Wrote(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	return ok && s.wrote.Load()
+}
+
+// MarkReplicaRead records that the request read from a replica, which may be behind.
+func MarkReplicaRead(ctx context.Context) {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.replicaRead.Store(true)
+	}
+}
+
+// ReadReplica reports whether the request has read from a replica.
+func ReadReplica(ctx context.Context) bool {
+	s, ok := ctx.Value(sessionKey{}).(*session)
+	return ok && s.replicaRead.Load()
+}
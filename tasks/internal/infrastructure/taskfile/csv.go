@@ -0,0 +1,174 @@
+package taskfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns is the header written by CSVWriter and understood by CSVReader.
+var csvColumns = []string{
+	"external_id", "title", "description", "status",
+	"performer_id", "creator_id", "project_id", "observer_ids", "due_at",
+	"performer_email", "creator_email", "observer_emails",
+}
+
+type CSVReader struct {
+	r    *csv.Reader
+	cols map[string]int
+	line int
+}
+
+// NewCSVReader reads the header row and maps columns by name, so extra or reordered columns are fine.
+func NewCSVReader(r io.Reader) (*CSVReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	if _, ok := cols["title"]; !ok {
+		return nil, fmt.Errorf("csv header has no title column")
+	}
+	return &CSVReader{r: cr, cols: cols, line: 1}, nil
+}
+
+// Read returns the next row. A row that fails to parse or has a malformed cell yields a
+// *RowError.
+func (c *CSVReader) Read() (Record, error) {
+	row, err := c.r.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		c.line++
+		return Record{}, &RowError{ExternalID: "line-" + strconv.Itoa(c.line), Err: err}
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	c.line++
+
+	get := func(name string) string {
+		i, ok := c.cols[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	rec := Record{
+		ExternalID:     get("external_id"),
+		Title:          get("title"),
+		Description:    get("description"),
+		Status:         get("status"),
+		PerformerEmail: get("performer_email"),
+		CreatorEmail:   get("creator_email"),
+		ObserverEmails: splitList(get("observer_emails")),
+	}
+	if rec.ExternalID == "" {
+		rec.ExternalID = "line-" + strconv.Itoa(c.line)
+	}
+	if rec.PerformerID, err = parseOptionalID(get("performer_id")); err != nil {
+		return Record{}, &RowError{ExternalID: rec.ExternalID, Err: fmt.Errorf("line %d: performer_id: %w", c.line, err)}
+	}
+	if rec.CreatorID, err = parseOptionalID(get("creator_id")); err != nil {
+		return Record{}, &RowError{ExternalID: rec.ExternalID, Err: fmt.Errorf("line %d: creator_id: %w", c.line, err)}
+	}
+	if rec.ProjectID, err = parseOptionalID(get("project_id")); err != nil {
+		return Record{}, &RowError{ExternalID: rec.ExternalID, Err: fmt.Errorf("line %d: project_id: %w", c.line, err)}
+	}
+	if rec.ObserverIDs, err = parseIDList(get("observer_ids")); err != nil {
+		return Record{}, &RowError{ExternalID: rec.ExternalID, Err: fmt.Errorf("line %d: observer_ids: %w", c.line, err)}
+	}
+	if rec.DueAt, err = parseOptionalTime(get("due_at")); err != nil {
+		return Record{}, &RowError{ExternalID: rec.ExternalID, Err: fmt.Errorf("line %d: due_at: %w", c.line, err)}
+	}
+	return rec, nil
+}
+
+type CSVWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (c *CSVWriter) Write(rec Record) error {
+	if !c.headerWritten {
+		if err := c.w.Write(csvColumns); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	return c.w.Write([]string{
+		rec.ExternalID,
+		rec.Title,
+		rec.Description,
+		rec.Status,
+		formatOptionalID(rec.PerformerID),
+		formatOptionalID(rec.CreatorID),
+		formatOptionalID(rec.ProjectID),
+		joinIDs(rec.ObserverIDs),
+		formatOptionalTime(rec.DueAt),
+		rec.PerformerEmail,
+		rec.CreatorEmail,
+		strings.Join(rec.ObserverEmails, ";"),
+	})
+}
+
+func (c *CSVWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func parseOptionalID(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+func formatOptionalID(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+// parseOptionalTime accepts RFC 3339 timestamps or plain dates (midnight UTC).
+func parseOptionalTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, s); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func joinIDs(ids []uint64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,97 @@
+package taskfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JiraReader converts a Jira "Export Excel CSV (all fields)" file.
+// Jira repeats the Watchers column once per watcher, so every column with that name is collected.
+type JiraReader struct {
+	r       *csv.Reader
+	cols    map[string]int
+	watcher []int
+	users   UserMap
+	line    int
+}
+
+func NewJiraReader(r io.Reader, users UserMap) (*JiraReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read jira header: %w", err)
+	}
+
+	jr := &JiraReader{r: cr, cols: make(map[string]int), users: users}
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(h))
+		if name == "watchers" {
+			jr.watcher = append(jr.watcher, i)
+			continue
+		}
+		if _, dup := jr.cols[name]; !dup {
+			jr.cols[name] = i
+		}
+	}
+	for _, required := range []string{"issue key", "summary"} {
+		if _, ok := jr.cols[required]; !ok {
+			return nil, fmt.Errorf("jira export has no %q column", required)
+		}
+	}
+	return jr, nil
+}
+
+// Read returns the next issue. An issue whose row fails to parse or that names a user
+// missing from the user map yields a *RowError.
+func (j *JiraReader) Read() (Record, error) {
+	row, err := j.r.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		j.line++
+		return Record{}, &RowError{ExternalID: "line-" + strconv.Itoa(j.line), Err: err}
+	}
+	if err != nil {
+		return Record{}, err
+	}
+	j.line++
+
+	get := func(name string) string {
+		i, ok := j.cols[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	rec := Record{
+		ExternalID:  get("issue key"),
+		Title:       get("summary"),
+		Description: get("description"),
+		Status:      NormalizeStatus(get("status")),
+	}
+	if rec.PerformerEmail, err = j.users.Email(get("assignee")); err != nil {
+		return Record{}, &RowError{ExternalID: rec.ExternalID, Err: fmt.Errorf("assignee: %w", err)}
+	}
+	if rec.CreatorEmail, err = j.users.Email(get("reporter")); err != nil {
+		return Record{}, &RowError{ExternalID: rec.ExternalID, Err: fmt.Errorf("reporter: %w", err)}
+	}
+	for _, i := range j.watcher {
+		if i >= len(row) {
+			continue
+		}
+		email, err := j.users.Email(row[i])
+		if err != nil {
+			return Record{}, &RowError{ExternalID: rec.ExternalID, Err: fmt.Errorf("watcher: %w", err)}
+		}
+		if email != "" {
+			rec.ObserverEmails = append(rec.ObserverEmails, email)
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,62 @@
+package taskfile
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// JSONLReader reads one JSON object per line; blank lines are skipped and a line that is
+// not a valid record yields a *RowError.
+type JSONLReader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &JSONLReader{sc: sc}
+}
+
+func (j *JSONLReader) Read() (Record, error) {
+	for j.sc.Scan() {
+		j.line++
+		data := j.sc.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return Record{}, &RowError{ExternalID: "line-" + strconv.Itoa(j.line), Err: fmt.Errorf("line %d: %w", j.line, err)}
+		}
+		if rec.ExternalID == "" {
+			rec.ExternalID = "line-" + strconv.Itoa(j.line)
+		}
+		return rec, nil
+	}
+	if err := j.sc.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+type JSONLWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	bw := bufio.NewWriter(w)
+	return &JSONLWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (j *JSONLWriter) Write(rec Record) error {
+	return j.enc.Encode(rec)
+}
+
+func (j *JSONLWriter) Flush() error {
+	return j.w.Flush()
+}
@@ -0,0 +1,168 @@
+package taskfile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Record is one task in a bulk import/export file.
+// Users may be referenced either by ID or by email; emails are resolved by the importer.
+type Record struct {
+	ExternalID     string     `json:"external_id,omitempty"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	PerformerID    uint64     `json:"performer_id,omitempty"`
+	CreatorID      uint64     `json:"creator_id,omitempty"`
+	ProjectID      uint64     `json:"project_id,omitempty"`
+	ObserverIDs    []uint64   `json:"observer_ids,omitempty"`
+	DueAt          *time.Time `json:"due_at,omitempty"`
+	PerformerEmail string     `json:"performer_email,omitempty"`
+	CreatorEmail   string     `json:"creator_email,omitempty"`
+	ObserverEmails []string   `json:"observer_emails,omitempty"`
+}
+
+// Reader yields records one at a time and returns io.EOF when exhausted.
+type Reader interface {
+	Read() (Record, error)
+}
+
+// Writer appends records to an export file. Flush must be called before closing the underlying file.
+type Writer interface {
+	Write(rec Record) error
+	Flush() error
+}
+
+// Supported file formats.
+const (
+	FormatCSV    = "csv"
+	FormatJSONL  = "jsonl"
+	FormatJira   = "jira"
+	FormatTrello = "trello"
+)
+
+// NormalizeStatus maps the workflow states used by other trackers onto TaskFlow statuses.
+// Unknown states are lower-cased with spaces replaced by underscores.
+func NormalizeStatus(s string) string {
+	key := strings.ToLower(strings.TrimSpace(s))
+	switch key {
+	case "":
+		return ""
+	case "to do", "todo", "open", "backlog", "selected for development":
+		return "new"
+	case "in progress", "doing", "in review", "review":
+		return "in_progress"
+	case "done", "closed", "resolved", "complete", "completed":
+		return "done"
+	}
+	return strings.ReplaceAll(key, " ", "_")
+}
+
+// UserMap translates external user handles (Jira display names, Trello usernames) to emails.
+type UserMap map[string]string
+
+// Email returns the email for an external handle. Handles that already look like an
+// email are returned unchanged and an empty handle yields an empty string; a handle with
+// no entry in the map is an error.
+func (m UserMap) Email(handle string) (string, error) {
+	handle = strings.TrimSpace(handle)
+	if handle == "" {
+		return "", nil
+	}
+	if strings.Contains(handle, "@") {
+		return handle, nil
+	}
+	email, ok := m[strings.ToLower(handle)]
+	if !ok {
+		return "", fmt.Errorf("user %q has no email in the user map", handle)
+	}
+	return email, nil
+}
+
+// RowError is returned by Reader.Read for a record that cannot be converted, such as a
+// malformed cell or an unmapped user. The reader stays usable: the next Read returns the
+// next record. Any other error means the file itself could not be read.
+type RowError struct {
+	ExternalID string
+	Err        error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("%s: %v", e.ExternalID, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+func parseIDList(s string) ([]uint64, error) {
+	fields := splitList(s)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint64, 0, len(fields))
+	for _, f := range fields {
+		var id uint64
+		if _, err := fmt.Sscan(f, &id); err != nil {
+			return nil, fmt.Errorf("invalid id %q", f)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// splitList splits a multi-value cell separated by ';', ',' or whitespace.
+func splitList(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ';' || r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// NewReader returns a Reader for the given format.
+func NewReader(format string, r io.Reader, users UserMap) (Reader, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVReader(r)
+	case FormatJSONL:
+		return NewJSONLReader(r), nil
+	case FormatJira:
+		return NewJiraReader(r, users)
+	case FormatTrello:
+		return NewTrelloReader(r, users)
+	}
+	return nil, fmt.Errorf("unsupported import format %q", format)
+}
+
+// NewWriter returns a Writer for the given format. Only the native formats can be exported.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatJSONL:
+		return NewJSONLWriter(w), nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// LoadUserMap reads a two-column CSV of "handle,email" pairs.
+func LoadUserMap(r io.Reader) (UserMap, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	m := make(UserMap, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		m[strings.ToLower(strings.TrimSpace(row[0]))] = strings.TrimSpace(row[1])
+	}
+	return m, nil
+}
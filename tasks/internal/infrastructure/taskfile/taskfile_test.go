@@ -0,0 +1,216 @@
+package taskfile_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"tasks/internal/infrastructure/taskfile"
+	"testing"
+)
+
+func readAll(t *testing.T, r taskfile.Reader) []taskfile.Record {
+	t.Helper()
+	var out []taskfile.Record
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, rec)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	in := []taskfile.Record{
+		{ExternalID: "1", Title: "Write docs", Description: "with, commas", Status: "new", PerformerID: 2, CreatorID: 1, ProjectID: 7, ObserverIDs: []uint64{3, 4}},
+		{ExternalID: "2", Title: "Ship", CreatorEmail: "boss@example.com", ObserverEmails: []string{"a@example.com", "b@example.com"}},
+	}
+
+	var buf bytes.Buffer
+	w := taskfile.NewCSVWriter(&buf)
+	for _, rec := range in {
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := taskfile.NewCSVReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, r); !reflect.DeepEqual(got, in) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", got, in)
+	}
+}
+
+func TestJiraReader(t *testing.T) {
+	data := "Summary,Issue key,Status,Assignee,Reporter,Watchers,Watchers,Description\n" +
+		"Fix login,PRJ-1,In Progress,alice@example.com,Bob,carol,,Broken on Safari\n"
+	users := taskfile.UserMap{"bob": "bob@example.com", "carol": "carol@example.com"}
+
+	r, err := taskfile.NewJiraReader(strings.NewReader(data), users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readAll(t, r)
+	want := []taskfile.Record{{
+		ExternalID:     "PRJ-1",
+		Title:          "Fix login",
+		Description:    "Broken on Safari",
+		Status:         "in_progress",
+		PerformerEmail: "alice@example.com",
+		CreatorEmail:   "bob@example.com",
+		ObserverEmails: []string{"carol@example.com"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestTrelloReader(t *testing.T) {
+	data := `{
+		"lists": [{"id": "l1", "name": "Doing"}],
+		"members": [{"id": "m1", "username": "alice"}, {"id": "m2", "username": "bob"}],
+		"cards": [
+			{"id": "c1", "name": "Plan sprint", "desc": "", "idList": "l1", "idMembers": ["m1", "m2"]},
+			{"id": "c2", "name": "Archived", "closed": true, "idList": "l1"}
+		],
+		"actions": [{"type": "createCard", "idMemberCreator": "m2", "data": {"card": {"id": "c1"}}}]
+	}`
+	users := taskfile.UserMap{"alice": "alice@example.com", "bob": "bob@example.com"}
+
+	r, err := taskfile.NewTrelloReader(strings.NewReader(data), users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readAll(t, r)
+	want := []taskfile.Record{{
+		ExternalID:     "c1",
+		Title:          "Plan sprint",
+		Status:         "in_progress",
+		PerformerEmail: "alice@example.com",
+		CreatorEmail:   "bob@example.com",
+		ObserverEmails: []string{"bob@example.com"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// readRows reads r to the end and returns the records together with the external IDs of
+// the rows that failed with a *RowError.
+func readRows(t *testing.T, r taskfile.Reader) (recs []taskfile.Record, failed []string) {
+	t.Helper()
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return recs, failed
+		}
+		var rowErr *taskfile.RowError
+		if errors.As(err, &rowErr) {
+			failed = append(failed, rowErr.ExternalID)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func TestReadersReportRowErrors(t *testing.T) {
+	users := taskfile.UserMap{"bob": "bob@example.com"}
+	tests := []struct {
+		name       string
+		open       func(data string) (taskfile.Reader, error)
+		data       string
+		wantTitles []string
+		wantFailed []string
+	}{
+		{
+			name: "csv bad id",
+			open: func(data string) (taskfile.Reader, error) { return taskfile.NewCSVReader(strings.NewReader(data)) },
+			data: "external_id,title,performer_id\n" +
+				"1,ok,2\n" +
+				"2,bad,two\n" +
+				"3,also ok,\n",
+			wantTitles: []string{"ok", "also ok"},
+			wantFailed: []string{"2"},
+		},
+		{
+			name: "csv bad quote",
+			open: func(data string) (taskfile.Reader, error) { return taskfile.NewCSVReader(strings.NewReader(data)) },
+			data: "title\n" +
+				"ok\n" +
+				"a \"quoted\" mess\n" +
+				"also ok\n",
+			wantTitles: []string{"ok", "also ok"},
+			wantFailed: []string{"line-3"},
+		},
+		{
+			name: "jsonl",
+			open: func(data string) (taskfile.Reader, error) {
+				return taskfile.NewJSONLReader(strings.NewReader(data)), nil
+			},
+			data: `{"title": "ok"}` + "\n" +
+				`{"title": "bad", "performer_id": "two"}` + "\n" +
+				`{"title": "also ok"}` + "\n",
+			wantTitles: []string{"ok", "also ok"},
+			wantFailed: []string{"line-2"},
+		},
+		{
+			name: "jira unmapped assignee",
+			open: func(data string) (taskfile.Reader, error) {
+				return taskfile.NewJiraReader(strings.NewReader(data), users)
+			},
+			data: "Summary,Issue key,Assignee,Watchers\n" +
+				"ok,PRJ-1,Bob,\n" +
+				"bad,PRJ-2,Dave,\n" +
+				"also ok,PRJ-3,,\n" +
+				"bad watcher,PRJ-4,,Erin\n",
+			wantTitles: []string{"ok", "also ok"},
+			wantFailed: []string{"PRJ-2", "PRJ-4"},
+		},
+		{
+			name: "trello unmapped member",
+			open: func(data string) (taskfile.Reader, error) {
+				return taskfile.NewTrelloReader(strings.NewReader(data), users)
+			},
+			data: `{
+				"members": [{"id": "m1", "username": "bob"}, {"id": "m2", "username": "dave"}],
+				"cards": [
+					{"id": "c1", "name": "ok", "idMembers": ["m1"]},
+					{"id": "c2", "name": "bad", "idMembers": ["m1", "m2"]},
+					{"id": "c3", "name": "bad creator"}
+				],
+				"actions": [{"type": "createCard", "idMemberCreator": "m2", "data": {"card": {"id": "c3"}}}]
+			}`,
+			wantTitles: []string{"ok"},
+			wantFailed: []string{"c2", "c3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := tt.open(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recs, failed := readRows(t, r)
+			var titles []string
+			for _, rec := range recs {
+				titles = append(titles, rec.Title)
+			}
+			if !reflect.DeepEqual(titles, tt.wantTitles) || !reflect.DeepEqual(failed, tt.wantFailed) {
+				t.Fatalf("read %q, failed %q; want %q and %q", titles, failed, tt.wantTitles, tt.wantFailed)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package taskfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// trelloBoard is the subset of a Trello board JSON export used for import.
+type trelloBoard struct {
+	Lists []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"lists"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+	Cards []struct {
+		ID        string     `json:"id"`
+		Name      string     `json:"name"`
+		Desc      string     `json:"desc"`
+		Closed    bool       `json:"closed"`
+		IDList    string     `json:"idList"`
+		IDMembers []string   `json:"idMembers"`
+		Due       *time.Time `json:"due"`
+	} `json:"cards"`
+	Actions []struct {
+		Type            string `json:"type"`
+		IDMemberCreator string `json:"idMemberCreator"`
+		Data            struct {
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+	} `json:"actions"`
+}
+
+// TrelloReader converts a Trello board export. The list a card sits on becomes its status,
+// the first card member its performer, the remaining members observers, and the author of
+// the createCard action its creator. Archived cards are skipped; a card with a member
+// missing from the user map yields a *RowError.
+type TrelloReader struct {
+	records []Record
+	errs    []error
+	pos     int
+}
+
+func NewTrelloReader(r io.Reader, users UserMap) (*TrelloReader, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, fmt.Errorf("decode trello board: %w", err)
+	}
+
+	lists := make(map[string]string, len(board.Lists))
+	for _, l := range board.Lists {
+		lists[l.ID] = l.Name
+	}
+	members := make(map[string]string, len(board.Members))
+	unmapped := make(map[string]error)
+	for _, m := range board.Members {
+		email, err := users.Email(m.Username)
+		if err != nil {
+			unmapped[m.ID] = err
+			continue
+		}
+		members[m.ID] = email
+	}
+	creators := make(map[string]string)
+	for _, a := range board.Actions {
+		if a.Type == "createCard" {
+			creators[a.Data.Card.ID] = a.IDMemberCreator
+		}
+	}
+
+	t := &TrelloReader{records: make([]Record, 0, len(board.Cards)), errs: make([]error, 0, len(board.Cards))}
+	for _, c := range board.Cards {
+		if c.Closed {
+			continue
+		}
+		rec := Record{
+			ExternalID:   c.ID,
+			Title:        c.Name,
+			Description:  c.Desc,
+			Status:       NormalizeStatus(lists[c.IDList]),
+			CreatorEmail: members[creators[c.ID]],
+			DueAt:        c.Due,
+		}
+		err := unmapped[creators[c.ID]]
+		if err != nil {
+			err = fmt.Errorf("creator: %w", err)
+		}
+		for i, id := range c.IDMembers {
+			if unmapped[id] != nil && err == nil {
+				err = fmt.Errorf("member: %w", unmapped[id])
+			}
+			email := members[id]
+			if email == "" {
+				continue
+			}
+			if i == 0 {
+				rec.PerformerEmail = email
+			} else {
+				rec.ObserverEmails = append(rec.ObserverEmails, email)
+			}
+		}
+		if err != nil {
+			err = &RowError{ExternalID: c.ID, Err: err}
+		}
+		t.records = append(t.records, rec)
+		t.errs = append(t.errs, err)
+	}
+	return t, nil
+}
+
+func (t *TrelloReader) Read() (Record, error) {
+	if t.pos >= len(t.records) {
+		return Record{}, io.EOF
+	}
+	rec, err := t.records[t.pos], t.errs[t.pos]
+	t.pos++
+	if err != nil {
+		return Record{}, err
+	}
+	return rec, nil
+}
@@ -0,0 +1,157 @@
+package watch
+
+import (
+	"context"
+	"sync"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+	"time"
+)
+
+const (
+	defaultBacklog   = 4096
+	subscriberBuffer = 256
+)
+
+// Hub fans task changes out to in-process subscribers and keeps a bounded backlog
+// so that a reconnecting client can resume from the last sequence it saw.
+// It implements ports.EventProducer, so it is fed by the same use-case calls as Kafka.
+type Hub struct {
+	mu      sync.Mutex
+	seq     uint64
+	backlog []domain.TaskChange // ring buffer
+	start   int                 // index of the oldest entry
+	size    int
+	subs    map[*subscription]struct{}
+}
+
+// NewHub creates a hub that buffers the last backlog changes (defaultBacklog if <= 0).
+func NewHub(backlog int) *Hub {
+	if backlog <= 0 {
+		backlog = defaultBacklog
+	}
+	return &Hub{
+		backlog: make([]domain.TaskChange, backlog),
+		subs:    make(map[*subscription]struct{}),
+	}
+}
+
+func (h *Hub) PublishCreated(ctx context.Context, task domain.Task) error {
+	h.publish(domain.ChangeCreated, task)
+	return nil
+}
+
+func (h *Hub) PublishUpdated(ctx context.Context, task domain.Task) error {
+	h.publish(domain.ChangeUpdated, task)
+	return nil
+}
+
+func (h *Hub) PublishDeleted(ctx context.Context, task domain.Task) error {
+	h.publish(domain.ChangeDeleted, task)
+	return nil
+}
+
+// PublishMentioned is a no-op: mentions are delivered by the notification service, not watch streams.
+func (h *Hub) PublishMentioned(ctx context.Context, task domain.Task, userID uint) error {
+	return nil
+}
+
+// PublishReminder is a no-op: reminders do not change the task.
+func (h *Hub) PublishReminder(ctx context.Context, task domain.Task, reminder domain.Reminder) error {
+	return nil
+}
+
+func (h *Hub) publish(t domain.ChangeType, task domain.Task) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	change := domain.TaskChange{Seq: h.seq, Type: t, Task: task, At: time.Now()}
+
+	end := (h.start + h.size) % len(h.backlog)
+	h.backlog[end] = change
+	if h.size < len(h.backlog) {
+		h.size++
+	} else {
+		h.start = (h.start + 1) % len(h.backlog)
+	}
+
+	for sub := range h.subs {
+		select {
+		case sub.ch <- change:
+		default:
+			// never block publishers on a slow reader; it can resume from its last sequence
+			h.dropLocked(sub, domain.ErrWatchLagged)
+		}
+	}
+}
+
+func (h *Hub) Subscribe(fromSeq uint64) (ports.Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []domain.TaskChange
+	if fromSeq > 0 && fromSeq < h.seq {
+		oldest := h.seq - uint64(h.size) + 1
+		if h.size == 0 || fromSeq+1 < oldest {
+			return nil, domain.ErrSequenceTooOld
+		}
+		for i := 0; i < h.size; i++ {
+			change := h.backlog[(h.start+i)%len(h.backlog)]
+			if change.Seq > fromSeq {
+				replay = append(replay, change)
+			}
+		}
+	} else if fromSeq > h.seq {
+		// the client saw sequences from a previous process lifetime
+		return nil, domain.ErrSequenceTooOld
+	}
+
+	sub := &subscription{hub: h, ch: make(chan domain.TaskChange, len(replay)+subscriberBuffer)}
+	for _, change := range replay {
+		sub.ch <- change
+	}
+	h.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// LastSeq returns the sequence of the most recent change.
+func (h *Hub) LastSeq() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.seq
+}
+
+func (h *Hub) dropLocked(sub *subscription, err error) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+type subscription struct {
+	hub *Hub
+	ch  chan domain.TaskChange
+	err error // guarded by hub.mu, written before ch is closed
+}
+
+func (s *subscription) Changes() <-chan domain.TaskChange { return s.ch }
+
+func (s *subscription) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.err
+}
+
+func (s *subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.dropLocked(s, nil)
+}
+
+var (
+	_ ports.EventProducer = (*Hub)(nil)
+	_ ports.TaskWatcher   = (*Hub)(nil)
+)
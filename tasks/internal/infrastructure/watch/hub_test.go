@@ -0,0 +1,72 @@
+package watch_test
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/domain"
+	"tasks/internal/infrastructure/watch"
+	"testing"
+)
+
+func publishN(h *watch.Hub, n int) {
+	for i := 1; i <= n; i++ {
+		_ = h.PublishUpdated(context.Background(), domain.Task{ID: uint(i)})
+	}
+}
+
+func TestHubResume(t *testing.T) {
+	h := watch.NewHub(4)
+	publishN(h, 6) // backlog holds sequences 3..6
+
+	tests := []struct {
+		name    string
+		fromSeq uint64
+		want    []uint64
+		wantErr error
+	}{
+		{"only new changes", 0, nil, nil},
+		{"resume inside backlog", 3, []uint64{4, 5, 6}, nil},
+		{"resume at oldest boundary", 2, []uint64{3, 4, 5, 6}, nil},
+		{"resume before backlog", 1, nil, domain.ErrSequenceTooOld},
+		{"resume from another process", 42, nil, domain.ErrSequenceTooOld},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := h.Subscribe(tt.fromSeq)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			defer sub.Close()
+
+			var got []uint64
+			for len(got) < len(tt.want) {
+				got = append(got, (<-sub.Changes()).Seq)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	h := watch.NewHub(0)
+	sub, err := h.Subscribe(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publishN(h, 1000)
+
+	for range sub.Changes() {
+	}
+	if !errors.Is(sub.Err(), domain.ErrWatchLagged) {
+		t.Errorf("expected ErrWatchLagged, got %v", sub.Err())
+	}
+}
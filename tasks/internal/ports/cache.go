@@ -11,3 +11,27 @@ type Cache interface {
 	SetTask(ctx context.Context, task domain.Task) error
 	GetTask(ctx context.Context, taskID uint) (domain.Task, error)
 }
+
+// CacheStats are the counters of a task cache since the process started.
+type CacheStats struct {
+	// LocalHits and LocalMisses count lookups in the in-process cache; a miss goes to Redis.
+	LocalHits   uint64
+	LocalMisses uint64
+	// RedisHits and RedisMisses count lookups that reached Redis; a miss loads the task
+	// from its shard. Lookups that failed because Redis was down count as misses.
+	RedisHits   uint64
+	RedisMisses uint64
+	// Coalesced counts lookups that waited for another caller's Redis fetch of the task.
+	Coalesced uint64
+	// Evictions counts entries dropped to make room, Invalidations those dropped because
+	// the task changed.
+	Evictions     uint64
+	Invalidations uint64
+	Entries       int
+	Capacity      int
+}
+
+// CacheStatsReporter is a cache that counts its hits and misses.
+type CacheStatsReporter interface {
+	CacheStats() CacheStats
+}
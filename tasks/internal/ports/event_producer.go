@@ -11,4 +11,8 @@ type EventProducer interface {
 	PublishCreated(ctx context.Context, task domain.Task) error
 	PublishDeleted(ctx context.Context, task domain.Task) error
 	PublishUpdated(ctx context.Context, task domain.Task) error
+	// PublishMentioned announces that userID was mentioned in the task.
+	PublishMentioned(ctx context.Context, task domain.Task, userID uint) error
+	// PublishReminder announces a TaskDueSoon or TaskOverdue reminder.
+	PublishReminder(ctx context.Context, task domain.Task, reminder domain.Reminder) error
 }
@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// MentionResolver turns @handles in free text into user IDs.
+// Handles that do not match a user are ignored.
+type MentionResolver interface {
+	Resolve(ctx context.Context, text string) ([]uint, error)
+}
@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// ProjectAccess decides who can see a project. There is no project membership yet, so
+// a user sees a project when they perform, created or observe one of its tasks.
+type ProjectAccess interface {
+	CanSeeProject(ctx context.Context, userID, projectID uint) (bool, error)
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"tasks/internal/domain"
+	"time"
+)
+
+// ReminderStore finds tasks with upcoming deadlines and keeps the durable record of sent reminders.
+type ReminderStore interface {
+	// DueTasks returns tasks on the shard whose due date is within [from, to].
+	DueTasks(ctx context.Context, shardIndex int, from, to time.Time) ([]domain.Task, error)
+	// Claim records the reminder as sent. It returns false if it was already recorded.
+	Claim(ctx context.Context, shardIndex int, r domain.Reminder) (bool, error)
+	// Release removes a claim, e.g. when publishing the reminder failed.
+	Release(ctx context.Context, shardIndex int, r domain.Reminder) error
+}
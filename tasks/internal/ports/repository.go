@@ -3,14 +3,35 @@ package ports
 import (
 	"context"
 	"tasks/internal/domain"
+	"time"
 )
 
 // TaskFilter represents query criteria for listing/searching tasks.
-// Kept minimal for current needs (title, creator, performer).
+// Kept minimal for current needs (title, status, creator, performer, project, tenant,
+// due-date window). DueAfter is inclusive and DueBefore exclusive; a task without a due
+// date never matches a window. ExcludeDone drops tasks with status "done".
 type TaskFilter struct {
 	Title       string
+	Status      string
 	CreatorID   uint
 	PerformerID uint
+	ProjectID   uint
+	TenantID    uint
+	DueAfter    *time.Time
+	DueBefore   *time.Time
+	ExcludeDone bool
+}
+
+// StatsDimensions are the fields task counts can be grouped by. A created_week is the
+// Monday the task's week starts on, as YYYY-MM-DD. By label, a task is counted once for
+// each of its labels, and tasks without labels under "".
+var StatsDimensions = []string{"status", "performer", "creator", "label", "project", "tenant", "created_week"}
+
+// TaskCount is the number of tasks in one group; Keys holds the value of each group-by
+// dimension, in the order requested.
+type TaskCount struct {
+	Keys  []string
+	Count int64
 }
 
 type UpdateTaskInput struct {
@@ -20,7 +41,14 @@ type UpdateTaskInput struct {
 	Status      string
 	PerformerID uint
 	CreatorID   uint
+	ProjectID   uint
 	ObserverIDs []uint
+	// MentionIDs replaces the task's mentions; nil keeps them, e.g. when they could not
+	// be resolved.
+	MentionIDs *[]uint
+	// Labels replaces the task's labels.
+	Labels []string
+	DueAt  *time.Time
 }
 
 // Repository represents persistence operations required by use-cases.
@@ -30,13 +58,17 @@ type Repository interface {
 	// Find returns tasks matching the filter from the specified shard index.
 	// If shardIndex is negative, caller may interpret it as "search all shards" (adapter-specific).
 	Find(ctx context.Context, filter TaskFilter, shardIndex int) ([]domain.Task, error)
+	// CountTasks counts the tasks on the shard matching the filter, grouped by groupBy
+	// (names from StatsDimensions), in the database.
+	CountTasks(ctx context.Context, filter TaskFilter, groupBy []string, shardIndex int) ([]TaskCount, error)
 	Delete(ctx context.Context, taskID uint) error
 	GetByID(ctx context.Context, taskID uint) (*domain.Task, error)
 	Update(ctx context.Context, input UpdateTaskInput) (*domain.Task, error)
 }
 
 // IDAllocator generates IDs for new tasks.
-// NextID should return the next unique ID (e.g., from a per-shard allocator).
+// NextID returns an ID that is unique across all shards for a task stored on shardIndex;
+// allocators may use the shard to avoid central coordination.
 type IDAllocator interface {
-	NextID(ctx context.Context) (uint, error)
+	NextID(ctx context.Context, shardIndex int) (uint, error)
 }
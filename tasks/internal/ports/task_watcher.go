@@ -0,0 +1,50 @@
+package ports
+
+import "tasks/internal/domain"
+
+// TaskWatcher lets use-cases subscribe to task changes published in this process.
+type TaskWatcher interface {
+	// Subscribe starts a subscription. With fromSeq > 0 buffered changes after fromSeq are
+	// replayed first; domain.ErrSequenceTooOld is returned if they are no longer available.
+	Subscribe(fromSeq uint64) (Subscription, error)
+}
+
+type Subscription interface {
+	// Changes is closed when the subscription ends; Err then reports why.
+	Changes() <-chan domain.TaskChange
+	Err() error
+	Close()
+}
+
+// WatchFilter selects which changes a watcher receives. Zero values match everything.
+type WatchFilter struct {
+	TaskIDs     []uint
+	PerformerID uint
+	CreatorID   uint
+	ProjectID   uint
+}
+
+func (f WatchFilter) Matches(task domain.Task) bool {
+	if len(f.TaskIDs) > 0 {
+		found := false
+		for _, id := range f.TaskIDs {
+			if id == task.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.PerformerID != 0 && f.PerformerID != task.PerformerId {
+		return false
+	}
+	if f.CreatorID != 0 && f.CreatorID != task.CreatorId {
+		return false
+	}
+	if f.ProjectID != 0 && f.ProjectID != task.ProjectId {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"tasks/internal/domain"
+)
+
+// UserDirectory resolves users known to the auth service.
+type UserDirectory interface {
+	// FindByEmail returns the user with exactly the given email or an error if there is none.
+	FindByEmail(ctx context.Context, email string) (domain.User, error)
+	// FindByName returns the user whose name equals name case-insensitively.
+	FindByName(ctx context.Context, name string) (domain.User, error)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"tasks/internal/domain"
+)
+
+// ViewListFilter selects the views a user can see: their own plus views shared with ProjectID.
+type ViewListFilter struct {
+	OwnerID   uint
+	ProjectID uint
+}
+
+// ViewRepository persists saved views. Views are small, so lookups may scan every shard.
+type ViewRepository interface {
+	Save(ctx context.Context, view domain.View, shardIndex int) error
+	GetByID(ctx context.Context, id string) (*domain.View, error)
+	List(ctx context.Context, filter ViewListFilter) ([]domain.View, error)
+	Update(ctx context.Context, view domain.View) error
+	Delete(ctx context.Context, id string) error
+}
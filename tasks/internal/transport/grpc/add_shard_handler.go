@@ -0,0 +1,26 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+)
+
+func (s *ShardAdminServer) AddShard(ctx context.Context, req *taskpb.AddShardRequest) (*taskpb.AddShardResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	res, err := s.AddShardUC.Execute(ctx, use_case.AddShardCommand{ID: req.Id, DSN: req.Dsn, Weight: req.Weight, Replicas: req.Replicas})
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+
+	return &taskpb.AddShardResponse{
+		ShardIndex:       int32(res.Shard.Index),
+		ShardId:          res.Shard.ID,
+		TopologyVersion:  res.Topology.Version,
+		ShardCount:       int32(len(res.Topology.Shards)),
+		RebalanceStarted: res.RebalanceStarted,
+	}, nil
+}
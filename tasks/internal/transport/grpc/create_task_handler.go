@@ -16,11 +16,15 @@ func (s *TaskServer) CreateTask(
 		Description: req.Description,
 		PerformerID: uint(req.PerformerId),
 		CreatorID:   uint(req.CreatorId),
+		ProjectID:   uint(req.ProjectId),
+		TenantID:    uint(req.TenantId),
+		Labels:      req.Labels,
+		DueAt:       timestampToTimePtr(req.DueAt),
 	}
 
 	task, err := s.CreateUC.Execute(ctx, cmd)
 	if err != nil {
-		return nil, err
+		return nil, taskError(err)
 	}
 
 	return &taskpb.TaskResponse{
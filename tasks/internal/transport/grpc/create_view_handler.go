@@ -0,0 +1,26 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+)
+
+func (s *TaskServer) CreateView(ctx context.Context, req *taskpb.CreateViewRequest) (*taskpb.ViewResponse, error) {
+	cmd := use_case.CreateViewCommand{
+		Name:       req.Name,
+		OwnerID:    uint(req.OwnerId),
+		Filter:     viewFilterFromProto(req.Filter),
+		SortBy:     req.SortBy,
+		SortDesc:   req.SortDesc,
+		Columns:    req.Columns,
+		Visibility: visibilityFromProto(req.Visibility),
+		ProjectID:  uint(req.ProjectId),
+	}
+
+	view, err := s.CreateViewUC.Execute(ctx, cmd)
+	if err != nil {
+		return nil, viewError(err)
+	}
+	return &taskpb.ViewResponse{View: ViewToProto(&view)}, nil
+}
@@ -13,7 +13,7 @@ func (s *TaskServer) DeleteTask(ctx context.Context, req *taskpb.DeleteTaskReque
 	ok, err := s.DeleteUC.Execute(ctx, cmd)
 
 	if err != nil {
-		return nil, err
+		return nil, taskError(err)
 	}
 
 	if !ok {
@@ -0,0 +1,19 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+)
+
+func (s *TaskServer) DeleteView(ctx context.Context, req *taskpb.DeleteViewRequest) (*taskpb.DeleteViewResponse, error) {
+	cmd := use_case.DeleteViewCommand{
+		ID:          req.Id,
+		RequesterID: uint(req.RequesterId),
+	}
+
+	if err := s.DeleteViewUC.Execute(ctx, cmd); err != nil {
+		return nil, viewError(err)
+	}
+	return &taskpb.DeleteViewResponse{Message: "View deleted"}, nil
+}
@@ -0,0 +1,29 @@
+package grpc
+
+import (
+	"context"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ShardAdminServer) DrainShard(ctx context.Context, req *taskpb.DrainShardRequest) (*taskpb.DrainShardResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if req.ShardId == "" {
+		return nil, status.Error(codes.InvalidArgument, "shard_id is required")
+	}
+
+	res, err := s.DrainShardUC.Execute(ctx, req.ShardId)
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+
+	return &taskpb.DrainShardResponse{
+		Shard:            shardInfoToProto(res.Shard),
+		TopologyVersion:  res.Topology.Version,
+		RebalanceStarted: res.RebalanceStarted,
+	}, nil
+}
@@ -0,0 +1,19 @@
+package grpc
+
+import (
+	"tasks/internal/domain"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+)
+
+func (s *TaskServer) ExportTasks(req *taskpb.ExportTasksRequest, stream taskpb.TaskService_ExportTasksServer) error {
+	cmd := use_case.ExportTasksCommand{
+		PerformerID: uint(req.PerformerId),
+		CreatorID:   uint(req.CreatorId),
+		TenantID:    uint(req.TenantId),
+	}
+
+	return s.ExportUC.Execute(stream.Context(), cmd, func(task domain.Task) error {
+		return stream.Send(ToProto(&task))
+	})
+}
@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *ShardAdminServer) GetRebalanceProgress(ctx context.Context, req *taskpb.GetRebalanceProgressRequest) (*taskpb.RebalanceProgress, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	p, err := s.GetRebalanceProgressUC.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &taskpb.RebalanceProgress{
+		Running:         p.Running,
+		TopologyVersion: p.TopologyVersion,
+		ShardsTotal:     int32(p.ShardsTotal),
+		ShardsScanned:   int32(p.ShardsScanned),
+		TasksMoved:      int64(p.TasksMoved),
+		TasksFailed:     int64(p.TasksFailed),
+		LastError:       p.LastError,
+		PerformersTotal: int32(p.PerformersTotal),
+		PerformersDone:  int32(p.PerformersDone),
+		ResumedMoves:    int32(p.ResumedMoves),
+		TasksTotal:      int64(p.TasksTotal),
+		TasksRemaining:  int64(p.TasksRemaining),
+	}
+	if !p.StartedAt.IsZero() {
+		res.StartedAt = timestamppb.New(p.StartedAt)
+	}
+	if !p.FinishedAt.IsZero() {
+		res.FinishedAt = timestamppb.New(p.FinishedAt)
+	}
+	return res, nil
+}
@@ -13,7 +13,7 @@ func (s *TaskServer) GetTask(ctx context.Context, req *taskpb.GetTaskRequest) (*
 	task, err := s.GetTaskUC.Execute(ctx, cmd)
 
 	if err != nil {
-		return nil, err
+		return nil, taskError(err)
 	}
 	toProtoTask := ToProto(&task)
 	return &taskpb.TaskResponse{Task: toProtoTask}, nil
@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *TaskServer) GetTaskStats(
+	ctx context.Context,
+	req *taskpb.GetTaskStatsRequest,
+) (*taskpb.GetTaskStatsResponse, error) {
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	stats, err := s.GetTaskStatsUC.Execute(ctx, use_case.GetTaskStatsCommand{
+		GroupBy:     req.GroupBy,
+		Title:       req.Title,
+		Status:      req.Status,
+		PerformerID: uint(req.PerformerId),
+		CreatorID:   uint(req.CreatorId),
+		ProjectID:   uint(req.ProjectId),
+		TenantID:    uint(req.TenantId),
+		Limit:       int(req.Limit),
+	})
+	if err != nil {
+		if errors.Is(err, use_case.ErrInvalidGroupBy) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, taskError(err)
+	}
+
+	res := &taskpb.GetTaskStatsResponse{
+		GroupBy:          stats.GroupBy,
+		Total:            stats.Total,
+		RebalancePending: stats.RebalancePending,
+	}
+	for _, g := range stats.Groups {
+		res.Groups = append(res.Groups, &taskpb.TaskStatsGroup{Keys: g.Keys, Count: g.Count})
+	}
+	for _, idx := range stats.FailedShards {
+		res.FailedShards = append(res.FailedShards, int32(idx))
+	}
+	return res, nil
+}
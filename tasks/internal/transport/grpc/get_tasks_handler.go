@@ -2,8 +2,12 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"tasks/internal/use_case"
 	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // its have to be transport adapter
@@ -14,14 +18,24 @@ func (s *TaskServer) GetTasks(
 
 	cmd := use_case.GetTasksCommand{
 		Title:       req.Title,
+		Status:      req.Status,
 		PerformerID: uint(req.PerformerId),
 		CreatorID:   uint(req.CreatorId),
+		ProjectID:   uint(req.ProjectId),
+		TenantID:    uint(req.TenantId),
+		SortBy:      req.SortBy,
+		SortDesc:    req.SortDesc,
+		ViewID:      req.ViewId,
+		RequesterID: uint(req.RequesterId),
 	}
 
 	tasks, err := s.GetTasksUC.Execute(ctx, cmd)
 
 	if err != nil {
-		return nil, err
+		if errors.Is(err, use_case.ErrUnknownSortField) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown sort field %q", req.SortBy)
+		}
+		return nil, taskError(viewError(err))
 	}
 
 	protoTasks := make([]*taskpb.Task, 0, len(tasks))
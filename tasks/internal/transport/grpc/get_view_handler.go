@@ -0,0 +1,20 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+)
+
+func (s *TaskServer) GetView(ctx context.Context, req *taskpb.GetViewRequest) (*taskpb.ViewResponse, error) {
+	cmd := use_case.GetViewCommand{
+		ID:          req.Id,
+		RequesterID: uint(req.RequesterId),
+	}
+
+	view, err := s.GetViewUC.Execute(ctx, cmd)
+	if err != nil {
+		return nil, viewError(err)
+	}
+	return &taskpb.ViewResponse{View: ViewToProto(&view)}, nil
+}
@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ShardAdminServer) ListHotPerformers(ctx context.Context, req *taskpb.ListHotPerformersRequest) (*taskpb.ListHotPerformersResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	hot, err := s.ListHotPerformersUC.Execute(ctx, shard.HotThresholds{
+		TaskShare:    req.TaskShare,
+		MinTasks:     req.MinTasks,
+		RequestShare: req.RequestShare,
+		MinRequests:  req.MinRequests,
+		Limit:        int(req.Limit),
+	})
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+
+	res := &taskpb.ListHotPerformersResponse{}
+	for _, h := range hot {
+		p := &taskpb.HotPerformer{
+			ShardIndex:   int32(h.Shard),
+			ShardId:      h.ShardID,
+			PerformerId:  uint64(h.PerformerID),
+			Tasks:        h.Tasks,
+			TaskShare:    h.TaskShare,
+			Requests:     h.Requests,
+			RequestShare: h.RequestShare,
+		}
+		if h.Override != nil {
+			p.Override = overrideToProto(*h.Override)
+		}
+		res.Performers = append(res.Performers, p)
+	}
+	return res, nil
+}
+
+func (s *ShardAdminServer) SetPerformerPlacement(ctx context.Context, req *taskpb.SetPerformerPlacementRequest) (*taskpb.SetPerformerPlacementResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if req.PerformerId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "performer_id is required")
+	}
+
+	res, err := s.SetPerformerPlacementUC.Execute(ctx, shard.PerformerOverride{
+		PerformerID: uint(req.PerformerId),
+		Shards:      req.ShardIds,
+		SplitBy:     req.SplitBy,
+	})
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+	return &taskpb.SetPerformerPlacementResponse{
+		TopologyVersion:  res.Topology.Version,
+		RebalanceStarted: res.RebalanceStarted,
+	}, nil
+}
+
+func overrideToProto(o shard.PerformerOverride) *taskpb.PerformerOverride {
+	return &taskpb.PerformerOverride{PerformerId: uint64(o.PerformerID), ShardIds: o.Shards, SplitBy: o.SplitBy}
+}
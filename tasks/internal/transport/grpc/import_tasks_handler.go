@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"errors"
+	"io"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+)
+
+// ImportTasks consumes a stream of records and creates a task for each valid one.
+// The dry_run flag of the first message applies to the whole stream.
+func (s *TaskServer) ImportTasks(stream taskpb.TaskService_ImportTasksServer) error {
+	ctx := stream.Context()
+	resp := &taskpb.ImportTasksResponse{}
+	first := true
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			resp.DryRun = req.DryRun
+			first = false
+		}
+
+		rec := use_case.ImportTaskRecord{ExternalID: req.ExternalId}
+		if t := req.Task; t != nil {
+			rec.Task = use_case.CreateTaskCommand{
+				Title:       t.Title,
+				Description: t.Description,
+				Status:      t.Status,
+				PerformerID: uint(t.PerformerId),
+				CreatorID:   uint(t.CreatorId),
+				ProjectID:   uint(t.ProjectId),
+				TenantID:    uint(t.TenantId),
+				DueAt:       timestampToTimePtr(t.DueAt),
+				ObserverIDs: uint64SliceToUint(t.ObserverIds),
+				Labels:      t.Labels,
+			}
+		}
+
+		result := s.ImportUC.Execute(ctx, rec, resp.DryRun)
+		resp.Total++
+
+		pbResult := &taskpb.ImportTaskResult{
+			ExternalId: result.ExternalID,
+			TaskId:     uint64(result.TaskID),
+		}
+		if result.Err != nil {
+			pbResult.Error = result.Err.Error()
+			resp.Failed++
+		} else if !resp.DryRun {
+			resp.Created++
+		}
+		resp.Results = append(resp.Results, pbResult)
+	}
+}
@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	"context"
+	"tasks/proto/taskpb"
+)
+
+func (s *ShardAdminServer) ListShards(ctx context.Context, req *taskpb.ListShardsRequest) (*taskpb.ListShardsResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	topo, replicas := s.ListShardsUC.Execute(ctx)
+	res := &taskpb.ListShardsResponse{TopologyVersion: topo.Version, ShardKey: string(topo.ShardKey)}
+	for _, info := range topo.Shards {
+		res.Shards = append(res.Shards, shardInfoToProto(info))
+	}
+	for _, o := range topo.Overrides {
+		res.Overrides = append(res.Overrides, overrideToProto(o))
+	}
+	for _, p := range topo.Tenants {
+		res.Tenants = append(res.Tenants, tenantPlacementToProto(p))
+	}
+	for _, r := range replicas {
+		res.Replicas = append(res.Replicas, replicaStatusToProto(r))
+	}
+	return res, nil
+}
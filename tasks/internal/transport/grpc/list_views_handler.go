@@ -0,0 +1,25 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+)
+
+func (s *TaskServer) ListViews(ctx context.Context, req *taskpb.ListViewsRequest) (*taskpb.ListViewsResponse, error) {
+	cmd := use_case.ListViewsCommand{
+		RequesterID: uint(req.RequesterId),
+		ProjectID:   uint(req.ProjectId),
+	}
+
+	views, err := s.ListViewsUC.Execute(ctx, cmd)
+	if err != nil {
+		return nil, viewError(err)
+	}
+
+	protoViews := make([]*taskpb.View, 0, len(views))
+	for _, view := range views {
+		protoViews = append(protoViews, ViewToProto(&view))
+	}
+	return &taskpb.ListViewsResponse{Views: protoViews}, nil
+}
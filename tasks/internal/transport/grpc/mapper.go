@@ -20,8 +20,13 @@ func ToProto(task *domain.Task) *taskpb.Task {
 		Description: task.Description,
 		PerformerId: uint64(task.PerformerId),
 		CreatorId:   uint64(task.CreatorId),
+		ProjectId:   uint64(task.ProjectId),
+		TenantId:    uint64(task.TenantId),
 		ObserverIds: observersToIDs(task.Observers),
+		MentionIds:  uintSliceToUint64(task.Mentions),
+		Labels:      task.Labels,
 		Status:      task.Status,
+		DueAt:       timeToTimestamp(task.DueAt),
 		CreatedAt:   timestamppb.New(task.CreatedAt),
 		UpdatedAt:   timestamppb.New(task.UpdatedAt),
 	}
@@ -38,14 +43,18 @@ func ToDomain(pb *taskpb.Task) *domain.Task {
 		Description: pb.Description,
 		PerformerId: uint(pb.PerformerId),
 		CreatorId:   uint(pb.CreatorId),
+		ProjectId:   uint(pb.ProjectId),
+		TenantId:    uint(pb.TenantId),
 		Observers:   idsToObservers(pb.ObserverIds),
+		Mentions:    uint64SliceToUint(pb.MentionIds),
+		Labels:      pb.Labels,
 		Status:      pb.Status,
+		DueAt:       timestampToTimePtr(pb.DueAt),
 		CreatedAt:   timestampToTime(pb.CreatedAt),
 		UpdatedAt:   timestampToTime(pb.UpdatedAt),
 	}
 }
 
-//nolint:unused // Reserved conversion helper for partial update payload mapping.
 func uintSliceToUint64(src []uint) []uint64 {
 	if len(src) == 0 {
 		return nil
@@ -57,7 +66,6 @@ func uintSliceToUint64(src []uint) []uint64 {
 	return res
 }
 
-//nolint:unused // Reserved conversion helper for partial update payload mapping.
 func uint64SliceToUint(src []uint64) []uint {
 	if len(src) == 0 {
 		return nil
@@ -103,3 +111,18 @@ func timestampToTime(ts *timestamppb.Timestamp) time.Time {
 	}
 	return ts.AsTime()
 }
+
+func timestampToTimePtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func timeToTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"strconv"
+	"tasks/internal/infrastructure/session"
 	"tasks/logger"
 
 	"github.com/google/uuid"
@@ -10,10 +12,6 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
-type contextKey string
-
-const requestIDContextKey contextKey = "requestID"
-
 func UnaryLoggingInterceptor() grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
@@ -30,7 +28,13 @@ func UnaryLoggingInterceptor() grpc.UnaryServerInterceptor {
 			reqID = uuid.New().String()
 		}
 
-		ctx = context.WithValue(ctx, requestIDContextKey, reqID)
+		ctx = logger.WithRequestID(ctx, reqID)
+		// the gateway forwards the authenticated user for read-your-writes routing
+		var userID uint64
+		if values := md.Get("x-user-id"); len(values) > 0 {
+			userID, _ = strconv.ParseUint(values[0], 10, 64)
+		}
+		ctx = session.New(ctx, uint(userID))
 		logger.Info(ctx, "Incoming gRPC request", zap.String("method", info.FullMethod))
 		resp, err := handler(ctx, req)
 		if err != nil {
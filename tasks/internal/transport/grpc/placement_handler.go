@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *ShardAdminServer) ExplainPlacement(ctx context.Context, req *taskpb.ExplainPlacementRequest) (*taskpb.ExplainPlacementResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	e, err := s.ExplainPlacementUC.Execute(ctx, uint(req.TaskId), uint(req.PerformerId))
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+
+	res := &taskpb.ExplainPlacementResponse{
+		TaskId:              uint64(e.TaskID),
+		PerformerId:         uint64(e.Key.PerformerID),
+		ProjectId:           uint64(e.Key.ProjectID),
+		CreatorId:           uint64(e.Key.CreatorID),
+		TenantId:            uint64(e.Key.TenantID),
+		TaskFound:           e.TaskFound,
+		ShardKey:            string(e.ShardKey),
+		RingKey:             e.RingKey,
+		Hash:                e.Hash,
+		VnodeHash:           e.VnodeHash,
+		Vnode:               e.Vnode,
+		Rule:                e.Rule,
+		ExpectedShardIndex:  int32(e.Expected),
+		ExpectedShardId:     e.ExpectedID,
+		DirectoryShardIndex: int32(e.Directory),
+		DirectoryError:      e.DirError,
+		RebalancePending:    e.Moving,
+	}
+	if e.Vnode != "" {
+		res.RingShardIndex, res.RingShardId = int32(e.RingShard), e.RingID
+	}
+	for _, c := range e.Copies {
+		res.Copies = append(res.Copies, &taskpb.TaskCopy{
+			ShardIndex:  int32(c.Shard),
+			ShardId:     c.ShardID,
+			PerformerId: uint64(c.Key.PerformerID),
+			TenantId:    uint64(c.Key.TenantID),
+			Deleted:     c.Deleted,
+			UpdatedAt:   timestamppb.New(c.UpdatedAt),
+		})
+	}
+	return res, nil
+}
+
+func (s *ShardAdminServer) DumpRing(ctx context.Context, req *taskpb.DumpRingRequest) (*taskpb.DumpRingResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	layout := s.DumpRingUC.Execute(req.IncludeVnodes)
+	res := &taskpb.DumpRingResponse{
+		TopologyVersion: layout.TopologyVersion,
+		ShardKey:        string(layout.ShardKey),
+		VnodesPerShard:  int32(layout.VnodesPerShard),
+	}
+	for _, sh := range layout.Shards {
+		res.Shards = append(res.Shards, &taskpb.RingShard{
+			Index:             int32(sh.Index),
+			Id:                sh.ID,
+			State:             string(sh.State),
+			Weight:            sh.Weight,
+			Vnodes:            int32(sh.Vnodes),
+			Share:             sh.Share,
+			DedicatedTenantId: uint64(sh.TenantID),
+		})
+	}
+	for _, v := range layout.Vnodes {
+		res.Vnodes = append(res.Vnodes, &taskpb.RingVnode{Hash: v.Hash, ShardIndex: int32(v.Shard), Key: v.Key})
+	}
+	return res, nil
+}
@@ -0,0 +1,23 @@
+package grpc
+
+import (
+	"context"
+	"tasks/proto/taskpb"
+)
+
+func (s *ShardAdminServer) RebuildDirectory(ctx context.Context, req *taskpb.RebuildDirectoryRequest) (*taskpb.RebuildDirectoryResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := s.RebuildDirectoryUC.Execute(ctx)
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+	return &taskpb.RebuildDirectoryResponse{
+		ShardsScanned: int32(r.ShardsScanned),
+		TasksAssigned: int64(r.TasksAssigned),
+		Duplicates:    int64(r.Duplicates),
+		Durable:       r.Durable,
+	}, nil
+}
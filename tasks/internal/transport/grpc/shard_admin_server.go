@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"tasks/internal/domain/shard"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type ShardAdminServer struct {
+	taskpb.UnimplementedShardAdminServer
+	// AdminToken must be sent as "authorization: Bearer <token>" metadata. Without it
+	// every call is refused, unless AllowUnauthenticated is set.
+	AdminToken string
+	// AllowUnauthenticated opens the service when AdminToken is empty. For local
+	// development only: the RPCs add shards with any DSN and move all tasks.
+	AllowUnauthenticated bool
+
+	AddShardUC              *use_case.AddShard
+	GetRebalanceProgressUC  *use_case.GetRebalanceProgress
+	DrainShardUC            *use_case.DrainShard
+	ListShardsUC            *use_case.ListShards
+	VerifyShardsUC          *use_case.VerifyShards
+	RebuildDirectoryUC      *use_case.RebuildDirectory
+	SetShardWeightUC        *use_case.SetShardWeight
+	SimulateShardWeightsUC  *use_case.SimulateShardWeights
+	ListHotPerformersUC     *use_case.ListHotPerformers
+	SetPerformerPlacementUC *use_case.SetPerformerPlacement
+	GetShardStatusUC        *use_case.GetShardStatus
+	SetShardKeyUC           *use_case.SetShardKey
+	SetTenantPlacementUC    *use_case.SetTenantPlacement
+	ExplainPlacementUC      *use_case.ExplainPlacement
+	DumpRingUC              *use_case.DumpRing
+}
+
+func (s *ShardAdminServer) authorize(ctx context.Context) error {
+	if s.AdminToken == "" {
+		if s.AllowUnauthenticated {
+			return nil
+		}
+		return status.Error(codes.Unauthenticated, "admin token not configured")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get("authorization") {
+		token := strings.TrimPrefix(v, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.AdminToken)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "admin token required")
+}
+
+func shardInfoToProto(info shard.ShardInfo) *taskpb.ShardInfo {
+	return &taskpb.ShardInfo{Index: int32(info.Index), Id: info.ID, State: string(info.State), Weight: info.EffectiveWeight()}
+}
+
+// shardAdminError maps topology errors to gRPC status codes.
+func shardAdminError(err error) error {
+	switch {
+	case errors.Is(err, use_case.ErrShardDSNRequired),
+		errors.Is(err, shard.ErrInvalidShardWeight),
+		errors.Is(err, shard.ErrInvalidOverride),
+		errors.Is(err, shard.ErrInvalidShardKey),
+		errors.Is(err, shard.ErrInvalidTenantPlacement),
+		errors.Is(err, shard.ErrNothingToExplain):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, shard.ErrShardNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, shard.ErrShardIDTaken):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, shard.ErrTopologyMismatch),
+		errors.Is(err, shard.ErrInvalidShardState),
+		errors.Is(err, shard.ErrLastActiveShard),
+		errors.Is(err, shard.ErrRebalanceRunning):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, shard.ErrTopologyBusy):
+		return status.Error(codes.Aborted, err.Error())
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *ShardAdminServer) SetShardKey(ctx context.Context, req *taskpb.SetShardKeyRequest) (*taskpb.SetShardKeyResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if req.ShardKey == "" {
+		return nil, status.Error(codes.InvalidArgument, "shard_key is required")
+	}
+
+	res, err := s.SetShardKeyUC.Execute(ctx, shard.ShardKey(req.ShardKey))
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+	return &taskpb.SetShardKeyResponse{
+		TopologyVersion:  res.Topology.Version,
+		RebalanceStarted: res.RebalanceStarted,
+	}, nil
+}
+
+func (s *ShardAdminServer) SetTenantPlacement(ctx context.Context, req *taskpb.SetTenantPlacementRequest) (*taskpb.SetTenantPlacementResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if req.TenantId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "tenant_id is required")
+	}
+
+	res, err := s.SetTenantPlacementUC.Execute(ctx, shard.TenantPlacement{
+		TenantID: uint(req.TenantId),
+		Shards:   req.ShardIds,
+	})
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+	return &taskpb.SetTenantPlacementResponse{
+		TopologyVersion:  res.Topology.Version,
+		RebalanceStarted: res.RebalanceStarted,
+	}, nil
+}
+
+func tenantPlacementToProto(p shard.TenantPlacement) *taskpb.TenantPlacement {
+	return &taskpb.TenantPlacement{TenantId: uint64(p.TenantID), ShardIds: p.Shards}
+}
@@ -0,0 +1,80 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/cache"
+	"tasks/proto/taskpb"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *ShardAdminServer) GetShardStatus(ctx context.Context, req *taskpb.GetShardStatusRequest) (*taskpb.GetShardStatusResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	shards, replicas := s.GetShardStatusUC.Execute(ctx)
+	res := &taskpb.GetShardStatusResponse{}
+	for _, h := range shards {
+		res.Shards = append(res.Shards, &taskpb.ShardStatus{
+			Index:               int32(h.Shard),
+			Id:                  h.ShardID,
+			State:               string(h.State),
+			Breaker:             h.Breaker,
+			ConsecutiveFailures: int32(h.Failures),
+			LastError:           h.LastError,
+			OpenedAt:            optionalTimestamp(h.OpenedAt),
+			PingLatencyMs:       milliseconds(h.PingLatency),
+			PingError:           h.PingError,
+			CheckedAt:           optionalTimestamp(h.CheckedAt),
+			Pool: &taskpb.ConnectionPool{
+				Open:      int32(h.Pool.OpenConnections),
+				InUse:     int32(h.Pool.InUse),
+				Idle:      int32(h.Pool.Idle),
+				MaxOpen:   int32(h.Pool.MaxOpenConnections),
+				WaitCount: h.Pool.WaitCount,
+				WaitMs:    milliseconds(h.Pool.WaitDuration),
+			},
+		})
+	}
+	for _, r := range replicas {
+		res.Replicas = append(res.Replicas, replicaStatusToProto(r))
+	}
+	res.Cache = &taskpb.CacheStatus{RedisAvailable: cache.Available()}
+	if st, ok := s.GetShardStatusUC.CacheStats(); ok {
+		res.Cache.LocalHits = st.LocalHits
+		res.Cache.LocalMisses = st.LocalMisses
+		res.Cache.RedisHits = st.RedisHits
+		res.Cache.RedisMisses = st.RedisMisses
+		res.Cache.Coalesced = st.Coalesced
+		res.Cache.Evictions = st.Evictions
+		res.Cache.Invalidations = st.Invalidations
+		res.Cache.Entries = int32(st.Entries)
+		res.Cache.Capacity = int32(st.Capacity)
+	}
+	return res, nil
+}
+
+func replicaStatusToProto(r shard.ReplicaStatus) *taskpb.ReplicaStatus {
+	return &taskpb.ReplicaStatus{
+		ShardIndex: int32(r.Shard),
+		Dsn:        r.DSN,
+		LagSeconds: r.Lag.Seconds(),
+		Healthy:    r.Healthy,
+		Error:      r.Error,
+		CheckedAt:  optionalTimestamp(r.CheckedAt),
+	}
+}
+
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
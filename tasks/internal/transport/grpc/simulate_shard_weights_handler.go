@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+	"tasks/proto/taskpb"
+)
+
+func (s *ShardAdminServer) SimulateShardWeights(ctx context.Context, req *taskpb.SimulateShardWeightsRequest) (*taskpb.SimulateShardWeightsResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	sim, err := s.SimulateShardWeightsUC.Execute(ctx, req.Weights)
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+
+	res := &taskpb.SimulateShardWeightsResponse{
+		PerformersTotal: sim.PerformersTotal,
+		PerformersMoved: sim.PerformersMoved,
+		TasksTotal:      sim.TasksTotal,
+		TasksMoved:      sim.TasksMoved,
+	}
+	for _, d := range sim.Shards {
+		res.Shards = append(res.Shards, &taskpb.ShardDistribution{
+			Index:              int32(d.Index),
+			Id:                 d.ID,
+			CurrentWeight:      d.CurrentWeight,
+			ProposedWeight:     d.ProposedWeight,
+			CurrentShare:       d.CurrentShare,
+			ProposedShare:      d.ProposedShare,
+			CurrentPerformers:  d.CurrentPerformers,
+			ProposedPerformers: d.ProposedPerformers,
+			CurrentTasks:       d.CurrentTasks,
+			ProposedTasks:      d.ProposedTasks,
+		})
+	}
+	return res, nil
+}
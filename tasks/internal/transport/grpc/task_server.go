@@ -1,15 +1,45 @@
 package grpc
 
 import (
+	"errors"
+	"tasks/internal/domain/shard"
 	"tasks/internal/use_case"
 	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type TaskServer struct {
 	taskpb.UnimplementedTaskServiceServer
-	CreateUC   *use_case.CreateTask
-	GetTaskUC  *use_case.GetTask
-	GetTasksUC *use_case.GetTasks
-	DeleteUC   *use_case.DeleteTask
-	UpdateUC   *use_case.UpdateTask
+	CreateUC       *use_case.CreateTask
+	GetTaskUC      *use_case.GetTask
+	GetTasksUC     *use_case.GetTasks
+	GetTaskStatsUC *use_case.GetTaskStats
+	DeleteUC       *use_case.DeleteTask
+	UpdateUC       *use_case.UpdateTask
+	ImportUC       *use_case.ImportTasks
+	ExportUC       *use_case.ExportTasks
+	WatchUC        *use_case.WatchTasks
+
+	CreateViewUC *use_case.CreateView
+	GetViewUC    *use_case.GetView
+	ListViewsUC  *use_case.ListViews
+	UpdateViewUC *use_case.UpdateView
+	DeleteViewUC *use_case.DeleteView
+}
+
+// taskError maps errors of the task use cases to gRPC status codes. A shard with an open
+// circuit breaker is UNAVAILABLE, so clients can retry later or elsewhere; a task that
+// is still being moved to another shard is ABORTED and can be retried right away. An
+// update whose move is pending is UNAVAILABLE too: it was saved, but reads may not show
+// it until the move is recovered.
+func taskError(err error) error {
+	if errors.Is(err, shard.ErrShardUnavailable) || errors.Is(err, shard.ErrTaskMovePending) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	if errors.Is(err, shard.ErrTaskMoving) {
+		return status.Error(codes.Aborted, err.Error())
+	}
+	return err
 }
@@ -19,7 +19,10 @@ func (s *TaskServer) UpdateTask(ctx context.Context, req *taskpb.UpdateTaskReque
 		Status:      req.Status,
 		PerformerID: uint(req.PerformerId),
 		CreatorID:   uint(req.CreatorId),
+		ProjectID:   uint(req.ProjectId),
 		ObserverIDs: req.ObserverIds,
+		Labels:      req.Labels,
+		DueAt:       timestampToTimePtr(req.DueAt),
 	}
 
 	task, err := s.UpdateUC.Execute(ctx, cmd)
@@ -27,7 +30,7 @@ func (s *TaskServer) UpdateTask(ctx context.Context, req *taskpb.UpdateTaskReque
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, status.Errorf(codes.NotFound, "task %d not found", req.Id)
 		}
-		return nil, err
+		return nil, taskError(err)
 	}
 
 	return &taskpb.TaskResponse{Task: ToProto(&task)}, nil
@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+)
+
+func (s *TaskServer) UpdateView(ctx context.Context, req *taskpb.UpdateViewRequest) (*taskpb.ViewResponse, error) {
+	cmd := use_case.UpdateViewCommand{
+		ID:          req.Id,
+		RequesterID: uint(req.RequesterId),
+		Name:        req.Name,
+		Filter:      viewFilterFromProto(req.Filter),
+		SortBy:      req.SortBy,
+		SortDesc:    req.SortDesc,
+		Columns:     req.Columns,
+		Visibility:  visibilityFromProto(req.Visibility),
+		ProjectID:   uint(req.ProjectId),
+	}
+
+	view, err := s.UpdateViewUC.Execute(ctx, cmd)
+	if err != nil {
+		return nil, viewError(err)
+	}
+	return &taskpb.ViewResponse{View: ViewToProto(&view)}, nil
+}
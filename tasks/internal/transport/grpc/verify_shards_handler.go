@@ -0,0 +1,74 @@
+package grpc
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+	"tasks/proto/taskpb"
+)
+
+func (s *ShardAdminServer) VerifyShards(ctx context.Context, req *taskpb.VerifyShardsRequest) (*taskpb.VerifyShardsResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	r, err := s.VerifyShardsUC.Execute(ctx, req.Repair)
+	if err != nil {
+		return nil, shardAdminError(err)
+	}
+
+	res := &taskpb.VerifyShardsResponse{
+		ShardsScanned:    int32(r.ShardsScanned),
+		TasksScanned:     int64(r.TasksScanned),
+		Found:            verifyCountsToProto(r.Found),
+		Repaired:         verifyCountsToProto(r.Repaired),
+		RebalancePending: r.RebalancePending,
+		RebalanceStarted: r.RebalanceStarted,
+		TasksMoving:      int64(r.TasksMoving),
+	}
+	for _, id := range r.Moving {
+		res.MovingTasks = append(res.MovingTasks, uint64(id))
+	}
+	for _, d := range r.Duplicates {
+		shards := make([]int32, len(d.Shards))
+		for i, idx := range d.Shards {
+			shards[i] = int32(idx)
+		}
+		res.Duplicates = append(res.Duplicates, &taskpb.DuplicateTask{TaskId: uint64(d.TaskID), Shards: shards, KeptShard: int32(d.Kept)})
+	}
+	for _, m := range r.Misplaced {
+		res.Misplaced = append(res.Misplaced, &taskpb.MisplacedTask{
+			TaskId:        uint64(m.TaskID),
+			PerformerId:   uint64(m.PerformerID),
+			Shard:         int32(m.Shard),
+			ExpectedShard: int32(m.Expected),
+		})
+	}
+	res.StaleMappings = mappingIssuesToProto(r.StaleMappings)
+	res.MissingMappings = mappingIssuesToProto(r.MissingMappings)
+	for _, o := range r.OrphanObservers {
+		res.OrphanObservers = append(res.OrphanObservers, &taskpb.OrphanObserver{
+			Shard:      int32(o.Shard),
+			ObserverId: uint64(o.ObserverID),
+			TaskId:     uint64(o.TaskID),
+		})
+	}
+	return res, nil
+}
+
+func verifyCountsToProto(c shard.VerifyCounts) *taskpb.VerifyCounts {
+	return &taskpb.VerifyCounts{
+		Duplicates:      int64(c.Duplicates),
+		Misplaced:       int64(c.Misplaced),
+		StaleMappings:   int64(c.StaleMappings),
+		MissingMappings: int64(c.MissingMappings),
+		OrphanObservers: int64(c.OrphanObservers),
+	}
+}
+
+func mappingIssuesToProto(issues []shard.MappingIssue) []*taskpb.MappingIssue {
+	out := make([]*taskpb.MappingIssue, 0, len(issues))
+	for _, m := range issues {
+		out = append(out, &taskpb.MappingIssue{TaskId: uint64(m.TaskID), MappedShard: int32(m.Mapped), ActualShard: int32(m.Actual)})
+	}
+	return out
+}
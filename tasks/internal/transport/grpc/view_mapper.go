@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"errors"
+	"tasks/internal/domain"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func ViewToProto(v *domain.View) *taskpb.View {
+	if v == nil {
+		return nil
+	}
+	return &taskpb.View{
+		Id:      v.ID,
+		Name:    v.Name,
+		OwnerId: uint64(v.OwnerID),
+		Filter: &taskpb.ViewFilter{
+			Title:       v.Filter.Title,
+			Status:      v.Filter.Status,
+			PerformerId: uint64(v.Filter.PerformerID),
+			CreatorId:   uint64(v.Filter.CreatorID),
+			ProjectId:   uint64(v.Filter.ProjectID),
+			DueAfter:    timeToTimestamp(v.Filter.DueAfter),
+			DueBefore:   timeToTimestamp(v.Filter.DueBefore),
+			ExcludeDone: v.Filter.ExcludeDone,
+		},
+		SortBy:     v.SortBy,
+		SortDesc:   v.SortDesc,
+		Columns:    v.Columns,
+		Visibility: visibilityToProto(v.Visibility),
+		ProjectId:  uint64(v.ProjectID),
+		CreatedAt:  timestamppb.New(v.CreatedAt),
+		UpdatedAt:  timestamppb.New(v.UpdatedAt),
+	}
+}
+
+func viewFilterFromProto(f *taskpb.ViewFilter) domain.ViewFilter {
+	if f == nil {
+		return domain.ViewFilter{}
+	}
+	return domain.ViewFilter{
+		Title:       f.Title,
+		Status:      f.Status,
+		PerformerID: uint(f.PerformerId),
+		CreatorID:   uint(f.CreatorId),
+		ProjectID:   uint(f.ProjectId),
+		DueAfter:    timestampToTimePtr(f.DueAfter),
+		DueBefore:   timestampToTimePtr(f.DueBefore),
+		ExcludeDone: f.ExcludeDone,
+	}
+}
+
+func visibilityToProto(v string) taskpb.ViewVisibility {
+	if v == domain.ViewProject {
+		return taskpb.ViewVisibility_PROJECT
+	}
+	return taskpb.ViewVisibility_PRIVATE
+}
+
+func visibilityFromProto(v taskpb.ViewVisibility) string {
+	if v == taskpb.ViewVisibility_PROJECT {
+		return domain.ViewProject
+	}
+	return domain.ViewPrivate
+}
+
+// viewError maps view use-case errors to gRPC status codes.
+func viewError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrViewNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, domain.ErrViewForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, domain.ErrInvalidView):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,53 @@
+package grpc
+
+import (
+	"errors"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+	"tasks/internal/use_case"
+	"tasks/proto/taskpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *TaskServer) WatchTasks(req *taskpb.WatchTasksRequest, stream taskpb.TaskService_WatchTasksServer) error {
+	cmd := use_case.WatchTasksCommand{
+		Filter: ports.WatchFilter{
+			TaskIDs:     uint64SliceToUint(req.TaskIds),
+			PerformerID: uint(req.PerformerId),
+			CreatorID:   uint(req.CreatorId),
+			ProjectID:   uint(req.ProjectId),
+		},
+		FromSequence: req.FromSequence,
+	}
+
+	err := s.WatchUC.Execute(stream.Context(), cmd, func(change domain.TaskChange) error {
+		return stream.Send(&taskpb.TaskChange{
+			Sequence:   change.Seq,
+			Type:       changeTypeToProto(change.Type),
+			Task:       ToProto(&change.Task),
+			OccurredAt: timestamppb.New(change.At),
+		})
+	})
+	switch {
+	case errors.Is(err, domain.ErrSequenceTooOld):
+		return status.Errorf(codes.OutOfRange, "sequence %d is no longer available, resync with GetTasks", req.FromSequence)
+	case errors.Is(err, domain.ErrWatchLagged):
+		return status.Error(codes.ResourceExhausted, "watcher fell behind, reconnect with the last received sequence")
+	}
+	return err
+}
+
+func changeTypeToProto(t domain.ChangeType) taskpb.TaskChange_Type {
+	switch t {
+	case domain.ChangeCreated:
+		return taskpb.TaskChange_CREATED
+	case domain.ChangeUpdated:
+		return taskpb.TaskChange_UPDATED
+	case domain.ChangeDeleted:
+		return taskpb.TaskChange_DELETED
+	}
+	return taskpb.TaskChange_TYPE_UNSPECIFIED
+}
@@ -0,0 +1,62 @@
+package use_case
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"tasks/internal/domain/shard"
+)
+
+var ErrShardDSNRequired = errors.New("shard dsn is required")
+
+type AddShard struct {
+	sharder *shard.ShardManager
+}
+
+func NewAddShard(sharder *shard.ShardManager) *AddShard {
+	return &AddShard{sharder: sharder}
+}
+
+type AddShardCommand struct {
+	// ID is the stable shard identifier; empty means the new slot index.
+	ID  string
+	DSN string
+	// Weight scales the shard's share of the ring; 0 means 1.
+	Weight float64
+	// Replicas are DSNs of read replicas of the shard.
+	Replicas []string
+}
+
+type AddShardResult struct {
+	Shard            shard.ShardInfo
+	Topology         shard.Topology
+	RebalanceStarted bool
+}
+
+// Execute adds the shard to the shared topology and starts moving performers whose
+// ring position changed. Rebalancing outlives the request.
+func (uc *AddShard) Execute(ctx context.Context, cmd AddShardCommand) (AddShardResult, error) {
+	dsn := strings.TrimSpace(cmd.DSN)
+	if dsn == "" {
+		return AddShardResult{}, ErrShardDSNRequired
+	}
+
+	var replicas []string
+	for _, r := range cmd.Replicas {
+		if r = strings.TrimSpace(r); r != "" {
+			replicas = append(replicas, r)
+		}
+	}
+	info, topo, err := uc.sharder.AddShard(ctx, shard.ShardInfo{
+		ID:       strings.TrimSpace(cmd.ID),
+		DSN:      dsn,
+		Weight:   cmd.Weight,
+		Replicas: replicas,
+	})
+	if err != nil {
+		return AddShardResult{}, err
+	}
+
+	started := shard.StartRebalance(context.WithoutCancel(ctx))
+	return AddShardResult{Shard: info, Topology: topo, RebalanceStarted: started}, nil
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"tasks/internal/domain"
 	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/persistence"
 	"tasks/internal/ports"
+	"time"
 )
 
 type CreateTask struct {
@@ -13,31 +15,49 @@ type CreateTask struct {
 	producer  ports.EventProducer
 	sharder   *shard.ShardManager
 	allocator ports.IDAllocator
+	mentions  ports.MentionResolver
 }
 
 // NewCreateTask constructs CreateTask use-case with its dependencies.
-func NewCreateTask(repo ports.Repository, cache ports.Cache, producer ports.EventProducer, sharder *shard.ShardManager, allocator ports.IDAllocator) *CreateTask {
-	return &CreateTask{repo: repo, cache: cache, producer: producer, sharder: sharder, allocator: allocator}
+// mentions may be nil, in which case descriptions are not scanned for @handles.
+func NewCreateTask(repo ports.Repository, cache ports.Cache, producer ports.EventProducer, sharder *shard.ShardManager, allocator ports.IDAllocator, mentions ports.MentionResolver) *CreateTask {
+	return &CreateTask{repo: repo, cache: cache, producer: producer, sharder: sharder, allocator: allocator, mentions: mentions}
 }
 
 type CreateTaskCommand struct {
 	Title       string
 	Description string
+	Status      string
 	PerformerID uint
 	CreatorID   uint
+	ProjectID   uint
+	TenantID    uint
 	ObserverIDs []uint
+	Labels      []string
+	DueAt       *time.Time
 }
 
 func (uc *CreateTask) Execute(ctx context.Context, cmd CreateTaskCommand) (domain.Task, error) {
+	shardIndex := uc.sharder.ResolveTask(shard.TaskKey{PerformerID: cmd.PerformerID, ProjectID: cmd.ProjectID, CreatorID: cmd.CreatorID, TenantID: cmd.TenantID})
 
-	id, err := uc.allocator.NextID(ctx)
+	id, err := uc.allocator.NextID(ctx, shardIndex)
 	if err != nil {
 		return domain.Task{}, err
 	}
 
-	shardIndex := uc.sharder.Resolve(cmd.PerformerID)
-
 	task := domain.NewTask(id, cmd.Title, cmd.Description, cmd.CreatorID, cmd.PerformerID)
+	task.ProjectId = cmd.ProjectID
+	task.TenantId = cmd.TenantID
+	task.Labels = domain.NormalizeLabels(cmd.Labels)
+	task.DueAt = cmd.DueAt
+	if cmd.Status != "" {
+		task.Status = cmd.Status
+	}
+	for _, observerID := range cmd.ObserverIDs {
+		task.Observers = append(task.Observers, persistence.Observer{UserId: observerID})
+	}
+
+	task.Mentions, _ = resolveMentions(ctx, uc.mentions, task.Description)
 
 	if err := uc.repo.Save(ctx, task, shardIndex); err != nil {
 		return domain.Task{}, err
@@ -45,6 +65,7 @@ func (uc *CreateTask) Execute(ctx context.Context, cmd CreateTaskCommand) (domai
 
 	_ = uc.cache.SetTask(ctx, task)
 	_ = uc.producer.PublishCreated(ctx, task)
+	publishMentions(ctx, uc.producer, task, task.Mentions)
 
 	return task, nil
 }
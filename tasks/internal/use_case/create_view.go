@@ -0,0 +1,80 @@
+package use_case
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tasks/internal/domain"
+	"tasks/internal/domain/shard"
+	"tasks/internal/ports"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CreateView struct {
+	views   ports.ViewRepository
+	sharder *shard.ShardManager
+}
+
+// NewCreateView constructs CreateView use-case with its dependencies.
+func NewCreateView(views ports.ViewRepository, sharder *shard.ShardManager) *CreateView {
+	return &CreateView{views: views, sharder: sharder}
+}
+
+type CreateViewCommand struct {
+	Name       string
+	OwnerID    uint
+	Filter     domain.ViewFilter
+	SortBy     string
+	SortDesc   bool
+	Columns    []string
+	Visibility string
+	ProjectID  uint
+}
+
+func (uc *CreateView) Execute(ctx context.Context, cmd CreateViewCommand) (domain.View, error) {
+	now := time.Now()
+	view := domain.View{
+		ID:         uuid.NewString(),
+		Name:       strings.TrimSpace(cmd.Name),
+		OwnerID:    cmd.OwnerID,
+		Filter:     cmd.Filter,
+		SortBy:     cmd.SortBy,
+		SortDesc:   cmd.SortDesc,
+		Columns:    cmd.Columns,
+		Visibility: cmd.Visibility,
+		ProjectID:  cmd.ProjectID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if view.Visibility == "" {
+		view.Visibility = domain.ViewPrivate
+	}
+	if err := validateView(view); err != nil {
+		return domain.View{}, err
+	}
+
+	if err := uc.views.Save(ctx, view, uc.sharder.Resolve(view.OwnerID)); err != nil {
+		return domain.View{}, err
+	}
+	return view, nil
+}
+
+func validateView(v domain.View) error {
+	switch {
+	case v.Name == "":
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidView)
+	case len([]rune(v.Name)) > 255:
+		return fmt.Errorf("%w: name exceeds 255 characters", domain.ErrInvalidView)
+	case v.OwnerID == 0:
+		return fmt.Errorf("%w: owner is required", domain.ErrInvalidView)
+	case v.Visibility != domain.ViewPrivate && v.Visibility != domain.ViewProject:
+		return fmt.Errorf("%w: visibility must be %q or %q", domain.ErrInvalidView, domain.ViewPrivate, domain.ViewProject)
+	case v.Visibility == domain.ViewProject && v.ProjectID == 0:
+		return fmt.Errorf("%w: project views need a project_id", domain.ErrInvalidView)
+	case !validSortField(v.SortBy):
+		return fmt.Errorf("%w: %w %q", domain.ErrInvalidView, ErrUnknownSortField, v.SortBy)
+	}
+	return nil
+}
@@ -51,7 +51,6 @@ func (uc *DeleteTask) Execute(
 	}
 
 	_ = cache.DeleteTaskCache(ctx, taskID)
-	_ = cache.DelTaskShard(ctx, taskID)
 	// If we have err here, we return true, because task is already deleted, but log the error for debugging
 	if err := uc.producer.PublishDeleted(ctx, *task); err != nil {
 		return true, err
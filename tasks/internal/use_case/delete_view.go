@@ -0,0 +1,41 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+)
+
+type DeleteView struct {
+	views    ports.ViewRepository
+	projects ports.ProjectAccess
+}
+
+// NewDeleteView constructs DeleteView use-case with its dependencies.
+func NewDeleteView(views ports.ViewRepository, projects ports.ProjectAccess) *DeleteView {
+	return &DeleteView{views: views, projects: projects}
+}
+
+type DeleteViewCommand struct {
+	ID          string
+	RequesterID uint
+}
+
+// Execute deletes the view. Only the owner may delete a view.
+func (uc *DeleteView) Execute(ctx context.Context, cmd DeleteViewCommand) error {
+	view, err := uc.views.GetByID(ctx, cmd.ID)
+	if err != nil {
+		return err
+	}
+	if view.OwnerID != cmd.RequesterID {
+		visible, err := canSeeView(ctx, uc.projects, view, cmd.RequesterID)
+		if err != nil {
+			return err
+		}
+		if !visible {
+			return domain.ErrViewNotFound
+		}
+		return domain.ErrViewForbidden
+	}
+	return uc.views.Delete(ctx, cmd.ID)
+}
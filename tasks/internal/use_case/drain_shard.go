@@ -0,0 +1,32 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type DrainShard struct {
+	sharder *shard.ShardManager
+}
+
+func NewDrainShard(sharder *shard.ShardManager) *DrainShard {
+	return &DrainShard{sharder: sharder}
+}
+
+type DrainShardResult struct {
+	Shard            shard.ShardInfo
+	Topology         shard.Topology
+	RebalanceStarted bool
+}
+
+// Execute takes the shard off the ring and starts a rebalance that moves its tasks and
+// views away; the rebalance removes the shard from the topology once it is empty.
+func (uc *DrainShard) Execute(ctx context.Context, id string) (DrainShardResult, error) {
+	info, topo, err := uc.sharder.DrainShard(ctx, id)
+	if err != nil {
+		return DrainShardResult{}, err
+	}
+
+	started := shard.StartRebalance(context.WithoutCancel(ctx))
+	return DrainShardResult{Shard: info, Topology: topo, RebalanceStarted: started}, nil
+}
@@ -0,0 +1,18 @@
+package use_case
+
+import (
+	"tasks/internal/domain/shard"
+)
+
+type DumpRing struct {
+	sharder *shard.ShardManager
+}
+
+func NewDumpRing(sharder *shard.ShardManager) *DumpRing {
+	return &DumpRing{sharder: sharder}
+}
+
+// Execute returns the ring layout, with every vnode if vnodes is set.
+func (uc *DumpRing) Execute(vnodes bool) shard.RingLayout {
+	return uc.sharder.Ring(vnodes)
+}
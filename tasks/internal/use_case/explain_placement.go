@@ -0,0 +1,19 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type ExplainPlacement struct {
+	sharder *shard.ShardManager
+}
+
+func NewExplainPlacement(sharder *shard.ShardManager) *ExplainPlacement {
+	return &ExplainPlacement{sharder: sharder}
+}
+
+// Execute explains where the task, or else the performer's tasks, should live and where the task is.
+func (uc *ExplainPlacement) Execute(ctx context.Context, taskID, performerID uint) (shard.PlacementExplanation, error) {
+	return uc.sharder.ExplainPlacement(ctx, taskID, performerID)
+}
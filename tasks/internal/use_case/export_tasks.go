@@ -0,0 +1,58 @@
+package use_case
+
+import (
+	"context"
+	"fmt"
+	"tasks/internal/domain"
+	"tasks/internal/domain/shard"
+	"tasks/internal/ports"
+)
+
+type ExportTasks struct {
+	repo    ports.Repository
+	sharder *shard.ShardManager
+}
+
+// NewExportTasks constructs ExportTasks use-case with its dependencies.
+func NewExportTasks(repo ports.Repository, sharder *shard.ShardManager) *ExportTasks {
+	return &ExportTasks{repo: repo, sharder: sharder}
+}
+
+type ExportTasksCommand struct {
+	PerformerID uint
+	CreatorID   uint
+	TenantID    uint
+}
+
+// Execute streams every task matching the command to emit, shard by shard.
+// Unlike GetTasks a failing shard aborts the export, since a partial dump is worse than none.
+// Tasks copied to a second shard by a running rebalance are emitted once.
+func (uc *ExportTasks) Execute(ctx context.Context, cmd ExportTasksCommand, emit func(domain.Task) error) error {
+	filter := ports.TaskFilter{
+		CreatorID:   cmd.CreatorID,
+		PerformerID: cmd.PerformerID,
+		TenantID:    cmd.TenantID,
+	}
+	indexes := uc.sharder.ShardIndexes()
+	if cmd.TenantID != 0 {
+		indexes = uc.sharder.TenantShards(ctx, cmd.TenantID)
+	}
+
+	seen := make(map[uint]bool)
+	for _, i := range indexes {
+		tasks, err := uc.repo.Find(ctx, filter, i)
+		if err != nil {
+			return fmt.Errorf("export shard %d: %w", i, err)
+		}
+		for _, task := range tasks {
+			if seen[task.ID] {
+				continue
+			}
+			seen[task.ID] = true
+			if err := emit(task); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
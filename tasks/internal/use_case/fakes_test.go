@@ -0,0 +1,199 @@
+package use_case_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+)
+
+var errNotFound = errors.New("task not found")
+
+// fakeRepo keeps tasks in memory and applies updates as the Postgres repository does.
+type fakeRepo struct {
+	mu      sync.Mutex
+	tasks   map[uint]domain.Task
+	updates []ports.UpdateTaskInput
+	filters []ports.TaskFilter
+}
+
+func newFakeRepo(tasks ...domain.Task) *fakeRepo {
+	r := &fakeRepo{tasks: make(map[uint]domain.Task)}
+	for _, t := range tasks {
+		r.tasks[t.ID] = t
+	}
+	return r
+}
+
+func (r *fakeRepo) Save(_ context.Context, task domain.Task, _ int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks[task.ID] = task
+	return nil
+}
+
+// Find records the filter and returns every task: filtering is the repository's job.
+func (r *fakeRepo) Find(_ context.Context, filter ports.TaskFilter, _ int) ([]domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.filters = append(r.filters, filter)
+	var tasks []domain.Task
+	for _, t := range r.tasks {
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
+
+func (r *fakeRepo) CountTasks(context.Context, ports.TaskFilter, []string, int) ([]ports.TaskCount, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeRepo) Delete(_ context.Context, taskID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tasks, taskID)
+	return nil
+}
+
+func (r *fakeRepo) GetByID(_ context.Context, taskID uint) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.tasks[taskID]
+	if !ok {
+		return nil, errNotFound
+	}
+	return &t, nil
+}
+
+func (r *fakeRepo) Update(_ context.Context, input ports.UpdateTaskInput) (*domain.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, input)
+	t, ok := r.tasks[input.ID]
+	if !ok {
+		return nil, errNotFound
+	}
+	t.Title, t.Description, t.Status = input.Title, input.Description, input.Status
+	t.PerformerId, t.CreatorId, t.ProjectId = input.PerformerID, input.CreatorID, input.ProjectID
+	if input.MentionIDs != nil {
+		t.Mentions = *input.MentionIDs
+	}
+	t.Labels = input.Labels
+	r.tasks[t.ID] = t
+	return &t, nil
+}
+
+// fakeProducer records the mentions it is asked to announce.
+type fakeProducer struct {
+	mu        sync.Mutex
+	mentioned []uint
+}
+
+func (p *fakeProducer) PublishCreated(context.Context, domain.Task) error { return nil }
+func (p *fakeProducer) PublishDeleted(context.Context, domain.Task) error { return nil }
+func (p *fakeProducer) PublishUpdated(context.Context, domain.Task) error { return nil }
+
+func (p *fakeProducer) PublishMentioned(_ context.Context, _ domain.Task, userID uint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.mentioned = append(p.mentioned, userID)
+	return nil
+}
+
+func (p *fakeProducer) PublishReminder(context.Context, domain.Task, domain.Reminder) error {
+	return nil
+}
+
+// fakeMentions resolves every text to ids, or fails with err.
+type fakeMentions struct {
+	ids []uint
+	err error
+}
+
+func (m fakeMentions) Resolve(context.Context, string) ([]uint, error) { return m.ids, m.err }
+
+// fakeCache is a ports.Cache that misses until a task is set.
+type fakeCache struct {
+	mu    sync.Mutex
+	tasks map[uint]domain.Task
+}
+
+func (c *fakeCache) SetTask(_ context.Context, task domain.Task) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tasks == nil {
+		c.tasks = make(map[uint]domain.Task)
+	}
+	c.tasks[task.ID] = task
+	return nil
+}
+
+func (c *fakeCache) GetTask(_ context.Context, taskID uint) (domain.Task, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tasks[taskID]
+	if !ok {
+		return domain.Task{}, errNotFound
+	}
+	return t, nil
+}
+
+// fakeViews keeps views in memory; List returns the owner's views and the project views
+// of filter.ProjectID.
+type fakeViews struct {
+	views map[string]domain.View
+}
+
+func newFakeViews(views ...domain.View) *fakeViews {
+	f := &fakeViews{views: make(map[string]domain.View)}
+	for _, v := range views {
+		f.views[v.ID] = v
+	}
+	return f
+}
+
+func (f *fakeViews) Save(_ context.Context, v domain.View, _ int) error {
+	f.views[v.ID] = v
+	return nil
+}
+
+func (f *fakeViews) GetByID(_ context.Context, id string) (*domain.View, error) {
+	v, ok := f.views[id]
+	if !ok {
+		return nil, domain.ErrViewNotFound
+	}
+	return &v, nil
+}
+
+func (f *fakeViews) List(_ context.Context, filter ports.ViewListFilter) ([]domain.View, error) {
+	var out []domain.View
+	for _, v := range f.views {
+		if v.OwnerID == filter.OwnerID || (filter.ProjectID != 0 && v.Visibility == domain.ViewProject && v.ProjectID == filter.ProjectID) {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeViews) Update(_ context.Context, v domain.View) error {
+	f.views[v.ID] = v
+	return nil
+}
+
+func (f *fakeViews) Delete(_ context.Context, id string) error {
+	delete(f.views, id)
+	return nil
+}
+
+// fakeProjects lets each user see the listed projects.
+type fakeProjects map[uint][]uint
+
+func (f fakeProjects) CanSeeProject(_ context.Context, userID, projectID uint) (bool, error) {
+	for _, id := range f[userID] {
+		if id == projectID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,18 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type GetRebalanceProgress struct{}
+
+func NewGetRebalanceProgress() *GetRebalanceProgress {
+	return &GetRebalanceProgress{}
+}
+
+// Execute returns the latest rebalance run; a zero value means none has run yet.
+func (uc *GetRebalanceProgress) Execute(ctx context.Context) (shard.RebalanceProgress, error) {
+	p, _, err := shard.GetRebalanceProgress(ctx)
+	return p, err
+}
@@ -0,0 +1,32 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+	"tasks/internal/ports"
+)
+
+type GetShardStatus struct {
+	sharder *shard.ShardManager
+	cache   ports.CacheStatsReporter
+}
+
+// NewGetShardStatus constructs GetShardStatus use-case. cache may be nil when the task
+// cache keeps no counters.
+func NewGetShardStatus(sharder *shard.ShardManager, cache ports.CacheStatsReporter) *GetShardStatus {
+	return &GetShardStatus{sharder: sharder, cache: cache}
+}
+
+// Execute returns the shards' breakers, pings and pools and the replicas' lag as seen by
+// this replica.
+func (uc *GetShardStatus) Execute(ctx context.Context) ([]shard.ShardHealth, []shard.ReplicaStatus) {
+	return uc.sharder.HealthStatuses(), uc.sharder.ReplicaStatuses()
+}
+
+// CacheStats returns the task cache counters of this replica, if they are kept.
+func (uc *GetShardStatus) CacheStats() (ports.CacheStats, bool) {
+	if uc.cache == nil {
+		return ports.CacheStats{}, false
+	}
+	return uc.cache.CacheStats(), true
+}
@@ -2,15 +2,21 @@ package use_case
 
 import (
 	"context"
+	"fmt"
 	"tasks/internal/domain"
+	"tasks/internal/infrastructure/session"
 	"tasks/internal/ports"
 	"tasks/logger"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type GetTask struct {
 	repo     ports.Repository
 	cache    ports.Cache
 	producer ports.EventProducer
+	// loads lets concurrent misses for one task by one user share a single database read.
+	loads singleflight.Group
 }
 
 // NewGetTask constructs GetTask use-case with its dependencies.
@@ -45,14 +51,30 @@ func (uc *GetTask) Execute(ctx context.Context, cmd GetTaskCommand) (domain.Task
 		logger.ZapError(err),
 	)
 
-	// Fetch from repository
-	repoTask, err := uc.repo.GetByID(ctx, taskID)
+	// Fetch from repository, once for all callers missing the same task. The read may go
+	// to a replica unless the user wrote recently, so only the user's own callers share it.
+	userID, wrote := session.UserID(ctx), session.Wrote(ctx)
+	v, err, _ := uc.loads.Do(fmt.Sprintf("%d:%d", taskID, userID), func() (interface{}, error) {
+		// shared by every waiting caller, so not cancelled with the first one; the read
+		// gets a session of its own to tell whether a replica served it
+		ctx := session.New(context.WithoutCancel(ctx), userID)
+		if wrote {
+			session.MarkWrite(ctx)
+		}
+		repoTask, err := uc.repo.GetByID(ctx, taskID)
+		if err != nil {
+			return domain.Task{}, err
+		}
+
+		// Best-effort cache set. A replica may be behind a change whose invalidation
+		// already ran, so only a primary read is cached.
+		if !session.ReadReplica(ctx) {
+			_ = uc.cache.SetTask(ctx, *repoTask)
+		}
+		return *repoTask, nil
+	})
 	if err != nil {
 		return domain.Task{}, err
 	}
-
-	// Best-effort cache set
-	_ = uc.cache.SetTask(ctx, *repoTask)
-
-	return *repoTask, nil
+	return v.(domain.Task), nil
 }
@@ -0,0 +1,147 @@
+package use_case
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"tasks/internal/domain/shard"
+	"tasks/internal/ports"
+	"tasks/logger"
+)
+
+var ErrInvalidGroupBy = errors.New("invalid group_by")
+
+type GetTaskStats struct {
+	repo    ports.Repository
+	sharder *shard.ShardManager
+}
+
+// NewGetTaskStats constructs GetTaskStats use-case with its dependencies.
+func NewGetTaskStats(repo ports.Repository, sharder *shard.ShardManager) *GetTaskStats {
+	return &GetTaskStats{repo: repo, sharder: sharder}
+}
+
+type GetTaskStatsCommand struct {
+	GroupBy     []string
+	Title       string
+	Status      string
+	PerformerID uint
+	CreatorID   uint
+	ProjectID   uint
+	TenantID    uint
+	// Limit keeps the largest groups only; 0 keeps all.
+	Limit int
+}
+
+type TaskStats struct {
+	GroupBy []string
+	// Groups are sorted by count, largest first, then by keys.
+	Groups []ports.TaskCount
+	// Total counts the matching tasks, also those of groups cut by Limit, each once even
+	// when grouped by label.
+	Total int64
+	// FailedShards could not be queried; their tasks are missing from the counts.
+	FailedShards []int
+	// RebalancePending means tasks being moved may be counted on two shards.
+	RebalancePending bool
+}
+
+// Execute counts the matching tasks on each shard in parallel, grouped in the database,
+// and merges the per-shard counts. Shards that fail are reported and skipped, unless
+// every shard fails.
+func (uc *GetTaskStats) Execute(ctx context.Context, cmd GetTaskStatsCommand) (TaskStats, error) {
+	stats := TaskStats{GroupBy: cmd.GroupBy}
+	for i, dim := range cmd.GroupBy {
+		if !slices.Contains(ports.StatsDimensions, dim) {
+			return stats, fmt.Errorf("%w: unknown dimension %q", ErrInvalidGroupBy, dim)
+		}
+		if slices.Contains(cmd.GroupBy[:i], dim) {
+			return stats, fmt.Errorf("%w: %q given twice", ErrInvalidGroupBy, dim)
+		}
+	}
+	// without Redis it is unknown whether tasks are moving; assume they are
+	moving, err := shard.RebalancePending(ctx)
+	stats.RebalancePending = moving || err != nil
+
+	filter := ports.TaskFilter{
+		Title:       cmd.Title,
+		Status:      cmd.Status,
+		CreatorID:   cmd.CreatorID,
+		PerformerID: cmd.PerformerID,
+		ProjectID:   cmd.ProjectID,
+		TenantID:    cmd.TenantID,
+	}
+	indexes := uc.sharder.ShardIndexes()
+	switch {
+	case filter.PerformerID != 0 && !stats.RebalancePending:
+		indexes = uc.sharder.PerformerShards(filter.PerformerID)
+	case filter.TenantID != 0:
+		indexes = uc.sharder.TenantShards(ctx, filter.TenantID)
+	}
+
+	// by label a task is in several groups, so the total is counted on its own
+	byLabel := slices.Contains(cmd.GroupBy, "label")
+	results := make([][]ports.TaskCount, len(indexes))
+	totals := make([]int64, len(indexes))
+	errs := make([]error, len(indexes))
+	var wg sync.WaitGroup
+	for i, idx := range indexes {
+		wg.Add(1)
+		go func(i, idx int) {
+			defer wg.Done()
+			results[i], errs[i] = uc.repo.CountTasks(ctx, filter, cmd.GroupBy, idx)
+			if errs[i] != nil || !byLabel {
+				return
+			}
+			var all []ports.TaskCount
+			all, errs[i] = uc.repo.CountTasks(ctx, filter, nil, idx)
+			for _, c := range all {
+				totals[i] += c.Count
+			}
+		}(i, idx)
+	}
+	wg.Wait()
+
+	merged := make(map[string]int)
+	for i, idx := range indexes {
+		if errs[i] != nil {
+			logger.Warn(ctx, "task stats: shard query failed", logger.ZapInt("shard", idx), logger.ZapError(errs[i]))
+			stats.FailedShards = append(stats.FailedShards, idx)
+			continue
+		}
+		stats.Total += totals[i]
+		for _, c := range results[i] {
+			key := strings.Join(c.Keys, "\x00")
+			if j, ok := merged[key]; ok {
+				stats.Groups[j].Count += c.Count
+				continue
+			}
+			merged[key] = len(stats.Groups)
+			stats.Groups = append(stats.Groups, c)
+		}
+	}
+	if len(indexes) > 0 && len(stats.FailedShards) == len(indexes) {
+		return stats, errs[0]
+	}
+
+	if !byLabel {
+		for _, g := range stats.Groups {
+			stats.Total += g.Count
+		}
+	}
+	sort.Slice(stats.Groups, func(i, j int) bool {
+		a, b := stats.Groups[i], stats.Groups[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return slices.Compare(a.Keys, b.Keys) < 0
+	})
+	if cmd.Limit > 0 && len(stats.Groups) > cmd.Limit {
+		stats.Groups = stats.Groups[:cmd.Limit]
+	}
+	return stats, nil
+}
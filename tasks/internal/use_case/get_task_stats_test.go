@@ -0,0 +1,138 @@
+package use_case_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"tasks/internal/domain/shard"
+	"tasks/internal/infrastructure/cache"
+	"tasks/internal/ports"
+	"tasks/internal/use_case"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"gorm.io/gorm"
+)
+
+// statsRepo answers CountTasks per shard: counts for a grouped query, the shard's total
+// for an ungrouped one, and an error for a failing shard.
+type statsRepo struct {
+	*fakeRepo
+	counts  map[int][]ports.TaskCount
+	totals  map[int]int64
+	failing map[int]bool
+}
+
+func (r statsRepo) CountTasks(_ context.Context, _ ports.TaskFilter, groupBy []string, shardIndex int) ([]ports.TaskCount, error) {
+	if r.failing[shardIndex] {
+		return nil, errors.New("shard down")
+	}
+	if len(groupBy) == 0 {
+		return []ports.TaskCount{{Keys: []string{}, Count: r.totals[shardIndex]}}, nil
+	}
+	return r.counts[shardIndex], nil
+}
+
+// newStatsSharder returns three shards without databases; RebalancePending needs Redis.
+func newStatsSharder(t *testing.T) *shard.ShardManager {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	t.Setenv("REDIS_URL", "redis://"+mr.Addr())
+	cache.InitRedisFromEnv()
+	t.Cleanup(func() { _ = cache.CloseRedis() })
+	shards := []shard.ShardInfo{
+		{Index: 0, ID: "a", State: shard.ShardActive},
+		{Index: 1, ID: "b", State: shard.ShardActive},
+		{Index: 2, ID: "c", State: shard.ShardActive},
+	}
+	return shard.NewShardManagerFromTopology(shard.Topology{Shards: shards}, make([]*gorm.DB, len(shards)))
+}
+
+func count(n int64, keys ...string) ports.TaskCount {
+	return ports.TaskCount{Keys: keys, Count: n}
+}
+
+func TestGetTaskStats(t *testing.T) {
+	sharder := newStatsSharder(t)
+	byStatus := map[int][]ports.TaskCount{
+		0: {count(2, "done"), count(1, "new")},
+		1: {count(3, "done"), count(1, "blocked")},
+		2: {count(1, "new")},
+	}
+	tests := []struct {
+		name       string
+		repo       statsRepo
+		cmd        use_case.GetTaskStatsCommand
+		wantGroups []ports.TaskCount
+		wantTotal  int64
+		wantFailed []int
+		wantErr    bool
+	}{
+		{
+			name:       "merges shards",
+			repo:       statsRepo{counts: byStatus},
+			cmd:        use_case.GetTaskStatsCommand{GroupBy: []string{"status"}},
+			wantGroups: []ports.TaskCount{count(5, "done"), count(2, "new"), count(1, "blocked")},
+			wantTotal:  8,
+		},
+		{
+			name:       "limit keeps the total",
+			repo:       statsRepo{counts: byStatus},
+			cmd:        use_case.GetTaskStatsCommand{GroupBy: []string{"status"}, Limit: 2},
+			wantGroups: []ports.TaskCount{count(5, "done"), count(2, "new")},
+			wantTotal:  8,
+		},
+		{
+			name:       "failed shard is reported and skipped",
+			repo:       statsRepo{counts: byStatus, failing: map[int]bool{1: true}},
+			cmd:        use_case.GetTaskStatsCommand{GroupBy: []string{"status"}},
+			wantGroups: []ports.TaskCount{count(2, "done"), count(2, "new")},
+			wantTotal:  4,
+			wantFailed: []int{1},
+		},
+		{
+			name:    "every shard failed",
+			repo:    statsRepo{failing: map[int]bool{0: true, 1: true, 2: true}},
+			cmd:     use_case.GetTaskStatsCommand{GroupBy: []string{"status"}},
+			wantErr: true,
+		},
+		{
+			name: "label total counts tasks once",
+			repo: statsRepo{
+				counts: map[int][]ports.TaskCount{0: {count(2, "bug"), count(1, "ui")}, 1: {count(1, "bug"), count(1, "")}},
+				totals: map[int]int64{0: 2, 1: 2},
+			},
+			cmd:        use_case.GetTaskStatsCommand{GroupBy: []string{"label"}},
+			wantGroups: []ports.TaskCount{count(3, "bug"), count(1, ""), count(1, "ui")},
+			wantTotal:  4,
+		},
+		{
+			name:    "unknown dimension",
+			cmd:     use_case.GetTaskStatsCommand{GroupBy: []string{"color"}},
+			wantErr: true,
+		},
+		{
+			name:    "repeated dimension",
+			cmd:     use_case.GetTaskStatsCommand{GroupBy: []string{"status", "status"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := use_case.NewGetTaskStats(tt.repo, sharder).Execute(context.Background(), tt.cmd)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Execute = %+v, want an error", stats)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(stats.Groups, tt.wantGroups) || stats.Total != tt.wantTotal || !reflect.DeepEqual(stats.FailedShards, tt.wantFailed) {
+				t.Fatalf("groups %v, total %d, failed %v; want %v, %d, %v",
+					stats.Groups, stats.Total, stats.FailedShards, tt.wantGroups, tt.wantTotal, tt.wantFailed)
+			}
+		})
+	}
+}
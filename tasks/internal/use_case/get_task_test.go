@@ -0,0 +1,54 @@
+package use_case_test
+
+import (
+	"context"
+	"tasks/internal/domain"
+	"tasks/internal/infrastructure/session"
+	"tasks/internal/use_case"
+	"testing"
+)
+
+// replicaRepo serves reads as a replica would, unless the request wrote.
+type replicaRepo struct {
+	*fakeRepo
+}
+
+func (r replicaRepo) GetByID(ctx context.Context, taskID uint) (*domain.Task, error) {
+	if !session.Wrote(ctx) {
+		session.MarkReplicaRead(ctx)
+	}
+	return r.fakeRepo.GetByID(ctx, taskID)
+}
+
+func TestGetTaskCachesPrimaryReadsOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		wrote     bool
+		wantCache bool
+	}{
+		{name: "replica read", wrote: false, wantCache: false},
+		{name: "primary read after a write", wrote: true, wantCache: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := &fakeCache{}
+			uc := use_case.NewGetTask(replicaRepo{newFakeRepo(domain.Task{ID: 1, Title: "one"})}, cache, nil)
+			ctx := session.New(context.Background(), 7)
+			if tt.wrote {
+				session.MarkWrite(ctx)
+			}
+
+			task, err := uc.Execute(ctx, use_case.GetTaskCommand{ID: 1})
+			if err != nil || task.Title != "one" {
+				t.Fatalf("Execute = %+v, %v", task, err)
+			}
+			if _, err := cache.GetTask(ctx, 1); (err == nil) != tt.wantCache {
+				t.Fatalf("cached: %v, want %v", err == nil, tt.wantCache)
+			}
+			// the caller's own session is not marked by the shared read
+			if session.ReadReplica(ctx) {
+				t.Fatal("caller's session marked as a replica read")
+			}
+		})
+	}
+}
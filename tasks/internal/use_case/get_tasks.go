@@ -5,12 +5,15 @@ import (
 	"tasks/internal/domain"
 	"tasks/internal/domain/shard"
 	"tasks/internal/ports"
+	"time"
 )
 
 type GetTasks struct {
 	repo      ports.Repository
 	sharder   *shard.ShardManager
 	allocator ports.IDAllocator
+	views     ports.ViewRepository
+	projects  ports.ProjectAccess
 }
 
 // NewGetTasks constructs GetTasks use-case with its dependencies.
@@ -18,38 +21,146 @@ func NewGetTasks(
 	repo ports.Repository,
 	sharder *shard.ShardManager,
 	allocator ports.IDAllocator,
+	views ports.ViewRepository,
+	projects ports.ProjectAccess,
 ) *GetTasks {
 	return &GetTasks{
 		repo:      repo,
 		sharder:   sharder,
 		allocator: allocator,
+		views:     views,
+		projects:  projects,
 	}
 }
 
 type GetTasksCommand struct {
 	Title       string
+	Status      string
 	PerformerID uint
 	CreatorID   uint
+	ProjectID   uint
+	TenantID    uint
+	DueAfter    *time.Time
+	DueBefore   *time.Time
+	ExcludeDone bool
+	SortBy      string
+	SortDesc    bool
+	// ViewID runs a saved view; fields set on the command override the stored filter.
+	ViewID      string
+	RequesterID uint
 }
 
 // Execute returns tasks matching the command filter across all shards.
 func (uc *GetTasks) Execute(ctx context.Context, cmd GetTasksCommand) ([]domain.Task, error) {
+	if cmd.ViewID != "" {
+		var err error
+		if cmd, err = uc.applyView(ctx, cmd); err != nil {
+			return nil, err
+		}
+	}
+	if !validSortField(cmd.SortBy) {
+		return nil, ErrUnknownSortField
+	}
+
 	filter := ports.TaskFilter{
 		Title:       cmd.Title,
+		Status:      cmd.Status,
 		CreatorID:   cmd.CreatorID,
 		PerformerID: cmd.PerformerID,
+		ProjectID:   cmd.ProjectID,
+		TenantID:    cmd.TenantID,
+		DueAfter:    cmd.DueAfter,
+		DueBefore:   cmd.DueBefore,
+		ExcludeDone: cmd.ExcludeDone,
 	}
 
-	shardCount := uc.sharder.GetShardCount()
 	var all []domain.Task
-	for i := 0; i < shardCount; i++ {
-		tasks, err := uc.repo.Find(ctx, filter, i)
+	if filter.PerformerID != 0 {
+		// only the performer's shards (several if the performer is split)
+		tasks, err := uc.repo.Find(ctx, filter, -1)
 		if err != nil {
-			// skip shards that return an error (e.g., connection issues) but continue scanning others
-			continue
+			return nil, err
+		}
+		all = tasks
+	} else {
+		// a tenant's tasks are only on its shards when placement follows the tenant
+		indexes := uc.sharder.ShardIndexes()
+		if filter.TenantID != 0 {
+			indexes = uc.sharder.TenantShards(ctx, filter.TenantID)
+		}
+		for _, i := range indexes {
+			tasks, err := uc.repo.Find(ctx, filter, i)
+			if err != nil {
+				// skip shards that return an error (e.g., connection issues) but continue scanning others
+				continue
+			}
+			all = append(all, tasks...)
 		}
-		all = append(all, tasks...)
 	}
+	all = dedupeTasks(all)
 
+	if err := sortTasks(all, cmd.SortBy, cmd.SortDesc); err != nil {
+		return nil, err
+	}
 	return all, nil
 }
+
+// dedupeTasks keeps one row per task ID. While a rebalance moves a performer, their tasks
+// exist on both shards; the most recently updated copy is kept.
+func dedupeTasks(tasks []domain.Task) []domain.Task {
+	pos := make(map[uint]int, len(tasks))
+	out := tasks[:0]
+	for _, t := range tasks {
+		i, seen := pos[t.ID]
+		if !seen {
+			pos[t.ID] = len(out)
+			out = append(out, t)
+			continue
+		}
+		if t.UpdatedAt.After(out[i].UpdatedAt) {
+			out[i] = t
+		}
+	}
+	return out
+}
+
+func (uc *GetTasks) applyView(ctx context.Context, cmd GetTasksCommand) (GetTasksCommand, error) {
+	view, err := uc.views.GetByID(ctx, cmd.ViewID)
+	if err != nil {
+		return cmd, err
+	}
+	visible, err := canSeeView(ctx, uc.projects, view, cmd.RequesterID)
+	if err != nil {
+		return cmd, err
+	}
+	if !visible {
+		return cmd, domain.ErrViewNotFound
+	}
+
+	if cmd.Title == "" {
+		cmd.Title = view.Filter.Title
+	}
+	if cmd.Status == "" {
+		cmd.Status = view.Filter.Status
+	}
+	if cmd.PerformerID == 0 {
+		cmd.PerformerID = view.Filter.PerformerID
+	}
+	if cmd.CreatorID == 0 {
+		cmd.CreatorID = view.Filter.CreatorID
+	}
+	if cmd.ProjectID == 0 {
+		cmd.ProjectID = view.Filter.ProjectID
+	}
+	if cmd.DueAfter == nil {
+		cmd.DueAfter = view.Filter.DueAfter
+	}
+	if cmd.DueBefore == nil {
+		cmd.DueBefore = view.Filter.DueBefore
+	}
+	cmd.ExcludeDone = cmd.ExcludeDone || view.Filter.ExcludeDone
+	if cmd.SortBy == "" {
+		cmd.SortBy, cmd.SortDesc = view.SortBy, view.SortDesc
+	}
+	return cmd, nil
+}
@@ -0,0 +1,52 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+)
+
+type GetView struct {
+	views    ports.ViewRepository
+	projects ports.ProjectAccess
+}
+
+// NewGetView constructs GetView use-case with its dependencies.
+func NewGetView(views ports.ViewRepository, projects ports.ProjectAccess) *GetView {
+	return &GetView{views: views, projects: projects}
+}
+
+type GetViewCommand struct {
+	ID          string
+	RequesterID uint
+}
+
+// Execute returns the view if the requester may see it. Private views of other users
+// are reported as not found so their existence does not leak.
+func (uc *GetView) Execute(ctx context.Context, cmd GetViewCommand) (domain.View, error) {
+	view, err := uc.views.GetByID(ctx, cmd.ID)
+	if err != nil {
+		return domain.View{}, err
+	}
+	visible, err := canSeeView(ctx, uc.projects, view, cmd.RequesterID)
+	if err != nil {
+		return domain.View{}, err
+	}
+	if !visible {
+		return domain.View{}, domain.ErrViewNotFound
+	}
+	return *view, nil
+}
+
+// canSeeView reports whether userID may read the view. Project access is only looked up
+// for another user's project view.
+func canSeeView(ctx context.Context, projects ports.ProjectAccess, view *domain.View, userID uint) (bool, error) {
+	inProject := false
+	if view.OwnerID != userID && view.Visibility == domain.ViewProject {
+		var err error
+		if inProject, err = projects.CanSeeProject(ctx, userID, view.ProjectID); err != nil {
+			return false, err
+		}
+	}
+	return view.VisibleTo(userID, inProject), nil
+}
@@ -0,0 +1,75 @@
+package use_case
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	ErrImportTitleRequired   = errors.New("title is required")
+	ErrImportTitleTooLong    = errors.New("title exceeds 255 characters")
+	ErrImportCreatorRequired = errors.New("creator_id is required")
+	ErrImportStatusTooLong   = errors.New("status exceeds 50 characters")
+)
+
+type ImportTasks struct {
+	create *CreateTask
+}
+
+// NewImportTasks constructs ImportTasks use-case on top of CreateTask.
+func NewImportTasks(create *CreateTask) *ImportTasks {
+	return &ImportTasks{create: create}
+}
+
+// ImportTaskRecord is a single row of an import; ExternalID identifies the row in the source file.
+type ImportTaskRecord struct {
+	ExternalID string
+	Task       CreateTaskCommand
+}
+
+type ImportTaskResult struct {
+	ExternalID string
+	TaskID     uint
+	Err        error
+}
+
+// Validate checks a record against the constraints of the tasks table.
+func (uc *ImportTasks) Validate(cmd CreateTaskCommand) error {
+	title := strings.TrimSpace(cmd.Title)
+	if title == "" {
+		return ErrImportTitleRequired
+	}
+	if len([]rune(title)) > 255 {
+		return ErrImportTitleTooLong
+	}
+	if cmd.CreatorID == 0 {
+		return ErrImportCreatorRequired
+	}
+	if len([]rune(cmd.Status)) > 50 {
+		return ErrImportStatusTooLong
+	}
+	return nil
+}
+
+// Execute validates the record and, unless dryRun is set, creates the task.
+// Errors are reported per record so that one bad row does not abort the whole import.
+func (uc *ImportTasks) Execute(ctx context.Context, rec ImportTaskRecord, dryRun bool) ImportTaskResult {
+	result := ImportTaskResult{ExternalID: rec.ExternalID}
+
+	if err := uc.Validate(rec.Task); err != nil {
+		result.Err = err
+		return result
+	}
+	if dryRun {
+		return result
+	}
+
+	task, err := uc.create.Execute(ctx, rec.Task)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.TaskID = task.ID
+	return result
+}
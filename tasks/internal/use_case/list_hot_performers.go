@@ -0,0 +1,44 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+// DefaultHotThresholds flag a performer with a tenth of a shard's tasks (at least 1000)
+// or a tenth of its requests (at least 600 a minute).
+var DefaultHotThresholds = shard.HotThresholds{
+	TaskShare:    0.1,
+	MinTasks:     1000,
+	RequestShare: 0.1,
+	MinRequests:  600,
+	Limit:        20,
+}
+
+type ListHotPerformers struct {
+	sharder *shard.ShardManager
+}
+
+func NewListHotPerformers(sharder *shard.ShardManager) *ListHotPerformers {
+	return &ListHotPerformers{sharder: sharder}
+}
+
+// Execute reports hot performers; zero fields of th take their DefaultHotThresholds value.
+func (uc *ListHotPerformers) Execute(ctx context.Context, th shard.HotThresholds) ([]shard.HotPerformer, error) {
+	if th.TaskShare <= 0 {
+		th.TaskShare = DefaultHotThresholds.TaskShare
+	}
+	if th.MinTasks <= 0 {
+		th.MinTasks = DefaultHotThresholds.MinTasks
+	}
+	if th.RequestShare <= 0 {
+		th.RequestShare = DefaultHotThresholds.RequestShare
+	}
+	if th.MinRequests <= 0 {
+		th.MinRequests = DefaultHotThresholds.MinRequests
+	}
+	if th.Limit <= 0 {
+		th.Limit = DefaultHotThresholds.Limit
+	}
+	return uc.sharder.HotPerformers(ctx, th)
+}
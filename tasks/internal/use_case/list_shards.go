@@ -0,0 +1,19 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type ListShards struct {
+	sharder *shard.ShardManager
+}
+
+func NewListShards(sharder *shard.ShardManager) *ListShards {
+	return &ListShards{sharder: sharder}
+}
+
+// Execute returns the topology and the read replicas' last lag checks as seen by this replica.
+func (uc *ListShards) Execute(ctx context.Context) (shard.Topology, []shard.ReplicaStatus) {
+	return uc.sharder.Topology(), uc.sharder.ReplicaStatuses()
+}
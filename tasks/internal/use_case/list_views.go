@@ -0,0 +1,38 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+)
+
+type ListViews struct {
+	views    ports.ViewRepository
+	projects ports.ProjectAccess
+}
+
+// NewListViews constructs ListViews use-case with its dependencies.
+func NewListViews(views ports.ViewRepository, projects ports.ProjectAccess) *ListViews {
+	return &ListViews{views: views, projects: projects}
+}
+
+type ListViewsCommand struct {
+	RequesterID uint
+	ProjectID   uint
+}
+
+// Execute returns the requester's own views plus views shared with ProjectID, if the
+// requester can see that project.
+func (uc *ListViews) Execute(ctx context.Context, cmd ListViewsCommand) ([]domain.View, error) {
+	filter := ports.ViewListFilter{OwnerID: cmd.RequesterID}
+	if cmd.ProjectID != 0 {
+		inProject, err := uc.projects.CanSeeProject(ctx, cmd.RequesterID, cmd.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if inProject {
+			filter.ProjectID = cmd.ProjectID
+		}
+	}
+	return uc.views.List(ctx, filter)
+}
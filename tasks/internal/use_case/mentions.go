@@ -0,0 +1,29 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+	"tasks/logger"
+)
+
+// resolveMentions is best-effort: an unreachable auth service must not block task writes.
+// ok is false when there is no resolver or it failed; an edit then keeps the mentions the
+// task already has, so it neither drops them nor notifies them again once resolution works.
+func resolveMentions(ctx context.Context, resolver ports.MentionResolver, text string) (ids []uint, ok bool) {
+	if resolver == nil {
+		return nil, false
+	}
+	ids, err := resolver.Resolve(ctx, text)
+	if err != nil {
+		logger.Warn(ctx, "mention resolution failed", logger.ZapError(err))
+		return nil, false
+	}
+	return ids, true
+}
+
+func publishMentions(ctx context.Context, producer ports.EventProducer, task domain.Task, userIDs []uint) {
+	for _, userID := range userIDs {
+		_ = producer.PublishMentioned(ctx, task, userID)
+	}
+}
@@ -0,0 +1,19 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type RebuildDirectory struct {
+	sharder *shard.ShardManager
+}
+
+func NewRebuildDirectory(sharder *shard.ShardManager) *RebuildDirectory {
+	return &RebuildDirectory{sharder: sharder}
+}
+
+// Execute repopulates the task->shard directory from the rows on the shards.
+func (uc *RebuildDirectory) Execute(ctx context.Context) (shard.RebuildReport, error) {
+	return uc.sharder.RebuildDirectory(ctx)
+}
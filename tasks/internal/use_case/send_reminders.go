@@ -0,0 +1,74 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain"
+	"tasks/internal/domain/shard"
+	"tasks/internal/ports"
+	"tasks/logger"
+	"time"
+)
+
+// SendReminders scans every shard for tasks near or past their due date and publishes
+// TaskDueSoon/TaskOverdue events. Register RunOnce as a leader job so only one replica scans;
+// each reminder is also claimed in the durable store before it is published, so it is sent once.
+type SendReminders struct {
+	store    ports.ReminderStore
+	producer ports.EventProducer
+	sharder  *shard.ShardManager
+	policy   domain.ReminderPolicy
+}
+
+func NewSendReminders(store ports.ReminderStore, producer ports.EventProducer, sharder *shard.ShardManager, policy domain.ReminderPolicy) *SendReminders {
+	return &SendReminders{store: store, producer: producer, sharder: sharder, policy: policy}
+}
+
+// Execute runs one scan and returns the number of reminders sent.
+// A failing shard is logged and skipped so the others still get their reminders.
+func (uc *SendReminders) Execute(ctx context.Context, now time.Time) (int, error) {
+	from, to := uc.policy.Window(now)
+	sent := 0
+	// a task being moved by a rebalance is on two shards; remind it from the first one only
+	seen := make(map[uint]bool)
+	for _, i := range uc.sharder.ShardIndexes() {
+		if ctx.Err() != nil {
+			// the leader lease was lost or the service is stopping
+			return sent, ctx.Err()
+		}
+		tasks, err := uc.store.DueTasks(ctx, i, from, to)
+		if err != nil {
+			logger.Warn(ctx, "reminders: scan shard failed", logger.ZapInt("shard", i), logger.ZapError(err))
+			continue
+		}
+		for _, task := range tasks {
+			if seen[task.ID] {
+				continue
+			}
+			seen[task.ID] = true
+			for _, r := range uc.policy.Due(task, now) {
+				ok, err := uc.store.Claim(ctx, i, r)
+				if err != nil {
+					logger.Warn(ctx, "reminders: claim failed", logger.ZapUint("task_id", r.TaskID), logger.ZapError(err))
+					continue
+				}
+				if !ok {
+					continue
+				}
+				if err := uc.producer.PublishReminder(ctx, task, r); err != nil {
+					logger.Warn(ctx, "reminders: publish failed", logger.ZapUint("task_id", r.TaskID), logger.ZapError(err))
+					// let the next scan retry it
+					_ = uc.store.Release(ctx, i, r)
+					continue
+				}
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// RunOnce runs one scan at the current time; it has the signature of leader.Job.Run.
+func (uc *SendReminders) RunOnce(ctx context.Context) error {
+	_, err := uc.Execute(ctx, time.Now())
+	return err
+}
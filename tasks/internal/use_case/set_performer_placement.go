@@ -0,0 +1,31 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type SetPerformerPlacement struct {
+	sharder *shard.ShardManager
+}
+
+func NewSetPerformerPlacement(sharder *shard.ShardManager) *SetPerformerPlacement {
+	return &SetPerformerPlacement{sharder: sharder}
+}
+
+type SetPerformerPlacementResult struct {
+	Topology         shard.Topology
+	RebalanceStarted bool
+}
+
+// Execute publishes the override and starts a rebalance that moves the performer's
+// tasks to their new shards.
+func (uc *SetPerformerPlacement) Execute(ctx context.Context, o shard.PerformerOverride) (SetPerformerPlacementResult, error) {
+	topo, err := uc.sharder.SetPerformerOverride(ctx, o)
+	if err != nil {
+		return SetPerformerPlacementResult{}, err
+	}
+
+	started := shard.StartRebalance(context.WithoutCancel(ctx))
+	return SetPerformerPlacementResult{Topology: topo, RebalanceStarted: started}, nil
+}
@@ -0,0 +1,31 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type SetShardKey struct {
+	sharder *shard.ShardManager
+}
+
+func NewSetShardKey(sharder *shard.ShardManager) *SetShardKey {
+	return &SetShardKey{sharder: sharder}
+}
+
+type SetShardKeyResult struct {
+	Topology         shard.Topology
+	RebalanceStarted bool
+}
+
+// Execute publishes the new shard key and starts a rebalance that moves every task to
+// its shard under the new key.
+func (uc *SetShardKey) Execute(ctx context.Context, key shard.ShardKey) (SetShardKeyResult, error) {
+	topo, err := uc.sharder.SetShardKey(ctx, key)
+	if err != nil {
+		return SetShardKeyResult{}, err
+	}
+
+	started := shard.StartRebalance(context.WithoutCancel(ctx))
+	return SetShardKeyResult{Topology: topo, RebalanceStarted: started}, nil
+}
@@ -0,0 +1,32 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type SetShardWeight struct {
+	sharder *shard.ShardManager
+}
+
+func NewSetShardWeight(sharder *shard.ShardManager) *SetShardWeight {
+	return &SetShardWeight{sharder: sharder}
+}
+
+type SetShardWeightResult struct {
+	Shard            shard.ShardInfo
+	Topology         shard.Topology
+	RebalanceStarted bool
+}
+
+// Execute publishes the new weight and starts a rebalance that moves the performers
+// whose ring position changed.
+func (uc *SetShardWeight) Execute(ctx context.Context, id string, weight float64) (SetShardWeightResult, error) {
+	info, topo, err := uc.sharder.SetShardWeight(ctx, id, weight)
+	if err != nil {
+		return SetShardWeightResult{}, err
+	}
+
+	started := shard.StartRebalance(context.WithoutCancel(ctx))
+	return SetShardWeightResult{Shard: info, Topology: topo, RebalanceStarted: started}, nil
+}
@@ -0,0 +1,31 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type SetTenantPlacement struct {
+	sharder *shard.ShardManager
+}
+
+func NewSetTenantPlacement(sharder *shard.ShardManager) *SetTenantPlacement {
+	return &SetTenantPlacement{sharder: sharder}
+}
+
+type SetTenantPlacementResult struct {
+	Topology         shard.Topology
+	RebalanceStarted bool
+}
+
+// Execute publishes the placement and starts a rebalance that moves the tenant's tasks
+// onto its dedicated shards, and the other tenants' tasks off them.
+func (uc *SetTenantPlacement) Execute(ctx context.Context, p shard.TenantPlacement) (SetTenantPlacementResult, error) {
+	topo, err := uc.sharder.SetTenantPlacement(ctx, p)
+	if err != nil {
+		return SetTenantPlacementResult{}, err
+	}
+
+	started := shard.StartRebalance(context.WithoutCancel(ctx))
+	return SetTenantPlacementResult{Topology: topo, RebalanceStarted: started}, nil
+}
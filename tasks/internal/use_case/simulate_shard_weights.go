@@ -0,0 +1,19 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type SimulateShardWeights struct {
+	sharder *shard.ShardManager
+}
+
+func NewSimulateShardWeights(sharder *shard.ShardManager) *SimulateShardWeights {
+	return &SimulateShardWeights{sharder: sharder}
+}
+
+// Execute reports what applying weights (shard ID -> weight) would do to the ring.
+func (uc *SimulateShardWeights) Execute(ctx context.Context, weights map[string]float64) (shard.RingSimulation, error) {
+	return uc.sharder.SimulateWeights(ctx, weights)
+}
@@ -0,0 +1,52 @@
+package use_case
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"tasks/internal/domain"
+)
+
+var ErrUnknownSortField = errors.New("unknown sort field")
+
+// taskLess holds the "less" function for every sortable field.
+var taskLess = map[string]func(a, b *domain.Task) bool{
+	"id":           func(a, b *domain.Task) bool { return a.ID < b.ID },
+	"title":        func(a, b *domain.Task) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) },
+	"status":       func(a, b *domain.Task) bool { return a.Status < b.Status },
+	"performer_id": func(a, b *domain.Task) bool { return a.PerformerId < b.PerformerId },
+	"creator_id":   func(a, b *domain.Task) bool { return a.CreatorId < b.CreatorId },
+	"project_id":   func(a, b *domain.Task) bool { return a.ProjectId < b.ProjectId },
+	"created_at":   func(a, b *domain.Task) bool { return a.CreatedAt.Before(b.CreatedAt) },
+	"updated_at":   func(a, b *domain.Task) bool { return a.UpdatedAt.Before(b.UpdatedAt) },
+}
+
+func validSortField(field string) bool {
+	_, ok := taskLess[field]
+	return field == "" || ok
+}
+
+// sortTasks orders tasks merged from several shards; ties keep ID order for stable pages.
+func sortTasks(tasks []domain.Task, by string, desc bool) error {
+	if by == "" {
+		return nil
+	}
+	less, ok := taskLess[by]
+	if !ok {
+		return ErrUnknownSortField
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := &tasks[i], &tasks[j]
+		if desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return nil
+}
@@ -4,20 +4,24 @@ import (
 	"context"
 	"tasks/internal/domain"
 	"tasks/internal/ports"
+	"time"
 )
 
 type UpdateTask struct {
 	repo     ports.Repository
 	producer ports.EventProducer
+	mentions ports.MentionResolver
 }
 
 func NewUpdateTask(
 	repo ports.Repository,
 	producer ports.EventProducer,
+	mentions ports.MentionResolver,
 ) *UpdateTask {
 	return &UpdateTask{
 		repo:     repo,
 		producer: producer,
+		mentions: mentions,
 	}
 }
 
@@ -28,7 +32,10 @@ type UpdateTaskCommand struct {
 	Status      string
 	PerformerID uint
 	CreatorID   uint
+	ProjectID   uint
 	ObserverIDs []uint64
+	Labels      []string
+	DueAt       *time.Time
 }
 
 func (uc *UpdateTask) Execute(ctx context.Context, cmd UpdateTaskCommand) (domain.Task, error) {
@@ -39,7 +46,21 @@ func (uc *UpdateTask) Execute(ctx context.Context, cmd UpdateTaskCommand) (domai
 		Status:      cmd.Status,
 		PerformerID: cmd.PerformerID,
 		CreatorID:   cmd.CreatorID,
+		ProjectID:   cmd.ProjectID,
 		ObserverIDs: uint64SliceToUint(cmd.ObserverIDs),
+		Labels:      domain.NormalizeLabels(cmd.Labels),
+		DueAt:       cmd.DueAt,
+	}
+
+	// only users newly mentioned by this edit are notified
+	var previous []uint
+	if uc.mentions != nil {
+		if current, err := uc.repo.GetByID(ctx, input.ID); err == nil {
+			previous = current.Mentions
+		}
+		if ids, ok := resolveMentions(ctx, uc.mentions, cmd.Description); ok {
+			input.MentionIDs = &ids
+		}
 	}
 
 	task, err := uc.repo.Update(ctx, input)
@@ -49,6 +70,9 @@ func (uc *UpdateTask) Execute(ctx context.Context, cmd UpdateTaskCommand) (domai
 
 	if uc.producer != nil {
 		_ = uc.producer.PublishUpdated(ctx, *task)
+		if input.MentionIDs != nil {
+			publishMentions(ctx, uc.producer, *task, domain.NewMentions(previous, task.Mentions))
+		}
 	}
 
 	return *task, nil
@@ -0,0 +1,43 @@
+package use_case_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+	"tasks/internal/use_case"
+	"testing"
+)
+
+func TestUpdateTaskMentions(t *testing.T) {
+	tests := []struct {
+		name          string
+		mentions      ports.MentionResolver
+		wantMentions  []uint
+		wantMentioned []uint
+	}{
+		{name: "no resolver keeps mentions", mentions: nil, wantMentions: []uint{5}},
+		{name: "failed resolver keeps mentions", mentions: fakeMentions{err: errors.New("auth down")}, wantMentions: []uint{5}},
+		{name: "resolved", mentions: fakeMentions{ids: []uint{5, 6}}, wantMentions: []uint{5, 6}, wantMentioned: []uint{6}},
+		{name: "resolved to none", mentions: fakeMentions{ids: []uint{}}, wantMentions: []uint{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo(domain.Task{ID: 1, Description: "ask @ann", Mentions: []uint{5}})
+			producer := &fakeProducer{}
+			uc := use_case.NewUpdateTask(repo, producer, tt.mentions)
+
+			task, err := uc.Execute(context.Background(), use_case.UpdateTaskCommand{ID: 1, Title: "edited", Description: "ask @ann and @bob"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(task.Mentions, tt.wantMentions) {
+				t.Fatalf("mentions %v, want %v", task.Mentions, tt.wantMentions)
+			}
+			if !reflect.DeepEqual(producer.mentioned, tt.wantMentioned) {
+				t.Fatalf("announced %v, want %v", producer.mentioned, tt.wantMentioned)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package use_case
+
+import (
+	"context"
+	"strings"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+	"time"
+)
+
+type UpdateView struct {
+	views    ports.ViewRepository
+	projects ports.ProjectAccess
+}
+
+// NewUpdateView constructs UpdateView use-case with its dependencies.
+func NewUpdateView(views ports.ViewRepository, projects ports.ProjectAccess) *UpdateView {
+	return &UpdateView{views: views, projects: projects}
+}
+
+type UpdateViewCommand struct {
+	ID          string
+	RequesterID uint
+	Name        string
+	Filter      domain.ViewFilter
+	SortBy      string
+	SortDesc    bool
+	Columns     []string
+	Visibility  string
+	ProjectID   uint
+}
+
+// Execute replaces the view definition. Only the owner may change a view.
+func (uc *UpdateView) Execute(ctx context.Context, cmd UpdateViewCommand) (domain.View, error) {
+	view, err := uc.views.GetByID(ctx, cmd.ID)
+	if err != nil {
+		return domain.View{}, err
+	}
+	if view.OwnerID != cmd.RequesterID {
+		visible, err := canSeeView(ctx, uc.projects, view, cmd.RequesterID)
+		if err != nil {
+			return domain.View{}, err
+		}
+		if !visible {
+			return domain.View{}, domain.ErrViewNotFound
+		}
+		return domain.View{}, domain.ErrViewForbidden
+	}
+
+	view.Name = strings.TrimSpace(cmd.Name)
+	view.Filter = cmd.Filter
+	view.SortBy = cmd.SortBy
+	view.SortDesc = cmd.SortDesc
+	view.Columns = cmd.Columns
+	view.Visibility = cmd.Visibility
+	view.ProjectID = cmd.ProjectID
+	view.UpdatedAt = time.Now()
+	if view.Visibility == "" {
+		view.Visibility = domain.ViewPrivate
+	}
+	if err := validateView(*view); err != nil {
+		return domain.View{}, err
+	}
+
+	if err := uc.views.Update(ctx, *view); err != nil {
+		return domain.View{}, err
+	}
+	return *view, nil
+}
@@ -0,0 +1,19 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain/shard"
+)
+
+type VerifyShards struct {
+	sharder *shard.ShardManager
+}
+
+func NewVerifyShards(sharder *shard.ShardManager) *VerifyShards {
+	return &VerifyShards{sharder: sharder}
+}
+
+// Execute checks tasks, observers and task->shard mappings for drift; with repair it fixes them.
+func (uc *VerifyShards) Execute(ctx context.Context, repair bool) (shard.VerifyReport, error) {
+	return uc.sharder.Verify(ctx, repair)
+}
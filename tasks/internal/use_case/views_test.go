@@ -0,0 +1,132 @@
+package use_case_test
+
+import (
+	"context"
+	"errors"
+	"tasks/internal/domain"
+	"tasks/internal/use_case"
+	"testing"
+	"time"
+)
+
+var (
+	weekStart = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	weekEnd   = weekStart.AddDate(0, 0, 7)
+)
+
+// newViewFixture holds a private view and a view shared with project 5, both owned by
+// user 1. User 2 can see project 5, user 3 cannot.
+func newViewFixture() (*fakeViews, fakeProjects) {
+	views := newFakeViews(
+		domain.View{ID: "private", OwnerID: 1, Visibility: domain.ViewPrivate,
+			Filter: domain.ViewFilter{PerformerID: 1, ExcludeDone: true}},
+		domain.View{ID: "shared", OwnerID: 1, Visibility: domain.ViewProject, ProjectID: 5,
+			Filter: domain.ViewFilter{PerformerID: 1, ProjectID: 5, DueAfter: &weekStart, DueBefore: &weekEnd, ExcludeDone: true}, SortBy: "title"},
+	)
+	return views, fakeProjects{2: {5}}
+}
+
+func TestGetTasksAppliesView(t *testing.T) {
+	views, projects := newViewFixture()
+	override := weekStart.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name string
+		cmd  use_case.GetTasksCommand
+		// wantBefore is the due_before the repository is asked for
+		wantBefore time.Time
+	}{
+		{name: "stored filter", cmd: use_case.GetTasksCommand{ViewID: "shared", RequesterID: 1}, wantBefore: weekEnd},
+		{name: "request overrides", cmd: use_case.GetTasksCommand{ViewID: "shared", RequesterID: 2, DueBefore: &override}, wantBefore: override},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			uc := use_case.NewGetTasks(repo, nil, nil, views, projects)
+			if _, err := uc.Execute(context.Background(), tt.cmd); err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.filters) != 1 {
+				t.Fatalf("%d Find calls, want 1", len(repo.filters))
+			}
+			f := repo.filters[0]
+			if f.PerformerID != 1 || f.ProjectID != 5 || !f.ExcludeDone {
+				t.Fatalf("filter %+v does not hold the stored performer, project and exclude_done", f)
+			}
+			if f.DueAfter == nil || !f.DueAfter.Equal(weekStart) || f.DueBefore == nil || !f.DueBefore.Equal(tt.wantBefore) {
+				t.Fatalf("due window %v..%v, want %v..%v", f.DueAfter, f.DueBefore, weekStart, tt.wantBefore)
+			}
+		})
+	}
+}
+
+func TestViewAccess(t *testing.T) {
+	tests := []struct {
+		name      string
+		viewID    string
+		requester uint
+		wantRead  error
+		wantWrite error
+	}{
+		{name: "owner", viewID: "shared", requester: 1},
+		{name: "project member", viewID: "shared", requester: 2, wantWrite: domain.ErrViewForbidden},
+		{name: "outsider", viewID: "shared", requester: 3, wantRead: domain.ErrViewNotFound, wantWrite: domain.ErrViewNotFound},
+		{name: "project member on a private view", viewID: "private", requester: 2, wantRead: domain.ErrViewNotFound, wantWrite: domain.ErrViewNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			views, projects := newViewFixture()
+			ctx := context.Background()
+
+			_, err := use_case.NewGetView(views, projects).Execute(ctx, use_case.GetViewCommand{ID: tt.viewID, RequesterID: tt.requester})
+			if !errors.Is(err, tt.wantRead) {
+				t.Fatalf("GetView: err = %v, want %v", err, tt.wantRead)
+			}
+			_, err = use_case.NewGetTasks(newFakeRepo(), nil, nil, views, projects).
+				Execute(ctx, use_case.GetTasksCommand{ViewID: tt.viewID, RequesterID: tt.requester})
+			if !errors.Is(err, tt.wantRead) {
+				t.Fatalf("GetTasks: err = %v, want %v", err, tt.wantRead)
+			}
+			_, err = use_case.NewUpdateView(views, projects).
+				Execute(ctx, use_case.UpdateViewCommand{ID: tt.viewID, RequesterID: tt.requester, Name: "renamed"})
+			if !errors.Is(err, tt.wantWrite) {
+				t.Fatalf("UpdateView: err = %v, want %v", err, tt.wantWrite)
+			}
+			err = use_case.NewDeleteView(views, projects).Execute(ctx, use_case.DeleteViewCommand{ID: tt.viewID, RequesterID: tt.requester})
+			if !errors.Is(err, tt.wantWrite) {
+				t.Fatalf("DeleteView: err = %v, want %v", err, tt.wantWrite)
+			}
+		})
+	}
+}
+
+func TestListViewsScopesProjectViews(t *testing.T) {
+	views, projects := newViewFixture()
+	views.views["other"] = domain.View{ID: "other", OwnerID: 3, Visibility: domain.ViewPrivate}
+
+	tests := []struct {
+		name      string
+		requester uint
+		want      int
+	}{
+		{name: "project member", requester: 2, want: 1},
+		{name: "outsider sees only own views", requester: 3, want: 1},
+		{name: "owner", requester: 1, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := use_case.NewListViews(views, projects).Execute(context.Background(), use_case.ListViewsCommand{RequesterID: tt.requester, ProjectID: 5})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("%d views, want %d: %+v", len(got), tt.want, got)
+			}
+			for _, v := range got {
+				if v.OwnerID != tt.requester && v.ID != "shared" {
+					t.Fatalf("listed %q of user %d", v.ID, v.OwnerID)
+				}
+			}
+		})
+	}
+}
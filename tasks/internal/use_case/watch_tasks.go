@@ -0,0 +1,48 @@
+package use_case
+
+import (
+	"context"
+	"tasks/internal/domain"
+	"tasks/internal/ports"
+)
+
+type WatchTasks struct {
+	watcher ports.TaskWatcher
+}
+
+// NewWatchTasks constructs WatchTasks use-case with its dependencies.
+func NewWatchTasks(watcher ports.TaskWatcher) *WatchTasks {
+	return &WatchTasks{watcher: watcher}
+}
+
+type WatchTasksCommand struct {
+	Filter       ports.WatchFilter
+	FromSequence uint64
+}
+
+// Execute emits matching changes until ctx is cancelled or the subscription ends.
+// Non-matching changes are skipped here rather than in the hub so one hub serves every filter.
+func (uc *WatchTasks) Execute(ctx context.Context, cmd WatchTasksCommand, emit func(domain.TaskChange) error) error {
+	sub, err := uc.watcher.Subscribe(cmd.FromSequence)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-sub.Changes():
+			if !ok {
+				return sub.Err()
+			}
+			if !cmd.Filter.Matches(change.Task) {
+				continue
+			}
+			if err := emit(change); err != nil {
+				return err
+			}
+		}
+	}
+}
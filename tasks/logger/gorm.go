@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Gorm writes GORM's logs to the zap logger, with the request ID of the query's context.
+// Failed queries are logged as errors and queries slower than SlowThreshold as warnings;
+// at the Info level every query is logged at debug. Queries are logged with placeholders,
+// never with their parameters.
+type Gorm struct {
+	Level         gormlogger.LogLevel
+	SlowThreshold time.Duration // 0 disables slow-query logging
+	fields        []zap.Field
+}
+
+// NewGorm returns a GORM logger whose entries carry fields, e.g. the shard.
+func NewGorm(level gormlogger.LogLevel, slowThreshold time.Duration, fields ...zap.Field) *Gorm {
+	return &Gorm{Level: level, SlowThreshold: slowThreshold, fields: fields}
+}
+
+// ParseGormLevel parses silent, error, warn or info; empty means warn.
+func ParseGormLevel(s string) (gormlogger.LogLevel, error) {
+	switch s {
+	case "silent":
+		return gormlogger.Silent, nil
+	case "error":
+		return gormlogger.Error, nil
+	case "", "warn":
+		return gormlogger.Warn, nil
+	case "info":
+		return gormlogger.Info, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q; want silent, error, warn or info", s)
+}
+
+func (g *Gorm) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	c := *g
+	c.Level = level
+	return &c
+}
+
+func (g *Gorm) logger(ctx context.Context) *zap.Logger {
+	return base().With(g.fields...).With(extractRequestID(ctx)...)
+}
+
+func (g *Gorm) Info(ctx context.Context, msg string, args ...interface{}) {
+	if g.Level >= gormlogger.Info {
+		g.logger(ctx).Info(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *Gorm) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if g.Level >= gormlogger.Warn {
+		g.logger(ctx).Warn(fmt.Sprintf(msg, args...))
+	}
+}
+
+func (g *Gorm) Error(ctx context.Context, msg string, args ...interface{}) {
+	if g.Level >= gormlogger.Error {
+		g.logger(ctx).Error(fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace logs a finished query. Not-found errors are expected and not logged.
+func (g *Gorm) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if g.Level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	var level zapcore.Level
+	msg, extra := "query", zap.Skip()
+	switch {
+	case err != nil && !errors.Is(err, gormlogger.ErrRecordNotFound) && g.Level >= gormlogger.Error:
+		level, msg, extra = zap.ErrorLevel, "query failed", zap.Error(err)
+	case g.SlowThreshold > 0 && elapsed > g.SlowThreshold && g.Level >= gormlogger.Warn:
+		level, msg, extra = zap.WarnLevel, "slow query", zap.Duration("threshold", g.SlowThreshold)
+	case g.Level >= gormlogger.Info:
+		level = zap.DebugLevel
+	default:
+		return
+	}
+	sql, rows := fc()
+	g.logger(ctx).Log(level, msg, zap.String("sql", sql), zap.Duration("elapsed", elapsed), zap.Int64("rows", rows), extra)
+}
+
+// ParamsFilter keeps query parameters, which may hold task contents, out of the logs.
+func (g *Gorm) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
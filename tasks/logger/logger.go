@@ -8,28 +8,51 @@ import (
 
 var log *zap.Logger
 
+type contextKey struct{}
+
 func Init() {
 	log, _ = zap.NewProduction()
 }
 
+// WithRequestID returns ctx carrying the request ID that log entries of the request include.
+func WithRequestID(ctx context.Context, reqID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, reqID)
+}
+
+// RequestID returns the request ID of ctx, or "".
+func RequestID(ctx context.Context) string {
+	reqID, _ := ctx.Value(contextKey{}).(string)
+	return reqID
+}
+
+// base returns the logger, or zap's global one (a no-op by default) before Init.
+func base() *zap.Logger {
+	if log == nil {
+		return zap.L()
+	}
+	return log
+}
+
 func Info(ctx context.Context, msg string, fields ...zap.Field) {
-	log.With(extractRequestID(ctx)...).Info(msg, fields...)
+	base().With(extractRequestID(ctx)...).Info(msg, fields...)
 }
 
 func Warn(ctx context.Context, msg string, fields ...zap.Field) {
-	log.With(extractRequestID(ctx)...).Warn(msg, fields...)
+	base().With(extractRequestID(ctx)...).Warn(msg, fields...)
 }
 
 func Error(ctx context.Context, msg string, err error, fields ...zap.Field) {
-	log.With(extractRequestID(ctx)...).Error(msg, append(fields, zap.Error(err))...)
+	base().With(extractRequestID(ctx)...).Error(msg, append(fields, zap.Error(err))...)
 }
 
-// ZapUint and ZapError are helpers to create zap fields for use outside the logger package
+// ZapUint, ZapInt, ZapString and ZapError are helpers to create zap fields for use outside the logger package
 func ZapUint(key string, v uint) zap.Field { return zap.Uint64(key, uint64(v)) }
+func ZapInt(key string, v int) zap.Field   { return zap.Int(key, v) }
+func ZapString(key, v string) zap.Field    { return zap.String(key, v) }
 func ZapError(err error) zap.Field         { return zap.Error(err) }
 
 func extractRequestID(ctx context.Context) []zap.Field {
-	if reqID, ok := ctx.Value("requestID").(string); ok {
+	if reqID := RequestID(ctx); reqID != "" {
 		return []zap.Field{zap.String("request_id", reqID)}
 	}
 	return nil